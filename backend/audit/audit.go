@@ -0,0 +1,231 @@
+// Package audit keeps an append-only record of the actions taken by users and
+// by the agent on their behalf. Every event stores the hash of the previous
+// one, so editing, reordering or removing events breaks the chain.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+)
+
+// Actors of events without a user
+const (
+	// ActorAgent is the agent of a flow, acting on behalf of the flow's owner
+	ActorAgent = "agent"
+	// ActorAPIKey is a client authenticated with the global API_KEY
+	ActorAPIKey = "api-key"
+	// ActorAnonymous is a client of a server without authentication
+	ActorAnonymous = "anonymous"
+)
+
+// Actions recorded in the log
+const (
+	ActionLogin                = "auth.login"
+	ActionFlowCreated          = "flow.created"
+	ActionFlowFinished         = "flow.finished"
+	ActionFlowCheckpoint       = "flow.checkpointed"
+	ActionFlowRolledBack       = "flow.rolled_back"
+	ActionFlowForked           = "flow.forked"
+	ActionFlowShared           = "flow.shared"
+	ActionTaskCreated          = "task.created"
+	ActionToolCalled           = "tool.called"
+	ActionCommandExecuted      = "command.executed"
+	ActionCommandDenied        = "command.denied"
+	ActionFileWritten          = "file.written"
+	ActionURLFetched           = "url.fetched"
+	ActionContentFlagged       = "content.flagged"
+	ActionApprovalRequested    = "approval.requested"
+	ActionTaskApproved         = "task.approved"
+	ActionTaskRejected         = "task.rejected"
+	ActionMCPToolCalled        = "mcp.tool_called"
+	ActionUserCreated          = "user.created"
+	ActionPasswordChanged      = "user.password_changed"
+	ActionTokenCreated         = "token.created"
+	ActionTokenRotated         = "token.rotated"
+	ActionTokenRevoked         = "token.revoked"
+	ActionTeamCreated          = "team.created"
+	ActionTeamDeleted          = "team.deleted"
+	ActionTeamMemberSet        = "team.member_set"
+	ActionTeamMemberRemoved    = "team.member_removed"
+	ActionSecretSet            = "secret.set"
+	ActionSecretDeleted        = "secret.deleted"
+	ActionCommandPolicySet     = "command_policy.set"
+	ActionCommandPolicyDeleted = "command_policy.deleted"
+)
+
+// GenesisHash is the previous hash of the first event
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Event is an action to record
+type Event struct {
+	Action string
+	FlowID int64
+	TaskID int64
+	// Target is what the action was applied to: a command, a path, a URL...
+	Target  string
+	Details map[string]any
+	// Err is the error the action failed with, if any
+	Err error
+}
+
+// appendMu serializes appends, so every event chains to the one before it
+var appendMu sync.Mutex
+
+// Record appends an event done by the user of the request
+// Failures are logged: auditing never blocks the action itself
+func Record(ctx context.Context, db *database.Queries, event Event) {
+	userID, actor := actorFromContext(ctx)
+	record(ctx, db, userID, actor, event)
+}
+
+// RecordAgent appends an event done by the agent of a flow, on behalf of the
+// flow's owner
+func RecordAgent(db *database.Queries, event Event) {
+	if db == nil {
+		return
+	}
+	ctx := context.Background()
+
+	var owner sql.NullInt64
+	if event.FlowID != 0 {
+		var err error
+		if owner, err = db.ReadFlowOwner(ctx, event.FlowID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logging.Warn("Failed to read flow owner for audit event", "flow_id", event.FlowID, "error", err.Error())
+		}
+	}
+	record(ctx, db, owner, ActorAgent, event)
+}
+
+func record(ctx context.Context, db *database.Queries, userID sql.NullInt64, actor string, event Event) {
+	if db == nil {
+		return
+	}
+	if _, err := appendEvent(context.WithoutCancel(ctx), db, userID, actor, event, time.Now()); err != nil {
+		logging.Error("Failed to record audit event", "action", event.Action, "flow_id", event.FlowID, "error", err.Error())
+	}
+}
+
+func actorFromContext(ctx context.Context) (sql.NullInt64, string) {
+	if user, ok := auth.UserFromContext(ctx); ok {
+		return sql.NullInt64{Int64: user.ID, Valid: true}, user.Username
+	}
+	if auth.Authenticated(ctx) {
+		return sql.NullInt64{}, ActorAPIKey
+	}
+	return sql.NullInt64{}, ActorAnonymous
+}
+
+// appendEvent chains the event to the latest one and stores it
+func appendEvent(ctx context.Context, db *database.Queries, userID sql.NullInt64, actor string, event Event, now time.Time) (database.AuditEvent, error) {
+	details := "{}"
+	if len(event.Details) > 0 {
+		raw, err := json.Marshal(event.Details)
+		if err != nil {
+			return database.AuditEvent{}, fmt.Errorf("failed to encode details: %w", err)
+		}
+		details = string(raw)
+	}
+
+	row := database.AuditEvent{
+		CreatedAt: now.UTC().Truncate(time.Microsecond),
+		UserID:    userID,
+		Actor:     actor,
+		Action:    event.Action,
+		FlowID:    nullID(event.FlowID),
+		TaskID:    nullID(event.TaskID),
+		Target:    event.Target,
+		Details:   details,
+	}
+	if event.Err != nil {
+		row.Error = sql.NullString{String: event.Err.Error(), Valid: true}
+	}
+
+	appendMu.Lock()
+	defer appendMu.Unlock()
+
+	row.PrevHash = GenesisHash
+	last, err := db.ReadLastAuditEvent(ctx)
+	if err == nil {
+		row.PrevHash = last.Hash
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return database.AuditEvent{}, fmt.Errorf("failed to read last audit event: %w", err)
+	}
+	row.Hash = Hash(row)
+
+	return db.CreateAuditEvent(ctx, database.CreateAuditEventParams{
+		CreatedAt: row.CreatedAt,
+		UserID:    row.UserID,
+		Actor:     row.Actor,
+		Action:    row.Action,
+		FlowID:    row.FlowID,
+		TaskID:    row.TaskID,
+		Target:    row.Target,
+		Details:   row.Details,
+		Error:     row.Error,
+		PrevHash:  row.PrevHash,
+		Hash:      row.Hash,
+	})
+}
+
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// hashedEvent is the content covered by an event's hash, in a fixed order
+type hashedEvent struct {
+	CreatedAt string  `json:"createdAt"`
+	UserID    *int64  `json:"userId"`
+	Actor     string  `json:"actor"`
+	Action    string  `json:"action"`
+	FlowID    *int64  `json:"flowId"`
+	TaskID    *int64  `json:"taskId"`
+	Target    string  `json:"target"`
+	Details   string  `json:"details"`
+	Error     *string `json:"error"`
+}
+
+// Hash returns the hash of an event: SHA-256 of the previous hash, a newline
+// and the JSON encoding of the event's content
+func Hash(event database.AuditEvent) string {
+	content, _ := json.Marshal(hashedEvent{
+		CreatedAt: event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		UserID:    nullInt(event.UserID),
+		Actor:     event.Actor,
+		Action:    event.Action,
+		FlowID:    nullInt(event.FlowID),
+		TaskID:    nullInt(event.TaskID),
+		Target:    event.Target,
+		Details:   event.Details,
+		Error:     nullString(event.Error),
+	})
+
+	h := sha256.New()
+	h.Write([]byte(event.PrevHash + "\n"))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func nullInt(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
+
+func nullString(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
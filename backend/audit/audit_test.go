@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/arandu-ai/arandu/database"
+)
+
+// chain builds a valid log of n events
+func chain(n int) []database.AuditEvent {
+	events := make([]database.AuditEvent, n)
+	prev := GenesisHash
+	for i := range events {
+		events[i] = database.AuditEvent{
+			ID:        int64(i + 1),
+			CreatedAt: time.Date(2026, 10, 18, 12, 0, i, 1000, time.UTC),
+			UserID:    sql.NullInt64{Int64: 1, Valid: true},
+			Actor:     "alice",
+			Action:    ActionCommandExecuted,
+			FlowID:    sql.NullInt64{Int64: 7, Valid: true},
+			Target:    "ls -la",
+			Details:   "{}",
+			PrevHash:  prev,
+		}
+		events[i].Hash = Hash(events[i])
+		prev = events[i].Hash
+	}
+	return events
+}
+
+func TestVerificationCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		tamper     func([]database.AuditEvent) []database.AuditEvent
+		wantValid  bool
+		wantBroken int64
+	}{
+		{
+			name:      "untouched",
+			tamper:    func(e []database.AuditEvent) []database.AuditEvent { return e },
+			wantValid: true,
+		},
+		{
+			name: "edited target",
+			tamper: func(e []database.AuditEvent) []database.AuditEvent {
+				e[2].Target = "true"
+				return e
+			},
+			wantBroken: 3,
+		},
+		{
+			name: "edited error",
+			tamper: func(e []database.AuditEvent) []database.AuditEvent {
+				e[1].Error = sql.NullString{String: "denied", Valid: true}
+				return e
+			},
+			wantBroken: 2,
+		},
+		{
+			name: "edited and rehashed",
+			tamper: func(e []database.AuditEvent) []database.AuditEvent {
+				e[1].Actor = "bob"
+				e[1].Hash = Hash(e[1])
+				return e
+			},
+			wantBroken: 3,
+		},
+		{
+			name: "removed event",
+			tamper: func(e []database.AuditEvent) []database.AuditEvent {
+				return append(e[:1], e[2:]...)
+			},
+			wantBroken: 3,
+		},
+		{
+			name: "reordered events",
+			tamper: func(e []database.AuditEvent) []database.AuditEvent {
+				e[0], e[1] = e[1], e[0]
+				return e
+			},
+			wantBroken: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := Verification{Valid: true, Head: GenesisHash}
+			events := tt.tamper(chain(5))
+
+			v.check(events)
+			if v.Valid != tt.wantValid || v.BrokenAt != tt.wantBroken {
+				t.Errorf("check() valid = %v broken at %d, want %v %d", v.Valid, v.BrokenAt, tt.wantValid, tt.wantBroken)
+			}
+			if tt.wantValid && (v.Events != 5 || v.Head != events[4].Hash) {
+				t.Errorf("check() events = %d head = %s", v.Events, v.Head)
+			}
+		})
+	}
+}
+
+func TestVerificationAcrossPages(t *testing.T) {
+	events := chain(6)
+	v := Verification{Valid: true, Head: GenesisHash}
+	if !v.check(events[:3]) || !v.check(events[3:]) {
+		t.Fatalf("check() broken at %d", v.BrokenAt)
+	}
+	if v.Events != 6 {
+		t.Errorf("Events = %d, want 6", v.Events)
+	}
+}
+
+func TestHashIgnoresTimeZone(t *testing.T) {
+	event := chain(1)[0]
+	local := event
+	local.CreatedAt = event.CreatedAt.In(time.FixedZone("UTC-3", -3*3600))
+	if Hash(local) != Hash(event) {
+		t.Error("the hash of an event should not depend on the time zone it is read in")
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	event := chain(1)[0]
+	entry := NewEntry(event)
+	if entry.UserID == nil || *entry.UserID != 1 || entry.TaskID != nil || entry.Error != nil {
+		t.Errorf("NewEntry() = %+v", entry)
+	}
+	if string(entry.Details) != "{}" || entry.Hash != event.Hash || entry.PrevHash != GenesisHash {
+		t.Errorf("NewEntry() = %+v", entry)
+	}
+}
@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/arandu-ai/arandu/database"
+)
+
+// pageSize is how many events are read at a time when walking the log
+const pageSize = 500
+
+// Verification is the result of checking the hash chain
+type Verification struct {
+	Valid bool
+	// Events is the number of events that chain correctly
+	Events int
+	// BrokenAt is the first event that does not chain to the one before it
+	BrokenAt int64
+	// Head is the hash of the latest valid event; keeping a copy elsewhere
+	// also detects events removed from the end of the log
+	Head string
+}
+
+// Verify walks the whole log and recomputes every hash
+func Verify(ctx context.Context, db *database.Queries) (Verification, error) {
+	v := Verification{Valid: true, Head: GenesisHash}
+
+	var after int64
+	for {
+		events, err := db.ReadAuditEventsAfter(ctx, database.ReadAuditEventsAfterParams{ID: after, Limit: pageSize})
+		if err != nil {
+			return Verification{}, fmt.Errorf("failed to read audit events: %w", err)
+		}
+		if len(events) == 0 || !v.check(events) {
+			return v, nil
+		}
+		after = events[len(events)-1].ID
+	}
+}
+
+// check continues the verification with the next events of the log
+func (v *Verification) check(events []database.AuditEvent) bool {
+	for _, event := range events {
+		if event.PrevHash != v.Head || Hash(event) != event.Hash {
+			v.Valid = false
+			v.BrokenAt = event.ID
+			return false
+		}
+		v.Head = event.Hash
+		v.Events++
+	}
+	return true
+}
+
+// Entry is an event as exported, with everything needed to check its hash
+type Entry struct {
+	ID        int64           `json:"id"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UserID    *int64          `json:"userId"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	FlowID    *int64          `json:"flowId"`
+	TaskID    *int64          `json:"taskId"`
+	Target    string          `json:"target"`
+	Details   json.RawMessage `json:"details"`
+	Error     *string         `json:"error"`
+	PrevHash  string          `json:"prevHash"`
+	Hash      string          `json:"hash"`
+}
+
+// NewEntry converts a stored event for export
+func NewEntry(event database.AuditEvent) Entry {
+	return Entry{
+		ID:        event.ID,
+		CreatedAt: event.CreatedAt.UTC(),
+		UserID:    nullInt(event.UserID),
+		Actor:     event.Actor,
+		Action:    event.Action,
+		FlowID:    nullInt(event.FlowID),
+		TaskID:    nullInt(event.TaskID),
+		Target:    event.Target,
+		Details:   json.RawMessage(event.Details),
+		Error:     nullString(event.Error),
+		PrevHash:  event.PrevHash,
+		Hash:      event.Hash,
+	}
+}
+
+// Export writes the log as JSON lines, oldest first
+// With a flow id only the events of that flow are written
+func Export(ctx context.Context, db *database.Queries, w io.Writer, flowID int64) error {
+	enc := json.NewEncoder(w)
+
+	var after int64
+	for {
+		events, err := db.ReadAuditEventsAfter(ctx, database.ReadAuditEventsAfterParams{ID: after, Limit: pageSize})
+		if err != nil {
+			return fmt.Errorf("failed to read audit events: %w", err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		for _, event := range events {
+			if flowID != 0 && event.FlowID.Int64 != flowID {
+				continue
+			}
+			if err := enc.Encode(NewEntry(event)); err != nil {
+				return err
+			}
+		}
+		after = events[len(events)-1].ID
+	}
+}
@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+)
+
+// TokenPrefix starts every API token, so they can be told apart from the
+// global API_KEY and spotted by secret scanners
+const TokenPrefix = "arandu_"
+
+// MinPasswordLength is the shortest password accepted for an account
+const MinPasswordLength = 10
+
+// LoginTokenName is the name of the tokens created by a password login
+const LoginTokenName = "login"
+
+// lastUsedResolution is how often the last use of a token is recorded
+const lastUsedResolution = time.Minute
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,31}$`)
+
+// dummyHash is compared against when the username does not exist, so a
+// login takes the same time for known and unknown users
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("arandu-dummy-password"), bcrypt.DefaultCost)
+
+// HashPassword hashes a password with bcrypt
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must have at least %d characters", MinPasswordLength)
+	}
+	// bcrypt ignores everything after 72 bytes
+	if len(password) > 72 {
+		return "", fmt.Errorf("password must have at most 72 bytes")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether the password matches the hash
+func CheckPassword(hash string, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// ValidateUsername checks the format of a username
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("username must be 2-32 lowercase letters, digits, '.', '_' or '-'")
+	}
+	return nil
+}
+
+// NewToken generates an API token and the hash stored in the database
+// The token itself is only shown once
+func NewToken() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hash under which a token is stored
+// Tokens are random, so a plain SHA-256 is enough to protect them at rest
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsToken reports whether s looks like an API token
+func IsToken(s string) bool {
+	return strings.HasPrefix(s, TokenPrefix)
+}
+
+func userFromRow(row database.User, token database.ApiToken) *User {
+	return &User{ID: row.ID, Username: row.Username, Admin: row.Admin, TokenID: token.ID, Scope: token.Scope}
+}
+
+// Authenticate returns the owner of an API token
+func Authenticate(ctx context.Context, db *database.Queries, token string) (*User, error) {
+	if !IsToken(token) {
+		return nil, ErrInvalidCredentials
+	}
+
+	row, err := db.ReadApiTokenUser(ctx, HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api token: %w", err)
+	}
+
+	// Tokens are checked on every request; record their use once a minute
+	if !row.TokenLastUsedAt.Valid || time.Since(row.TokenLastUsedAt.Time) > lastUsedResolution {
+		if err := db.UpdateApiTokenLastUsed(ctx, row.TokenID); err != nil {
+			logging.Warn("Failed to update api token usage", "token_id", row.TokenID, "error", err.Error())
+		}
+	}
+
+	return &User{ID: row.ID, Username: row.Username, Admin: row.Admin, TokenID: row.TokenID, Scope: row.TokenScope}, nil
+}
+
+// Login checks a username and password and creates a login token
+func Login(ctx context.Context, db *database.Queries, username string, password string) (*User, string, error) {
+	row, err := db.ReadUserByUsername(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, "", ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read user: %w", err)
+	}
+	if !CheckPassword(row.PasswordHash, password) {
+		return nil, "", ErrInvalidCredentials
+	}
+
+	token, apiToken, err := CreateToken(ctx, db, row.ID, LoginTokenName, ScopeOperator)
+	if err != nil {
+		return nil, "", err
+	}
+	return userFromRow(row, apiToken), token, nil
+}
+
+// Logout revokes the token used by a login
+func Logout(ctx context.Context, db *database.Queries, token string) error {
+	return db.DeleteApiTokenByHash(ctx, HashToken(token))
+}
+
+// CreateToken creates an API token for a user and returns it in clear once
+func CreateToken(ctx context.Context, db *database.Queries, userID int64, name string, scope string) (string, database.ApiToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		return "", database.ApiToken{}, fmt.Errorf("token name must have 1-64 characters")
+	}
+	if err := ValidateScope(scope); err != nil {
+		return "", database.ApiToken{}, err
+	}
+
+	token, hash, err := NewToken()
+	if err != nil {
+		return "", database.ApiToken{}, err
+	}
+
+	apiToken, err := db.CreateApiToken(ctx, database.CreateApiTokenParams{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hash,
+		Scope:     scope,
+	})
+	if err != nil {
+		return "", database.ApiToken{}, fmt.Errorf("failed to create api token: %w", err)
+	}
+	return token, apiToken, nil
+}
+
+// RotateToken replaces the secret of a user's API token and returns the new one
+// The token keeps its id, name and scope; the old secret stops working at once
+func RotateToken(ctx context.Context, db *database.Queries, userID int64, tokenID int64) (string, database.ApiToken, error) {
+	token, hash, err := NewToken()
+	if err != nil {
+		return "", database.ApiToken{}, err
+	}
+
+	apiToken, err := db.RotateApiToken(ctx, database.RotateApiTokenParams{
+		TokenHash: hash,
+		ID:        tokenID,
+		UserID:    userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", database.ApiToken{}, fmt.Errorf("api token %d not found", tokenID)
+	}
+	if err != nil {
+		return "", database.ApiToken{}, fmt.Errorf("failed to rotate api token: %w", err)
+	}
+	return token, apiToken, nil
+}
+
+// CreateUser creates a local account
+func CreateUser(ctx context.Context, db *database.Queries, username string, password string, admin bool) (database.User, error) {
+	if err := ValidateUsername(username); err != nil {
+		return database.User{}, err
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	if _, err := db.ReadUserByUsername(ctx, username); err == nil {
+		return database.User{}, fmt.Errorf("user %s already exists", username)
+	}
+
+	user, err := db.CreateUser(ctx, database.CreateUserParams{
+		Username:     username,
+		PasswordHash: hash,
+		Admin:        admin,
+	})
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// ChangePassword replaces the password of a user after checking the current one
+func ChangePassword(ctx context.Context, db *database.Queries, userID int64, current string, password string) error {
+	row, err := db.ReadUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to read user: %w", err)
+	}
+	if !CheckPassword(row.PasswordHash, current) {
+		return ErrInvalidCredentials
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return db.UpdateUserPassword(ctx, database.UpdateUserPasswordParams{PasswordHash: hash, ID: userID})
+}
+
+// Bootstrap creates the first admin account when there are no users yet
+func Bootstrap(ctx context.Context, db *database.Queries, username string, password string) error {
+	count, err := db.CountUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count users: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	if username == "" || password == "" {
+		logging.Warn("Multi-user mode is enabled but there are no users; set ADMIN_USERNAME and ADMIN_PASSWORD to create the first admin")
+		return nil
+	}
+
+	if _, err := CreateUser(ctx, db, username, password, true); err != nil {
+		return fmt.Errorf("failed to create admin user: %w", err)
+	}
+	logging.Info("Admin user created", "username", username)
+	return nil
+}
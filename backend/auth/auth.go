@@ -0,0 +1,133 @@
+// Package auth manages local user accounts and their API tokens, and carries
+// the authenticated user through request contexts so resolvers can check
+// who owns a flow and which teams it is shared with.
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/arandu-ai/arandu/config"
+)
+
+var (
+	// ErrUnauthenticated is returned when a request has no valid user
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden is returned when the user may not access a resource
+	ErrForbidden = errors.New("not authorized")
+	// ErrInvalidCredentials is returned for a wrong username, password or token
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Token scopes, from least to most privileged
+const (
+	// ScopeReadOnly may run queries and subscriptions, and watch terminals and screenshots
+	ScopeReadOnly = "readOnly"
+	// ScopeOperator may also run mutations: create flows, send tasks, run commands
+	ScopeOperator = "operator"
+)
+
+var scopeRanks = map[string]int{
+	ScopeReadOnly: 1,
+	ScopeOperator: 2,
+}
+
+// User is the authenticated user of a request
+type User struct {
+	ID       int64
+	Username string
+	Admin    bool
+	// TokenID is the API token used to authenticate
+	TokenID int64
+	// Scope is the scope of that token
+	Scope string
+}
+
+type contextKey struct{}
+
+type apiKeyContextKey struct{}
+
+// Enabled reports whether flows are private to their owners
+// With MULTI_USER off every client sees every flow, as before accounts existed
+func Enabled() bool {
+	return config.Config.MultiUser
+}
+
+// WithUser returns a context carrying the user
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext returns the user of the request, if any
+func UserFromContext(ctx context.Context) (*User, bool) {
+	user, ok := ctx.Value(contextKey{}).(*User)
+	return user, ok && user != nil
+}
+
+// WithAPIKey returns a context marked as authenticated with the global API_KEY
+func WithAPIKey(ctx context.Context) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, true)
+}
+
+// Authenticated reports whether the request carried the API key or a user token
+func Authenticated(ctx context.Context) bool {
+	if _, ok := UserFromContext(ctx); ok {
+		return true
+	}
+	apiKey, _ := ctx.Value(apiKeyContextKey{}).(bool)
+	return apiKey
+}
+
+// ValidateScope checks that a scope exists
+func ValidateScope(scope string) error {
+	if _, ok := scopeRanks[scope]; !ok {
+		return fmt.Errorf("unknown scope %q", scope)
+	}
+	return nil
+}
+
+// RequireScope checks that the request may act with the given scope
+// Only user tokens carry a scope; the global API key and unauthenticated
+// requests (when authentication is off) have full access
+func RequireScope(ctx context.Context, scope string) error {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if scopeRanks[user.Scope] < scopeRanks[scope] {
+		return fmt.Errorf("%w: token scope %s does not allow this operation", ErrForbidden, user.Scope)
+	}
+	return nil
+}
+
+// RequireUser returns the user of the request or ErrUnauthenticated
+func RequireUser(ctx context.Context) (*User, error) {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return user, nil
+}
+
+// RequireAdmin returns the user of the request if it is an admin
+func RequireAdmin(ctx context.Context) (*User, error) {
+	user, err := RequireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Admin {
+		return nil, ErrForbidden
+	}
+	return user, nil
+}
+
+// OwnerID is the owner to record on a flow created by the request
+func OwnerID(ctx context.Context) sql.NullInt64 {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: user.ID, Valid: true}
+}
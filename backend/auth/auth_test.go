@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/config"
+)
+
+func TestCanAccessFlow(t *testing.T) {
+	defer func(enabled bool) { config.Config.MultiUser = enabled }(config.Config.MultiUser)
+
+	owned := sql.NullInt64{Int64: 1, Valid: true}
+	alice := WithUser(context.Background(), &User{ID: 1, Username: "alice"})
+	bob := WithUser(context.Background(), &User{ID: 2, Username: "bob"})
+	admin := WithUser(context.Background(), &User{ID: 3, Username: "root", Admin: true})
+
+	tests := []struct {
+		name      string
+		multiUser bool
+		ctx       context.Context
+		ownerID   sql.NullInt64
+		want      error
+	}{
+		{name: "disabled allows anonymous", multiUser: false, ctx: context.Background(), ownerID: owned, want: nil},
+		{name: "anonymous", multiUser: true, ctx: context.Background(), ownerID: owned, want: ErrUnauthenticated},
+		{name: "owner", multiUser: true, ctx: alice, ownerID: owned, want: nil},
+		{name: "other user", multiUser: true, ctx: bob, ownerID: owned, want: ErrForbidden},
+		{name: "admin on someone else's flow", multiUser: true, ctx: admin, ownerID: owned, want: ErrForbidden},
+		{name: "admin on flow without owner", multiUser: true, ctx: admin, ownerID: sql.NullInt64{}, want: nil},
+		{name: "user on flow without owner", multiUser: true, ctx: alice, ownerID: sql.NullInt64{}, want: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Config.MultiUser = tt.multiUser
+			// Without a team no memberships are read
+			if err := CanAccessFlow(tt.ctx, nil, FlowAccess{OwnerID: tt.ownerID}, TeamViewer); !errors.Is(err, tt.want) {
+				t.Errorf("CanAccessFlow() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnerID(t *testing.T) {
+	if got := OwnerID(context.Background()); got.Valid {
+		t.Errorf("OwnerID(anonymous) = %v, want NULL", got)
+	}
+	ctx := WithUser(context.Background(), &User{ID: 7})
+	if got := OwnerID(ctx); !got.Valid || got.Int64 != 7 {
+		t.Errorf("OwnerID(user 7) = %v", got)
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "valid", password: "correct horse battery", wantErr: false},
+		{name: "too short", password: "short", wantErr: true},
+		{name: "too long", password: strings.Repeat("a", 73), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := HashPassword(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HashPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !CheckPassword(hash, tt.password) {
+				t.Error("CheckPassword() rejected the hashed password")
+			}
+			if CheckPassword(hash, tt.password+"x") {
+				t.Error("CheckPassword() accepted a wrong password")
+			}
+		})
+	}
+}
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		wantErr  bool
+	}{
+		{username: "alice", wantErr: false},
+		{username: "j.doe-2_x", wantErr: false},
+		{username: "a", wantErr: true},
+		{username: "Alice", wantErr: true},
+		{username: "-alice", wantErr: true},
+		{username: "alice smith", wantErr: true},
+		{username: strings.Repeat("a", 33), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			if err := ValidateUsername(tt.username); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUsername(%q) error = %v, wantErr %v", tt.username, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	token, hash, err := NewToken()
+	if err != nil {
+		t.Fatalf("NewToken() error = %v", err)
+	}
+	if !IsToken(token) {
+		t.Errorf("token %q has no %s prefix", token, TokenPrefix)
+	}
+	if hash != HashToken(token) || hash == token {
+		t.Error("hash does not match HashToken(token)")
+	}
+
+	other, _, _ := NewToken()
+	if other == token {
+		t.Error("NewToken() returned the same token twice")
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	readOnly := WithUser(context.Background(), &User{ID: 1, Scope: ScopeReadOnly})
+	operator := WithUser(context.Background(), &User{ID: 1, Scope: ScopeOperator})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		scope   string
+		wantErr bool
+	}{
+		{name: "api key or anonymous", ctx: context.Background(), scope: ScopeOperator, wantErr: false},
+		{name: "read-only reads", ctx: readOnly, scope: ScopeReadOnly, wantErr: false},
+		{name: "read-only writes", ctx: readOnly, scope: ScopeOperator, wantErr: true},
+		{name: "operator writes", ctx: operator, scope: ScopeOperator, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RequireScope(tt.ctx, tt.scope)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RequireScope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrForbidden) {
+				t.Errorf("RequireScope() error = %v, want ErrForbidden", err)
+			}
+		})
+	}
+
+	if err := ValidateScope("admin"); err == nil {
+		t.Error("ValidateScope(admin) should fail")
+	}
+}
+
+func TestAuthenticated(t *testing.T) {
+	if Authenticated(context.Background()) {
+		t.Error("empty context should not be authenticated")
+	}
+	if !Authenticated(WithAPIKey(context.Background())) {
+		t.Error("API key context should be authenticated")
+	}
+	if !Authenticated(WithUser(context.Background(), &User{ID: 1})) {
+		t.Error("user context should be authenticated")
+	}
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+)
+
+// RoleAdmin is granted by single sign-on groups mapped to "admin"; the other
+// roles are the token scopes
+const RoleAdmin = "admin"
+
+// SSOTokenName is the name of the tokens created by a single sign-on login
+const SSOTokenName = "sso"
+
+var roleRanks = map[string]int{
+	ScopeReadOnly: 1,
+	ScopeOperator: 2,
+	RoleAdmin:     3,
+}
+
+var invalidUsernameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// SSOIdentity is a user authenticated by the identity provider
+type SSOIdentity struct {
+	// Subject identifies the user at the provider and never changes
+	Subject  string
+	Username string
+	Groups   []string
+}
+
+// ParseRoleMapping parses "group=role,group=role" into a group to role map
+func ParseRoleMapping(s string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" {
+			return nil, fmt.Errorf("invalid role mapping %q, want group=role", pair)
+		}
+		if _, ok := roleRanks[role]; !ok {
+			return nil, fmt.Errorf("unknown role %q for group %s (use admin, operator or readOnly)", role, group)
+		}
+		mapping[group] = role
+	}
+	return mapping, nil
+}
+
+// RoleForGroups returns the highest role the groups are mapped to
+// With an empty mapping every user is an operator; with a mapping, users in
+// none of its groups are rejected
+func RoleForGroups(mapping map[string]string, groups []string) (string, error) {
+	if len(mapping) == 0 {
+		return ScopeOperator, nil
+	}
+
+	role := ""
+	for _, group := range groups {
+		if r, ok := mapping[group]; ok && roleRanks[r] > roleRanks[role] {
+			role = r
+		}
+	}
+	if role == "" {
+		return "", ErrForbidden
+	}
+	return role, nil
+}
+
+// SSOLogin signs in a user authenticated by the identity provider and creates
+// a session token. The account is created on the first login and its admin
+// flag follows the groups on every login
+func SSOLogin(ctx context.Context, db *database.Queries, identity SSOIdentity, role string) (*User, string, error) {
+	if identity.Subject == "" {
+		return nil, "", fmt.Errorf("identity has no subject")
+	}
+	subject := database.StringToNullString(identity.Subject)
+	admin := role == RoleAdmin
+
+	row, err := db.ReadUserByOidcSubject(ctx, subject)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		username, err := availableUsername(ctx, db, identity)
+		if err != nil {
+			return nil, "", err
+		}
+		row, err = db.CreateOidcUser(ctx, database.CreateOidcUserParams{
+			Username:    username,
+			Admin:       admin,
+			OidcSubject: subject,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create user: %w", err)
+		}
+		logging.Info("Single sign-on user created", "username", username, "admin", admin)
+
+	case err != nil:
+		return nil, "", fmt.Errorf("failed to read user: %w", err)
+
+	case row.Admin != admin:
+		if err := db.UpdateUserAdmin(ctx, database.UpdateUserAdminParams{Admin: admin, ID: row.ID}); err != nil {
+			return nil, "", fmt.Errorf("failed to update user role: %w", err)
+		}
+		row.Admin = admin
+	}
+
+	scope := ScopeOperator
+	if role == ScopeReadOnly {
+		scope = ScopeReadOnly
+	}
+	token, apiToken, err := CreateToken(ctx, db, row.ID, SSOTokenName, scope)
+	if err != nil {
+		return nil, "", err
+	}
+	return userFromRow(row, apiToken), token, nil
+}
+
+// availableUsername derives a local username from the provider's one
+// A name taken by another account gets a suffix from the subject
+func availableUsername(ctx context.Context, db *database.Queries, identity SSOIdentity) (string, error) {
+	username := SSOUsername(identity.Username, identity.Subject)
+	if _, err := db.ReadUserByUsername(ctx, username); errors.Is(err, sql.ErrNoRows) {
+		return username, nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read user: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(identity.Subject))
+	suffix := "-" + hex.EncodeToString(sum[:3])
+	return strings.TrimRight(username[:min(len(username), 32-len(suffix))], "._-") + suffix, nil
+}
+
+// SSOUsername turns a provider username or email into a valid local username
+func SSOUsername(name string, subject string) string {
+	name, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(name)), "@")
+	name = invalidUsernameChars.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "._-")
+	if len(name) > 32 {
+		name = strings.TrimRight(name[:32], "._-")
+	}
+	if ValidateUsername(name) != nil {
+		sum := sha256.Sum256([]byte(subject))
+		return "user-" + hex.EncodeToString(sum[:4])
+	}
+	return name
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseRoleMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: map[string]string{}},
+		{name: "all roles", input: "platform=admin, dev=operator ,support=readOnly", want: map[string]string{"platform": "admin", "dev": "operator", "support": "readOnly"}},
+		{name: "group with spaces", input: "Platform Team=admin", want: map[string]string{"Platform Team": "admin"}},
+		{name: "unknown role", input: "dev=root", wantErr: true},
+		{name: "missing role", input: "dev", wantErr: true},
+		{name: "missing group", input: "=admin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRoleMapping(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRoleMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseRoleMapping() = %v, want %v", got, tt.want)
+			}
+			for group, role := range tt.want {
+				if got[group] != role {
+					t.Errorf("role of %s = %q, want %q", group, got[group], role)
+				}
+			}
+		})
+	}
+}
+
+func TestRoleForGroups(t *testing.T) {
+	mapping := map[string]string{"platform": RoleAdmin, "dev": ScopeOperator, "support": ScopeReadOnly}
+
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		groups  []string
+		want    string
+		wantErr error
+	}{
+		{name: "no mapping", mapping: nil, groups: nil, want: ScopeOperator},
+		{name: "read only", mapping: mapping, groups: []string{"support"}, want: ScopeReadOnly},
+		{name: "highest role wins", mapping: mapping, groups: []string{"support", "platform", "dev"}, want: RoleAdmin},
+		{name: "unmapped groups", mapping: mapping, groups: []string{"marketing"}, wantErr: ErrForbidden},
+		{name: "no groups", mapping: mapping, groups: nil, wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RoleForGroups(tt.mapping, tt.groups)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RoleForGroups() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RoleForGroups() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSOUsername(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "alice", want: "alice"},
+		{name: "Alice.Smith@example.com", want: "alice.smith"},
+		{name: "José Pérez", want: "jos-p-rez"},
+		{name: "  bob  ", want: "bob"},
+		{name: strings.Repeat("a", 40), want: strings.Repeat("a", 32)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SSOUsername(tt.name, "sub"); got != tt.want {
+				t.Errorf("SSOUsername(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	// Names that cannot be turned into a username fall back to the subject
+	fallback := SSOUsername("", "sub-1")
+	if !strings.HasPrefix(fallback, "user-") || ValidateUsername(fallback) != nil {
+		t.Errorf("SSOUsername(empty) = %q", fallback)
+	}
+	if fallback == SSOUsername("@@", "sub-2") {
+		t.Error("fallback usernames of different subjects should differ")
+	}
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arandu-ai/arandu/database"
+)
+
+// Team roles, from least to most privileged
+// A user's role on a flow is TeamAdmin for its owner and the team role for
+// members of the team the flow is shared with
+const (
+	// TeamViewer may watch flows: queries, subscriptions, terminals and screenshots
+	TeamViewer = "viewer"
+	// TeamOperator may also drive them: send tasks, finish, checkpoint, roll back and fork
+	TeamOperator = "operator"
+	// TeamAdmin may also share flows and manage the team's members
+	TeamAdmin = "admin"
+)
+
+var teamRoleRanks = map[string]int{
+	TeamViewer:   1,
+	TeamOperator: 2,
+	TeamAdmin:    3,
+}
+
+// FlowAccess is who a flow belongs to
+type FlowAccess struct {
+	OwnerID sql.NullInt64
+	TeamID  sql.NullInt64
+}
+
+// ValidateTeamRole checks that a team role exists
+func ValidateTeamRole(role string) error {
+	if _, ok := teamRoleRanks[role]; !ok {
+		return fmt.Errorf("unknown team role %q (use viewer, operator or admin)", role)
+	}
+	return nil
+}
+
+// HasRole reports whether role grants at least the required one
+func HasRole(role string, required string) bool {
+	return role != "" && teamRoleRanks[role] >= teamRoleRanks[required]
+}
+
+// ValidateTeamName checks the name of a team
+func ValidateTeamName(name string) error {
+	if name == "" || len(name) > 64 || strings.TrimSpace(name) != name {
+		return fmt.Errorf("team name must have 1-64 characters without surrounding spaces")
+	}
+	return nil
+}
+
+// TeamRoles returns the role of the user of the request in each of their teams
+func TeamRoles(ctx context.Context, db *database.Queries) (map[int64]string, error) {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	members, err := db.ReadTeamMembershipsByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read teams: %w", err)
+	}
+	roles := make(map[int64]string, len(members))
+	for _, member := range members {
+		roles[member.TeamID] = member.Role
+	}
+	return roles, nil
+}
+
+// RoleOnFlow returns the role of the user of the request on a flow, or ""
+// without access. teams holds the user's team roles, as from TeamRoles
+// Flows are private to their owner unless shared with a team. Flows created
+// before accounts existed have no owner and are only visible to admins
+func RoleOnFlow(ctx context.Context, flow FlowAccess, teams map[int64]string) string {
+	if !Enabled() {
+		return TeamAdmin
+	}
+
+	user, ok := UserFromContext(ctx)
+	switch {
+	case !ok:
+		return ""
+	case flow.OwnerID.Valid && flow.OwnerID.Int64 == user.ID:
+		return TeamAdmin
+	case !flow.OwnerID.Valid && user.Admin:
+		return TeamAdmin
+	case flow.TeamID.Valid:
+		return teams[flow.TeamID.Int64]
+	}
+	return ""
+}
+
+// FlowRole returns the role of the user of the request on a flow, or ""
+// without access
+func FlowRole(ctx context.Context, db *database.Queries, flow FlowAccess) (string, error) {
+	var teams map[int64]string
+	if Enabled() && flow.TeamID.Valid {
+		var err error
+		if teams, err = TeamRoles(ctx, db); err != nil {
+			return "", err
+		}
+	}
+	return RoleOnFlow(ctx, flow, teams), nil
+}
+
+// CanAccessFlow checks that the user of the request has at least the role on a flow
+func CanAccessFlow(ctx context.Context, db *database.Queries, flow FlowAccess, role string) error {
+	if !Enabled() {
+		return nil
+	}
+	if _, err := RequireUser(ctx); err != nil {
+		return err
+	}
+
+	current, err := FlowRole(ctx, db, flow)
+	if err != nil {
+		return err
+	}
+	if !HasRole(current, role) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// RequireTeamAdmin checks that the user of the request may manage a team:
+// admins manage every team, team admins their own
+func RequireTeamAdmin(ctx context.Context, db *database.Queries, teamID int64) error {
+	user, err := RequireUser(ctx)
+	if err != nil {
+		return err
+	}
+	if user.Admin {
+		return nil
+	}
+
+	member, err := db.ReadTeamMember(ctx, database.ReadTeamMemberParams{TeamID: teamID, UserID: user.ID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrForbidden
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read team member: %w", err)
+	}
+	if !HasRole(member.Role, TeamAdmin) {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/arandu-ai/arandu/config"
+)
+
+func TestRoleOnFlow(t *testing.T) {
+	defer func(enabled bool) { config.Config.MultiUser = enabled }(config.Config.MultiUser)
+	config.Config.MultiUser = true
+
+	owned := sql.NullInt64{Int64: 1, Valid: true}
+	team := sql.NullInt64{Int64: 10, Valid: true}
+	alice := WithUser(context.Background(), &User{ID: 1, Username: "alice"})
+	bob := WithUser(context.Background(), &User{ID: 2, Username: "bob"})
+	admin := WithUser(context.Background(), &User{ID: 3, Username: "root", Admin: true})
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		flow  FlowAccess
+		teams map[int64]string
+		want  string
+	}{
+		{name: "owner", ctx: alice, flow: FlowAccess{OwnerID: owned}, want: TeamAdmin},
+		{name: "owner of shared flow", ctx: alice, flow: FlowAccess{OwnerID: owned, TeamID: team}, teams: map[int64]string{10: TeamViewer}, want: TeamAdmin},
+		{name: "private flow", ctx: bob, flow: FlowAccess{OwnerID: owned}, teams: map[int64]string{10: TeamAdmin}, want: ""},
+		{name: "team viewer", ctx: bob, flow: FlowAccess{OwnerID: owned, TeamID: team}, teams: map[int64]string{10: TeamViewer}, want: TeamViewer},
+		{name: "team operator", ctx: bob, flow: FlowAccess{OwnerID: owned, TeamID: team}, teams: map[int64]string{10: TeamOperator}, want: TeamOperator},
+		{name: "other team", ctx: bob, flow: FlowAccess{OwnerID: owned, TeamID: team}, teams: map[int64]string{11: TeamAdmin}, want: ""},
+		{name: "admin outside the team", ctx: admin, flow: FlowAccess{OwnerID: owned, TeamID: team}, want: ""},
+		{name: "admin on flow without owner", ctx: admin, flow: FlowAccess{}, want: TeamAdmin},
+		{name: "anonymous", ctx: context.Background(), flow: FlowAccess{OwnerID: owned, TeamID: team}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoleOnFlow(tt.ctx, tt.flow, tt.teams); got != tt.want {
+				t.Errorf("RoleOnFlow() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleOnFlowDisabled(t *testing.T) {
+	defer func(enabled bool) { config.Config.MultiUser = enabled }(config.Config.MultiUser)
+	config.Config.MultiUser = false
+
+	// Without accounts every client drives every flow, as before
+	if got := RoleOnFlow(context.Background(), FlowAccess{}, nil); got != TeamAdmin {
+		t.Errorf("RoleOnFlow() = %q, want %q", got, TeamAdmin)
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	tests := []struct {
+		role     string
+		required string
+		want     bool
+	}{
+		{role: TeamViewer, required: TeamViewer, want: true},
+		{role: TeamViewer, required: TeamOperator, want: false},
+		{role: TeamOperator, required: TeamViewer, want: true},
+		{role: TeamOperator, required: TeamAdmin, want: false},
+		{role: TeamAdmin, required: TeamOperator, want: true},
+		{role: "", required: TeamViewer, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := HasRole(tt.role, tt.required); got != tt.want {
+			t.Errorf("HasRole(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTeamName(t *testing.T) {
+	for _, name := range []string{"platform", "Red Team", "a"} {
+		if err := ValidateTeamName(name); err != nil {
+			t.Errorf("ValidateTeamName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", " padded", "x\n", string(make([]byte, 65))} {
+		if err := ValidateTeamName(name); err == nil {
+			t.Errorf("ValidateTeamName(%q) should fail", name)
+		}
+	}
+}
@@ -39,6 +39,28 @@ type config struct {
 	// Browser (Bug fix #65: configurable Chrome debugging URL)
 	ChromeDebugURL string `env:"CHROME_DEBUG_URL" envDefault:""`
 
+	// Browser: Allow the agent to evaluate JavaScript in its browser page
+	BrowserAllowEvaluate bool `env:"BROWSER_ALLOW_EVALUATE" envDefault:"false"`
+
+	// Browser: Maximum number of browser actions running at the same time across all flows
+	BrowserMaxConcurrency int `env:"BROWSER_MAX_CONCURRENCY" envDefault:"4"`
+
+	// Browser: Directory where screenshots are stored, one folder per flow and task
+	ScreenshotsDir string `env:"SCREENSHOTS_DIR" envDefault:"./screenshots"`
+
+	// Browser: Maximum size in MB of a file fetched with the download action
+	DownloadMaxSizeMB int64 `env:"DOWNLOAD_MAX_SIZE_MB" envDefault:"100"`
+
+	// Browser: Comma-separated MIME types the download action accepts (wildcards like "image/*" allowed)
+	DownloadAllowedTypes string `env:"DOWNLOAD_ALLOWED_TYPES" envDefault:"text/*,application/*,image/*"`
+
+	// Search: Backend of the search tool ("searxng", "json" or "file"; empty disables it)
+	SearchProvider string `env:"SEARCH_PROVIDER" envDefault:""`
+	// Search: SearXNG instance URL, JSON endpoint URL or path of the results file
+	SearchURL string `env:"SEARCH_URL" envDefault:""`
+	// Search: Optional API key sent as a Bearer token
+	SearchAPIKey string `env:"SEARCH_API_KEY" envDefault:""`
+
 	// Security: CORS allowed origins (comma-separated list)
 	// Use "*" for development only, specify exact origins in production
 	// Example: "http://localhost:3000,https://myapp.com"
@@ -57,6 +79,68 @@ type config struct {
 	// Security: Allow any Docker image (development only)
 	AllowAnyDockerImage bool `env:"ALLOW_ANY_DOCKER_IMAGE" envDefault:"false"`
 
+	// Security: JSON file extending the Docker image allow-list
+	// Supports digest pinning and custom images with descriptions for the model
+	DockerImagesFile string `env:"DOCKER_IMAGES_FILE" envDefault:""`
+
+	// Security: Image used when the model picks one the allow-list rejects
+	// It must be allowed too; otherwise the first allowed image is used
+	DefaultDockerImage string `env:"DEFAULT_DOCKER_IMAGE" envDefault:"debian:latest"`
+
+	// Tools: JSON file declaring extra tools, run as a command in the flow container or as a webhook
+	CustomToolsFile string `env:"CUSTOM_TOOLS_FILE" envDefault:""`
+
+	// MCP: JSON file declaring the MCP servers flows can connect to
+	MCPServersFile string `env:"MCP_SERVERS_FILE" envDefault:""`
+
+	// MCP: Serve Arandu's own tools to other agents at /mcp
+	MCPServerEnabled bool `env:"MCP_SERVER_ENABLED" envDefault:"false"`
+
+	// Sandbox: Default resource limits for flow containers (overridable per flow)
+	// A value of 0 (or empty) disables the corresponding limit
+	SandboxMemoryMB       int64   `env:"SANDBOX_MEMORY_MB" envDefault:"2048"`
+	SandboxCPUs           float64 `env:"SANDBOX_CPUS" envDefault:"2"`
+	SandboxPidsLimit      int64   `env:"SANDBOX_PIDS_LIMIT" envDefault:"512"`
+	SandboxDiskQuota      string  `env:"SANDBOX_DISK_QUOTA" envDefault:""`
+	SandboxReadOnlyRootfs bool    `env:"SANDBOX_READ_ONLY_ROOTFS" envDefault:"false"`
+
+	// Sandbox: Upper bounds for the per-flow overrides (0 = no bound)
+	SandboxMaxMemoryMB  int64   `env:"SANDBOX_MAX_MEMORY_MB" envDefault:"8192"`
+	SandboxMaxCPUs      float64 `env:"SANDBOX_MAX_CPUS" envDefault:"4"`
+	SandboxMaxPidsLimit int64   `env:"SANDBOX_MAX_PIDS_LIMIT" envDefault:"4096"`
+	SandboxMaxDiskQuota string  `env:"SANDBOX_MAX_DISK_QUOTA" envDefault:""`
+
+	// Sandbox: Network mode for flow containers (none, egress, full)
+	// "egress" attaches the container to an internal network and routes traffic
+	// through the allow-list proxy
+	SandboxNetworkMode string `env:"SANDBOX_NETWORK_MODE" envDefault:"full"`
+	// Comma-separated network modes a flow may request besides the default one
+	// Empty allows only the default mode and the ones more restrictive than it
+	SandboxAllowedNetworkModes string `env:"SANDBOX_ALLOWED_NETWORK_MODES" envDefault:""`
+	SandboxEgressNetwork       string `env:"SANDBOX_EGRESS_NETWORK" envDefault:"arandu-egress"`
+	SandboxEgressProxyURL      string `env:"SANDBOX_EGRESS_PROXY_URL" envDefault:"http://arandu:3128"`
+	SandboxEgressProxyAddr     string `env:"SANDBOX_EGRESS_PROXY_ADDR" envDefault:""`
+	// Comma-separated list of domains reachable in egress mode (e.g. "pypi.org,.npmjs.org")
+	SandboxEgressAllowList string `env:"SANDBOX_EGRESS_ALLOWLIST" envDefault:""`
+
+	// Sandbox: Prefix of the internal network, one per flow (<prefix>-<flow id>),
+	// that joins the browser and the flow container to open the flow's own
+	// services with sandbox://<port>/<path> URLs
+	SandboxBrowserNetwork string `env:"SANDBOX_BROWSER_NETWORK" envDefault:"arandu-flow"`
+
+	// Browser: Network of the browser container where Chrome serves its
+	// debugging port. Only the server joins it; flow networks never reach CDP
+	BrowserControlNetwork string `env:"BROWSER_CONTROL_NETWORK" envDefault:"arandu-browser-control"`
+
+	// Sandbox: Pre-warmed containers per image to speed up flow start-up
+	// Example: "python:latest=2,node:latest=1"
+	ContainerPool string `env:"CONTAINER_POOL" envDefault:""`
+
+	// Sandbox: Snapshots of the flow container used by checkpoint/rollback
+	// Risky commands (package managers, recursive deletes...) trigger an automatic snapshot
+	SnapshotBeforeRiskyCommands bool `env:"SNAPSHOT_BEFORE_RISKY_COMMANDS" envDefault:"true"`
+	SnapshotMaxPerFlow          int  `env:"SNAPSHOT_MAX_PER_FLOW" envDefault:"5"`
+
 	// Server: Base URL for external access (used for screenshot URLs, etc.)
 	BaseURL string `env:"BASE_URL" envDefault:""`
 
@@ -67,6 +151,51 @@ type config struct {
 	// Authentication: Enable API key requirement
 	RequireAPIKey bool `env:"REQUIRE_API_KEY" envDefault:"false"`
 
+	// Authentication: Local user accounts; each flow is private to the user who created it
+	MultiUser bool `env:"MULTI_USER" envDefault:"false"`
+
+	// Authentication: First admin account, created at startup when there are no users
+	AdminUsername string `env:"ADMIN_USERNAME" envDefault:""`
+	AdminPassword string `env:"ADMIN_PASSWORD" envDefault:""`
+
+	// Authentication: OIDC single sign-on (authorization code flow); empty issuer = disabled
+	OIDCIssuerURL    string `env:"OIDC_ISSUER_URL" envDefault:""`
+	OIDCClientID     string `env:"OIDC_CLIENT_ID" envDefault:""`
+	OIDCClientSecret string `env:"OIDC_CLIENT_SECRET" envDefault:""`
+	// Callback registered at the IdP, e.g. https://arandu.example.com/auth/oidc/callback
+	OIDCRedirectURL string `env:"OIDC_REDIRECT_URL" envDefault:""`
+	OIDCScopes      string `env:"OIDC_SCOPES" envDefault:"openid,profile,email,groups"`
+	// Claims holding the username and the groups of the user
+	OIDCUsernameClaim string `env:"OIDC_USERNAME_CLAIM" envDefault:"preferred_username"`
+	OIDCGroupsClaim   string `env:"OIDC_GROUPS_CLAIM" envDefault:"groups"`
+	// Group to role mapping, e.g. "platform=admin,dev=operator,support=readOnly"
+	// Empty = every user of the IdP is an operator
+	OIDCRoleMapping string `env:"OIDC_ROLE_MAPPING" envDefault:""`
+	// Label of the login button
+	OIDCProviderName string `env:"OIDC_PROVIDER_NAME" envDefault:"SSO"`
+
+	// Secrets: Key that encrypts the secrets vault at rest, 32 bytes in base64
+	// (openssl rand -base64 32); empty = secrets disabled
+	SecretsKey string `env:"SECRETS_KEY" envDefault:""`
+
+	// Redaction: Mask credentials in command, code and browser output
+	RedactionEnabled bool `env:"REDACTION_ENABLED" envDefault:"true"`
+	// Minimum Shannon entropy (bits per char) of random-looking tokens to mask; 0 = off
+	RedactionEntropyThreshold float64 `env:"REDACTION_ENTROPY_THRESHOLD" envDefault:"4.5"`
+	// JSON file with extra patterns ({"patterns": [{"name", "pattern", "group"}]})
+	RedactionPatternsFile string `env:"REDACTION_PATTERNS_FILE" envDefault:""`
+
+	// Untrusted content: Delimit web pages, search results and files in the prompt
+	// and flag text in them that looks like instructions to the agent
+	UntrustedContentDefense bool `env:"UNTRUSTED_CONTENT_DEFENSE" envDefault:"true"`
+	// Network access and writes outside /app that wait for approval after untrusted
+	// content: off, flagged (only after flagged content) or always
+	UntrustedContentApproval string `env:"UNTRUSTED_CONTENT_APPROVAL" envDefault:"flagged"`
+
+	// Command policy: JSON file with the allow, deny and requireApproval rules on
+	// terminal commands of every flow; teams and flows can add their own
+	CommandPolicyFile string `env:"COMMAND_POLICY_FILE" envDefault:""`
+
 	// Logging: Log level (debug, info, warn, error)
 	LogLevel string `env:"LOG_LEVEL" envDefault:"info"`
 
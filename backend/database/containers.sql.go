@@ -17,7 +17,7 @@ INSERT INTO containers (
 VALUES (
   ?, ?, ?
 )
-RETURNING id, name, local_id, image, status
+RETURNING id, name, local_id, image, status, parent_id, base_image
 `
 
 type CreateContainerParams struct {
@@ -35,12 +35,14 @@ func (q *Queries) CreateContainer(ctx context.Context, arg CreateContainerParams
 		&i.LocalID,
 		&i.Image,
 		&i.Status,
+		&i.ParentID,
+		&i.BaseImage,
 	)
 	return i, err
 }
 
 const getAllRunningContainers = `-- name: GetAllRunningContainers :many
-SELECT id, name, local_id, image, status FROM containers WHERE status = 'running'
+SELECT id, name, local_id, image, status, parent_id, base_image FROM containers WHERE status = 'running'
 `
 
 func (q *Queries) GetAllRunningContainers(ctx context.Context) ([]Container, error) {
@@ -58,6 +60,8 @@ func (q *Queries) GetAllRunningContainers(ctx context.Context) ([]Container, err
 			&i.LocalID,
 			&i.Image,
 			&i.Status,
+			&i.ParentID,
+			&i.BaseImage,
 		); err != nil {
 			return nil, err
 		}
@@ -72,11 +76,39 @@ func (q *Queries) GetAllRunningContainers(ctx context.Context) ([]Container, err
 	return items, nil
 }
 
+const updateContainerLineage = `-- name: UpdateContainerLineage :one
+UPDATE containers
+SET parent_id = ?, base_image = ?
+WHERE id = ?
+RETURNING id, name, local_id, image, status, parent_id, base_image
+`
+
+type UpdateContainerLineageParams struct {
+	ParentID  sql.NullInt64
+	BaseImage sql.NullString
+	ID        int64
+}
+
+func (q *Queries) UpdateContainerLineage(ctx context.Context, arg UpdateContainerLineageParams) (Container, error) {
+	row := q.db.QueryRowContext(ctx, updateContainerLineage, arg.ParentID, arg.BaseImage, arg.ID)
+	var i Container
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.LocalID,
+		&i.Image,
+		&i.Status,
+		&i.ParentID,
+		&i.BaseImage,
+	)
+	return i, err
+}
+
 const updateContainerLocalId = `-- name: UpdateContainerLocalId :one
 UPDATE containers
 SET local_id = ?
 WHERE id = ?
-RETURNING id, name, local_id, image, status
+RETURNING id, name, local_id, image, status, parent_id, base_image
 `
 
 type UpdateContainerLocalIdParams struct {
@@ -93,6 +125,35 @@ func (q *Queries) UpdateContainerLocalId(ctx context.Context, arg UpdateContaine
 		&i.LocalID,
 		&i.Image,
 		&i.Status,
+		&i.ParentID,
+		&i.BaseImage,
+	)
+	return i, err
+}
+
+const updateContainerName = `-- name: UpdateContainerName :one
+UPDATE containers
+SET name = ?
+WHERE id = ?
+RETURNING id, name, local_id, image, status, parent_id, base_image
+`
+
+type UpdateContainerNameParams struct {
+	Name sql.NullString
+	ID   int64
+}
+
+func (q *Queries) UpdateContainerName(ctx context.Context, arg UpdateContainerNameParams) (Container, error) {
+	row := q.db.QueryRowContext(ctx, updateContainerName, arg.Name, arg.ID)
+	var i Container
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.LocalID,
+		&i.Image,
+		&i.Status,
+		&i.ParentID,
+		&i.BaseImage,
 	)
 	return i, err
 }
@@ -101,7 +162,7 @@ const updateContainerStatus = `-- name: UpdateContainerStatus :one
 UPDATE containers
 SET status = ?
 WHERE id = ?
-RETURNING id, name, local_id, image, status
+RETURNING id, name, local_id, image, status, parent_id, base_image
 `
 
 type UpdateContainerStatusParams struct {
@@ -118,6 +179,8 @@ func (q *Queries) UpdateContainerStatus(ctx context.Context, arg UpdateContainer
 		&i.LocalID,
 		&i.Image,
 		&i.Status,
+		&i.ParentID,
+		&i.BaseImage,
 	)
 	return i, err
 }
@@ -10,14 +10,63 @@ import (
 	"database/sql"
 )
 
+const countFlows = `-- name: CountFlows :one
+SELECT COUNT(*) FROM flows f
+WHERE f.status IS COALESCE(?, f.status)
+  AND f.model IS COALESCE(?, f.model)
+  AND f.model_provider IS COALESCE(?, f.model_provider)
+  AND f.owner_id IS COALESCE(?, f.owner_id)
+  AND f.team_id IS COALESCE(?, f.team_id)
+  AND f.created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(?, '9999-12-31'))
+  AND (
+    CAST(? AS BOOLEAN)
+    OR f.owner_id = CAST(? AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(? AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(? AS INTEGER))
+  )
+`
+
+type CountFlowsParams struct {
+	Status        sql.NullString
+	Model         sql.NullString
+	ModelProvider sql.NullString
+	OwnerID       sql.NullInt64
+	TeamID        sql.NullInt64
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	AllFlows      bool
+	ViewerID      int64
+	ViewerAdmin   bool
+}
+
+func (q *Queries) CountFlows(ctx context.Context, arg CountFlowsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFlows,
+		arg.Status,
+		arg.Model,
+		arg.ModelProvider,
+		arg.OwnerID,
+		arg.TeamID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AllFlows,
+		arg.ViewerID,
+		arg.ViewerAdmin,
+		arg.ViewerID,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFlow = `-- name: CreateFlow :one
 INSERT INTO flows (
-  name, status, container_id, model, model_provider
+  name, status, container_id, model, model_provider, sandbox, mcp_servers, owner_id, team_id
 )
 VALUES (
-  ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, created_at, updated_at, name, status, container_id, model, model_provider
+RETURNING id, created_at, updated_at, name, status, container_id, model, model_provider, sandbox, mcp_servers, owner_id, team_id
 `
 
 type CreateFlowParams struct {
@@ -26,6 +75,10 @@ type CreateFlowParams struct {
 	ContainerID   sql.NullInt64
 	Model         sql.NullString
 	ModelProvider sql.NullString
+	Sandbox       sql.NullString
+	McpServers    sql.NullString
+	OwnerID       sql.NullInt64
+	TeamID        sql.NullInt64
 }
 
 func (q *Queries) CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, error) {
@@ -35,6 +88,10 @@ func (q *Queries) CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, e
 		arg.ContainerID,
 		arg.Model,
 		arg.ModelProvider,
+		arg.Sandbox,
+		arg.McpServers,
+		arg.OwnerID,
+		arg.TeamID,
 	)
 	var i Flow
 	err := row.Scan(
@@ -46,29 +103,44 @@ func (q *Queries) CreateFlow(ctx context.Context, arg CreateFlowParams) (Flow, e
 		&i.ContainerID,
 		&i.Model,
 		&i.ModelProvider,
+		&i.Sandbox,
+		&i.McpServers,
+		&i.OwnerID,
+		&i.TeamID,
 	)
 	return i, err
 }
 
 const readAllFlows = `-- name: ReadAllFlows :many
 SELECT
-  f.id, f.created_at, f.updated_at, f.name, f.status, f.container_id, f.model, f.model_provider,
-  c.name AS container_name
+  f.id, f.created_at, f.updated_at, f.name, f.status, f.container_id, f.model, f.model_provider, f.sandbox, f.mcp_servers, f.owner_id, f.team_id,
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
 FROM flows f
 LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
 ORDER BY f.created_at DESC
 `
 
 type ReadAllFlowsRow struct {
-	ID            int64
-	CreatedAt     sql.NullTime
-	UpdatedAt     sql.NullTime
-	Name          sql.NullString
-	Status        sql.NullString
-	ContainerID   sql.NullInt64
-	Model         sql.NullString
-	ModelProvider sql.NullString
-	ContainerName sql.NullString
+	ID                int64
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+	Name              sql.NullString
+	Status            sql.NullString
+	ContainerID       sql.NullInt64
+	Model             sql.NullString
+	ModelProvider     sql.NullString
+	Sandbox           sql.NullString
+	McpServers        sql.NullString
+	OwnerID           sql.NullInt64
+	TeamID            sql.NullInt64
+	ContainerName     sql.NullString
+	BrowserUrl        sql.NullString
+	BrowserScreenshot sql.NullString
 }
 
 func (q *Queries) ReadAllFlows(ctx context.Context) ([]ReadAllFlowsRow, error) {
@@ -89,7 +161,13 @@ func (q *Queries) ReadAllFlows(ctx context.Context) ([]ReadAllFlowsRow, error) {
 			&i.ContainerID,
 			&i.Model,
 			&i.ModelProvider,
+			&i.Sandbox,
+			&i.McpServers,
+			&i.OwnerID,
+			&i.TeamID,
 			&i.ContainerName,
+			&i.BrowserUrl,
+			&i.BrowserScreenshot,
 		); err != nil {
 			return nil, err
 		}
@@ -106,29 +184,42 @@ func (q *Queries) ReadAllFlows(ctx context.Context) ([]ReadAllFlowsRow, error) {
 
 const readFlow = `-- name: ReadFlow :one
 SELECT
-  f.id, f.created_at, f.updated_at, f.name, f.status, f.container_id, f.model, f.model_provider,
+  f.id, f.created_at, f.updated_at, f.name, f.status, f.container_id, f.model, f.model_provider, f.sandbox, f.mcp_servers, f.owner_id, f.team_id,
   c.name AS container_name,
   c.image AS container_image,
   c.status AS container_status,
-  c.local_id AS container_local_id
+  c.local_id AS container_local_id,
+  c.base_image AS container_base_image,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
 FROM flows f
 LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
 WHERE f.id = ?
 `
 
 type ReadFlowRow struct {
-	ID               int64
-	CreatedAt        sql.NullTime
-	UpdatedAt        sql.NullTime
-	Name             sql.NullString
-	Status           sql.NullString
-	ContainerID      sql.NullInt64
-	Model            sql.NullString
-	ModelProvider    sql.NullString
-	ContainerName    sql.NullString
-	ContainerImage   sql.NullString
-	ContainerStatus  sql.NullString
-	ContainerLocalID sql.NullString
+	ID                 int64
+	CreatedAt          sql.NullTime
+	UpdatedAt          sql.NullTime
+	Name               sql.NullString
+	Status             sql.NullString
+	ContainerID        sql.NullInt64
+	Model              sql.NullString
+	ModelProvider      sql.NullString
+	Sandbox            sql.NullString
+	McpServers         sql.NullString
+	OwnerID            sql.NullInt64
+	TeamID             sql.NullInt64
+	ContainerName      sql.NullString
+	ContainerImage     sql.NullString
+	ContainerStatus    sql.NullString
+	ContainerLocalID   sql.NullString
+	ContainerBaseImage sql.NullString
+	BrowserUrl         sql.NullString
+	BrowserScreenshot  sql.NullString
 }
 
 func (q *Queries) ReadFlow(ctx context.Context, id int64) (ReadFlowRow, error) {
@@ -143,19 +234,510 @@ func (q *Queries) ReadFlow(ctx context.Context, id int64) (ReadFlowRow, error) {
 		&i.ContainerID,
 		&i.Model,
 		&i.ModelProvider,
+		&i.Sandbox,
+		&i.McpServers,
+		&i.OwnerID,
+		&i.TeamID,
 		&i.ContainerName,
 		&i.ContainerImage,
 		&i.ContainerStatus,
 		&i.ContainerLocalID,
+		&i.ContainerBaseImage,
+		&i.BrowserUrl,
+		&i.BrowserScreenshot,
 	)
 	return i, err
 }
 
+const readFlowOwner = `-- name: ReadFlowOwner :one
+SELECT owner_id FROM flows
+WHERE id = ?
+`
+
+func (q *Queries) ReadFlowOwner(ctx context.Context, id int64) (sql.NullInt64, error) {
+	row := q.db.QueryRowContext(ctx, readFlowOwner, id)
+	var owner_id sql.NullInt64
+	err := row.Scan(&owner_id)
+	return owner_id, err
+}
+
+const readFlowsPageAsc = `-- name: ReadFlowsPageAsc :many
+SELECT
+  f.id, f.created_at, f.updated_at, f.name, f.status, f.container_id, f.model, f.model_provider, f.sandbox, f.mcp_servers, f.owner_id, f.team_id,
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
+FROM flows f
+LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
+WHERE f.status IS COALESCE(?, f.status)
+  AND f.model IS COALESCE(?, f.model)
+  AND f.model_provider IS COALESCE(?, f.model_provider)
+  AND f.owner_id IS COALESCE(?, f.owner_id)
+  AND f.team_id IS COALESCE(?, f.team_id)
+  AND f.created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(?, '9999-12-31'))
+  AND (
+    CAST(? AS BOOLEAN)
+    OR f.owner_id = CAST(? AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(? AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(? AS INTEGER))
+  )
+  AND f.id > COALESCE(?, 0)
+ORDER BY f.id ASC
+LIMIT ?
+`
+
+type ReadFlowsPageAscParams struct {
+	Status        sql.NullString
+	Model         sql.NullString
+	ModelProvider sql.NullString
+	OwnerID       sql.NullInt64
+	TeamID        sql.NullInt64
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	AllFlows      bool
+	ViewerID      int64
+	ViewerAdmin   bool
+	AfterID       sql.NullInt64
+	Limit         int64
+}
+
+type ReadFlowsPageAscRow struct {
+	ID                int64
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+	Name              sql.NullString
+	Status            sql.NullString
+	ContainerID       sql.NullInt64
+	Model             sql.NullString
+	ModelProvider     sql.NullString
+	Sandbox           sql.NullString
+	McpServers        sql.NullString
+	OwnerID           sql.NullInt64
+	TeamID            sql.NullInt64
+	ContainerName     sql.NullString
+	BrowserUrl        sql.NullString
+	BrowserScreenshot sql.NullString
+}
+
+func (q *Queries) ReadFlowsPageAsc(ctx context.Context, arg ReadFlowsPageAscParams) ([]ReadFlowsPageAscRow, error) {
+	rows, err := q.db.QueryContext(ctx, readFlowsPageAsc,
+		arg.Status,
+		arg.Model,
+		arg.ModelProvider,
+		arg.OwnerID,
+		arg.TeamID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AllFlows,
+		arg.ViewerID,
+		arg.ViewerAdmin,
+		arg.ViewerID,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReadFlowsPageAscRow
+	for rows.Next() {
+		var i ReadFlowsPageAscRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Status,
+			&i.ContainerID,
+			&i.Model,
+			&i.ModelProvider,
+			&i.Sandbox,
+			&i.McpServers,
+			&i.OwnerID,
+			&i.TeamID,
+			&i.ContainerName,
+			&i.BrowserUrl,
+			&i.BrowserScreenshot,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const readFlowsPageByNameAsc = `-- name: ReadFlowsPageByNameAsc :many
+SELECT
+  f.id, f.created_at, f.updated_at, f.name, f.status, f.container_id, f.model, f.model_provider, f.sandbox, f.mcp_servers, f.owner_id, f.team_id,
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
+FROM flows f
+LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
+WHERE f.status IS COALESCE(?, f.status)
+  AND f.model IS COALESCE(?, f.model)
+  AND f.model_provider IS COALESCE(?, f.model_provider)
+  AND f.owner_id IS COALESCE(?, f.owner_id)
+  AND f.team_id IS COALESCE(?, f.team_id)
+  AND f.created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(?, '9999-12-31'))
+  AND (
+    CAST(? AS BOOLEAN)
+    OR f.owner_id = CAST(? AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(? AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(? AS INTEGER))
+  )
+  AND (
+    ? IS NULL
+    OR (COALESCE(f.name, ''), f.id) > (CAST(? AS TEXT), ?)
+  )
+ORDER BY COALESCE(f.name, '') ASC, f.id ASC
+LIMIT ?
+`
+
+type ReadFlowsPageByNameAscParams struct {
+	Status        sql.NullString
+	Model         sql.NullString
+	ModelProvider sql.NullString
+	OwnerID       sql.NullInt64
+	TeamID        sql.NullInt64
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	AllFlows      bool
+	ViewerID      int64
+	ViewerAdmin   bool
+	AfterID       sql.NullInt64
+	AfterName     string
+	Limit         int64
+}
+
+type ReadFlowsPageByNameAscRow struct {
+	ID                int64
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+	Name              sql.NullString
+	Status            sql.NullString
+	ContainerID       sql.NullInt64
+	Model             sql.NullString
+	ModelProvider     sql.NullString
+	Sandbox           sql.NullString
+	McpServers        sql.NullString
+	OwnerID           sql.NullInt64
+	TeamID            sql.NullInt64
+	ContainerName     sql.NullString
+	BrowserUrl        sql.NullString
+	BrowserScreenshot sql.NullString
+}
+
+func (q *Queries) ReadFlowsPageByNameAsc(ctx context.Context, arg ReadFlowsPageByNameAscParams) ([]ReadFlowsPageByNameAscRow, error) {
+	rows, err := q.db.QueryContext(ctx, readFlowsPageByNameAsc,
+		arg.Status,
+		arg.Model,
+		arg.ModelProvider,
+		arg.OwnerID,
+		arg.TeamID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AllFlows,
+		arg.ViewerID,
+		arg.ViewerAdmin,
+		arg.ViewerID,
+		arg.AfterID,
+		arg.AfterName,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReadFlowsPageByNameAscRow
+	for rows.Next() {
+		var i ReadFlowsPageByNameAscRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Status,
+			&i.ContainerID,
+			&i.Model,
+			&i.ModelProvider,
+			&i.Sandbox,
+			&i.McpServers,
+			&i.OwnerID,
+			&i.TeamID,
+			&i.ContainerName,
+			&i.BrowserUrl,
+			&i.BrowserScreenshot,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const readFlowsPageByNameDesc = `-- name: ReadFlowsPageByNameDesc :many
+SELECT
+  f.id, f.created_at, f.updated_at, f.name, f.status, f.container_id, f.model, f.model_provider, f.sandbox, f.mcp_servers, f.owner_id, f.team_id,
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
+FROM flows f
+LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
+WHERE f.status IS COALESCE(?, f.status)
+  AND f.model IS COALESCE(?, f.model)
+  AND f.model_provider IS COALESCE(?, f.model_provider)
+  AND f.owner_id IS COALESCE(?, f.owner_id)
+  AND f.team_id IS COALESCE(?, f.team_id)
+  AND f.created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(?, '9999-12-31'))
+  AND (
+    CAST(? AS BOOLEAN)
+    OR f.owner_id = CAST(? AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(? AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(? AS INTEGER))
+  )
+  AND (
+    ? IS NULL
+    OR (COALESCE(f.name, ''), f.id) < (CAST(? AS TEXT), ?)
+  )
+ORDER BY COALESCE(f.name, '') DESC, f.id DESC
+LIMIT ?
+`
+
+type ReadFlowsPageByNameDescParams struct {
+	Status        sql.NullString
+	Model         sql.NullString
+	ModelProvider sql.NullString
+	OwnerID       sql.NullInt64
+	TeamID        sql.NullInt64
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	AllFlows      bool
+	ViewerID      int64
+	ViewerAdmin   bool
+	AfterID       sql.NullInt64
+	AfterName     string
+	Limit         int64
+}
+
+type ReadFlowsPageByNameDescRow struct {
+	ID                int64
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+	Name              sql.NullString
+	Status            sql.NullString
+	ContainerID       sql.NullInt64
+	Model             sql.NullString
+	ModelProvider     sql.NullString
+	Sandbox           sql.NullString
+	McpServers        sql.NullString
+	OwnerID           sql.NullInt64
+	TeamID            sql.NullInt64
+	ContainerName     sql.NullString
+	BrowserUrl        sql.NullString
+	BrowserScreenshot sql.NullString
+}
+
+func (q *Queries) ReadFlowsPageByNameDesc(ctx context.Context, arg ReadFlowsPageByNameDescParams) ([]ReadFlowsPageByNameDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, readFlowsPageByNameDesc,
+		arg.Status,
+		arg.Model,
+		arg.ModelProvider,
+		arg.OwnerID,
+		arg.TeamID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AllFlows,
+		arg.ViewerID,
+		arg.ViewerAdmin,
+		arg.ViewerID,
+		arg.AfterID,
+		arg.AfterName,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReadFlowsPageByNameDescRow
+	for rows.Next() {
+		var i ReadFlowsPageByNameDescRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Status,
+			&i.ContainerID,
+			&i.Model,
+			&i.ModelProvider,
+			&i.Sandbox,
+			&i.McpServers,
+			&i.OwnerID,
+			&i.TeamID,
+			&i.ContainerName,
+			&i.BrowserUrl,
+			&i.BrowserScreenshot,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const readFlowsPageDesc = `-- name: ReadFlowsPageDesc :many
+SELECT
+  f.id, f.created_at, f.updated_at, f.name, f.status, f.container_id, f.model, f.model_provider, f.sandbox, f.mcp_servers, f.owner_id, f.team_id,
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
+FROM flows f
+LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
+WHERE f.status IS COALESCE(?, f.status)
+  AND f.model IS COALESCE(?, f.model)
+  AND f.model_provider IS COALESCE(?, f.model_provider)
+  AND f.owner_id IS COALESCE(?, f.owner_id)
+  AND f.team_id IS COALESCE(?, f.team_id)
+  AND f.created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(?, '9999-12-31'))
+  AND (
+    CAST(? AS BOOLEAN)
+    OR f.owner_id = CAST(? AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(? AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(? AS INTEGER))
+  )
+  AND f.id < COALESCE(?, 9223372036854775807)
+ORDER BY f.id DESC
+LIMIT ?
+`
+
+type ReadFlowsPageDescParams struct {
+	Status        sql.NullString
+	Model         sql.NullString
+	ModelProvider sql.NullString
+	OwnerID       sql.NullInt64
+	TeamID        sql.NullInt64
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	AllFlows      bool
+	ViewerID      int64
+	ViewerAdmin   bool
+	AfterID       sql.NullInt64
+	Limit         int64
+}
+
+type ReadFlowsPageDescRow struct {
+	ID                int64
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+	Name              sql.NullString
+	Status            sql.NullString
+	ContainerID       sql.NullInt64
+	Model             sql.NullString
+	ModelProvider     sql.NullString
+	Sandbox           sql.NullString
+	McpServers        sql.NullString
+	OwnerID           sql.NullInt64
+	TeamID            sql.NullInt64
+	ContainerName     sql.NullString
+	BrowserUrl        sql.NullString
+	BrowserScreenshot sql.NullString
+}
+
+func (q *Queries) ReadFlowsPageDesc(ctx context.Context, arg ReadFlowsPageDescParams) ([]ReadFlowsPageDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, readFlowsPageDesc,
+		arg.Status,
+		arg.Model,
+		arg.ModelProvider,
+		arg.OwnerID,
+		arg.TeamID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AllFlows,
+		arg.ViewerID,
+		arg.ViewerAdmin,
+		arg.ViewerID,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReadFlowsPageDescRow
+	for rows.Next() {
+		var i ReadFlowsPageDescRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Status,
+			&i.ContainerID,
+			&i.Model,
+			&i.ModelProvider,
+			&i.Sandbox,
+			&i.McpServers,
+			&i.OwnerID,
+			&i.TeamID,
+			&i.ContainerName,
+			&i.BrowserUrl,
+			&i.BrowserScreenshot,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateFlowContainer = `-- name: UpdateFlowContainer :one
 UPDATE flows
 SET container_id = ?
 WHERE id = ?
-RETURNING id, created_at, updated_at, name, status, container_id, model, model_provider
+RETURNING id, created_at, updated_at, name, status, container_id, model, model_provider, sandbox, mcp_servers, owner_id, team_id
 `
 
 type UpdateFlowContainerParams struct {
@@ -175,6 +757,10 @@ func (q *Queries) UpdateFlowContainer(ctx context.Context, arg UpdateFlowContain
 		&i.ContainerID,
 		&i.Model,
 		&i.ModelProvider,
+		&i.Sandbox,
+		&i.McpServers,
+		&i.OwnerID,
+		&i.TeamID,
 	)
 	return i, err
 }
@@ -183,7 +769,7 @@ const updateFlowName = `-- name: UpdateFlowName :one
 UPDATE flows
 SET name = ?
 WHERE id = ?
-RETURNING id, created_at, updated_at, name, status, container_id, model, model_provider
+RETURNING id, created_at, updated_at, name, status, container_id, model, model_provider, sandbox, mcp_servers, owner_id, team_id
 `
 
 type UpdateFlowNameParams struct {
@@ -203,6 +789,10 @@ func (q *Queries) UpdateFlowName(ctx context.Context, arg UpdateFlowNameParams)
 		&i.ContainerID,
 		&i.Model,
 		&i.ModelProvider,
+		&i.Sandbox,
+		&i.McpServers,
+		&i.OwnerID,
+		&i.TeamID,
 	)
 	return i, err
 }
@@ -211,7 +801,7 @@ const updateFlowStatus = `-- name: UpdateFlowStatus :one
 UPDATE flows
 SET status = ?
 WHERE id = ?
-RETURNING id, created_at, updated_at, name, status, container_id, model, model_provider
+RETURNING id, created_at, updated_at, name, status, container_id, model, model_provider, sandbox, mcp_servers, owner_id, team_id
 `
 
 type UpdateFlowStatusParams struct {
@@ -231,6 +821,42 @@ func (q *Queries) UpdateFlowStatus(ctx context.Context, arg UpdateFlowStatusPara
 		&i.ContainerID,
 		&i.Model,
 		&i.ModelProvider,
+		&i.Sandbox,
+		&i.McpServers,
+		&i.OwnerID,
+		&i.TeamID,
+	)
+	return i, err
+}
+
+const updateFlowTeam = `-- name: UpdateFlowTeam :one
+UPDATE flows
+SET team_id = ?
+WHERE id = ?
+RETURNING id, created_at, updated_at, name, status, container_id, model, model_provider, sandbox, mcp_servers, owner_id, team_id
+`
+
+type UpdateFlowTeamParams struct {
+	TeamID sql.NullInt64
+	ID     int64
+}
+
+func (q *Queries) UpdateFlowTeam(ctx context.Context, arg UpdateFlowTeamParams) (Flow, error) {
+	row := q.db.QueryRowContext(ctx, updateFlowTeam, arg.TeamID, arg.ID)
+	var i Flow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Status,
+		&i.ContainerID,
+		&i.Model,
+		&i.ModelProvider,
+		&i.Sandbox,
+		&i.McpServers,
+		&i.OwnerID,
+		&i.TeamID,
 	)
 	return i, err
 }
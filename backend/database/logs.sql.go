@@ -8,8 +8,62 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const copyLogsToFlow = `-- name: CopyLogsToFlow :exec
+INSERT INTO logs (
+  message, created_at, flow_id, type
+)
+SELECT
+  message,
+  created_at,
+  CAST(? AS INTEGER),
+  type
+FROM logs
+WHERE flow_id = ? AND created_at <= ?
+ORDER BY id ASC
+`
+
+type CopyLogsToFlowParams struct {
+	TargetFlowID int64
+	SourceFlowID sql.NullInt64
+	Until        time.Time
+}
+
+func (q *Queries) CopyLogsToFlow(ctx context.Context, arg CopyLogsToFlowParams) error {
+	_, err := q.db.ExecContext(ctx, copyLogsToFlow, arg.TargetFlowID, arg.SourceFlowID, arg.Until)
+	return err
+}
+
+const countLogs = `-- name: CountLogs :one
+SELECT COUNT(*)
+FROM logs
+WHERE flow_id = ?
+  AND type = COALESCE(?, type)
+  AND created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND created_at < datetime(COALESCE(?, '9999-12-31'))
+`
+
+type CountLogsParams struct {
+	FlowID        sql.NullInt64
+	Type          sql.NullString
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+}
+
+func (q *Queries) CountLogs(ctx context.Context, arg CountLogsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countLogs,
+		arg.FlowID,
+		arg.Type,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createLog = `-- name: CreateLog :one
 INSERT INTO logs (
   message, flow_id, type
@@ -74,3 +128,117 @@ func (q *Queries) GetLogsByFlowId(ctx context.Context, flowID sql.NullInt64) ([]
 	}
 	return items, nil
 }
+
+const readLogsPageAsc = `-- name: ReadLogsPageAsc :many
+SELECT id, message, created_at, flow_id, type
+FROM logs
+WHERE flow_id = ?
+  AND type = COALESCE(?, type)
+  AND created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND created_at < datetime(COALESCE(?, '9999-12-31'))
+  AND id > COALESCE(?, 0)
+ORDER BY id ASC
+LIMIT ?
+`
+
+type ReadLogsPageAscParams struct {
+	FlowID        sql.NullInt64
+	Type          sql.NullString
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	AfterID       sql.NullInt64
+	Limit         int64
+}
+
+func (q *Queries) ReadLogsPageAsc(ctx context.Context, arg ReadLogsPageAscParams) ([]Log, error) {
+	rows, err := q.db.QueryContext(ctx, readLogsPageAsc,
+		arg.FlowID,
+		arg.Type,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Log
+	for rows.Next() {
+		var i Log
+		if err := rows.Scan(
+			&i.ID,
+			&i.Message,
+			&i.CreatedAt,
+			&i.FlowID,
+			&i.Type,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const readLogsPageDesc = `-- name: ReadLogsPageDesc :many
+SELECT id, message, created_at, flow_id, type
+FROM logs
+WHERE flow_id = ?
+  AND type = COALESCE(?, type)
+  AND created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND created_at < datetime(COALESCE(?, '9999-12-31'))
+  AND id < COALESCE(?, 9223372036854775807)
+ORDER BY id DESC
+LIMIT ?
+`
+
+type ReadLogsPageDescParams struct {
+	FlowID        sql.NullInt64
+	Type          sql.NullString
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	AfterID       sql.NullInt64
+	Limit         int64
+}
+
+func (q *Queries) ReadLogsPageDesc(ctx context.Context, arg ReadLogsPageDescParams) ([]Log, error) {
+	rows, err := q.db.QueryContext(ctx, readLogsPageDesc,
+		arg.FlowID,
+		arg.Type,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Log
+	for rows.Next() {
+		var i Log
+		if err := rows.Scan(
+			&i.ID,
+			&i.Message,
+			&i.CreatedAt,
+			&i.FlowID,
+			&i.Type,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
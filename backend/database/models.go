@@ -9,12 +9,48 @@ import (
 	"time"
 )
 
+type ApiToken struct {
+	ID         int64
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+	UserID     int64
+	Name       string
+	TokenHash  string
+	Scope      string
+}
+
+type AuditEvent struct {
+	ID        int64
+	CreatedAt time.Time
+	UserID    sql.NullInt64
+	Actor     string
+	Action    string
+	FlowID    sql.NullInt64
+	TaskID    sql.NullInt64
+	Target    string
+	Details   string
+	Error     sql.NullString
+	PrevHash  string
+	Hash      string
+}
+
+type CommandPolicy struct {
+	ID        int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	FlowID    sql.NullInt64
+	TeamID    sql.NullInt64
+	Policy    string
+}
+
 type Container struct {
-	ID      int64
-	Name    sql.NullString
-	LocalID sql.NullString
-	Image   sql.NullString
-	Status  sql.NullString
+	ID        int64
+	Name      sql.NullString
+	LocalID   sql.NullString
+	Image     sql.NullString
+	Status    sql.NullString
+	ParentID  sql.NullInt64
+	BaseImage sql.NullString
 }
 
 type Flow struct {
@@ -26,6 +62,10 @@ type Flow struct {
 	ContainerID   sql.NullInt64
 	Model         sql.NullString
 	ModelProvider sql.NullString
+	Sandbox       sql.NullString
+	McpServers    sql.NullString
+	OwnerID       sql.NullInt64
+	TeamID        sql.NullInt64
 }
 
 type Log struct {
@@ -36,15 +76,72 @@ type Log struct {
 	Type      string
 }
 
+type Screenshot struct {
+	ID        int64
+	CreatedAt time.Time
+	FlowID    int64
+	TaskID    int64
+	Url       string
+	Path      string
+	Sha256    string
+	Size      int64
+}
+
+type Secret struct {
+	ID        int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Name      string
+	Value     []byte
+	UserID    sql.NullInt64
+	TeamID    sql.NullInt64
+}
+
+type Snapshot struct {
+	ID          int64
+	CreatedAt   time.Time
+	FlowID      int64
+	TaskID      int64
+	ContainerID sql.NullInt64
+	Image       string
+	Reason      string
+}
+
 type Task struct {
-	ID         int64
-	CreatedAt  sql.NullTime
-	UpdatedAt  sql.NullTime
-	Type       sql.NullString
-	Status     sql.NullString
-	Args       sql.NullString
-	Results    sql.NullString
-	Message    sql.NullString
-	FlowID     sql.NullInt64
-	ToolCallID sql.NullString
+	ID             int64
+	CreatedAt      sql.NullTime
+	UpdatedAt      sql.NullTime
+	Type           sql.NullString
+	Status         sql.NullString
+	Args           sql.NullString
+	Results        sql.NullString
+	Message        sql.NullString
+	FlowID         sql.NullInt64
+	ToolCallID     sql.NullString
+	Redactions     string
+	Untrusted      bool
+	InjectionFlags string
+	Approval       string
+}
+
+type Team struct {
+	ID        int64
+	CreatedAt time.Time
+	Name      string
+}
+
+type TeamMember struct {
+	TeamID    int64
+	UserID    int64
+	CreatedAt time.Time
+	Role      string
+}
+
+type User struct {
+	ID           int64
+	CreatedAt    time.Time
+	Username     string
+	PasswordHash string
+	Admin        bool
+	OidcSubject  sql.NullString
 }
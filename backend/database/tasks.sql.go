@@ -10,6 +10,82 @@ import (
 	"database/sql"
 )
 
+const copyTasksToFlow = `-- name: CopyTasksToFlow :exec
+INSERT INTO tasks (
+  created_at,
+  updated_at,
+  type,
+  status,
+  args,
+  results,
+  message,
+  flow_id,
+  tool_call_id,
+  redactions,
+  untrusted,
+  injection_flags,
+  approval
+)
+SELECT
+  created_at,
+  updated_at,
+  type,
+  status,
+  args,
+  results,
+  message,
+  CAST(? AS INTEGER),
+  tool_call_id,
+  redactions,
+  untrusted,
+  injection_flags,
+  approval
+FROM tasks
+WHERE flow_id = ? AND id <= ?
+ORDER BY id ASC
+`
+
+type CopyTasksToFlowParams struct {
+	TargetFlowID int64
+	SourceFlowID sql.NullInt64
+	UntilTaskID  int64
+}
+
+func (q *Queries) CopyTasksToFlow(ctx context.Context, arg CopyTasksToFlowParams) error {
+	_, err := q.db.ExecContext(ctx, copyTasksToFlow, arg.TargetFlowID, arg.SourceFlowID, arg.UntilTaskID)
+	return err
+}
+
+const countTasks = `-- name: CountTasks :one
+SELECT COUNT(*) FROM tasks
+WHERE flow_id = ?
+  AND status IS COALESCE(?, status)
+  AND type IS COALESCE(?, type)
+  AND created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND created_at < datetime(COALESCE(?, '9999-12-31'))
+`
+
+type CountTasksParams struct {
+	FlowID        sql.NullInt64
+	Status        sql.NullString
+	Type          sql.NullString
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+}
+
+func (q *Queries) CountTasks(ctx context.Context, arg CountTasksParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTasks,
+		arg.FlowID,
+		arg.Status,
+		arg.Type,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTask = `-- name: CreateTask :one
 INSERT INTO tasks (
   type,
@@ -22,7 +98,7 @@ INSERT INTO tasks (
 ) VALUES (
   ?, ?, ?, ?, ?, ?, ?
 )
-RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id
+RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval
 `
 
 type CreateTaskParams struct {
@@ -57,12 +133,58 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 		&i.Message,
 		&i.FlowID,
 		&i.ToolCallID,
+		&i.Redactions,
+		&i.Untrusted,
+		&i.InjectionFlags,
+		&i.Approval,
+	)
+	return i, err
+}
+
+const deleteTasksAfter = `-- name: DeleteTasksAfter :exec
+DELETE FROM tasks
+WHERE flow_id = ? AND id > ?
+`
+
+type DeleteTasksAfterParams struct {
+	FlowID sql.NullInt64
+	ID     int64
+}
+
+func (q *Queries) DeleteTasksAfter(ctx context.Context, arg DeleteTasksAfterParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTasksAfter, arg.FlowID, arg.ID)
+	return err
+}
+
+const readTask = `-- name: ReadTask :one
+SELECT id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval FROM tasks
+WHERE id = ?
+`
+
+func (q *Queries) ReadTask(ctx context.Context, id int64) (Task, error) {
+	row := q.db.QueryRowContext(ctx, readTask, id)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.Status,
+		&i.Args,
+		&i.Results,
+		&i.Message,
+		&i.FlowID,
+		&i.ToolCallID,
+		&i.Redactions,
+		&i.Untrusted,
+		&i.InjectionFlags,
+		&i.Approval,
 	)
 	return i, err
 }
 
 const readTasksByFlowId = `-- name: ReadTasksByFlowId :many
-SELECT id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id FROM tasks
+SELECT id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval FROM tasks
 WHERE flow_id = ?
 ORDER BY created_at ASC
 `
@@ -87,6 +209,146 @@ func (q *Queries) ReadTasksByFlowId(ctx context.Context, flowID sql.NullInt64) (
 			&i.Message,
 			&i.FlowID,
 			&i.ToolCallID,
+			&i.Redactions,
+			&i.Untrusted,
+			&i.InjectionFlags,
+			&i.Approval,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const readTasksPageAsc = `-- name: ReadTasksPageAsc :many
+SELECT id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval FROM tasks
+WHERE flow_id = ?
+  AND status IS COALESCE(?, status)
+  AND type IS COALESCE(?, type)
+  AND created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND created_at < datetime(COALESCE(?, '9999-12-31'))
+  AND id > COALESCE(?, 0)
+ORDER BY id ASC
+LIMIT ?
+`
+
+type ReadTasksPageAscParams struct {
+	FlowID        sql.NullInt64
+	Status        sql.NullString
+	Type          sql.NullString
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	AfterID       sql.NullInt64
+	Limit         int64
+}
+
+func (q *Queries) ReadTasksPageAsc(ctx context.Context, arg ReadTasksPageAscParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, readTasksPageAsc,
+		arg.FlowID,
+		arg.Status,
+		arg.Type,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.Status,
+			&i.Args,
+			&i.Results,
+			&i.Message,
+			&i.FlowID,
+			&i.ToolCallID,
+			&i.Redactions,
+			&i.Untrusted,
+			&i.InjectionFlags,
+			&i.Approval,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const readTasksPageDesc = `-- name: ReadTasksPageDesc :many
+SELECT id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval FROM tasks
+WHERE flow_id = ?
+  AND status IS COALESCE(?, status)
+  AND type IS COALESCE(?, type)
+  AND created_at >= datetime(COALESCE(?, '0001-01-01'))
+  AND created_at < datetime(COALESCE(?, '9999-12-31'))
+  AND id < COALESCE(?, 9223372036854775807)
+ORDER BY id DESC
+LIMIT ?
+`
+
+type ReadTasksPageDescParams struct {
+	FlowID        sql.NullInt64
+	Status        sql.NullString
+	Type          sql.NullString
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	AfterID       sql.NullInt64
+	Limit         int64
+}
+
+func (q *Queries) ReadTasksPageDesc(ctx context.Context, arg ReadTasksPageDescParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, readTasksPageDesc,
+		arg.FlowID,
+		arg.Status,
+		arg.Type,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Type,
+			&i.Status,
+			&i.Args,
+			&i.Results,
+			&i.Message,
+			&i.FlowID,
+			&i.ToolCallID,
+			&i.Redactions,
+			&i.Untrusted,
+			&i.InjectionFlags,
+			&i.Approval,
 		); err != nil {
 			return nil, err
 		}
@@ -101,11 +363,68 @@ func (q *Queries) ReadTasksByFlowId(ctx context.Context, flowID sql.NullInt64) (
 	return items, nil
 }
 
+const updateTaskApproval = `-- name: UpdateTaskApproval :one
+UPDATE tasks
+SET approval = ?, results = ?
+WHERE id = ? AND approval = ?
+RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval
+`
+
+type UpdateTaskApprovalParams struct {
+	Approval        string
+	Results         sql.NullString
+	ID              int64
+	CurrentApproval string
+}
+
+func (q *Queries) UpdateTaskApproval(ctx context.Context, arg UpdateTaskApprovalParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, updateTaskApproval,
+		arg.Approval,
+		arg.Results,
+		arg.ID,
+		arg.CurrentApproval,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.Status,
+		&i.Args,
+		&i.Results,
+		&i.Message,
+		&i.FlowID,
+		&i.ToolCallID,
+		&i.Redactions,
+		&i.Untrusted,
+		&i.InjectionFlags,
+		&i.Approval,
+	)
+	return i, err
+}
+
+const updateTaskRedactions = `-- name: UpdateTaskRedactions :exec
+UPDATE tasks
+SET redactions = ?
+WHERE id = ?
+`
+
+type UpdateTaskRedactionsParams struct {
+	Redactions string
+	ID         int64
+}
+
+func (q *Queries) UpdateTaskRedactions(ctx context.Context, arg UpdateTaskRedactionsParams) error {
+	_, err := q.db.ExecContext(ctx, updateTaskRedactions, arg.Redactions, arg.ID)
+	return err
+}
+
 const updateTaskResults = `-- name: UpdateTaskResults :one
 UPDATE tasks
 SET results = ?
 WHERE id = ?
-RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id
+RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval
 `
 
 type UpdateTaskResultsParams struct {
@@ -127,6 +446,10 @@ func (q *Queries) UpdateTaskResults(ctx context.Context, arg UpdateTaskResultsPa
 		&i.Message,
 		&i.FlowID,
 		&i.ToolCallID,
+		&i.Redactions,
+		&i.Untrusted,
+		&i.InjectionFlags,
+		&i.Approval,
 	)
 	return i, err
 }
@@ -135,7 +458,7 @@ const updateTaskStatus = `-- name: UpdateTaskStatus :one
 UPDATE tasks
 SET status = ?
 WHERE id = ?
-RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id
+RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval
 `
 
 type UpdateTaskStatusParams struct {
@@ -157,6 +480,10 @@ func (q *Queries) UpdateTaskStatus(ctx context.Context, arg UpdateTaskStatusPara
 		&i.Message,
 		&i.FlowID,
 		&i.ToolCallID,
+		&i.Redactions,
+		&i.Untrusted,
+		&i.InjectionFlags,
+		&i.Approval,
 	)
 	return i, err
 }
@@ -165,7 +492,7 @@ const updateTaskToolCallId = `-- name: UpdateTaskToolCallId :one
 UPDATE tasks
 SET tool_call_id = ?
 WHERE id = ?
-RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id
+RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval
 `
 
 type UpdateTaskToolCallIdParams struct {
@@ -187,6 +514,45 @@ func (q *Queries) UpdateTaskToolCallId(ctx context.Context, arg UpdateTaskToolCa
 		&i.Message,
 		&i.FlowID,
 		&i.ToolCallID,
+		&i.Redactions,
+		&i.Untrusted,
+		&i.InjectionFlags,
+		&i.Approval,
+	)
+	return i, err
+}
+
+const updateTaskUntrusted = `-- name: UpdateTaskUntrusted :one
+UPDATE tasks
+SET untrusted = ?, injection_flags = ?
+WHERE id = ?
+RETURNING id, created_at, updated_at, type, status, args, results, message, flow_id, tool_call_id, redactions, untrusted, injection_flags, approval
+`
+
+type UpdateTaskUntrustedParams struct {
+	Untrusted      bool
+	InjectionFlags string
+	ID             int64
+}
+
+func (q *Queries) UpdateTaskUntrusted(ctx context.Context, arg UpdateTaskUntrustedParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, updateTaskUntrusted, arg.Untrusted, arg.InjectionFlags, arg.ID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Type,
+		&i.Status,
+		&i.Args,
+		&i.Results,
+		&i.Message,
+		&i.FlowID,
+		&i.ToolCallID,
+		&i.Redactions,
+		&i.Untrusted,
+		&i.InjectionFlags,
+		&i.Approval,
 	)
 	return i, err
 }
@@ -3,7 +3,6 @@ package executor
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/arandu-ai/arandu/assets"
@@ -20,26 +19,40 @@ import (
 
 const port = "9222"
 
+// chromeCommand starts Chrome with the debugging port bound to the address the
+// container has when it starts, on the control network. The flow networks are
+// connected later, so sandboxes never reach the DevTools protocol
+var chromeCommand = fmt.Sprintf(
+	`exec chrome --headless --no-sandbox --remote-debugging-port=%s --remote-debugging-address="$(hostname -i | cut -d' ' -f1)"`,
+	port,
+)
+
 func InitBrowser(db *database.Queries) error {
 	browserContainerName := BrowserName()
 	portBinding := nat.Port(fmt.Sprintf("%s/tcp", port))
 
+	if err := ensureNetwork(context.Background(), config.Config.BrowserControlNetwork, false); err != nil {
+		return err
+	}
+
 	_, err := SpawnContainer(context.Background(), browserContainerName, &container.Config{
 		Image: "ghcr.io/go-rod/rod",
 		ExposedPorts: nat.PortSet{
 			portBinding: struct{}{},
 		},
-		Cmd: []string{"chrome", "--headless", "--no-sandbox", fmt.Sprintf("--remote-debugging-port=%s", port), "--remote-debugging-address=0.0.0.0"},
+		Cmd: []string{"sh", "-c", chromeCommand},
 	}, &container.HostConfig{
+		NetworkMode: container.NetworkMode(config.Config.BrowserControlNetwork),
+		// Only published on the loopback of the host, for a server running outside Docker
 		PortBindings: nat.PortMap{
 			portBinding: []nat.PortBinding{
 				{
-					HostIP:   "0.0.0.0",
+					HostIP:   "127.0.0.1",
 					HostPort: port,
 				},
 			},
 		},
-	}, db)
+	}, nil, db)
 
 	if err != nil {
 		return fmt.Errorf("failed to spawn container: %w", err)
@@ -48,123 +61,84 @@ func InitBrowser(db *database.Queries) error {
 	return nil
 }
 
-func Content(url string) (result string, screenshotName string, err error) {
+func Content(flowID int64, url string) (result string, screenshot []byte, err error) {
 	logging.Debug("Trying to get content from URL", "url", url)
 
-	page, err := loadPage()
+	page, release, err := openURL(flowID, url)
 
 	if err != nil {
-		return "", "", fmt.Errorf("error loading page: %w", err)
+		return "", nil, err
 	}
 
-	err = loadUrl(page, url)
+	defer release()
 
-	if err != nil {
-		return "", "", fmt.Errorf("error loading url: %w", err)
-	}
-
-	script, err := templates.Render(assets.ScriptTemplates, "scripts/content.js", nil)
+	script, err := templates.Render(assets.ScriptTemplates, "scripts/markdown.js", nil)
 
 	if err != nil {
-		return "", "", fmt.Errorf("error reading script: %w", err)
+		return "", nil, fmt.Errorf("error reading script: %w", err)
 	}
 
 	pageText, err := page.Eval(string(script))
 
 	if err != nil {
-		return "", "", fmt.Errorf("error evaluating script: %w", err)
+		return "", nil, fmt.Errorf("error evaluating script: %w", err)
 	}
 
-	screenshot, err := page.Screenshot(false, nil)
+	screenshot, err = page.Screenshot(false, nil)
 
 	if err != nil {
-		return "", "", fmt.Errorf("error taking screenshot: %w", err)
+		return "", nil, fmt.Errorf("error taking screenshot: %w", err)
 	}
 
-	screenshotName, err = writeScreenshotToFile(screenshot)
-
-	if err != nil {
-		return "", "", fmt.Errorf("error writing screenshot to file: %w", err)
-	}
-
-	return pageText.Value.Str(), screenshotName, nil
+	return pageText.Value.Str(), screenshot, nil
 }
 
-func URLs(url string) (result string, screenshotName string, err error) {
+func URLs(flowID int64, url string) (result string, screenshot []byte, err error) {
 	logging.Debug("Trying to get URLs from page", "url", url)
 
-	page, err := loadPage()
+	page, release, err := openURL(flowID, url)
 
 	if err != nil {
-		return "", "", fmt.Errorf("error loading page: %w", err)
+		return "", nil, err
 	}
 
-	err = loadUrl(page, url)
-
-	if err != nil {
-		return "", "", fmt.Errorf("error loading url: %w", err)
-	}
+	defer release()
 
 	script, err := templates.Render(assets.ScriptTemplates, "scripts/urls.js", nil)
 
 	if err != nil {
-		return "", "", fmt.Errorf("error reading script: %w", err)
+		return "", nil, fmt.Errorf("error reading script: %w", err)
 	}
 
 	urls, err := page.Eval(string(script))
 
 	if err != nil {
-		return "", "", fmt.Errorf("error evaluating script: %w", err)
-	}
-
-	screenshot, err := page.Screenshot(true, nil)
-
-	if err != nil {
-		return "", "", fmt.Errorf("error taking screenshot: %w", err)
+		return "", nil, fmt.Errorf("error evaluating script: %w", err)
 	}
 
-	screenshotName, err = writeScreenshotToFile(screenshot)
+	screenshot, err = page.Screenshot(true, nil)
 
 	if err != nil {
-		return "", "", fmt.Errorf("error writing screenshot to file: %w", err)
+		return "", nil, fmt.Errorf("error taking screenshot: %w", err)
 	}
 
-	return urls.Value.Str(), screenshotName, nil
-}
-
-func writeScreenshotToFile(screenshot []byte) (filename string, err error) {
-	// Write screenshot to file
-	filename = fmt.Sprintf("%s.png", time.Now().Format("2006-01-02-15-04-05"))
-	path := "./tmp/browser/"
-	filepath := fmt.Sprintf("./tmp/browser/%s", filename)
-
-	err = os.MkdirAll(path, os.ModePerm)
-	if err != nil {
-		return "", fmt.Errorf("error creating directory: %w", err)
-	}
-
-	file, err := os.Create(filepath)
-
-	if err != nil {
-		return "", fmt.Errorf("error creating file: %w", err)
-	}
-
-	defer file.Close()
-
-	_, err = file.Write(screenshot)
-
-	if err != nil {
-		return "", fmt.Errorf("error writing to file: %w", err)
-	}
-
-	return filename, nil
+	return urls.Value.Str(), screenshot, nil
 }
 
 func BrowserName() string {
 	return "arandu-browser"
 }
 
-func loadPage() (*rod.Page, error) {
+// connectBrowser returns the shared connection to Chrome, connecting on first use.
+// Flows never use it directly: each one gets its own incognito context.
+func connectBrowser() (*rod.Browser, error) {
+	browserConn.Lock()
+	defer browserConn.Unlock()
+
+	if browserConn.browser != nil {
+		return browserConn.browser, nil
+	}
+
 	// Bug fix #65: Use configurable Chrome debug URL or try multiple fallbacks
 	var u string
 	var err error
@@ -217,6 +191,18 @@ func loadPage() (*rod.Page, error) {
 	}
 	logging.Info("Connected to browser", "product", version.Product)
 
+	browserConn.browser = browser
+	return browser, nil
+}
+
+// loadPage opens a new page in the flow's isolated browser context
+func loadPage(flowID int64) (*rod.Page, error) {
+	browser, err := flowBrowser(flowID)
+
+	if err != nil {
+		return nil, err
+	}
+
 	page, err := browser.Page(proto.TargetCreateTarget{})
 
 	if err != nil {
@@ -226,11 +212,43 @@ func loadPage() (*rod.Page, error) {
 	return page, nil
 }
 
-func loadUrl(page *rod.Page, url string) error {
+// openURL loads a URL in a new page of the flow's context.
+// release stops request hijacking and closes the page.
+func openURL(flowID int64, url string) (page *rod.Page, release func(), err error) {
+	page, err = loadPage(flowID)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading page: %w", err)
+	}
+
+	router, err := loadUrl(flowID, page, url)
+	release = func() {
+		if router != nil {
+			_ = router.Stop()
+		}
+		if err := page.Close(); err != nil {
+			logging.Debug("Failed to close browser page", "flow_id", flowID, "error", err.Error())
+		}
+	}
+
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("error loading url: %w", err)
+	}
+
+	return page, release, nil
+}
+
+func loadUrl(flowID int64, page *rod.Page, url string) (*rod.HijackRouter, error) {
 	pageRouter := page.HijackRequests()
 
 	// Do not load any images or css files
 	pageRouter.MustAdd("*", func(ctx *rod.Hijack) {
+		// Redirects and subresources follow the same URL policy as the page
+		if blockFlowRequest(flowID, ctx) {
+			return
+		}
+
 		// There're a lot of types you can use in this enum, like NetworkResourceTypeScript for javascript files
 		// In this case we're using NetworkResourceTypeImage to block images
 		if ctx.Request.Type() == proto.NetworkResourceTypeImage ||
@@ -251,14 +269,14 @@ func loadUrl(page *rod.Page, url string) error {
 	err := page.Navigate(url)
 
 	if err != nil {
-		return fmt.Errorf("error navigating to page: %w", err)
+		return pageRouter, fmt.Errorf("error navigating to page: %w", err)
 	}
 
 	err = page.WaitDOMStable(time.Second*1, 5)
 
 	if err != nil {
-		return fmt.Errorf("error waiting for page to stabilize: %w", err)
+		return pageRouter, fmt.Errorf("error waiting for page to stabilize: %w", err)
 	}
 
-	return nil
+	return pageRouter, nil
 }
@@ -0,0 +1,453 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arandu-ai/arandu/assets"
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/providers"
+	"github.com/arandu-ai/arandu/templates"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	// BrowserActionTimeout es el tiempo máximo de una acción interactiva del browser
+	BrowserActionTimeout = 30 * time.Second
+	// BrowserQueueTimeout es el tiempo máximo de espera por un hueco libre del browser
+	BrowserQueueTimeout = 2 * time.Minute
+	// MaxEvaluateScriptLength es el tamaño máximo del JavaScript que puede evaluar el agente
+	MaxEvaluateScriptLength = 4000
+	// browserScrollStep es el desplazamiento en píxeles de un scroll sin selector
+	browserScrollStep = 600
+)
+
+// clickableSelector son los elementos candidatos al buscar por texto visible
+const clickableSelector = "a, button, input[type=button], input[type=submit], input[type=reset], " +
+	"[role=button], [role=link], [role=tab], [role=menuitem], [role=option], label, summary, [onclick]"
+
+// browserConn es la conexión compartida con Chrome
+var browserConn struct {
+	sync.Mutex
+	browser *rod.Browser
+}
+
+// flowBrowserContext es el contexto incógnito de un flow: cookies, storage y
+// caché propios, más la página que usan las acciones interactivas
+type flowBrowserContext struct {
+	browser *rod.Browser
+	page    *rod.Page
+	// router aplica la política de URLs a cada petición de la página
+	router *rod.HijackRouter
+}
+
+// browserContexts guarda el contexto del browser de cada flow
+var browserContexts = struct {
+	sync.Mutex
+	flows map[int64]*flowBrowserContext
+}{flows: make(map[int64]*flowBrowserContext)}
+
+// flowContext devuelve el contexto del flow, creándolo si no existe
+// Debe llamarse con browserContexts bloqueado
+func flowContext(flowID int64) (*flowBrowserContext, error) {
+	if fc, ok := browserContexts.flows[flowID]; ok {
+		return fc, nil
+	}
+
+	browser, err := connectBrowser()
+	if err != nil {
+		return nil, err
+	}
+
+	incognito, err := browser.Incognito()
+	if err != nil {
+		// La conexión puede haberse perdido (p.ej. Chrome reiniciado); se reintenta una vez
+		resetBrowserConnection()
+		if browser, err = connectBrowser(); err != nil {
+			return nil, err
+		}
+		if incognito, err = browser.Incognito(); err != nil {
+			return nil, fmt.Errorf("error creating browser context: %w", err)
+		}
+	}
+
+	fc := &flowBrowserContext{browser: incognito}
+	browserContexts.flows[flowID] = fc
+	logging.Debug("Browser context created", "flow_id", flowID, "context_id", incognito.BrowserContextID)
+	return fc, nil
+}
+
+// resetBrowserConnection descarta la conexión con Chrome y los contextos que dependían de ella
+// Debe llamarse con browserContexts bloqueado
+func resetBrowserConnection() {
+	browserConn.Lock()
+	defer browserConn.Unlock()
+
+	logging.Warn("Resetting browser connection", "contexts", len(browserContexts.flows))
+	browserConn.browser = nil
+	clear(browserContexts.flows)
+}
+
+// flowBrowser devuelve el browser aislado del flow
+func flowBrowser(flowID int64) (*rod.Browser, error) {
+	browserContexts.Lock()
+	defer browserContexts.Unlock()
+
+	fc, err := flowContext(flowID)
+	if err != nil {
+		return nil, err
+	}
+	return fc.browser, nil
+}
+
+// flowPage devuelve la página interactiva del flow, abriendo una nueva si no existe
+func flowPage(flowID int64) (*rod.Page, error) {
+	browserContexts.Lock()
+	defer browserContexts.Unlock()
+
+	fc, err := flowContext(flowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if fc.page != nil {
+		return fc.page, nil
+	}
+
+	page, err := fc.browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, fmt.Errorf("error opening page: %w", err)
+	}
+
+	fc.page = page
+	fc.router = guardFlowRequests(flowID, page)
+	logging.Debug("Browser page opened", "flow_id", flowID)
+	return page, nil
+}
+
+// guardFlowRequests intercepta las peticiones de una página del flow y corta,
+// antes de enviarlas, las que no permite la política de URLs: recursos,
+// redirecciones y fetch() de los scripts, no solo la URL de la página
+func guardFlowRequests(flowID int64, page *rod.Page) *rod.HijackRouter {
+	router := page.HijackRequests()
+	router.MustAdd("*", func(ctx *rod.Hijack) {
+		if blockFlowRequest(flowID, ctx) {
+			return
+		}
+		ctx.ContinueRequest(&proto.FetchContinueRequest{})
+	})
+	go router.Run()
+	return router
+}
+
+// blockFlowRequest hace fallar la petición interceptada si su URL no está
+// permitida para el flow y devuelve si la bloqueó
+func blockFlowRequest(flowID int64, ctx *rod.Hijack) bool {
+	requestURL := ctx.Request.URL().String()
+	if err := validateFlowRequest(flowID, requestURL); err != nil {
+		logging.Warn("Browser request blocked", "flow_id", flowID, "url", requestURL, "error", err.Error())
+		ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+		return true
+	}
+	return false
+}
+
+// validateFlowRequest aplica la política de URLs del flow a una petición de
+// sus páginas. data: y blob: no salen a la red
+func validateFlowRequest(flowID int64, raw string) error {
+	lower := strings.ToLower(raw)
+	if strings.HasPrefix(lower, "data:") || strings.HasPrefix(lower, "blob:") {
+		return nil
+	}
+	return validateFlowURL(flowID, raw)
+}
+
+// CloseBrowserContext cierra el contexto del flow junto con todas sus páginas
+func CloseBrowserContext(flowID int64) {
+	browserContexts.Lock()
+	fc, ok := browserContexts.flows[flowID]
+	delete(browserContexts.flows, flowID)
+	browserContexts.Unlock()
+
+	removeSandboxNetwork(flowID)
+	if !ok {
+		return
+	}
+	if fc.router != nil {
+		_ = fc.router.Stop()
+	}
+	if err := fc.browser.Close(); err != nil {
+		logging.Warn("Failed to close browser context", "flow_id", flowID, "error", err.Error())
+		return
+	}
+	logging.Debug("Browser context closed", "flow_id", flowID)
+}
+
+// browserLimiter limita cuántas acciones del browser corren a la vez
+type browserLimiter struct {
+	slots chan struct{}
+}
+
+// newBrowserLimiter crea un limitador de n acciones simultáneas (mínimo 1)
+func newBrowserLimiter(n int) *browserLimiter {
+	return &browserLimiter{slots: make(chan struct{}, max(n, 1))}
+}
+
+// acquire espera un hueco libre; la función devuelta lo libera
+func (l *browserLimiter) acquire(ctx context.Context) (func(), error) {
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("browser is busy: %d actions already running", cap(l.slots))
+	}
+}
+
+var browserLimit = sync.OnceValue(func() *browserLimiter {
+	return newBrowserLimiter(config.Config.BrowserMaxConcurrency)
+})
+
+// acquireBrowserSlot reserva un hueco del browser, esperando como mucho BrowserQueueTimeout
+func acquireBrowserSlot() (func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), BrowserQueueTimeout)
+	defer cancel()
+	return browserLimit().acquire(ctx)
+}
+
+// BrowserInteract ejecuta una acción interactiva sobre la página del flow
+// Devuelve el texto de la página, la captura y la URL en la que quedó
+func BrowserInteract(flowID int64, args providers.BrowserArgs, db *database.Queries) (result string, screenshot []byte, pageURL string, err error) {
+	if args.Action == providers.Navigate {
+		if args.Url, err = ResolveSandboxURL(flowID, args.Url, db); err != nil {
+			return "", nil, "", err
+		}
+	}
+
+	if err := validateInteractiveArgs(flowID, args); err != nil {
+		return "", nil, "", err
+	}
+
+	page, err := flowPage(flowID)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("error loading page: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), BrowserActionTimeout)
+	defer cancel()
+	p := page.Context(ctx)
+
+	logging.Debug("Browser action", "flow_id", flowID, "action", args.Action, "selector", args.Selector)
+
+	output, err := runBrowserAction(p, args)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("error executing %s: %w", args.Action, err)
+	}
+
+	// Las peticiones bloqueadas ya no salieron; además la página no se queda en
+	// una URL no permitida
+	pageURL, err = enforceURLPolicy(flowID, p)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	result, screenshot, err = capturePage(p, pageURL, output, args)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	return result, screenshot, pageURL, nil
+}
+
+// validateInteractiveArgs comprueba los argumentos requeridos por cada acción
+func validateInteractiveArgs(flowID int64, args providers.BrowserArgs) error {
+	switch args.Action {
+	case providers.Navigate:
+		return validateFlowURL(flowID, args.Url)
+	case providers.Click, providers.WaitFor:
+		if args.Selector == "" && args.Text == "" {
+			return fmt.Errorf("%s requires a selector or text", args.Action)
+		}
+	case providers.Type, providers.Select:
+		if args.Selector == "" {
+			return fmt.Errorf("%s requires a selector", args.Action)
+		}
+	case providers.Scroll:
+		if args.Selector == "" && args.Direction != "" && args.Direction != "up" && args.Direction != "down" {
+			return fmt.Errorf("invalid scroll direction: %s", args.Direction)
+		}
+	case providers.Back:
+	case providers.Evaluate:
+		if !config.Config.BrowserAllowEvaluate {
+			return fmt.Errorf("evaluate is disabled by configuration")
+		}
+		if strings.TrimSpace(args.Script) == "" {
+			return fmt.Errorf("evaluate requires a script")
+		}
+		if len(args.Script) > MaxEvaluateScriptLength {
+			return fmt.Errorf("script too long: %d characters (max %d)", len(args.Script), MaxEvaluateScriptLength)
+		}
+	default:
+		return fmt.Errorf("unknown browser action: %s", args.Action)
+	}
+	return nil
+}
+
+// runBrowserAction ejecuta la acción y devuelve su salida propia (solo evaluate)
+func runBrowserAction(p *rod.Page, args providers.BrowserArgs) (string, error) {
+	switch args.Action {
+	case providers.Navigate:
+		if err := p.Navigate(args.Url); err != nil {
+			return "", err
+		}
+		return "", p.WaitLoad()
+
+	case providers.Click:
+		el, err := findElement(p, args)
+		if err != nil {
+			return "", err
+		}
+		return "", el.Click(proto.InputMouseButtonLeft, 1)
+
+	case providers.Type:
+		el, err := p.Element(args.Selector)
+		if err != nil {
+			return "", err
+		}
+		if err := el.SelectAllText(); err != nil {
+			return "", err
+		}
+		if err := el.Input(args.Text); err != nil {
+			return "", err
+		}
+		if args.Submit {
+			return "", p.Keyboard.Press(input.Enter)
+		}
+		return "", nil
+
+	case providers.Select:
+		el, err := p.Element(args.Selector)
+		if err != nil {
+			return "", err
+		}
+		return "", el.Select([]string{args.Text}, true, rod.SelectorTypeText)
+
+	case providers.Scroll:
+		if args.Selector != "" {
+			el, err := p.Element(args.Selector)
+			if err != nil {
+				return "", err
+			}
+			return "", el.ScrollIntoView()
+		}
+		offset := float64(browserScrollStep)
+		if args.Direction == "up" {
+			offset = -offset
+		}
+		return "", p.Mouse.Scroll(0, offset, 5)
+
+	case providers.WaitFor:
+		el, err := findElement(p, args)
+		if err != nil {
+			return "", err
+		}
+		return "", el.WaitVisible()
+
+	case providers.Back:
+		return "", p.NavigateBack()
+
+	case providers.Evaluate:
+		script, err := templates.Render(assets.ScriptTemplates, "scripts/evaluate.js", nil)
+		if err != nil {
+			return "", fmt.Errorf("error reading script: %w", err)
+		}
+		res, err := p.Evaluate(rod.Eval(string(script), args.Script).ByPromise())
+		if err != nil {
+			return "", err
+		}
+		return res.Value.Str(), nil
+	}
+
+	return "", fmt.Errorf("unknown browser action: %s", args.Action)
+}
+
+// findElement busca el elemento por selector CSS o por su texto visible
+func findElement(p *rod.Page, args providers.BrowserArgs) (*rod.Element, error) {
+	if args.Selector != "" {
+		return p.Element(args.Selector)
+	}
+
+	selector := clickableSelector
+	if args.Action == providers.WaitFor {
+		selector = "body *"
+	}
+	return p.ElementR(selector, textRegex(args.Text))
+}
+
+// textRegex construye la regex JS (sin distinguir mayúsculas) que busca el texto literal
+func textRegex(text string) string {
+	return "/" + regexp.QuoteMeta(strings.TrimSpace(text)) + "/i"
+}
+
+// enforceURLPolicy vuelve a about:blank si la página terminó en una URL no permitida
+func enforceURLPolicy(flowID int64, p *rod.Page) (string, error) {
+	info, err := p.Info()
+	if err != nil {
+		return "", fmt.Errorf("error getting page info: %w", err)
+	}
+
+	if info.URL == "about:blank" {
+		return info.URL, nil
+	}
+
+	if err := validateFlowURL(flowID, info.URL); err != nil {
+		if navErr := p.Navigate("about:blank"); navErr != nil {
+			logging.Warn("Failed to leave blocked page", "url", info.URL, "error", navErr.Error())
+		}
+		return "", fmt.Errorf("page navigated to a blocked URL: %w", err)
+	}
+
+	return info.URL, nil
+}
+
+// capturePage espera a que la página se estabilice y devuelve su texto y una captura
+func capturePage(p *rod.Page, pageURL string, output string, args providers.BrowserArgs) (string, []byte, error) {
+	if err := p.WaitDOMStable(time.Second, 5); err != nil {
+		logging.Debug("Page did not stabilize", "url", pageURL, "error", err.Error())
+	}
+
+	script, err := templates.Render(assets.ScriptTemplates, "scripts/markdown.js", nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("error reading script: %w", err)
+	}
+
+	pageText, err := p.Eval(string(script))
+	if err != nil {
+		return "", nil, fmt.Errorf("error evaluating script: %w", err)
+	}
+
+	screenshot, err := p.Screenshot(false, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("error taking screenshot: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "URL: %s\n", pageURL)
+	if output != "" {
+		fmt.Fprintf(&b, "Result:\n%s\n", output)
+	}
+	b.WriteString("Page content:\n")
+
+	// La salida de evaluate ocupa parte del resultado; la página se achica para que quepa entera
+	size := max(BrowserPageSize-b.Len(), minBrowserPageSize)
+	b.WriteString(paginateContent(pageText.Value.Str(), args.Page, args.Offset, size))
+
+	return b.String(), screenshot, nil
+}
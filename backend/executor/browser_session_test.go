@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/providers"
+)
+
+func TestValidateInteractiveArgs(t *testing.T) {
+	stubHostLookup(t)
+	config.Config.BrowserAllowEvaluate = true
+
+	tests := []struct {
+		name    string
+		args    providers.BrowserArgs
+		wantErr bool
+	}{
+		{name: "navigate valid url", args: providers.BrowserArgs{Action: providers.Navigate, Url: "https://example.com"}},
+		{name: "navigate blocked url", args: providers.BrowserArgs{Action: providers.Navigate, Url: "file:///etc/passwd"}, wantErr: true},
+		{name: "navigate without url", args: providers.BrowserArgs{Action: providers.Navigate}, wantErr: true},
+		{name: "click by selector", args: providers.BrowserArgs{Action: providers.Click, Selector: "#submit"}},
+		{name: "click by text", args: providers.BrowserArgs{Action: providers.Click, Text: "Sign in"}},
+		{name: "click without target", args: providers.BrowserArgs{Action: providers.Click}, wantErr: true},
+		{name: "type", args: providers.BrowserArgs{Action: providers.Type, Selector: "input[name=q]", Text: "arandu"}},
+		{name: "type without selector", args: providers.BrowserArgs{Action: providers.Type, Text: "arandu"}, wantErr: true},
+		{name: "select without selector", args: providers.BrowserArgs{Action: providers.Select, Text: "Spain"}, wantErr: true},
+		{name: "scroll down", args: providers.BrowserArgs{Action: providers.Scroll, Direction: "down"}},
+		{name: "scroll default", args: providers.BrowserArgs{Action: providers.Scroll}},
+		{name: "scroll invalid direction", args: providers.BrowserArgs{Action: providers.Scroll, Direction: "left"}, wantErr: true},
+		{name: "wait_for text", args: providers.BrowserArgs{Action: providers.WaitFor, Text: "Welcome"}},
+		{name: "back", args: providers.BrowserArgs{Action: providers.Back}},
+		{name: "evaluate", args: providers.BrowserArgs{Action: providers.Evaluate, Script: "document.title"}},
+		{name: "evaluate empty", args: providers.BrowserArgs{Action: providers.Evaluate, Script: "  "}, wantErr: true},
+		{name: "evaluate too long", args: providers.BrowserArgs{Action: providers.Evaluate, Script: strings.Repeat("a", MaxEvaluateScriptLength+1)}, wantErr: true},
+		{name: "unknown action", args: providers.BrowserArgs{Action: "hover"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInteractiveArgs(0, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateInteractiveArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateInteractiveArgs_EvaluateDisabled(t *testing.T) {
+	config.Config.BrowserAllowEvaluate = false
+
+	err := validateInteractiveArgs(0, providers.BrowserArgs{Action: providers.Evaluate, Script: "1 + 1"})
+	if err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("validateInteractiveArgs() error = %v, want disabled error", err)
+	}
+}
+
+func TestTextRegex(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Sign in", want: "/Sign in/i"},
+		{text: "  Save (draft) ", want: `/Save \(draft\)/i`},
+		{text: "$9.99", want: `/\$9\.99/i`},
+	}
+
+	for _, tt := range tests {
+		if got := textRegex(tt.text); got != tt.want {
+			t.Errorf("textRegex(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBrowserLimiter(t *testing.T) {
+	limiter := newBrowserLimiter(1)
+
+	release, err := limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx); err == nil {
+		t.Fatal("acquire() succeeded while the only slot was taken")
+	}
+
+	release()
+	release, err = limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() after release error = %v", err)
+	}
+	release()
+}
+
+func TestNewBrowserLimiter_Minimum(t *testing.T) {
+	if got := cap(newBrowserLimiter(0).slots); got != 1 {
+		t.Errorf("newBrowserLimiter(0) slots = %d, want 1", got)
+	}
+}
@@ -0,0 +1,168 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/arandu-ai/arandu/audit"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/security"
+)
+
+// Ámbitos de las políticas de comandos, en el orden en que se aplican
+const (
+	CommandPolicyServer = "server"
+	CommandPolicyTeam   = "team"
+	CommandPolicyFlow   = "flow"
+)
+
+// serverCommandPolicy es la política de COMMAND_POLICY_FILE; nil sin archivo
+var serverCommandPolicy *security.CommandPolicy
+
+// LoadCommandPolicy carga la política de comandos de todos los flows
+// Se llama al arrancar, antes de procesar tareas
+func LoadCommandPolicy(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading command policy file: %w", err)
+	}
+
+	policy, err := security.ParseCommandPolicy(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	policy.Scope = CommandPolicyServer
+	serverCommandPolicy = policy
+	return nil
+}
+
+// ServerCommandPolicy devuelve la política del servidor, o nil
+func ServerCommandPolicy() *security.CommandPolicy {
+	return serverCommandPolicy
+}
+
+// StoredCommandPolicy decodifica una política guardada en la base de datos
+func StoredCommandPolicy(row database.CommandPolicy, scope string) (*security.CommandPolicy, error) {
+	policy, err := security.ParseCommandPolicy([]byte(row.Policy))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s command policy %d: %w", scope, row.ID, err)
+	}
+	policy.Scope = scope
+	return policy, nil
+}
+
+// flowCommandPolicies devuelve las políticas que se aplican a un flow: la del
+// servidor, la del equipo con el que está compartido y la propia
+// Se combinan quedándose con la decisión más restrictiva
+func flowCommandPolicies(db *database.Queries, flowID int64) ([]*security.CommandPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DBTimeout)
+	defer cancel()
+
+	flow, err := db.ReadFlow(ctx, flowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow: %w", err)
+	}
+
+	policies := []*security.CommandPolicy{serverCommandPolicy}
+	if flow.TeamID.Valid {
+		row, err := db.ReadTeamCommandPolicy(ctx, flow.TeamID)
+		policy, err := optionalCommandPolicy(row, err, CommandPolicyTeam)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	row, err := db.ReadFlowCommandPolicy(ctx, sql.NullInt64{Int64: flowID, Valid: true})
+	policy, err := optionalCommandPolicy(row, err, CommandPolicyFlow)
+	if err != nil {
+		return nil, err
+	}
+	return append(policies, policy), nil
+}
+
+// optionalCommandPolicy decodifica el resultado de leer una política; nil si
+// el equipo o el flow no tienen
+func optionalCommandPolicy(row database.CommandPolicy, err error, scope string) (*security.CommandPolicy, error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s command policy: %w", scope, err)
+	}
+	return StoredCommandPolicy(row, scope)
+}
+
+// commandDecision evalúa un comando de la terminal contra las políticas del flow
+func commandDecision(db *database.Queries, flowID int64, command string) (security.CommandDecision, error) {
+	policies, err := flowCommandPolicies(db, flowID)
+	if err != nil {
+		return security.CommandDecision{}, err
+	}
+	return security.EvaluateCommand(command, policies...), nil
+}
+
+// describeCommandDecision nombra la política y la regla que decidieron
+func describeCommandDecision(decision security.CommandDecision) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "the %s command policy", decision.Scope)
+	if decision.Rule != "" {
+		fmt.Fprintf(&b, " (rule %s)", decision.Rule)
+	} else {
+		b.WriteString(" (default action)")
+	}
+	if decision.Reason != "" {
+		fmt.Fprintf(&b, ": %s", decision.Reason)
+	}
+	return b.String()
+}
+
+// commandApprovalReason explica por qué el comando espera aprobación
+func commandApprovalReason(decision security.CommandDecision) string {
+	return fmt.Sprintf("`%s` requires approval by %s", decision.Command, describeCommandDecision(decision))
+}
+
+// userCommandError explica por qué no se ejecuta un comando pedido por un
+// usuario: la política lo niega o pide aprobación, y las aprobaciones solo
+// existen para las tareas del agente. nil si puede ejecutarse
+func userCommandError(decision security.CommandDecision) error {
+	switch decision.Action {
+	case security.CommandDeny:
+		return fmt.Errorf("command not run: `%s` is denied by %s", decision.Command, describeCommandDecision(decision))
+	case security.CommandRequireApproval:
+		return fmt.Errorf("command not run: %s; send it to the flow as a message instead", commandApprovalReason(decision))
+	}
+	return nil
+}
+
+// denyCommand no ejecuta el comando y le devuelve al modelo el motivo como
+// resultado de la tarea, así puede elegir otra acción
+func denyCommand(db *database.Queries, task database.Task, command string, decision security.CommandDecision) error {
+	logging.Warn("Command denied by policy",
+		"flow_id", task.FlowID.Int64,
+		"task_id", task.ID,
+		"scope", decision.Scope,
+		"rule", decision.Rule,
+		"command", decision.Command,
+	)
+	audit.RecordAgent(db, audit.Event{
+		Action: audit.ActionCommandDenied,
+		FlowID: task.FlowID.Int64,
+		TaskID: task.ID,
+		Target: command,
+		Details: map[string]any{
+			"scope":   decision.Scope,
+			"rule":    decision.Rule,
+			"command": decision.Command,
+		},
+	})
+
+	results := fmt.Sprintf("Command not run: `%s` is denied by %s. Do not retry it or work around the policy; use another command or ask the user",
+		decision.Command, describeCommandDecision(decision))
+	return updateTaskResults(db, task.ID, results)
+}
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arandu-ai/arandu/security"
+)
+
+func TestLoadCommandPolicy(t *testing.T) {
+	defer func() { serverCommandPolicy = nil }()
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	raw := `{"rules": [{"name": "no-docker", "command": "docker", "action": "deny", "reason": "there is no Docker daemon"}]}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadCommandPolicy(path); err != nil {
+		t.Fatalf("LoadCommandPolicy() error = %v", err)
+	}
+	decision := security.EvaluateCommand("sudo docker ps", ServerCommandPolicy())
+	if decision.Action != security.CommandDeny || decision.Scope != CommandPolicyServer {
+		t.Fatalf("EvaluateCommand() = %+v, want a server denial", decision)
+	}
+
+	want := "the server command policy (rule no-docker): there is no Docker daemon"
+	if got := describeCommandDecision(decision); got != want {
+		t.Errorf("describeCommandDecision() = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"rules": [{"command": "docker", "action": "block"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCommandPolicy(path); err == nil {
+		t.Error("LoadCommandPolicy() expected error for an invalid action")
+	}
+}
+
+func TestUserCommandError(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision security.CommandDecision
+		wantErr  string
+	}{
+		{name: "allowed", decision: security.CommandDecision{Action: security.CommandAllow, Command: "ls"}},
+		{
+			name:     "denied",
+			decision: security.CommandDecision{Action: security.CommandDeny, Command: "docker ps", Scope: CommandPolicyServer, Rule: "no-docker"},
+			wantErr:  "`docker ps` is denied by the server command policy (rule no-docker)",
+		},
+		{
+			name:     "requires approval",
+			decision: security.CommandDecision{Action: security.CommandRequireApproval, Command: "npm publish", Scope: CommandPolicyFlow},
+			wantErr:  "`npm publish` requires approval by the flow command policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := userCommandError(tt.decision)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("userCommandError() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("userCommandError() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecUserCommand_BusyFlow(t *testing.T) {
+	const flowID = 987654
+	lock := lockFlow(flowID)
+	defer func() {
+		forgetFlowLock(flowID, lock)
+		lock.Unlock()
+	}()
+
+	_, err := ExecUserCommand(context.Background(), flowID, "ls", time.Second, nil)
+	if !errors.Is(err, ErrFlowBusy) {
+		t.Errorf("ExecUserCommand() error = %v, want ErrFlowBusy", err)
+	}
+}
+
+func TestCommandApprovalReason(t *testing.T) {
+	decision := security.CommandDecision{
+		Action:  security.CommandRequireApproval,
+		Command: "npm publish",
+		Scope:   CommandPolicyTeam,
+	}
+
+	want := "`npm publish` requires approval by the team command policy (default action)"
+	if got := commandApprovalReason(decision); got != want {
+		t.Errorf("commandApprovalReason() = %q, want %q", got, want)
+	}
+}
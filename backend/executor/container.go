@@ -22,42 +22,36 @@ var (
 	dockerClient *client.Client
 )
 
-const defaultImage = "debian:latest"
-
 // ensureImageExists verifica si la imagen existe localmente, si no la descarga
-// Retorna el nombre de la imagen a usar (puede ser defaultImage si falla el pull)
-func ensureImageExists(ctx context.Context, imageName string) string {
+// No cambia a otra imagen si falla: la imagen ya pasó la allow-list y el pinning
+func ensureImageExists(ctx context.Context, imageName string) error {
 	filterArgs := filters.NewArgs()
 	filterArgs.Add("reference", imageName)
 	images, err := dockerClient.ImageList(ctx, image.ListOptions{
 		Filters: filterArgs,
 	})
 	if err != nil {
-		logging.Warn("Error listing images, using default", "error", err.Error())
-		return defaultImage
+		return fmt.Errorf("error listing images: %w", err)
 	}
 
 	if len(images) > 0 {
 		logging.Debug("Image exists locally", "image", imageName)
-		return imageName
+		return nil
 	}
 
 	logging.Info("Pulling image", "image", imageName)
-	readCloser, pullErr := dockerClient.ImagePull(ctx, imageName, image.PullOptions{})
-	if pullErr != nil {
-		logging.Warn("Failed to pull image, using default",
-			"error", pullErr.Error(),
-			"default_image", defaultImage,
-		)
-		return defaultImage
+	readCloser, err := dockerClient.ImagePull(ctx, imageName, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("error pulling image %s: %w", imageName, err)
 	}
+	defer readCloser.Close()
 
 	// Wait for the pull to finish
-	if _, copyErr := io.Copy(io.Discard, readCloser); copyErr != nil {
-		logging.Warn("Error waiting for image pull", "error", copyErr.Error())
+	if _, err := io.Copy(io.Discard, readCloser); err != nil {
+		return fmt.Errorf("error waiting for image pull %s: %w", imageName, err)
 	}
 
-	return imageName
+	return nil
 }
 
 // createAndStartContainer crea e inicia un container Docker
@@ -117,13 +111,38 @@ func InitClient() error {
 	return nil
 }
 
-func SpawnContainer(ctx context.Context, name string, config *container.Config, hostConfig *container.HostConfig, db *database.Queries) (dbContainerID int64, err error) {
+// SpawnContainer crea el container y aplica los límites del sandbox si se indican
+// limits en nil deja el container sin restricciones (p.ej. el container del browser)
+func SpawnContainer(ctx context.Context, name string, config *container.Config, hostConfig *container.HostConfig, limits *SandboxLimits, db *database.Queries) (dbContainerID int64, err error) {
 	start := time.Now()
 
 	if config == nil {
 		return 0, fmt.Errorf("no config found for container %s", name)
 	}
 
+	if hostConfig == nil {
+		hostConfig = &container.HostConfig{}
+	}
+
+	if limits != nil {
+		if err := limits.Validate(); err != nil {
+			return 0, fmt.Errorf("invalid sandbox limits for container %s: %w", name, err)
+		}
+		if limits.NetworkMode == NetworkEgress {
+			if err := ensureEgressNetwork(ctx); err != nil {
+				return 0, err
+			}
+		}
+		applySandboxLimits(config, hostConfig, *limits)
+		logging.Debug("Sandbox limits applied",
+			"name", name,
+			"memory_mb", limits.MemoryMB,
+			"cpus", limits.CPUs,
+			"pids_limit", limits.PidsLimit,
+			"network_mode", limits.NetworkMode,
+		)
+	}
+
 	logging.Info("Spawning container", "image", config.Image, "name", name)
 
 	// Create DB record
@@ -154,7 +173,9 @@ func SpawnContainer(ctx context.Context, name string, config *container.Config,
 	}()
 
 	// Ensure image is available
-	config.Image = ensureImageExists(ctx, config.Image)
+	if err = ensureImageExists(ctx, config.Image); err != nil {
+		return dbContainer.ID, err
+	}
 
 	// Create and start container
 	localContainerID, err = createAndStartContainer(ctx, name, config, hostConfig)
@@ -204,7 +225,8 @@ func DeleteContainer(containerID string, dbID int64, db *database.Queries) error
 		return fmt.Errorf("error stopping container: %w", err)
 	}
 
-	if err := dockerClient.ContainerRemove(context.Background(), containerID, container.RemoveOptions{}); err != nil {
+	// RemoveVolumes borra también el volumen anónimo de /app de los sandboxes read-only
+	if err := dockerClient.ContainerRemove(context.Background(), containerID, container.RemoveOptions{RemoveVolumes: true}); err != nil {
 		return fmt.Errorf("error removing container: %w", err)
 	}
 
@@ -0,0 +1,179 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/providers"
+)
+
+// customToolMaxResponse limita el cuerpo que se acepta de un webhook
+const customToolMaxResponse = 1 << 20
+
+// customToolHandler procesa las tareas de las herramientas declaradas en CUSTOM_TOOLS_FILE
+var customToolHandler = TaskHandler{
+	Process: func(_ providers.Provider, db *database.Queries, t database.Task) error {
+		return processCustomToolTask(db, t)
+	},
+	NeedsNextTask: true,
+}
+
+// lookupTaskHandler devuelve el handler de un tipo de tarea, incluidas las herramientas propias
+func lookupTaskHandler(taskType string) (TaskHandler, bool) {
+	if handler, ok := taskHandlers[taskType]; ok {
+		return handler, true
+	}
+	if _, ok := providers.GetCustomTool(taskType); ok {
+		return customToolHandler, true
+	}
+	return TaskHandler{}, false
+}
+
+func processCustomToolTask(db *database.Queries, task database.Task) error {
+	tool, ok := providers.GetCustomTool(task.Type.String)
+	if !ok {
+		return fmt.Errorf("unknown custom tool: %s", task.Type.String)
+	}
+
+	args, err := unmarshalTaskArgs[map[string]any](task)
+	if err != nil {
+		return err
+	}
+	delete(args, "message")
+
+	var results string
+	if tool.Exec != nil {
+		results, err = runCustomToolCommand(task.FlowID.Int64, tool, args, db)
+	} else {
+		results, err = callCustomToolWebhook(task, tool, args)
+	}
+	if err != nil {
+		return fmt.Errorf("custom tool %s failed: %w", tool.Name, err)
+	}
+
+	_, err = storeToolResults(db, task, results)
+	return err
+}
+
+// renderCustomToolCommand arma el comando con los argumentos escapados para el shell
+func renderCustomToolCommand(tool *providers.CustomTool, args map[string]any) (string, error) {
+	// Los parámetros que el modelo omite se pasan como cadena vacía
+	quoted := map[string]string{}
+	if props, ok := tool.Parameters["properties"].(map[string]any); ok {
+		for name := range props {
+			quoted[name] = shellQuote("")
+		}
+	}
+	for name, value := range args {
+		quoted[name] = shellQuote(customToolArgString(value))
+	}
+
+	var buf bytes.Buffer
+	if err := tool.Template().Execute(&buf, quoted); err != nil {
+		return "", fmt.Errorf("error rendering command: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// customToolArgString convierte un argumento a texto; los objetos se pasan como JSON
+func customToolArgString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		bs, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(bs)
+	}
+}
+
+// shellQuote encierra el valor en comillas simples para sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+func runCustomToolCommand(flowID int64, tool *providers.CustomTool, args map[string]any, db *database.Queries) (string, error) {
+	command, err := renderCustomToolCommand(tool, args)
+	if err != nil {
+		return "", err
+	}
+
+	// El comando lo declara el operador; timeout corta las ejecuciones colgadas
+	return ExecCommandWithTimeout(flowID, command, tool.CallTimeout(), db)
+}
+
+// customToolRequest es el cuerpo que recibe el webhook de una herramienta
+type customToolRequest struct {
+	Tool   string         `json:"tool"`
+	FlowID int64          `json:"flowId"`
+	TaskID int64          `json:"taskId"`
+	Args   map[string]any `json:"args"`
+}
+
+func callCustomToolWebhook(task database.Task, tool *providers.CustomTool, args map[string]any) (string, error) {
+	body, err := json.Marshal(customToolRequest{
+		Tool:   tool.Name,
+		FlowID: task.FlowID.Int64,
+		TaskID: task.ID,
+		Args:   args,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error encoding webhook request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tool.CallTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tool.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("error creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range tool.Webhook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error calling webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, customToolMaxResponse+1))
+	if err != nil {
+		return "", fmt.Errorf("error reading webhook response: %w", err)
+	}
+	if len(data) > customToolMaxResponse {
+		data = append(data[:customToolMaxResponse], "\n... [truncated]"...)
+	}
+
+	logging.Info("Custom tool webhook called",
+		"tool", tool.Name,
+		"flow_id", task.FlowID.Int64,
+		"status", resp.StatusCode,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	result := strings.TrimSpace(string(data))
+	if result == "" {
+		result = "Tool executed successfully"
+	}
+	return result, nil
+}
@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/providers"
+)
+
+func loadTestCustomTools(t *testing.T, data string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tools.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	builtin := append(providers.Tools[:0:0], providers.Tools...)
+	t.Cleanup(func() { providers.Tools = builtin })
+
+	if err := providers.LoadCustomTools(path); err != nil {
+		t.Fatalf("LoadCustomTools() error = %v", err)
+	}
+}
+
+func TestRenderCustomToolCommand(t *testing.T) {
+	loadTestCustomTools(t, `{"tools": [{
+		"name": "grep_logs",
+		"description": "d",
+		"parameters": {"type": "object", "properties": {"pattern": {"type": "string"}, "file": {"type": "string"}, "lines": {"type": "integer"}}},
+		"exec": {"command": "grep -n {{.pattern}} {{.file}} | head -n {{.lines}}"}
+	}]}`)
+	tool, _ := providers.GetCustomTool("grep_logs")
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{name: "plain", args: map[string]any{"pattern": "error", "file": "/var/log/app.log", "lines": 5}, want: `grep -n 'error' '/var/log/app.log' | head -n '5'`},
+		{name: "injection", args: map[string]any{"pattern": "x'; rm -rf / #", "file": "$(id)", "lines": "`id`"}, want: `grep -n 'x'"'"'; rm -rf / #' '$(id)' | head -n '` + "`id`" + `'`},
+		{name: "missing", args: map[string]any{"pattern": "error"}, want: `grep -n 'error' '' | head -n ''`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderCustomToolCommand(tool, tt.args)
+			if err != nil {
+				t.Fatalf("renderCustomToolCommand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderCustomToolCommand() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"abc", `'abc'`},
+		{"", `''`},
+		{"it's", `'it'"'"'s'`},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCallCustomToolWebhook(t *testing.T) {
+	var got customToolRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		w.Write([]byte("PROJ-42 created\n"))
+	}))
+	defer server.Close()
+
+	loadTestCustomTools(t, `{"tools": [
+		{"name": "open_jira_ticket", "description": "d", "webhook": {"url": "`+server.URL+`", "headers": {"Authorization": "Bearer token"}}},
+		{"name": "broken_hook", "description": "d", "webhook": {"url": "`+server.URL+`"}}
+	]}`)
+
+	task := database.Task{ID: 7, FlowID: sql.NullInt64{Int64: 3, Valid: true}}
+
+	tool, _ := providers.GetCustomTool("open_jira_ticket")
+	result, err := callCustomToolWebhook(task, tool, map[string]any{"summary": "Bug"})
+	if err != nil {
+		t.Fatalf("callCustomToolWebhook() error = %v", err)
+	}
+	if result != "PROJ-42 created" {
+		t.Errorf("callCustomToolWebhook() = %q", result)
+	}
+	if got.Tool != "open_jira_ticket" || got.FlowID != 3 || got.TaskID != 7 || got.Args["summary"] != "Bug" {
+		t.Errorf("webhook request = %+v", got)
+	}
+
+	broken, _ := providers.GetCustomTool("broken_hook")
+	if _, err := callCustomToolWebhook(task, broken, nil); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("callCustomToolWebhook() error = %v, want 401", err)
+	}
+}
+
+func TestLookupTaskHandler(t *testing.T) {
+	loadTestCustomTools(t, `{"tools": [{"name": "run_tests", "description": "d", "exec": {"command": "make test"}}]}`)
+
+	if _, ok := lookupTaskHandler("terminal"); !ok {
+		t.Error("lookupTaskHandler(terminal) not found")
+	}
+	handler, ok := lookupTaskHandler("run_tests")
+	if !ok || !handler.NeedsNextTask {
+		t.Errorf("lookupTaskHandler(run_tests) = %+v, %v", handler, ok)
+	}
+	if _, ok := lookupTaskHandler("unknown"); ok {
+		t.Error("lookupTaskHandler(unknown) should not be found")
+	}
+}
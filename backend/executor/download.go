@@ -0,0 +1,335 @@
+package executor
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/providers"
+	"github.com/arandu-ai/arandu/security"
+	"github.com/docker/docker/api/types/container"
+)
+
+const (
+	// DownloadTimeout es el tiempo máximo de una descarga, incluida la copia al container
+	DownloadTimeout = 5 * time.Minute
+	// DefaultDownloadDir es el directorio del sandbox donde se guardan las descargas sin ruta
+	DefaultDownloadDir = "/app/downloads"
+	// downloadMaxRedirects es el máximo de redirecciones que se siguen
+	downloadMaxRedirects = 5
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// ErrDownloadTooLarge indica que el archivo supera DOWNLOAD_MAX_SIZE_MB
+var ErrDownloadTooLarge = errors.New("download exceeds the maximum size")
+
+// downloadClient descarga desde el servidor validando cada redirección y cada IP
+// a la que se conecta, para que un DNS que apunta a la red interna no sirva de atajo
+func downloadClient(flowID int64) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return validateDownloadAddress(address)
+		},
+	}
+
+	return &http.Client{
+		Timeout: DownloadTimeout,
+		Transport: &http.Transport{
+			// Sin proxy: la conexión tiene que pasar por el control del dialer
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= downloadMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", downloadMaxRedirects)
+			}
+			return validateFlowURL(flowID, req.URL.String())
+		},
+	}
+}
+
+// validateDownloadAddress rechaza conexiones a direcciones internas
+func validateDownloadAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if err := security.ValidateIPString(host); err != nil {
+		return fmt.Errorf("download blocked: %w", err)
+	}
+	return nil
+}
+
+// ParseMIMEAllowList interpreta la lista de tipos permitidos ("text/*,application/pdf")
+func ParseMIMEAllowList(raw string) []string {
+	var types []string
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// mimeAllowed indica si el tipo está en la lista; una lista vacía permite todo
+func mimeAllowed(mediaType string, allowList []string) bool {
+	if len(allowList) == 0 {
+		return true
+	}
+	for _, allowed := range allowList {
+		if allowed == "*/*" || allowed == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// downloadDestination calcula la ruta del archivo en el sandbox
+// Sin ruta (o con una ruta que termina en /) se usa el nombre del archivo remoto
+func downloadDestination(dest string, rawURL string, contentDisposition string) (string, error) {
+	if dest == "" {
+		dest = DefaultDownloadDir + "/"
+	}
+
+	if strings.HasSuffix(dest, "/") {
+		dest += downloadFilename(rawURL, contentDisposition)
+	}
+
+	if !path.IsAbs(dest) {
+		dest = path.Join("/app", dest)
+	}
+	dest = path.Clean(dest)
+
+	if err := validateCodeSecurity(dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// downloadFilename elige un nombre seguro para el archivo descargado
+func downloadFilename(rawURL string, contentDisposition string) string {
+	name := ""
+	if _, params, err := mime.ParseMediaType(contentDisposition); err == nil {
+		name = params["filename"]
+	}
+	if name == "" {
+		if u, err := url.Parse(rawURL); err == nil {
+			name = path.Base(u.Path)
+		}
+	}
+
+	name = strings.Trim(unsafeFilenameChars.ReplaceAllString(path.Base(name), "_"), "._")
+	if name == "" {
+		return "download"
+	}
+	return name
+}
+
+// Download descarga una URL desde el servidor y la copia al sandbox del flow
+// Es la vía de salida auditada: aplica la política de URLs, el límite de
+// tamaño y la lista de tipos MIME antes de tocar el container
+func Download(flowID int64, args providers.BrowserArgs, db *database.Queries) (string, error) {
+	if err := validateFlowURL(flowID, args.Url); err != nil {
+		return "", err
+	}
+
+	// Validar la ruta antes de descargar nada
+	if _, err := downloadDestination(args.Path, args.Url, ""); err != nil {
+		return "", err
+	}
+
+	containerName, err := ensureContainerRunning(flowID)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DownloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, args.Url, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid download request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := downloadClient(flowID).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error downloading %s: %w", args.Url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error downloading %s: server returned %s", args.Url, resp.Status)
+	}
+
+	maxSize := config.Config.DownloadMaxSizeMB << 20
+	if resp.ContentLength > maxSize {
+		return "", fmt.Errorf("%w: %d bytes (max %d MB)", ErrDownloadTooLarge, resp.ContentLength, config.Config.DownloadMaxSizeMB)
+	}
+
+	dest, err := downloadDestination(args.Path, resp.Request.URL.String(), resp.Header.Get("Content-Disposition"))
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp("", "arandu-download-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	mediaType, size, hash, err := saveDownload(file, resp, maxSize)
+	if err != nil {
+		return "", err
+	}
+
+	if err := copyFileToContainer(ctx, containerName, dest, file, size); err != nil {
+		return "", err
+	}
+
+	logging.Info("File downloaded to sandbox",
+		"flow_id", flowID,
+		"url", args.Url,
+		"path", dest,
+		"size", size,
+		"mime", mediaType,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	msg := fmt.Sprintf("Downloaded %s to %s (%d bytes, %s, sha256 %s)", resp.Request.URL, dest, size, mediaType, hash)
+	if err := createAndBroadcastLog(flowID, msg, LogTypeSystem, db); err != nil {
+		return "", err
+	}
+
+	return msg, nil
+}
+
+// saveDownload escribe el cuerpo en file comprobando el tamaño y el tipo MIME
+func saveDownload(file *os.File, resp *http.Response, maxSize int64) (mediaType string, size int64, hash string, err error) {
+	// Los primeros bytes sirven para detectar el tipo si el servidor no lo indica
+	head := make([]byte, 512)
+	n, err := io.ReadFull(resp.Body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", 0, "", fmt.Errorf("error reading download: %w", err)
+	}
+	head = head[:n]
+
+	mediaType, _, err = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	}
+	if !mimeAllowed(mediaType, ParseMIMEAllowList(config.Config.DownloadAllowedTypes)) {
+		return "", 0, "", fmt.Errorf("download blocked: content type %s is not allowed", mediaType)
+	}
+
+	hasher := sha256.New()
+	w := io.MultiWriter(file, hasher)
+	body := io.MultiReader(bytes.NewReader(head), resp.Body)
+
+	size, err = io.Copy(w, io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return "", 0, "", fmt.Errorf("error reading download: %w", err)
+	}
+	if size > maxSize {
+		return "", 0, "", fmt.Errorf("%w (max %d MB)", ErrDownloadTooLarge, config.Config.DownloadMaxSizeMB)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", 0, "", fmt.Errorf("error reading temporary file: %w", err)
+	}
+
+	return mediaType, size, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// copyFileToContainer copia el archivo al container creando los directorios que falten
+// El tar solo lleva el nombre del archivo y se extrae en su directorio, como en WriteFile,
+// para no escribir sobre la raíz del container (que puede ser de solo lectura)
+func copyFileToContainer(ctx context.Context, containerName string, dest string, file io.Reader, size int64) error {
+	dir := path.Dir(dest)
+	if err := makeContainerDir(ctx, containerName, dir); err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := writeDownloadTar(tw, path.Base(dest), file, size)
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	if err := dockerClient.CopyToContainer(ctx, containerName, dir, pr, container.CopyToContainerOptions{}); err != nil {
+		pr.CloseWithError(err)
+		return fmt.Errorf("error copying download to container: %w", err)
+	}
+	return nil
+}
+
+// makeContainerDir crea el directorio en el container si no existe
+func makeContainerDir(ctx context.Context, containerName string, dir string) error {
+	createResp, err := dockerClient.ContainerExecCreate(ctx, containerName, container.ExecOptions{
+		Cmd:          []string{"mkdir", "-p", dir},
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating download directory: %w", err)
+	}
+
+	resp, err := dockerClient.ContainerExecAttach(ctx, createResp.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return fmt.Errorf("error creating download directory: %w", err)
+	}
+	defer resp.Close()
+
+	output, _ := io.ReadAll(resp.Reader)
+
+	inspect, err := dockerClient.ContainerExecInspect(ctx, createResp.ID)
+	if err != nil {
+		return fmt.Errorf("error creating download directory: %w", err)
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("error creating download directory %s: %s", dir, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// writeDownloadTar escribe el archivo en el tar con el nombre indicado
+func writeDownloadTar(tw *tar.Writer, name string, file io.Reader, size int64) error {
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: size}); err != nil {
+		return fmt.Errorf("error writing tar header: %w", err)
+	}
+	if _, err := io.Copy(tw, file); err != nil {
+		return fmt.Errorf("error writing tar content: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package executor
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/config"
+)
+
+func TestMimeAllowed(t *testing.T) {
+	allowList := ParseMIMEAllowList(" text/*, application/pdf ,IMAGE/PNG,")
+
+	tests := []struct {
+		mediaType string
+		want      bool
+	}{
+		{"text/plain", true},
+		{"text/csv", true},
+		{"application/pdf", true},
+		{"image/png", true},
+		{"image/jpeg", false},
+		{"application/x-executable", false},
+		{"textual/plain", false},
+	}
+
+	for _, tt := range tests {
+		if got := mimeAllowed(tt.mediaType, allowList); got != tt.want {
+			t.Errorf("mimeAllowed(%q) = %v, want %v", tt.mediaType, got, tt.want)
+		}
+	}
+
+	if !mimeAllowed("application/octet-stream", nil) {
+		t.Error("mimeAllowed() with an empty list should allow everything")
+	}
+}
+
+func TestDownloadDestination(t *testing.T) {
+	tests := []struct {
+		name               string
+		dest               string
+		url                string
+		contentDisposition string
+		want               string
+		wantErr            bool
+	}{
+		{name: "default", url: "https://example.com/files/data.csv", want: "/app/downloads/data.csv"},
+		{name: "content disposition", url: "https://example.com/get?id=1", contentDisposition: `attachment; filename="report 2024.pdf"`, want: "/app/downloads/report_2024.pdf"},
+		{name: "no filename", url: "https://example.com/", want: "/app/downloads/download"},
+		{name: "traversal in filename", url: "https://example.com/x", contentDisposition: `attachment; filename="../../tmp/notes.txt"`, want: "/app/downloads/notes.txt"},
+		{name: "directory", dest: "/app/data/", url: "https://example.com/a.json", want: "/app/data/a.json"},
+		{name: "relative file", dest: "data/input.json", url: "https://example.com/a.json", want: "/app/data/input.json"},
+		{name: "outside app", dest: "/etc/cron.d/job", url: "https://example.com/a", wantErr: true},
+		{name: "relative traversal", dest: "../../etc/passwd", url: "https://example.com/a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := downloadDestination(tt.dest, tt.url, tt.contentDisposition)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("downloadDestination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("downloadDestination() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateDownloadAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{"93.184.216.34:443", false},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", false},
+		{"127.0.0.1:80", true},
+		{"10.0.0.5:80", true},
+		{"172.17.0.2:8080", true},
+		{"192.168.1.1:80", true},
+		{"169.254.169.254:80", true},
+		{"0.0.0.0:80", true},
+		{"[::1]:80", true},
+		{"[fd00::1]:80", true},
+		{"100.64.0.1:80", true},
+		{"0.1.2.3:80", true},
+		{"[::ffff:127.0.0.1]:80", true},
+		{"[64:ff9b::a9fe:a9fe]:80", true},
+		{"not-an-address", true},
+	}
+
+	for _, tt := range tests {
+		if err := validateDownloadAddress(tt.address); (err != nil) != tt.wantErr {
+			t.Errorf("validateDownloadAddress(%q) error = %v, wantErr %v", tt.address, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSaveDownload(t *testing.T) {
+	oldTypes := config.Config.DownloadAllowedTypes
+	config.Config.DownloadAllowedTypes = "text/*,image/png"
+	defer func() { config.Config.DownloadAllowedTypes = oldTypes }()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxSize     int64
+		wantType    string
+		wantErr     error
+	}{
+		{name: "declared type", contentType: "text/csv; charset=utf-8", body: "a,b\n1,2\n", maxSize: 100, wantType: "text/csv"},
+		{name: "sniffed type", body: "\x89PNG\r\n\x1a\n0000", maxSize: 100, wantType: "image/png"},
+		{name: "blocked type", contentType: "application/x-sh", body: "#!/bin/sh", maxSize: 100},
+		{name: "too large", contentType: "text/plain", body: strings.Repeat("x", 101), maxSize: 100, wantErr: ErrDownloadTooLarge},
+		{name: "exact size", contentType: "text/plain", body: strings.Repeat("x", 100), maxSize: 100, wantType: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.CreateTemp(t.TempDir(), "download-*")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			resp := &http.Response{
+				Header: http.Header{},
+				Body:   io.NopCloser(strings.NewReader(tt.body)),
+			}
+			if tt.contentType != "" {
+				resp.Header.Set("Content-Type", tt.contentType)
+			}
+
+			mediaType, size, hash, err := saveDownload(file, resp, tt.maxSize)
+			if tt.wantType == "" {
+				if err == nil {
+					t.Fatal("saveDownload() expected error")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("saveDownload() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("saveDownload() error = %v", err)
+			}
+
+			if mediaType != tt.wantType || size != int64(len(tt.body)) || len(hash) != 64 {
+				t.Errorf("saveDownload() = %q, %d, %q", mediaType, size, hash)
+			}
+
+			// El archivo queda listo para copiarse desde el principio
+			data, _ := io.ReadAll(file)
+			if string(data) != tt.body {
+				t.Errorf("file content = %q, want %q", data, tt.body)
+			}
+		})
+	}
+}
+
+func TestWriteDownloadTar(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := writeDownloadTar(tw, "data.csv", strings.NewReader("a,b"), 3); err != nil {
+		t.Fatalf("writeDownloadTar() error = %v", err)
+	}
+	tw.Close()
+
+	var names []string
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+
+	want := []string{"data.csv"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("tar entries = %v, want %v", names, want)
+	}
+}
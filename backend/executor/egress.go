@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/security"
+)
+
+// egressDialTimeout es el timeout para conectar con el destino final
+const egressDialTimeout = 10 * time.Second
+
+// hopHeaders son headers que no deben reenviarse entre proxy y destino
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// EgressProxy es un forward proxy HTTP/HTTPS que solo deja pasar tráfico
+// hacia los hosts de la allow-list. Los sandboxes en modo egress lo usan como
+// única salida a internet.
+type EgressProxy struct {
+	allowList []string
+	transport http.RoundTripper
+}
+
+// NewEgressProxy crea un proxy con la allow-list indicada
+func NewEgressProxy(allowList []string) *EgressProxy {
+	return &EgressProxy{
+		allowList: allowList,
+		transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           (&net.Dialer{Timeout: egressDialTimeout}).DialContext,
+			ResponseHeaderTimeout: 60 * time.Second,
+		},
+	}
+}
+
+// ParseEgressAllowList convierte la lista separada por comas de la configuración
+func ParseEgressAllowList(raw string) []string {
+	var allowList []string
+	for _, entry := range strings.Split(raw, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			allowList = append(allowList, entry)
+		}
+	}
+	return allowList
+}
+
+func (p *EgressProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if r.URL.Host != "" {
+		host = r.URL.Host
+	}
+
+	if !security.IsHostAllowed(host, p.allowList) {
+		logging.Warn("Egress request blocked", "host", host, "method", r.Method)
+		http.Error(w, "egress to "+host+" is not allowed by the sandbox policy", http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r, host)
+		return
+	}
+
+	p.forward(w, r)
+}
+
+// tunnel maneja CONNECT (HTTPS) conectando el cliente con el destino
+func (p *EgressProxy) tunnel(w http.ResponseWriter, r *http.Request, host string) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "tunneling not supported", http.StatusInternalServerError)
+		return
+	}
+
+	upstream, err := net.DialTimeout("tcp", host, egressDialTimeout)
+	if err != nil {
+		http.Error(w, "error connecting to "+host, http.StatusBadGateway)
+		return
+	}
+
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+
+	if _, err := clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		clientConn.Close()
+		upstream.Close()
+		return
+	}
+
+	logging.Debug("Egress tunnel opened", "host", host)
+
+	go pipe(upstream, clientConn)
+	go pipe(clientConn, upstream)
+}
+
+// forward reenvía una petición HTTP plana al destino
+func (p *EgressProxy) forward(w http.ResponseWriter, r *http.Request) {
+	outReq := r.Clone(r.Context())
+	outReq.RequestURI = ""
+	for _, h := range hopHeaders {
+		outReq.Header.Del(h)
+	}
+
+	resp, err := p.transport.RoundTrip(outReq)
+	if err != nil {
+		http.Error(w, "error forwarding request: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for _, h := range hopHeaders {
+		resp.Header.Del(h)
+	}
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func pipe(dst io.WriteCloser, src io.ReadCloser) {
+	defer dst.Close()
+	defer src.Close()
+	_, _ = io.Copy(dst, src)
+}
+
+// StartEgressProxy levanta el proxy de egress en la dirección indicada
+func StartEgressProxy(addr string, allowList []string) *http.Server {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           NewEgressProxy(allowList),
+		ReadHeaderTimeout: 15 * time.Second,
+	}
+
+	go func() {
+		logging.Info("Egress proxy starting", "addr", addr, "allowed_hosts", allowList)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Error("Egress proxy error", "error", err.Error())
+		}
+	}()
+
+	return server
+}
@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseEgressAllowList(t *testing.T) {
+	got := ParseEgressAllowList(" pypi.org, ,.npmjs.org ")
+	want := []string{"pypi.org", ".npmjs.org"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseEgressAllowList() = %v, want %v", got, want)
+	}
+
+	if got := ParseEgressAllowList(""); len(got) != 0 {
+		t.Errorf("ParseEgressAllowList(\"\") = %v, want empty", got)
+	}
+}
+
+func TestEgressProxy_BlocksUnlistedHost(t *testing.T) {
+	proxy := NewEgressProxy([]string{"pypi.org"})
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestEgressProxy_ForwardsAllowedHost(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello from upstream")
+	}))
+	defer upstream.Close()
+
+	proxyServer := httptest.NewServer(NewEgressProxy([]string{"127.0.0.1"}))
+	defer proxyServer.Close()
+
+	proxyURL, _ := url.Parse(proxyServer.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "hello from upstream" {
+		t.Errorf("got %d %q, want 200 %q", resp.StatusCode, body, "hello from upstream")
+	}
+}
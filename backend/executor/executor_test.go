@@ -70,13 +70,6 @@ func TestLogTypeConstants(t *testing.T) {
 	}
 }
 
-func TestDefaultImageConstant(t *testing.T) {
-	expected := "debian:latest"
-	if defaultImage != expected {
-		t.Errorf("defaultImage = %q, want %q", defaultImage, expected)
-	}
-}
-
 func TestPortConstant(t *testing.T) {
 	expected := "9222"
 	if port != expected {
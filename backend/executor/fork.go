@@ -0,0 +1,249 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/models"
+	"github.com/docker/docker/api/types/container"
+)
+
+// SnapshotFork es el motivo del snapshot con el que arranca un flow bifurcado
+const SnapshotFork SnapshotReason = "fork"
+
+// forkSnapshot elige el snapshot del flow origen desde el que se bifurca
+// Si se bifurca desde la última tarea se toma un snapshot nuevo del workspace;
+// si no (o si falla) se usa el último snapshot en o antes de la tarea
+func forkSnapshot(ctx context.Context, sourceID int64, fromTaskID int64, tasks []database.Task, db *database.Queries) (database.Snapshot, error) {
+	latest, err := db.ReadLatestSnapshotAtTask(ctx, database.ReadLatestSnapshotAtTaskParams{
+		FlowID: sourceID,
+		TaskID: fromTaskID,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.Snapshot{}, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	hasSnapshot := err == nil
+
+	lastTaskID := tasks[len(tasks)-1].ID
+	if fromTaskID == lastTaskID && (!hasSnapshot || latest.TaskID != fromTaskID) {
+		snapshot, err := CheckpointFlow(sourceID, db)
+		if err == nil {
+			return snapshot, nil
+		}
+		logging.Warn("Failed to snapshot source flow for fork",
+			"flow_id", sourceID,
+			"error", err.Error(),
+		)
+	}
+
+	if !hasSnapshot {
+		return database.Snapshot{}, fmt.Errorf("no snapshot found at or before task %d", fromTaskID)
+	}
+	return latest, nil
+}
+
+// ForkFlow crea un flow nuevo a partir de otro: copia las tareas y logs hasta
+// el snapshot elegido y arranca un container propio desde ese snapshot
+// modelProvider y modelID vacíos mantienen el modelo del flow origen
+func ForkFlow(sourceID int64, fromTaskID int64, modelProvider string, modelID string, db *database.Queries) (database.Flow, error) {
+	if (modelProvider == "") != (modelID == "") {
+		return database.Flow{}, fmt.Errorf("modelProvider and modelId must be set together")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SnapshotTimeout)
+	defer cancel()
+
+	source, err := db.ReadFlow(ctx, sourceID)
+	if err != nil {
+		return database.Flow{}, fmt.Errorf("failed to get flow: %w", err)
+	}
+
+	tasks, err := db.ReadTasksByFlowId(ctx, sql.NullInt64{Int64: sourceID, Valid: true})
+	if err != nil {
+		return database.Flow{}, fmt.Errorf("failed to get tasks by flow id: %w", err)
+	}
+	found := false
+	for _, t := range tasks {
+		if t.ID == fromTaskID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return database.Flow{}, fmt.Errorf("task %d does not belong to flow %d", fromTaskID, sourceID)
+	}
+
+	snapshot, err := forkSnapshot(ctx, sourceID, fromTaskID, tasks, db)
+	if err != nil {
+		return database.Flow{}, err
+	}
+
+	if _, err := dockerClient.ImageInspect(ctx, snapshot.Image); err != nil {
+		return database.Flow{}, fmt.Errorf("snapshot image %s is not available: %w", snapshot.Image, err)
+	}
+
+	if modelProvider == "" {
+		modelProvider = source.ModelProvider.String
+		modelID = source.Model.String
+	}
+
+	flow, err := db.CreateFlow(ctx, database.CreateFlowParams{
+		Name:          database.StringToNullString(source.Name.String + " (fork)"),
+		Status:        database.StringToNullString(string(models.FlowInProgress)),
+		Model:         database.StringToNullString(modelID),
+		ModelProvider: database.StringToNullString(modelProvider),
+		Sandbox:       source.Sandbox,
+		McpServers:    source.McpServers,
+		OwnerID:       source.OwnerID,
+		TeamID:        source.TeamID,
+	})
+	if err != nil {
+		return database.Flow{}, fmt.Errorf("failed to create flow: %w", err)
+	}
+
+	if err := copyFlowHistory(ctx, sourceID, flow.ID, snapshot, db); err != nil {
+		return flow, failFork(flow.ID, err, db)
+	}
+
+	containerID, err := spawnForkContainer(ctx, source, flow.ID, snapshot, db)
+	if err != nil {
+		return flow, failFork(flow.ID, err, db)
+	}
+
+	flow, err = db.UpdateFlowContainer(ctx, database.UpdateFlowContainerParams{
+		ID:          flow.ID,
+		ContainerID: sql.NullInt64{Int64: containerID, Valid: true},
+	})
+	if err != nil {
+		return flow, fmt.Errorf("failed to update flow container: %w", err)
+	}
+
+	AddQueue(flow.ID, db)
+
+	msg := fmt.Sprintf("Forked from flow %d after task %d", sourceID, snapshot.TaskID)
+	if err := createAndBroadcastLog(flow.ID, msg, LogTypeSystem, db); err != nil {
+		return flow, err
+	}
+
+	logging.Info("Flow forked",
+		"source_flow_id", sourceID,
+		"flow_id", flow.ID,
+		"task_id", snapshot.TaskID,
+		"model", modelID,
+	)
+
+	return flow, nil
+}
+
+// copyFlowHistory copia al flow nuevo las tareas hasta la del snapshot, los
+// logs generados hasta que se tomó y la política de comandos del flow
+func copyFlowHistory(ctx context.Context, sourceID int64, flowID int64, snapshot database.Snapshot, db *database.Queries) error {
+	source := sql.NullInt64{Int64: sourceID, Valid: true}
+
+	if err := db.CopyTasksToFlow(ctx, database.CopyTasksToFlowParams{
+		TargetFlowID: flowID,
+		SourceFlowID: source,
+		UntilTaskID:  snapshot.TaskID,
+	}); err != nil {
+		return fmt.Errorf("failed to copy tasks: %w", err)
+	}
+
+	if err := db.CopyLogsToFlow(ctx, database.CopyLogsToFlowParams{
+		TargetFlowID: flowID,
+		SourceFlowID: source,
+		Until:        snapshot.CreatedAt,
+	}); err != nil {
+		return fmt.Errorf("failed to copy logs: %w", err)
+	}
+
+	if err := db.CopyCommandPolicyToFlow(ctx, database.CopyCommandPolicyToFlowParams{
+		TargetFlowID: flowID,
+		SourceFlowID: source,
+	}); err != nil {
+		return fmt.Errorf("failed to copy command policy: %w", err)
+	}
+
+	return nil
+}
+
+// spawnForkContainer etiqueta el snapshot para el flow nuevo y arranca su container
+// La etiqueta propia hace que borrar snapshots de un flow no afecte al otro
+func spawnForkContainer(ctx context.Context, source database.ReadFlowRow, flowID int64, snapshot database.Snapshot, db *database.Queries) (int64, error) {
+	tasks, err := db.ReadTasksByFlowId(ctx, sql.NullInt64{Int64: flowID, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tasks by flow id: %w", err)
+	}
+	if len(tasks) == 0 {
+		return 0, fmt.Errorf("forked flow %d has no tasks", flowID)
+	}
+	lastTaskID := tasks[len(tasks)-1].ID
+
+	start := time.Now()
+	reference := snapshotReference(flowID, lastTaskID)
+	if err := dockerClient.ImageTag(ctx, snapshot.Image, reference); err != nil {
+		return 0, fmt.Errorf("error tagging snapshot image: %w", err)
+	}
+	logging.LogDockerOp("tag_image", "", time.Since(start), nil,
+		"source", snapshot.Image,
+		"image", reference,
+	)
+
+	if _, err := db.CreateSnapshot(ctx, database.CreateSnapshotParams{
+		FlowID:      flowID,
+		TaskID:      lastTaskID,
+		ContainerID: snapshot.ContainerID,
+		Image:       reference,
+		Reason:      string(SnapshotFork),
+	}); err != nil {
+		return 0, fmt.Errorf("error creating snapshot in database: %w", err)
+	}
+
+	limits, err := flowSandboxLimits(source.Sandbox.String)
+	if err != nil {
+		return 0, err
+	}
+
+	containerID, err := SpawnContainer(ctx,
+		TerminalName(flowID),
+		&container.Config{
+			Image: reference,
+			Cmd:   []string{"tail", "-f", "/dev/null"},
+		},
+		&container.HostConfig{},
+		&limits,
+		db,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to spawn container from snapshot: %w", err)
+	}
+
+	baseImage := source.ContainerImage.String
+	if source.ContainerBaseImage.Valid {
+		baseImage = source.ContainerBaseImage.String
+	}
+	if _, err := db.UpdateContainerLineage(ctx, database.UpdateContainerLineageParams{
+		ID:        containerID,
+		ParentID:  snapshot.ContainerID,
+		BaseImage: database.StringToNullString(baseImage),
+	}); err != nil {
+		return 0, fmt.Errorf("failed to update container lineage: %w", err)
+	}
+
+	return containerID, nil
+}
+
+// failFork marca como terminado un fork que no se pudo completar
+func failFork(flowID int64, forkErr error, db *database.Queries) error {
+	if _, err := db.UpdateFlowStatus(context.Background(), database.UpdateFlowStatusParams{
+		ID:     flowID,
+		Status: database.StringToNullString(string(models.FlowFinished)),
+	}); err != nil {
+		logging.Error("Failed to update forked flow status", "flow_id", flowID, "error", err.Error())
+	}
+	return forkErr
+}
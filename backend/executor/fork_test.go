@@ -0,0 +1,27 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestForkFlowRequiresCompleteModel(t *testing.T) {
+	tests := []struct {
+		name          string
+		modelProvider string
+		modelID       string
+	}{
+		{name: "provider without model", modelProvider: "ollama"},
+		{name: "model without provider", modelID: "qwen2.5-coder:14b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// La validación ocurre antes de tocar la base de datos
+			_, err := ForkFlow(1, 1, tt.modelProvider, tt.modelID, nil)
+			if err == nil || !strings.Contains(err.Error(), "must be set together") {
+				t.Errorf("ForkFlow() error = %v, want model validation error", err)
+			}
+		})
+	}
+}
@@ -1,22 +1,57 @@
 package executor
 
 import (
+	"database/sql"
+	"time"
+
 	"github.com/arandu-ai/arandu/database"
 	gmodel "github.com/arandu-ai/arandu/graph/model"
+	"github.com/arandu-ai/arandu/mcp"
+	"github.com/arandu-ai/arandu/security"
 	"github.com/arandu-ai/arandu/websocket"
 )
 
 // TaskToGraphQL convierte una tarea de database a modelo GraphQL
 func TaskToGraphQL(task database.Task) *gmodel.Task {
 	return &gmodel.Task{
-		ID:        uint(task.ID),
-		Message:   task.Message.String,
-		Type:      gmodel.TaskType(task.Type.String),
-		CreatedAt: task.CreatedAt.Time,
-		Status:    gmodel.TaskStatus(task.Status.String),
-		Args:      task.Args.String,
-		Results:   task.Results.String,
+		ID:             uint(task.ID),
+		Message:        task.Message.String,
+		Type:           taskTypeToGraphQL(task.Type.String),
+		CreatedAt:      task.CreatedAt.Time,
+		Status:         gmodel.TaskStatus(task.Status.String),
+		Args:           task.Args.String,
+		Results:        task.Results.String,
+		Redactions:     taskRedactionsJSON(task.Redactions),
+		Untrusted:      task.Untrusted,
+		InjectionFlags: taskInjectionFlags(task.InjectionFlags),
+		Approval:       taskApprovalToGraphQL(task.Approval),
+	}
+}
+
+// taskApprovalToGraphQL convierte el estado de aprobación; nil si la tarea no la necesitó
+func taskApprovalToGraphQL(approval string) *gmodel.TaskApproval {
+	if approval == "" {
+		return nil
+	}
+	a := gmodel.TaskApproval(approval)
+	return &a
+}
+
+// taskRedactionsJSON devuelve el conteo de redacciones; las tareas que aún no
+// se guardaron no lo tienen
+func taskRedactionsJSON(redactions string) string {
+	if redactions == "" {
+		return "{}"
+	}
+	return redactions
+}
+
+// taskTypeToGraphQL convierte el tipo de tarea; las herramientas de CUSTOM_TOOLS_FILE se exponen como custom
+func taskTypeToGraphQL(taskType string) gmodel.TaskType {
+	if t := gmodel.TaskType(taskType); t.IsValid() {
+		return t
 	}
+	return gmodel.TaskTypeCustom
 }
 
 // TasksToGraphQL convierte una lista de tareas de database a modelos GraphQL
@@ -65,9 +100,11 @@ func FlowRowToGraphQL(flow database.ReadAllFlowsRow) *gmodel.Flow {
 			Connected:     false, // En listados no tenemos el estado del container
 		},
 		Browser: &gmodel.Browser{
-			URL:           "",
-			ScreenshotURL: "",
+			URL:           flow.BrowserUrl.String,
+			ScreenshotURL: ScreenshotURL(flow.BrowserScreenshot.String),
 		},
+		CreatedAt: nullTimeToGraphQL(flow.CreatedAt),
+		TeamID:    nullIDToGraphQL(flow.TeamID),
 	}
 }
 
@@ -86,9 +123,11 @@ func FlowToGraphQL(flow database.ReadFlowRow) *gmodel.Flow {
 			Connected:     flow.ContainerStatus.String == "running",
 		},
 		Browser: &gmodel.Browser{
-			URL:           "",
-			ScreenshotURL: "",
+			URL:           flow.BrowserUrl.String,
+			ScreenshotURL: ScreenshotURL(flow.BrowserScreenshot.String),
 		},
+		CreatedAt: nullTimeToGraphQL(flow.CreatedAt),
+		TeamID:    nullIDToGraphQL(flow.TeamID),
 	}
 }
 
@@ -99,3 +138,259 @@ func FlowToGraphQLFull(flow database.ReadFlowRow, tasks []database.Task, logs []
 	gFlow.Terminal.Logs = LogsToGraphQL(logs)
 	return gFlow
 }
+
+// SandboxInputToLimits convierte el input GraphQL de sandbox a SandboxLimits
+// Los campos no indicados quedan en cero y se completan con los defaults al hacer Merge
+func SandboxInputToLimits(input *gmodel.SandboxInput) SandboxLimits {
+	var limits SandboxLimits
+	if input == nil {
+		return limits
+	}
+	if input.MemoryMb != nil {
+		limits.MemoryMB = int64(*input.MemoryMb)
+	}
+	if input.Cpus != nil {
+		limits.CPUs = *input.Cpus
+	}
+	if input.PidsLimit != nil {
+		limits.PidsLimit = int64(*input.PidsLimit)
+	}
+	if input.DiskQuota != nil {
+		limits.DiskQuota = *input.DiskQuota
+	}
+	if input.ReadOnlyRootfs != nil {
+		readOnly := *input.ReadOnlyRootfs
+		limits.ReadOnlyRootfs = &readOnly
+	}
+	if input.NetworkMode != nil {
+		limits.NetworkMode = SandboxNetworkMode(*input.NetworkMode)
+	}
+	return limits
+}
+
+// PoolStatusToGraphQL convierte el estado del pool de containers a GraphQL
+func PoolStatusToGraphQL(status []PoolStatus) []*gmodel.ContainerPoolStatus {
+	gStatus := make([]*gmodel.ContainerPoolStatus, len(status))
+	for i, s := range status {
+		gStatus[i] = &gmodel.ContainerPoolStatus{
+			Image:    s.Image,
+			Target:   s.Target,
+			Idle:     s.Idle,
+			Starting: s.Starting,
+		}
+		if s.LastError != "" {
+			lastError := s.LastError
+			gStatus[i].LastError = &lastError
+		}
+	}
+	return gStatus
+}
+
+// SnapshotToGraphQL convierte un snapshot de la base de datos a GraphQL
+func SnapshotToGraphQL(snapshot database.Snapshot) *gmodel.Snapshot {
+	return &gmodel.Snapshot{
+		ID:        uint(snapshot.ID),
+		TaskID:    uint(snapshot.TaskID),
+		Image:     snapshot.Image,
+		Reason:    snapshot.Reason,
+		CreatedAt: snapshot.CreatedAt,
+	}
+}
+
+// ScreenshotToGraphQL convierte una captura del browser a GraphQL
+func ScreenshotToGraphQL(screenshot database.Screenshot) *gmodel.Screenshot {
+	return &gmodel.Screenshot{
+		ID:            uint(screenshot.ID),
+		TaskID:        uint(screenshot.TaskID),
+		URL:           screenshot.Url,
+		ScreenshotURL: ScreenshotURL(screenshot.Path),
+		Sha256:        screenshot.Sha256,
+		Size:          int(screenshot.Size),
+		CreatedAt:     screenshot.CreatedAt,
+	}
+}
+
+// ScreenshotsToGraphQL convierte una lista de capturas a GraphQL
+func ScreenshotsToGraphQL(screenshots []database.Screenshot) []*gmodel.Screenshot {
+	gScreenshots := make([]*gmodel.Screenshot, len(screenshots))
+	for i, s := range screenshots {
+		gScreenshots[i] = ScreenshotToGraphQL(s)
+	}
+	return gScreenshots
+}
+
+// SnapshotsToGraphQL convierte una lista de snapshots a GraphQL
+func SnapshotsToGraphQL(snapshots []database.Snapshot) []*gmodel.Snapshot {
+	gSnapshots := make([]*gmodel.Snapshot, len(snapshots))
+	for i, s := range snapshots {
+		gSnapshots[i] = SnapshotToGraphQL(s)
+	}
+	return gSnapshots
+}
+
+// MCPServersToGraphQL convierte los servidores MCP configurados a GraphQL
+func MCPServersToGraphQL(servers []mcp.ServerConfig) []*gmodel.McpServer {
+	gServers := make([]*gmodel.McpServer, len(servers))
+	for i, s := range servers {
+		gServers[i] = &gmodel.McpServer{
+			Name:      s.Name,
+			Transport: string(s.Transport),
+			Default:   s.Default,
+		}
+	}
+	return gServers
+}
+
+// UserToGraphQL convierte un usuario de database a GraphQL
+func UserToGraphQL(user database.User) *gmodel.User {
+	return &gmodel.User{
+		ID:        uint(user.ID),
+		Username:  user.Username,
+		Admin:     user.Admin,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// nullIDToGraphQL convierte un id opcional de la base de datos, o nil
+func nullIDToGraphQL(teamID sql.NullInt64) *uint {
+	if !teamID.Valid {
+		return nil
+	}
+	id := uint(teamID.Int64)
+	return &id
+}
+
+// nullTimeToGraphQL convierte una fecha opcional de la base de datos, o nil
+func nullTimeToGraphQL(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// TeamToGraphQL convierte un equipo y sus miembros a GraphQL
+func TeamToGraphQL(team database.Team, members []database.ReadTeamMembersRow) *gmodel.Team {
+	gMembers := make([]*gmodel.TeamMember, len(members))
+	for i, member := range members {
+		gMembers[i] = &gmodel.TeamMember{
+			User: UserToGraphQL(database.User{
+				ID:        member.ID,
+				CreatedAt: member.CreatedAt,
+				Username:  member.Username,
+				Admin:     member.Admin,
+			}),
+			Role: gmodel.TeamRole(member.Role),
+		}
+	}
+
+	return &gmodel.Team{
+		ID:        uint(team.ID),
+		Name:      team.Name,
+		CreatedAt: team.CreatedAt,
+		Members:   gMembers,
+	}
+}
+
+// SecretToGraphQL convierte un secreto a GraphQL, sin su valor
+func SecretToGraphQL(secret database.Secret) *gmodel.Secret {
+	return &gmodel.Secret{
+		ID:        uint(secret.ID),
+		Name:      secret.Name,
+		TeamID:    nullIDToGraphQL(secret.TeamID),
+		CreatedAt: secret.CreatedAt,
+		UpdatedAt: secret.UpdatedAt,
+	}
+}
+
+// AuditEventToGraphQL convierte un evento de auditoría a GraphQL
+func AuditEventToGraphQL(event database.AuditEvent) *gmodel.AuditEvent {
+	gEvent := &gmodel.AuditEvent{
+		ID:        uint(event.ID),
+		CreatedAt: event.CreatedAt,
+		UserID:    nullIDToGraphQL(event.UserID),
+		Actor:     event.Actor,
+		Action:    event.Action,
+		FlowID:    nullIDToGraphQL(event.FlowID),
+		TaskID:    nullIDToGraphQL(event.TaskID),
+		Target:    event.Target,
+		Details:   event.Details,
+		PrevHash:  event.PrevHash,
+		Hash:      event.Hash,
+	}
+	if event.Error.Valid {
+		gEvent.Error = &event.Error.String
+	}
+	return gEvent
+}
+
+// APITokenToGraphQL convierte un token de API a GraphQL, sin el hash
+func APITokenToGraphQL(token database.ApiToken) *gmodel.APIToken {
+	gToken := &gmodel.APIToken{
+		ID:        uint(token.ID),
+		Name:      token.Name,
+		Scope:     gmodel.TokenScope(token.Scope),
+		CreatedAt: token.CreatedAt,
+	}
+	if token.LastUsedAt.Valid {
+		lastUsed := token.LastUsedAt.Time
+		gToken.LastUsedAt = &lastUsed
+	}
+	return gToken
+}
+
+// CommandPolicyToGraphQL convierte una política de comandos a GraphQL
+func CommandPolicyToGraphQL(policy *security.CommandPolicy, updatedAt time.Time) *gmodel.CommandPolicy {
+	rules := make([]*gmodel.CommandRule, len(policy.Rules))
+	for i, rule := range policy.Rules {
+		rules[i] = &gmodel.CommandRule{
+			Name:    rule.Name,
+			Command: rule.Command,
+			Args:    optionalString(rule.Args),
+			PipedTo: optionalString(rule.PipedTo),
+			Action:  gmodel.CommandAction(rule.Action),
+			Reason:  optionalString(rule.Reason),
+		}
+	}
+
+	return &gmodel.CommandPolicy{
+		Default:   gmodel.CommandAction(policy.Default),
+		Rules:     rules,
+		UpdatedAt: updatedAt,
+	}
+}
+
+// CommandPolicyInputToPolicy convierte el input GraphQL a una política, sin validarla
+func CommandPolicyInputToPolicy(input *gmodel.CommandPolicyInput) security.CommandPolicy {
+	var policy security.CommandPolicy
+	if input.Default != nil {
+		policy.Default = security.CommandAction(*input.Default)
+	}
+	policy.Rules = make([]security.CommandRule, len(input.Rules))
+	for i, rule := range input.Rules {
+		policy.Rules[i] = security.CommandRule{
+			Command: rule.Command,
+			Action:  security.CommandAction(rule.Action),
+		}
+		if rule.Name != nil {
+			policy.Rules[i].Name = *rule.Name
+		}
+		if rule.Args != nil {
+			policy.Rules[i].Args = *rule.Args
+		}
+		if rule.PipedTo != nil {
+			policy.Rules[i].PipedTo = *rule.PipedTo
+		}
+		if rule.Reason != nil {
+			policy.Rules[i].Reason = *rule.Reason
+		}
+	}
+	return policy
+}
+
+// optionalString devuelve nil para los campos vacíos
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
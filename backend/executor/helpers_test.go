@@ -2,10 +2,13 @@ package executor
 
 import (
 	"database/sql"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/arandu-ai/arandu/config"
 	"github.com/arandu-ai/arandu/database"
+	gmodel "github.com/arandu-ai/arandu/graph/model"
 )
 
 func TestTaskToGraphQL(t *testing.T) {
@@ -62,6 +65,24 @@ func TestTaskToGraphQL_EmptyFields(t *testing.T) {
 	}
 }
 
+func TestTaskToGraphQL_CustomTool(t *testing.T) {
+	tests := []struct {
+		taskType string
+		want     gmodel.TaskType
+	}{
+		{"browser", gmodel.TaskTypeBrowser},
+		{"search", gmodel.TaskTypeSearch},
+		{"run_tests", gmodel.TaskTypeCustom},
+	}
+
+	for _, tt := range tests {
+		result := TaskToGraphQL(database.Task{Type: sql.NullString{String: tt.taskType, Valid: true}})
+		if result.Type != tt.want {
+			t.Errorf("TaskToGraphQL(%q).Type = %q, want %q", tt.taskType, result.Type, tt.want)
+		}
+	}
+}
+
 func TestTasksToGraphQL(t *testing.T) {
 	tasks := []database.Task{
 		{ID: 1, Message: sql.NullString{String: "Task 1", Valid: true}},
@@ -231,6 +252,45 @@ func TestFlowToGraphQL_NotRunning(t *testing.T) {
 	}
 }
 
+func TestFlowToGraphQL_Browser(t *testing.T) {
+	config.Config.Port = 8080
+
+	empty := FlowToGraphQL(database.ReadFlowRow{ID: 1})
+	if empty.Browser.URL != "" || empty.Browser.ScreenshotURL != "" {
+		t.Errorf("Browser = %+v, want empty without screenshots", empty.Browser)
+	}
+
+	result := FlowToGraphQL(database.ReadFlowRow{
+		ID:                1,
+		BrowserUrl:        sql.NullString{String: "https://example.com", Valid: true},
+		BrowserScreenshot: sql.NullString{String: "1/3/abc.png", Valid: true},
+	})
+	if result.Browser.URL != "https://example.com" {
+		t.Errorf("Browser.URL = %q, want https://example.com", result.Browser.URL)
+	}
+	if result.Browser.ScreenshotURL != "http://localhost:8080/browser/1/3/abc.png" {
+		t.Errorf("Browser.ScreenshotURL = %q", result.Browser.ScreenshotURL)
+	}
+}
+
+func TestScreenshotsToGraphQL(t *testing.T) {
+	screenshots := []database.Screenshot{
+		{ID: 1, TaskID: 3, Url: "https://example.com", Path: "1/3/abc.png", Sha256: "abc", Size: 120},
+		{ID: 2, TaskID: 4, Url: "https://example.com/docs", Path: "1/4/def.png", Sha256: "def", Size: 80},
+	}
+
+	result := ScreenshotsToGraphQL(screenshots)
+	if len(result) != 2 {
+		t.Fatalf("len(result) = %d, want 2", len(result))
+	}
+	if result[0].TaskID != 3 || result[0].Sha256 != "abc" || result[0].Size != 120 {
+		t.Errorf("result[0] = %+v", result[0])
+	}
+	if !strings.HasSuffix(result[1].ScreenshotURL, "/browser/1/4/def.png") {
+		t.Errorf("result[1].ScreenshotURL = %q", result[1].ScreenshotURL)
+	}
+}
+
 func TestFlowToGraphQLFull(t *testing.T) {
 	flow := database.ReadFlowRow{
 		ID:              1,
@@ -0,0 +1,219 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/mcp"
+	"github.com/arandu-ai/arandu/providers"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// MCPConnectTimeout limita la conexión y el listado de herramientas de un servidor
+const MCPConnectTimeout = 30 * time.Second
+
+// mcpServerSession es la conexión de un flow con un servidor MCP
+type mcpServerSession struct {
+	client *mcp.Client
+	tools  []mcp.Tool
+}
+
+// mcpFlowSessions son las conexiones de un flow, creadas la primera vez que se necesitan
+type mcpFlowSessions struct {
+	mu      sync.Mutex
+	servers map[string]*mcpServerSession
+	// failed guarda los servidores que no respondieron para no reintentar en cada tarea
+	failed map[string]error
+}
+
+var (
+	mcpSessionsMu sync.Mutex
+	mcpSessions   = map[int64]*mcpFlowSessions{}
+)
+
+// FlowMCPServers devuelve los servidores MCP de un flow
+// Si el flow no eligió ninguno se usan los servidores marcados como default
+func FlowMCPServers(raw string) []string {
+	if raw == "" {
+		return mcp.DefaultServerNames()
+	}
+
+	var names []string
+	if err := json.Unmarshal([]byte(raw), &names); err != nil {
+		logging.Warn("Invalid mcp servers config", "value", raw, "error", err.Error())
+		return nil
+	}
+	return names
+}
+
+func flowMCPSessions(flowID int64) *mcpFlowSessions {
+	mcpSessionsMu.Lock()
+	defer mcpSessionsMu.Unlock()
+
+	sessions, ok := mcpSessions[flowID]
+	if !ok {
+		sessions = &mcpFlowSessions{
+			servers: map[string]*mcpServerSession{},
+			failed:  map[string]error{},
+		}
+		mcpSessions[flowID] = sessions
+	}
+	return sessions
+}
+
+// mcpSession devuelve la conexión del flow con el servidor, conectando si hace falta
+func mcpSession(flowID int64, name string) (*mcpServerSession, error) {
+	sessions := flowMCPSessions(flowID)
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+
+	if session, ok := sessions.servers[name]; ok {
+		return session, nil
+	}
+	if err, ok := sessions.failed[name]; ok {
+		return nil, err
+	}
+
+	cfg, ok := mcp.Server(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown mcp server: %s", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), MCPConnectTimeout)
+	defer cancel()
+
+	client, err := mcp.Connect(ctx, cfg)
+	if err != nil {
+		sessions.failed[name] = err
+		return nil, err
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		client.Close()
+		sessions.failed[name] = err
+		return nil, err
+	}
+
+	logging.Info("MCP server connected",
+		"flow_id", flowID,
+		"server", name,
+		"server_info", client.ServerInfo().Name,
+		"tools", len(tools),
+	)
+
+	session := &mcpServerSession{client: client, tools: tools}
+	sessions.servers[name] = session
+	return session, nil
+}
+
+// mcpToolsForFlow devuelve las herramientas de los servidores MCP del flow
+// Un servidor que no responde se omite para no bloquear el flow
+func mcpToolsForFlow(flowID int64, servers []string) []llms.Tool {
+	var tools []llms.Tool
+	for _, name := range servers {
+		session, err := mcpSession(flowID, name)
+		if err != nil {
+			logging.Warn("MCP server unavailable", "flow_id", flowID, "server", name, "error", err.Error())
+			continue
+		}
+		for _, tool := range session.tools {
+			tools = append(tools, providers.MCPTool(name, tool.Name, tool.Description, tool.InputSchema))
+		}
+	}
+	return tools
+}
+
+// resolveMCPTool busca la herramienta del servidor a partir del nombre que vio el modelo
+func resolveMCPTool(session *mcpServerSession, server string, tool string) (string, bool) {
+	want := providers.MCPToolName(server, tool)
+	for _, t := range session.tools {
+		if t.Name == tool || providers.MCPToolName(server, t.Name) == want {
+			return t.Name, true
+		}
+	}
+	return "", false
+}
+
+func processMCPTask(db *database.Queries, task database.Task) error {
+	args, err := unmarshalTaskArgs[providers.MCPArgs](task)
+	if err != nil {
+		return err
+	}
+
+	flow, err := db.ReadFlow(context.Background(), task.FlowID.Int64)
+	if err != nil {
+		return fmt.Errorf("failed to get flow: %w", err)
+	}
+	allowed := false
+	for _, name := range FlowMCPServers(flow.McpServers.String) {
+		allowed = allowed || name == args.Server
+	}
+	if !allowed {
+		return fmt.Errorf("mcp server %s is not enabled for this flow", args.Server)
+	}
+
+	session, err := mcpSession(task.FlowID.Int64, args.Server)
+	if err != nil {
+		return fmt.Errorf("mcp server %s is unavailable: %w", args.Server, err)
+	}
+
+	toolName, ok := resolveMCPTool(session, args.Server, args.Tool)
+	if !ok {
+		return fmt.Errorf("mcp server %s has no tool %s", args.Server, args.Tool)
+	}
+
+	cfg, _ := mcp.Server(args.Server)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.CallTimeout())
+	defer cancel()
+
+	start := time.Now()
+	result, err := session.client.CallTool(ctx, toolName, args.Arguments)
+	if err != nil {
+		return err
+	}
+
+	logging.Info("MCP tool called",
+		"flow_id", task.FlowID.Int64,
+		"server", args.Server,
+		"tool", toolName,
+		"is_error", result.IsError,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	// Los errores de la herramienta vuelven al modelo para que pueda corregir la llamada
+	text := strings.TrimSpace(result.Text())
+	if result.IsError {
+		text = "Tool error: " + text
+	} else if text == "" {
+		text = "Tool executed successfully"
+	}
+
+	_, err = storeToolResults(db, task, text)
+	return err
+}
+
+// CloseMCPSessions cierra las conexiones MCP del flow
+func CloseMCPSessions(flowID int64) {
+	mcpSessionsMu.Lock()
+	sessions, ok := mcpSessions[flowID]
+	delete(mcpSessions, flowID)
+	mcpSessionsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+	for name, session := range sessions.servers {
+		if err := session.client.Close(); err != nil {
+			logging.Warn("Failed to close MCP session", "flow_id", flowID, "server", name, "error", err.Error())
+		}
+	}
+}
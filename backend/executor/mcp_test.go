@@ -0,0 +1,145 @@
+package executor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arandu-ai/arandu/mcp"
+)
+
+// newTestMCPServer levanta un servidor MCP mínimo por HTTP con una herramienta "read.file"
+func newTestMCPServer(t *testing.T, connects *atomic.Int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			return
+		}
+
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		if _, ok := msg["id"]; !ok {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		var result any
+		switch msg["method"] {
+		case "initialize":
+			connects.Add(1)
+			result = map[string]any{
+				"protocolVersion": mcp.ProtocolVersion,
+				"serverInfo":      map[string]any{"name": "files", "version": "1.0.0"},
+				"capabilities":    map[string]any{"tools": map[string]any{}},
+			}
+		case "tools/list":
+			result = map[string]any{"tools": []any{map[string]any{
+				"name":        "read.file",
+				"description": "Reads a file",
+				"inputSchema": map[string]any{"type": "object", "properties": map[string]any{"path": map[string]any{"type": "string"}}},
+			}}}
+		default:
+			result = map[string]any{"content": []any{map[string]any{"type": "text", "text": "ok"}}}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": msg["id"], "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func loadTestMCPServers(t *testing.T, data string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcp.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte(`{"servers": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mcp.LoadServers(empty) })
+
+	if err := mcp.LoadServers(path); err != nil {
+		t.Fatalf("LoadServers() error = %v", err)
+	}
+}
+
+func TestFlowMCPServers(t *testing.T) {
+	loadTestMCPServers(t, `{"servers": [
+		{"name": "files", "transport": "http", "url": "https://mcp.example.com", "default": true},
+		{"name": "docs", "transport": "http", "url": "https://docs.example.com"}
+	]}`)
+
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{name: "default servers", raw: "", want: []string{"files"}},
+		{name: "flow servers", raw: `["docs"]`, want: []string{"docs"}},
+		{name: "disabled", raw: `[]`, want: []string{}},
+		{name: "invalid", raw: `docs`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FlowMCPServers(tt.raw)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FlowMCPServers() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("FlowMCPServers() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMCPToolsForFlow(t *testing.T) {
+	var connects atomic.Int32
+	server := newTestMCPServer(t, &connects)
+	loadTestMCPServers(t, `{"servers": [
+		{"name": "files", "transport": "http", "url": "`+server.URL+`"},
+		{"name": "down", "transport": "http", "url": "http://127.0.0.1:1/mcp"}
+	]}`)
+
+	const flowID = 4242
+	defer CloseMCPSessions(flowID)
+
+	// El servidor caído se omite y el resto de herramientas sigue disponible
+	tools := mcpToolsForFlow(flowID, []string{"files", "down"})
+	if len(tools) != 1 || tools[0].Function.Name != "mcp__files__read_file" {
+		t.Fatalf("mcpToolsForFlow() = %+v", tools)
+	}
+
+	// La sesión se reutiliza entre tareas del mismo flow
+	mcpToolsForFlow(flowID, []string{"files", "down"})
+	if connects.Load() != 1 {
+		t.Errorf("server initialized %d times, want 1", connects.Load())
+	}
+
+	session, err := mcpSession(flowID, "files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, ok := resolveMCPTool(session, "files", "read_file"); !ok || name != "read.file" {
+		t.Errorf("resolveMCPTool(read_file) = %q, %v", name, ok)
+	}
+	if _, ok := resolveMCPTool(session, "files", "write_file"); ok {
+		t.Error("resolveMCPTool() should not find unknown tools")
+	}
+
+	// Al cerrar el flow la próxima tarea vuelve a conectar
+	CloseMCPSessions(flowID)
+	mcpToolsForFlow(flowID, []string{"files"})
+	if connects.Load() != 2 {
+		t.Errorf("server initialized %d times after close, want 2", connects.Load())
+	}
+}
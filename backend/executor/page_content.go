@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arandu-ai/arandu/providers"
+)
+
+// BrowserPageSize es el tamaño máximo de cada página de contenido que ve el modelo
+// Queda por debajo de MaxResultsLength para que la cabecera y la URL no se recorten
+const BrowserPageSize = 3500
+
+// minBrowserPageSize es el tamaño mínimo de página cuando el resultado incluye otra salida
+const minBrowserPageSize = 1000
+
+// validatePagination comprueba los parámetros page y offset del browser
+func validatePagination(args providers.BrowserArgs) error {
+	if args.Page < 0 {
+		return fmt.Errorf("page must be 1 or greater, got %d", args.Page)
+	}
+	if args.Offset < 0 {
+		return fmt.Errorf("offset must be 0 or greater, got %d", args.Offset)
+	}
+	if args.Page > 1 && args.Offset > 0 {
+		return fmt.Errorf("page and offset cannot be used together")
+	}
+	return nil
+}
+
+// paginateContent devuelve la página pedida del contenido con una cabecera
+// que indica cómo seguir leyendo; el contenido corto se devuelve tal cual
+func paginateContent(content string, page int, offset int, size int) string {
+	total := len(content)
+	if total <= size && offset == 0 && page <= 1 {
+		return content
+	}
+
+	// Los cortes dependen del contenido, así que las páginas se calculan desde el principio
+	var starts []int
+	for start := 0; start < total; start = pageEnd(content, start, size) {
+		starts = append(starts, start)
+	}
+
+	start := 0
+	current := 0
+	switch {
+	case offset > 0:
+		if offset >= total {
+			return fmt.Sprintf("[Offset %d is past the end of the content (%d characters)]", offset, total)
+		}
+		start = runeStart(content, offset)
+		for i, s := range starts {
+			if s <= start {
+				current = i
+			}
+		}
+	case page > 1:
+		if page > len(starts) {
+			return fmt.Sprintf("[Page %d does not exist, the content has %d pages]", page, len(starts))
+		}
+		current = page - 1
+		start = starts[current]
+	}
+
+	end := pageEnd(content, start, size)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Page %d of %d, characters %d-%d of %d", current+1, len(starts), start, end, total)
+	if end < total {
+		if offset > 0 {
+			fmt.Fprintf(&b, ". Next: offset=%d", end)
+		} else {
+			fmt.Fprintf(&b, ". Next: page=%d", current+2)
+		}
+	}
+	b.WriteString("]\n")
+	b.WriteString(content[start:end])
+
+	return b.String()
+}
+
+// pageEnd busca dónde termina la página que empieza en start
+// Prefiere cortar entre párrafos fuera de bloques de código, luego en un salto de línea
+func pageEnd(content string, start int, size int) int {
+	limit := start + size
+	if limit >= len(content) {
+		return len(content)
+	}
+
+	chunk := content[start:limit]
+	half := size / 2
+
+	lastParagraph, lastLine := -1, -1
+	inFence := false
+	pos := 0
+	for _, line := range strings.SplitAfter(chunk, "\n") {
+		pos += len(line)
+		if !strings.HasSuffix(line, "\n") {
+			break
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if pos < half {
+			continue
+		}
+		lastLine = pos
+		if !inFence && strings.TrimSpace(line) == "" {
+			lastParagraph = pos
+		}
+	}
+
+	switch {
+	case lastParagraph > 0:
+		return start + lastParagraph
+	case lastLine > 0:
+		return start + lastLine
+	default:
+		return runeStart(content, limit)
+	}
+}
+
+// runeStart retrocede hasta el inicio del carácter UTF-8 que contiene i
+func runeStart(content string, i int) int {
+	for i > 0 && i < len(content) && !utf8.RuneStart(content[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/providers"
+)
+
+func TestValidatePagination(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    providers.BrowserArgs
+		wantErr bool
+	}{
+		{name: "defaults", args: providers.BrowserArgs{}},
+		{name: "page", args: providers.BrowserArgs{Page: 3}},
+		{name: "offset", args: providers.BrowserArgs{Offset: 7000}},
+		{name: "negative page", args: providers.BrowserArgs{Page: -1}, wantErr: true},
+		{name: "negative offset", args: providers.BrowserArgs{Offset: -5}, wantErr: true},
+		{name: "page and offset", args: providers.BrowserArgs{Page: 2, Offset: 100}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePagination(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePagination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaginateContent_Short(t *testing.T) {
+	content := "# Title\n\nShort page"
+	if got := paginateContent(content, 0, 0, 100); got != content {
+		t.Errorf("paginateContent() = %q, want content unchanged", got)
+	}
+}
+
+func TestPaginateContent_Pages(t *testing.T) {
+	paragraphs := make([]string, 20)
+	for i := range paragraphs {
+		paragraphs[i] = strings.Repeat("word ", 10)
+	}
+	content := strings.Join(paragraphs, "\n\n")
+
+	var rebuilt strings.Builder
+	for page := 1; ; page++ {
+		got := paginateContent(content, page, 0, 200)
+		header, body, _ := strings.Cut(got, "\n")
+		if !strings.HasPrefix(header, "[Page ") {
+			t.Fatalf("page %d header = %q", page, header)
+		}
+		if len(body) > 200 {
+			t.Errorf("page %d has %d characters, want at most 200", page, len(body))
+		}
+		rebuilt.WriteString(body)
+		if !strings.Contains(header, "Next: page=") {
+			break
+		}
+		if page > 20 {
+			t.Fatal("pagination did not finish")
+		}
+	}
+
+	if rebuilt.String() != content {
+		t.Error("joining all pages does not rebuild the content")
+	}
+}
+
+func TestPaginateContent_Offset(t *testing.T) {
+	content := strings.Repeat("line of text\n", 50)
+
+	got := paginateContent(content, 0, 130, 100)
+	if !strings.Contains(got, "characters 130-") || !strings.Contains(got, "Next: offset=") {
+		t.Errorf("paginateContent() header = %q", strings.SplitN(got, "\n", 2)[0])
+	}
+
+	if got := paginateContent(content, 0, len(content)+1, 100); !strings.Contains(got, "past the end") {
+		t.Errorf("paginateContent() past the end = %q", got)
+	}
+	if got := paginateContent(content, 99, 0, 100); !strings.Contains(got, "does not exist") {
+		t.Errorf("paginateContent() missing page = %q", got)
+	}
+}
+
+func TestPageEnd(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		size    int
+		want    int
+	}{
+		{name: "fits", content: "short", size: 100, want: 5},
+		{name: "paragraph break", content: strings.Repeat("a", 60) + "\n\n" + strings.Repeat("b", 60), size: 100, want: 62},
+		{name: "line break", content: strings.Repeat("a", 60) + "\n" + strings.Repeat("b", 60), size: 100, want: 61},
+		{name: "hard cut", content: strings.Repeat("a", 150), size: 100, want: 100},
+		{name: "utf8 boundary", content: strings.Repeat("a", 99) + "ñ" + strings.Repeat("a", 50), size: 100, want: 99},
+		{
+			name:    "keeps code blocks",
+			content: strings.Repeat("a", 45) + "\n\n```go\nfunc main() {\n\n\tfmt.Println()\n}\n```\n" + strings.Repeat("c", 80),
+			size:    80,
+			want:    47,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageEnd(tt.content, 0, tt.size); got != tt.want {
+				t.Errorf("pageEnd() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/security"
+	"github.com/docker/docker/api/types/container"
+)
+
+// PoolRefillTimeout es el tiempo máximo para arrancar un container del pool
+const PoolRefillTimeout = 10 * time.Minute
+
+var poolNameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// pooledContainer es un container idle esperando a ser asignado a un flow
+type pooledContainer struct {
+	dbID      int64
+	localID   string
+	name      string
+	createdAt time.Time
+}
+
+// PoolStatus resume el estado del pool para una imagen
+type PoolStatus struct {
+	Image     string
+	Target    int
+	Idle      int
+	Starting  int
+	LastError string
+}
+
+// ContainerPool mantiene containers pre-arrancados por imagen para que el
+// primer comando de un flow no tenga que esperar al pull y al arranque
+type ContainerPool struct {
+	mu        sync.Mutex
+	db        *database.Queries
+	targets   map[string]int
+	idle      map[string][]pooledContainer
+	starting  map[string]int
+	lastError map[string]string
+	spawn     func(ctx context.Context, name string, image string) (pooledContainer, error)
+	isRunning func(containerID string) (bool, error)
+	remove    func(c pooledContainer)
+}
+
+var containerPool = NewContainerPool()
+
+// NewContainerPool crea un pool vacío (sin imágenes configuradas)
+func NewContainerPool() *ContainerPool {
+	p := &ContainerPool{
+		targets:   make(map[string]int),
+		idle:      make(map[string][]pooledContainer),
+		starting:  make(map[string]int),
+		lastError: make(map[string]string),
+	}
+	p.spawn = p.spawnContainer
+	p.isRunning = IsContainerRunning
+	p.remove = p.deleteContainer
+	return p
+}
+
+// ParsePoolSizes interpreta la configuración "imagen=N,imagen=N"
+func ParsePoolSizes(raw string) (map[string]int, error) {
+	sizes := make(map[string]int)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid pool entry %q, expected image=size", entry)
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(entry[i+1:]))
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid pool size in %q", entry)
+		}
+
+		sizes[strings.TrimSpace(entry[:i])] = size
+	}
+	return sizes, nil
+}
+
+// InitContainerPool configura el pool global y empieza a llenarlo en background
+func InitContainerPool(sizes map[string]int, db *database.Queries) error {
+	containerPool.mu.Lock()
+	containerPool.db = db
+	for image, size := range sizes {
+		resolved, err := security.ResolveDockerImage(image)
+		if err != nil {
+			containerPool.mu.Unlock()
+			return fmt.Errorf("pool image %s: %w", image, err)
+		}
+		containerPool.targets[resolved] = size
+	}
+	images := make([]string, 0, len(containerPool.targets))
+	for image := range containerPool.targets {
+		images = append(images, image)
+	}
+	containerPool.mu.Unlock()
+
+	for _, image := range images {
+		containerPool.refill(image)
+	}
+
+	logging.Info("Container pool initialized", "images", len(images))
+	return nil
+}
+
+// Acquire entrega un container idle de la imagen indicada, si hay alguno
+// El pool se vuelve a llenar en background
+func (p *ContainerPool) Acquire(image string) (pooledContainer, bool) {
+	defer p.refill(image)
+
+	for {
+		c, ok := p.pop(image)
+		if !ok {
+			return pooledContainer{}, false
+		}
+
+		// Descartar containers que murieron mientras estaban idle
+		if running, err := p.isRunning(c.localID); err != nil || !running {
+			logging.Warn("Discarding dead pooled container", "name", c.name, "image", image)
+			go p.remove(c)
+			continue
+		}
+
+		logging.Debug("Pooled container acquired", "name", c.name, "image", image)
+		return c, true
+	}
+}
+
+// pop saca el container idle más antiguo de la imagen
+func (p *ContainerPool) pop(image string) (pooledContainer, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.idle[image]) == 0 {
+		return pooledContainer{}, false
+	}
+	c := p.idle[image][0]
+	p.idle[image] = p.idle[image][1:]
+	return c, true
+}
+
+// Status devuelve el estado del pool por imagen, ordenado por nombre
+func (p *ContainerPool) Status() []PoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := make([]PoolStatus, 0, len(p.targets))
+	for image, target := range p.targets {
+		status = append(status, PoolStatus{
+			Image:     image,
+			Target:    target,
+			Idle:      len(p.idle[image]),
+			Starting:  p.starting[image],
+			LastError: p.lastError[image],
+		})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Image < status[j].Image })
+	return status
+}
+
+// refill arranca los containers que falten para llegar al tamaño objetivo
+func (p *ContainerPool) refill(image string) {
+	p.mu.Lock()
+	missing := p.targets[image] - len(p.idle[image]) - p.starting[image]
+	if missing > 0 {
+		p.starting[image] += missing
+	}
+	p.mu.Unlock()
+
+	for i := 0; i < missing; i++ {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), PoolRefillTimeout)
+			defer cancel()
+
+			name := fmt.Sprintf("arandu-pool-%s-%d", poolNameSanitizer.ReplaceAllString(image, "-"), time.Now().UnixNano())
+			c, err := p.spawn(ctx, name, image)
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.starting[image]--
+			if err != nil {
+				logging.Error("Failed to warm pooled container", "image", image, "error", err.Error())
+				p.lastError[image] = err.Error()
+				return
+			}
+			delete(p.lastError, image)
+			p.idle[image] = append(p.idle[image], c)
+		}()
+	}
+}
+
+// spawnContainer arranca un container idle con los límites por defecto del sandbox
+func (p *ContainerPool) spawnContainer(ctx context.Context, name string, image string) (pooledContainer, error) {
+	limits := DefaultSandboxLimits()
+	dbID, err := SpawnContainer(ctx, name,
+		&container.Config{
+			Image: image,
+			Cmd:   []string{"tail", "-f", "/dev/null"},
+		},
+		&container.HostConfig{},
+		&limits,
+		p.db,
+	)
+	if err != nil {
+		return pooledContainer{}, err
+	}
+
+	info, err := dockerClient.ContainerInspect(ctx, name)
+	if err != nil {
+		return pooledContainer{}, fmt.Errorf("error inspecting pooled container: %w", err)
+	}
+
+	return pooledContainer{dbID: dbID, localID: info.ID, name: name, createdAt: time.Now()}, nil
+}
+
+// deleteContainer elimina un container del pool que ya no sirve
+func (p *ContainerPool) deleteContainer(c pooledContainer) {
+	if err := DeleteContainer(c.localID, c.dbID, p.db); err != nil {
+		logging.Error("Failed to delete pooled container", "name", c.name, "error", err.Error())
+	}
+}
+
+// claimPooledContainer asigna un container del pool a un flow renombrándolo
+func claimPooledContainer(ctx context.Context, c pooledContainer, flowID int64, db *database.Queries) error {
+	name := TerminalName(flowID)
+
+	if err := dockerClient.ContainerRename(ctx, c.localID, name); err != nil {
+		return fmt.Errorf("error renaming pooled container: %w", err)
+	}
+
+	if _, err := db.UpdateContainerName(ctx, database.UpdateContainerNameParams{
+		ID:   c.dbID,
+		Name: database.StringToNullString(name),
+	}); err != nil {
+		return fmt.Errorf("error updating pooled container name: %w", err)
+	}
+
+	logging.Info("Pooled container assigned to flow",
+		"flow_id", flowID,
+		"name", name,
+		"idle_for", time.Since(c.createdAt).Round(time.Second).String(),
+	)
+	return nil
+}
+
+// ContainerPoolStatus devuelve el estado del pool global
+func ContainerPoolStatus() []PoolStatus {
+	return containerPool.Status()
+}
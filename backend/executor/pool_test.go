@@ -0,0 +1,159 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParsePoolSizes(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string]int
+		wantErr bool
+	}{
+		{name: "empty", raw: "", want: map[string]int{}},
+		{name: "single image", raw: "python:latest=2", want: map[string]int{"python:latest": 2}},
+		{name: "multiple images with spaces", raw: " python:latest=2 , node:latest = 1 ", want: map[string]int{"python:latest": 2, "node:latest": 1}},
+		{name: "zero size", raw: "debian:latest=0", want: map[string]int{"debian:latest": 0}},
+		{name: "missing size", raw: "python:latest", wantErr: true},
+		{name: "missing image", raw: "=2", wantErr: true},
+		{name: "negative size", raw: "python:latest=-1", wantErr: true},
+		{name: "invalid size", raw: "python:latest=two", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePoolSizes(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePoolSizes(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParsePoolSizes(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+			for image, size := range tt.want {
+				if got[image] != size {
+					t.Errorf("size of %s = %d, want %d", image, got[image], size)
+				}
+			}
+		})
+	}
+}
+
+// fakePool crea un pool que no toca Docker
+func fakePool(spawnErr error) (*ContainerPool, *sync.Map) {
+	running := &sync.Map{}
+	p := NewContainerPool()
+
+	var mu sync.Mutex
+	var nextID int64
+	p.spawn = func(ctx context.Context, name string, image string) (pooledContainer, error) {
+		if spawnErr != nil {
+			return pooledContainer{}, spawnErr
+		}
+		mu.Lock()
+		nextID++
+		id := nextID
+		mu.Unlock()
+		running.Store(name, true)
+		return pooledContainer{dbID: id, localID: name, name: name, createdAt: time.Now()}, nil
+	}
+	p.isRunning = func(containerID string) (bool, error) {
+		_, ok := running.Load(containerID)
+		return ok, nil
+	}
+	p.remove = func(c pooledContainer) {
+		running.Delete(c.localID)
+	}
+	return p, running
+}
+
+// waitForIdle espera a que el pool termine de arrancar containers
+func waitForIdle(t *testing.T, p *ContainerPool, image string, idle int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, s := range p.Status() {
+			if s.Image == image && s.Starting == 0 && s.Idle == idle {
+				return
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("pool for %s did not reach %d idle containers: %+v", image, idle, p.Status())
+}
+
+func TestContainerPoolAcquireRefills(t *testing.T) {
+	p, _ := fakePool(nil)
+	p.targets["python:latest"] = 2
+	p.refill("python:latest")
+	waitForIdle(t, p, "python:latest", 2)
+
+	c, ok := p.Acquire("python:latest")
+	if !ok {
+		t.Fatal("Acquire should return a pooled container")
+	}
+	if c.dbID == 0 || c.localID == "" {
+		t.Errorf("Acquire returned an empty container: %+v", c)
+	}
+
+	// El pool vuelve a su tamaño objetivo tras entregar un container
+	waitForIdle(t, p, "python:latest", 2)
+}
+
+func TestContainerPoolAcquireUnknownImage(t *testing.T) {
+	p, _ := fakePool(nil)
+
+	if _, ok := p.Acquire("node:latest"); ok {
+		t.Error("Acquire should fail for an image without pool")
+	}
+	if status := p.Status(); len(status) != 0 {
+		t.Errorf("Status() = %+v, want empty", status)
+	}
+}
+
+func TestContainerPoolAcquireSkipsDeadContainers(t *testing.T) {
+	p, running := fakePool(nil)
+	p.targets["python:latest"] = 2
+	p.refill("python:latest")
+	waitForIdle(t, p, "python:latest", 2)
+
+	dead := p.idle["python:latest"][0]
+	running.Delete(dead.localID)
+
+	c, ok := p.Acquire("python:latest")
+	if !ok {
+		t.Fatal("Acquire should return the live container")
+	}
+	if c.localID == dead.localID {
+		t.Error("Acquire returned a dead container")
+	}
+}
+
+func TestContainerPoolStatusReportsErrors(t *testing.T) {
+	p, _ := fakePool(errors.New("pull failed"))
+	p.targets["python:latest"] = 1
+	p.targets["debian:latest"] = 0
+	p.refill("python:latest")
+	waitForIdle(t, p, "python:latest", 0)
+
+	status := p.Status()
+	if len(status) != 2 {
+		t.Fatalf("Status() returned %d entries, want 2", len(status))
+	}
+	if status[0].Image != "debian:latest" || status[1].Image != "python:latest" {
+		t.Errorf("Status() should be sorted by image: %+v", status)
+	}
+	if status[1].LastError != "pull failed" {
+		t.Errorf("LastError = %q, want %q", status[1].LastError, "pull failed")
+	}
+	if status[1].Target != 1 || status[1].Idle != 0 {
+		t.Errorf("unexpected status: %+v", status[1])
+	}
+}
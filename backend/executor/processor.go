@@ -4,8 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/arandu-ai/arandu/audit"
 	"github.com/arandu-ai/arandu/config"
 	"github.com/arandu-ai/arandu/database"
 	gmodel "github.com/arandu-ai/arandu/graph/model"
@@ -13,6 +17,7 @@ import (
 	"github.com/arandu-ai/arandu/logging"
 	"github.com/arandu-ai/arandu/models"
 	"github.com/arandu-ai/arandu/providers"
+	"github.com/arandu-ai/arandu/search"
 	"github.com/arandu-ai/arandu/security"
 	"github.com/docker/docker/api/types/container"
 )
@@ -21,7 +26,7 @@ import (
 const SummaryWordCount = 10
 
 // BrowserActionFunc type for browser action functions (Content, URLs)
-type BrowserActionFunc func(url string) (content string, screenshot string, err error)
+type BrowserActionFunc func(flowID int64, url string) (content string, screenshot []byte, err error)
 
 // unmarshalTaskArgs deserializa los argumentos de una tarea a un tipo específico
 func unmarshalTaskArgs[T any](task database.Task) (T, error) {
@@ -48,6 +53,53 @@ func validateCodeSecurity(path string) error {
 	return nil
 }
 
+// resolveFlowImage aplica la política de imágenes a la imagen elegida por el modelo
+// Si la imagen no está permitida se usa fallbackFlowImage en lugar de descargarla
+func resolveFlowImage(flowID int64, candidate string, db *database.Queries) (string, error) {
+	image, rejected := security.ResolveDockerImage(candidate)
+	if rejected == nil {
+		return image, nil
+	}
+
+	logging.Warn("Docker image rejected by policy",
+		"flow_id", flowID,
+		"image", candidate,
+		"error", rejected.Error(),
+	)
+
+	fallback, err := fallbackFlowImage()
+	if err != nil {
+		return "", fmt.Errorf("docker image %q is not allowed (%s) and %w", candidate, rejected.Error(), err)
+	}
+
+	msg := fmt.Sprintf("Image %q is not allowed (%s). Falling back to %s", candidate, rejected.Error(), fallback)
+	if logErr := createAndBroadcastLog(flowID, msg, LogTypeSystem, db); logErr != nil {
+		logging.Error("Failed to log image fallback", "flow_id", flowID, "error", logErr.Error())
+	}
+	return fallback, nil
+}
+
+// fallbackFlowImage devuelve DEFAULT_DOCKER_IMAGE si la política la permite,
+// o si no la primera imagen permitida
+func fallbackFlowImage() (string, error) {
+	if image, err := security.ResolveDockerImage(config.Config.DefaultDockerImage); err == nil {
+		return image, nil
+	}
+	for _, name := range security.AllowedDockerImageNames() {
+		if image, err := security.ResolveDockerImage(name); err == nil {
+			return image, nil
+		}
+	}
+	return "", fmt.Errorf("no docker image is allowed to fall back to")
+}
+
+// storeToolResults redacts secrets from a tool's output before it is saved
+// and sent to the model, and returns the stored text
+func storeToolResults(db *database.Queries, task database.Task, results string) (string, error) {
+	results = redactOutput(task.FlowID.Int64, results, db)
+	return results, updateTaskResults(db, task.ID, results)
+}
+
 // updateTaskResults is a helper to update task results in the database
 func updateTaskResults(db *database.Queries, taskID int64, results string) error {
 	_, err := db.UpdateTaskResults(context.Background(), database.UpdateTaskResultsParams{
@@ -66,10 +118,25 @@ func processBrowserTask(db *database.Queries, task database.Task) error {
 		return err
 	}
 
-	if err := validateBrowserSecurity(args.Url); err != nil {
+	if err := validatePagination(args); err != nil {
+		return err
+	}
+
+	// Downloads skip the browser: the file is fetched server-side and copied into the sandbox
+	if args.Action == providers.Download {
+		content, err := Download(task.FlowID.Int64, args, db)
+		recordURLFetched(db, task, args.Url, args.Action, err)
+		if err != nil {
+			return fmt.Errorf("failed to download file: %w", err)
+		}
+		_, err = storeToolResults(db, task, content)
 		return err
 	}
 
+	var content string
+	var png []byte
+	pageURL := args.Url
+
 	// Select the appropriate browser action based on the action type
 	var actionFn BrowserActionFunc
 	switch args.Action {
@@ -77,34 +144,122 @@ func processBrowserTask(db *database.Queries, task database.Task) error {
 		actionFn = Content
 	case providers.Url:
 		actionFn = URLs
-	default:
-		return fmt.Errorf("unknown browser action: %s", args.Action)
 	}
 
-	// Execute the browser action
-	content, screenshotName, err := actionFn(args.Url)
+	// The browser is shared by all flows; cap the number of concurrent actions
+	release, err := acquireBrowserSlot()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if actionFn != nil {
+		// sandbox://<port>/<path> points to a service in the flow container
+		if pageURL, err = ResolveSandboxURL(task.FlowID.Int64, args.Url, db); err != nil {
+			return err
+		}
+		if err := validateFlowURL(task.FlowID.Int64, pageURL); err != nil {
+			return err
+		}
+		content, png, err = actionFn(task.FlowID.Int64, pageURL)
+		if err == nil && args.Action == providers.Read {
+			content = paginateContent(content, args.Page, args.Offset, BrowserPageSize)
+		}
+	} else {
+		// Interactive actions run on the flow's persistent page
+		content, png, pageURL, err = BrowserInteract(task.FlowID.Int64, args, db)
+	}
+	recordURLFetched(db, task, pageURL, args.Action, err)
 	if err != nil {
 		return fmt.Errorf("failed to execute browser action: %w", err)
 	}
 
-	logging.Debug("Browser action completed", "url", args.Url, "action", args.Action, "screenshot", screenshotName)
+	// Keep what the agent saw for auditing, per flow and task
+	screenshot, err := saveScreenshot(db, task, pageURL, png)
+	if err != nil {
+		return fmt.Errorf("failed to save screenshot: %w", err)
+	}
+
+	logging.Debug("Browser action completed", "url", pageURL, "action", args.Action, "screenshot", screenshot.Path)
 
-	// Update task results
-	if err := updateTaskResults(db, task.ID, content); err != nil {
+	// Pages can echo credentials, e.g. an admin panel or a .env served by mistake
+	content, err = storeToolResults(db, task, content)
+	if err != nil {
 		return err
 	}
+	recordUntrustedContent(db, task, content)
 
 	// Broadcast browser update
-	baseURL := fmt.Sprintf("http://localhost:%d", config.Config.Port)
 	subscriptions.BroadcastBrowserUpdated(task.FlowID.Int64, &gmodel.Browser{
-		URL:           args.Url,
-		ScreenshotURL: baseURL + "/browser/" + screenshotName,
+		URL:           pageURL,
+		ScreenshotURL: ScreenshotURL(screenshot.Path),
+	})
+
+	return nil
+}
+
+// recordURLFetched adds a page or file fetched by the agent to the audit log
+func recordURLFetched(db *database.Queries, task database.Task, url string, action providers.BrowserAction, err error) {
+	audit.RecordAgent(db, audit.Event{
+		Action:  audit.ActionURLFetched,
+		FlowID:  task.FlowID.Int64,
+		TaskID:  task.ID,
+		Target:  url,
+		Details: map[string]any{"action": action},
+		Err:     err,
 	})
+}
+
+func processSearchTask(db *database.Queries, task database.Task) error {
+	args, err := unmarshalTaskArgs[providers.SearchArgs](task)
+	if err != nil {
+		return err
+	}
 
+	query := strings.TrimSpace(args.Query)
+	if query == "" {
+		return fmt.Errorf("search query cannot be empty")
+	}
+
+	// Without a backend the model is told to fall back to the browser instead of failing the flow
+	provider, err := search.Default()
+	if errors.Is(err, search.ErrNotConfigured) {
+		return updateTaskResults(db, task.ID, "Web search is not configured on this server. Use the browser tool to open a search engine instead.")
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), search.RequestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	results, err := provider.Search(ctx, query, search.ClampLimit(args.Limit))
+	if err != nil {
+		return fmt.Errorf("failed to search: %w", err)
+	}
+
+	logging.Debug("Search completed",
+		"provider", provider.Name(),
+		"query", query,
+		"results", len(results),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
+	formatted, err := storeToolResults(db, task, search.FormatResults(query, results))
+	if err != nil {
+		return err
+	}
+
+	// Titles and snippets come from third-party pages
+	recordUntrustedContent(db, task, formatted)
 	return nil
 }
 
 func processDoneTask(db *database.Queries, task database.Task) error {
+	CloseBrowserContext(task.FlowID.Int64)
+	CloseMCPSessions(task.FlowID.Int64)
+
 	flow, err := db.UpdateFlowStatus(context.Background(), database.UpdateFlowStatusParams{
 		ID:     task.FlowID.Int64,
 		Status: database.StringToNullString(string(models.FlowFinished)),
@@ -148,6 +303,11 @@ func processInputTask(provider providers.Provider, db *database.Queries, task da
 			return fmt.Errorf("failed to get docker image name: %w", err)
 		}
 
+		dockerImage, err = resolveFlowImage(task.FlowID.Int64, dockerImage, db)
+		if err != nil {
+			return err
+		}
+
 		flow, err := db.UpdateFlowName(context.Background(), database.UpdateFlowNameParams{
 			ID:   task.FlowID.Int64,
 			Name: database.StringToNullString(summary),
@@ -171,19 +331,15 @@ func processInputTask(provider providers.Provider, db *database.Queries, task da
 			return err
 		}
 
-		terminalContainerName := TerminalName(flow.ID)
-		terminalContainerID, err := SpawnContainer(context.Background(),
-			terminalContainerName,
-			&container.Config{
-				Image: dockerImage,
-				Cmd:   []string{"tail", "-f", "/dev/null"},
-			},
-			&container.HostConfig{},
-			db,
-		)
+		override, err := ParseSandboxLimits(flow.Sandbox.String)
+		if err != nil {
+			return err
+		}
+		limits := DefaultSandboxLimits().Merge(override)
 
+		terminalContainerID, err := startTerminalContainer(flow.ID, dockerImage, override, limits, db)
 		if err != nil {
-			return fmt.Errorf("failed to spawn container: %w", err)
+			return err
 		}
 
 		subscriptions.BroadcastFlowUpdated(flow.ID, &gmodel.Flow{
@@ -213,6 +369,44 @@ func processInputTask(provider providers.Provider, db *database.Queries, task da
 	return nil
 }
 
+// startTerminalContainer obtiene el container del flow, del pool si hay uno
+// listo para la imagen, o arrancando uno nuevo
+// Los flows con overrides de sandbox siempre arrancan su propio container: los
+// límites se fijan al crearlo
+func startTerminalContainer(flowID int64, dockerImage string, override SandboxLimits, limits SandboxLimits, db *database.Queries) (int64, error) {
+	ctx := context.Background()
+
+	if override == (SandboxLimits{}) {
+		if pooled, ok := containerPool.Acquire(dockerImage); ok {
+			if err := claimPooledContainer(ctx, pooled, flowID, db); err == nil {
+				return pooled.dbID, nil
+			} else {
+				logging.Warn("Failed to claim pooled container, spawning a new one",
+					"flow_id", flowID,
+					"error", err.Error(),
+				)
+				go containerPool.remove(pooled)
+			}
+		}
+	}
+
+	containerID, err := SpawnContainer(ctx,
+		TerminalName(flowID),
+		&container.Config{
+			Image: dockerImage,
+			Cmd:   []string{"tail", "-f", "/dev/null"},
+		},
+		&container.HostConfig{},
+		&limits,
+		db,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to spawn container: %w", err)
+	}
+
+	return containerID, nil
+}
+
 func processAskTask(db *database.Queries, task database.Task) error {
 	task, err := db.UpdateTaskStatus(context.Background(), database.UpdateTaskStatusParams{
 		Status: database.StringToNullString(models.TaskFinished),
@@ -232,6 +426,16 @@ func processTerminalTask(db *database.Queries, task database.Task) error {
 		return err
 	}
 
+	decision, err := commandDecision(db, task.FlowID.Int64, args.Input)
+	if err != nil {
+		return err
+	}
+	if decision.Action == security.CommandDeny {
+		return denyCommand(db, task, args.Input, decision)
+	}
+
+	checkpointBeforeRiskyCommand(task, args.Input, db)
+
 	results, err := ExecCommand(task.FlowID.Int64, args.Input, db)
 	if err != nil {
 		return fmt.Errorf("failed to execute command: %w", err)
@@ -254,12 +458,10 @@ func processCodeTask(db *database.Queries, task database.Task) error {
 
 	switch args.Action {
 	case providers.ReadFile:
-		// Use quoted path to prevent command injection
-		cmd := fmt.Sprintf("cat '%s'", args.Path)
-		var execErr error
-		results, execErr = ExecCommand(task.FlowID.Int64, cmd, db)
-		if execErr != nil {
-			return fmt.Errorf("error executing cat command: %w", execErr)
+		var readErr error
+		results, readErr = ReadFile(task.FlowID.Int64, args.Path, db)
+		if readErr != nil {
+			return readErr
 		}
 
 	case providers.UpdateFile:
@@ -272,5 +474,14 @@ func processCodeTask(db *database.Queries, task database.Task) error {
 		return fmt.Errorf("unknown code action: %s", args.Action)
 	}
 
-	return updateTaskResults(db, task.ID, results)
+	results, err = storeToolResults(db, task, results)
+	if err != nil {
+		return err
+	}
+
+	// Files may come from cloned repositories or downloads
+	if args.Action == providers.ReadFile {
+		recordUntrustedContent(db, task, results)
+	}
+	return nil
 }
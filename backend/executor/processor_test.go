@@ -4,10 +4,51 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/arandu-ai/arandu/config"
 	"github.com/arandu-ai/arandu/database"
 	"github.com/arandu-ai/arandu/providers"
+	"github.com/arandu-ai/arandu/security"
 )
 
+// TestFallbackFlowImage tests the image used when the model picks a disallowed one
+func TestFallbackFlowImage(t *testing.T) {
+	allowed := security.AllowedDockerImages
+	allowAny := config.Config.AllowAnyDockerImage
+	defaultImage := config.Config.DefaultDockerImage
+	t.Cleanup(func() {
+		security.AllowedDockerImages = allowed
+		config.Config.AllowAnyDockerImage = allowAny
+		config.Config.DefaultDockerImage = defaultImage
+	})
+	config.Config.AllowAnyDockerImage = false
+	config.Config.DefaultDockerImage = "debian:latest"
+
+	tests := []struct {
+		name    string
+		allowed map[string]bool
+		want    string
+		wantErr bool
+	}{
+		{name: "default allowed", allowed: map[string]bool{"python:3.12": true, "debian:latest": true}, want: "debian:latest"},
+		{name: "default not allowed", allowed: map[string]bool{"python:3.12": true, "node:20": true}, want: "node:20"},
+		{name: "disabled skipped", allowed: map[string]bool{"alpine:latest": false, "python:3.12": true}, want: "python:3.12"},
+		{name: "nothing allowed", allowed: map[string]bool{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			security.AllowedDockerImages = tt.allowed
+			got, err := fallbackFlowImage()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fallbackFlowImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("fallbackFlowImage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestUnmarshalTaskArgs tests the generic unmarshal function
 func TestUnmarshalTaskArgs(t *testing.T) {
 	tests := []struct {
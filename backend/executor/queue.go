@@ -3,10 +3,12 @@ package executor
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/arandu-ai/arandu/audit"
 	"github.com/arandu-ai/arandu/database"
 	"github.com/arandu-ai/arandu/graph/subscriptions"
 	"github.com/arandu-ai/arandu/logging"
@@ -64,6 +66,18 @@ var taskHandlers = map[string]TaskHandler{
 		},
 		NeedsNextTask: true,
 	},
+	string(models.Search): {
+		Process: func(_ providers.Provider, db *database.Queries, t database.Task) error {
+			return processSearchTask(db, t)
+		},
+		NeedsNextTask: true,
+	},
+	"mcp": {
+		Process: func(_ providers.Provider, db *database.Queries, t database.Task) error {
+			return processMCPTask(db, t)
+		},
+		NeedsNextTask: true,
+	},
 }
 
 // QueueManager maneja las colas de tareas de forma thread-safe
@@ -78,6 +92,76 @@ var queueManager = &QueueManager{
 	stopChannels: make(map[int64]chan struct{}),
 }
 
+// ErrFlowBusy indica que el flow está procesando tareas
+var ErrFlowBusy = errors.New("flow is busy processing tasks, try again when it is waiting for input")
+
+// flowLocks serializa el procesamiento de tareas de cada flow con las
+// operaciones que modifican su container (p.ej. rollback)
+var flowLocks sync.Map
+
+// flowLock devuelve el lock del flow, creándolo si no existe
+func flowLock(flowId int64) *sync.Mutex {
+	lock, _ := flowLocks.LoadOrStore(flowId, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// isCurrentFlowLock indica si lock sigue siendo el lock del flow; deja de
+// serlo cuando quien lo tenía lo olvidó con forgetFlowLock
+func isCurrentFlowLock(flowId int64, lock *sync.Mutex) bool {
+	current, ok := flowLocks.Load(flowId)
+	return ok && current == lock
+}
+
+// lockFlow bloquea el flow y devuelve su lock ya tomado
+// Si el lock se olvidó mientras se esperaba, se vuelve a intentar con el nuevo
+func lockFlow(flowId int64) *sync.Mutex {
+	for {
+		lock := flowLock(flowId)
+		lock.Lock()
+		if isCurrentFlowLock(flowId, lock) {
+			return lock
+		}
+		lock.Unlock()
+	}
+}
+
+// forgetFlowLock borra el lock de un flow terminado para que flowLocks no
+// crezca sin límite. Solo lo llama quien tiene el lock tomado, justo antes de
+// soltarlo; quienes esperaban lo detectan en lockFlow y toman uno nuevo
+func forgetFlowLock(flowId int64, lock *sync.Mutex) {
+	flowLocks.CompareAndDelete(flowId, lock)
+}
+
+// ReleaseFlowLock espera a que el flow quede libre y olvida su lock
+// Bloquea mientras se procesa una tarea, así que se llama en una goroutine
+func ReleaseFlowLock(flowId int64) {
+	lock := lockFlow(flowId)
+	forgetFlowLock(flowId, lock)
+	lock.Unlock()
+}
+
+// lockIdleFlow bloquea el flow si no está procesando ni tiene tareas pendientes
+// Devuelve la función para liberarlo
+func lockIdleFlow(flowId int64) (func(), error) {
+	for {
+		lock := flowLock(flowId)
+		if !lock.TryLock() {
+			return nil, ErrFlowBusy
+		}
+		if !isCurrentFlowLock(flowId, lock) {
+			lock.Unlock()
+			continue
+		}
+
+		if q, ok := getQueue(flowId); ok && len(q) > 0 {
+			lock.Unlock()
+			return nil, ErrFlowBusy
+		}
+
+		return lock.Unlock, nil
+	}
+}
+
 // AddQueue crea una nueva cola para un flow si no existe
 func AddQueue(flowId int64, db *database.Queries) {
 	queueManager.mu.Lock()
@@ -205,21 +289,73 @@ func processQueue(flowId int64, db *database.Queries) {
 
 // processTask procesa una tarea individual usando el mapa de handlers
 func processTask(flowId int64, task database.Task, provider providers.Provider, db *database.Queries) {
+	lock := lockFlow(flowId)
+	defer func() {
+		// done termina el flow: su lock se olvida antes de soltarlo
+		if task.Type.String == string(models.Done) {
+			forgetFlowLock(flowId, lock)
+		}
+		lock.Unlock()
+	}()
+
 	start := time.Now()
 	logging.Debug("Processing task", "task_id", task.ID, "type", task.Type.String)
 
-	// Broadcast task added
-	subscriptions.BroadcastTaskAdded(task.FlowID.Int64, TaskToGraphQL(task))
+	// Las tareas que vuelven de una aprobación ya se mostraron
+	if task.Approval == models.ApprovalNone {
+		subscriptions.BroadcastTaskAdded(task.FlowID.Int64, TaskToGraphQL(task))
+	}
 
 	// Buscar handler para este tipo de tarea
-	handler, ok := taskHandlers[task.Type.String]
+	handler, ok := lookupTaskHandler(task.Type.String)
 	if !ok {
 		logging.Warn("Unknown task type", "type", task.Type.String)
 		return
 	}
 
-	// Ejecutar el handler
-	err := handler.Process(provider, db, task)
+	// Un mensaje del usuario reemplaza a las acciones que esperaban aprobación
+	if task.Type.String == string(models.Input) {
+		dismissPendingApprovals(db, flowId)
+	}
+
+	// Las acciones sensibles después de contenido no confiable esperan al usuario
+	if task.Approval == models.ApprovalNone {
+		reason, err := approvalReason(db, task)
+		if err != nil {
+			logging.Error("Failed to check approval policy", "task_id", task.ID, "error", err.Error())
+			updateTaskError(db, task.ID, err)
+			return
+		}
+		if reason != "" {
+			requestApproval(db, task, reason)
+			return
+		}
+	}
+
+	var err error
+	if task.Approval == models.ApprovalRejected {
+		// El rechazo ya es el resultado; el modelo elige otra acción
+		logging.Info("Skipping rejected task", "task_id", task.ID, "flow_id", flowId)
+	} else {
+		// Ejecutar el handler, contando lo que se redacte de su salida
+		startRedactionTally(flowId)
+		err = handler.Process(provider, db, task)
+		finishRedactionTally(db, flowId, task.ID)
+
+		// Las llamadas a herramientas quedan en el log de auditoría; los mensajes
+		// del usuario se registran al crearlos
+		if task.Type.String != string(models.Input) {
+			audit.RecordAgent(db, audit.Event{
+				Action:  audit.ActionToolCalled,
+				FlowID:  task.FlowID.Int64,
+				TaskID:  task.ID,
+				Target:  task.Type.String,
+				Details: map[string]any{"args": task.Args.String},
+				Err:     err,
+			})
+		}
+	}
+
 	if err != nil {
 		logging.Error("Failed to process task",
 			"task_id", task.ID,
@@ -282,9 +418,18 @@ func getNextTask(provider providers.Provider, db *database.Queries, flowId int64
 		}
 	}
 
+	// Los containers restaurados de un snapshot corren una imagen interna,
+	// el modelo tiene que seguir viendo la imagen original
+	dockerImage := flow.ContainerImage.String
+	if flow.ContainerBaseImage.Valid {
+		dockerImage = flow.ContainerBaseImage.String
+	}
+
 	c := provider.NextTask(providers.NextTaskOptions{
 		Tasks:       tasks,
-		DockerImage: flow.ContainerImage.String,
+		DockerImage: dockerImage,
+		Tools:       mcpToolsForFlow(flowId, FlowMCPServers(flow.McpServers.String)),
+		Secrets:     flowRedactor(flowId, db).Names(),
 	})
 
 	lastTask := tasks[len(tasks)-1]
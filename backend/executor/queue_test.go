@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"sync"
 	"testing"
 	"time"
 )
@@ -28,7 +29,7 @@ func TestQueueConstants(t *testing.T) {
 
 func TestTaskHandlersRegistration(t *testing.T) {
 	// Verificar que todos los tipos de tarea esperados están registrados
-	expectedTypes := []string{"input", "ask", "terminal", "code", "done", "browser"}
+	expectedTypes := []string{"input", "ask", "terminal", "code", "done", "browser", "search", "mcp"}
 
 	for _, taskType := range expectedTypes {
 		t.Run(taskType, func(t *testing.T) {
@@ -56,6 +57,7 @@ func TestTaskHandlersNeedsNextTask(t *testing.T) {
 		{"code", true},
 		{"done", false},
 		{"browser", true},
+		{"search", true},
 	}
 
 	for _, tt := range tests {
@@ -72,8 +74,8 @@ func TestTaskHandlersNeedsNextTask(t *testing.T) {
 }
 
 func TestTaskHandlersCount(t *testing.T) {
-	// Verificar que tenemos exactamente 6 handlers
-	expected := 6
+	// Verificar que tenemos exactamente 8 handlers
+	expected := 8
 	if len(taskHandlers) != expected {
 		t.Errorf("len(taskHandlers) = %d, want %d", len(taskHandlers), expected)
 	}
@@ -120,3 +122,58 @@ func TestGetStopChannelNotExists(t *testing.T) {
 		t.Error("getStopChannel should return false for non-existent flow")
 	}
 }
+
+func TestReleaseFlowLock(t *testing.T) {
+	// Test que ReleaseFlowLock espera al lock tomado antes de borrarlo
+	lock := lockFlow(999998)
+	if again := flowLock(999998); again != lock {
+		t.Error("flowLock should return the same lock for the same flow")
+	}
+
+	released := make(chan struct{})
+	go func() {
+		ReleaseFlowLock(999998)
+		close(released)
+	}()
+
+	select {
+	case <-released:
+		t.Fatal("ReleaseFlowLock should wait for the lock holder")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if !isCurrentFlowLock(999998, lock) {
+		t.Error("the lock should not be forgotten while it is held")
+	}
+
+	lock.Unlock()
+	<-released
+	if _, ok := flowLocks.Load(int64(999998)); ok {
+		t.Error("ReleaseFlowLock should remove the flow lock")
+	}
+}
+
+func TestLockFlowAfterForget(t *testing.T) {
+	// Test que quien esperaba un lock olvidado no comparte el flow con el siguiente
+	lock := lockFlow(999997)
+
+	acquired := make(chan *sync.Mutex)
+	go func() { acquired <- lockFlow(999997) }()
+
+	time.Sleep(20 * time.Millisecond)
+	forgetFlowLock(999997, lock)
+	lock.Unlock()
+
+	waiter := <-acquired
+	if waiter == lock {
+		t.Fatal("lockFlow returned a forgotten lock")
+	}
+	if !isCurrentFlowLock(999997, waiter) {
+		t.Error("lockFlow should return the current flow lock")
+	}
+	if unlock, err := lockIdleFlow(999997); err == nil {
+		unlock()
+		t.Error("lockIdleFlow should fail while the new lock is held")
+	}
+	waiter.Unlock()
+	ReleaseFlowLock(999997)
+}
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/redact"
+)
+
+// outputDetectors son los detectores que se aplican a la salida de todos los
+// flows, además de los secretos de cada uno
+var outputDetectors []redact.Detector
+
+// InitRedaction arma los detectores de salida según la configuración:
+// los patrones incluidos, el de entropía y los del archivo del operador
+func InitRedaction() error {
+	if !config.Config.RedactionEnabled {
+		outputDetectors = nil
+		logging.Warn("Output redaction disabled; only vault secrets are masked")
+		return nil
+	}
+
+	detectors := redact.Builtin()
+	if threshold := config.Config.RedactionEntropyThreshold; threshold > 0 {
+		detectors = append(detectors, redact.NewEntropyDetector(threshold))
+	}
+	if path := config.Config.RedactionPatternsFile; path != "" {
+		custom, err := redact.LoadPatterns(path)
+		if err != nil {
+			return err
+		}
+		detectors = append(detectors, custom...)
+	}
+	outputDetectors = detectors
+
+	logging.Info("Output redaction enabled", "detectors", len(detectors))
+	return nil
+}
+
+// flowPipeline devuelve el pipeline de redacción del flow
+// Los secretos van primero: su reemplazo dice qué variable se ocultó
+func flowPipeline(flowID int64, db *database.Queries) *redact.Pipeline {
+	detectors := make([]redact.Detector, 0, len(outputDetectors)+1)
+	if r := flowRedactor(flowID, db); r != nil {
+		detectors = append(detectors, r)
+	}
+	return redact.New(append(detectors, outputDetectors...)...)
+}
+
+// redactionTally acumula las redacciones de la tarea en curso de un flow
+type redactionTally struct {
+	mu     sync.Mutex
+	counts redact.Counts
+}
+
+// taskRedactions guarda el conteo de la tarea en curso por flow
+// processTask toma el lock del flow, así que hay una sola a la vez
+var taskRedactions sync.Map
+
+// redactOutput quita los datos sensibles de la salida de una herramienta y
+// los suma al conteo de la tarea en curso del flow
+func redactOutput(flowID int64, text string, db *database.Queries) string {
+	redacted, counts := flowPipeline(flowID, db).Redact(text)
+	if counts.Total() == 0 {
+		return redacted
+	}
+
+	if t, ok := taskRedactions.Load(flowID); ok {
+		tally := t.(*redactionTally)
+		tally.mu.Lock()
+		tally.counts.Add(counts)
+		tally.mu.Unlock()
+	}
+	return redacted
+}
+
+// startRedactionTally empieza a contar las redacciones de una tarea
+func startRedactionTally(flowID int64) {
+	taskRedactions.Store(flowID, &redactionTally{counts: redact.Counts{}})
+}
+
+// finishRedactionTally guarda en la tarea las redacciones que se contaron
+func finishRedactionTally(db *database.Queries, flowID int64, taskID int64) {
+	t, ok := taskRedactions.LoadAndDelete(flowID)
+	if !ok {
+		return
+	}
+	tally := t.(*redactionTally)
+	tally.mu.Lock()
+	counts := tally.counts
+	tally.mu.Unlock()
+
+	if counts.Total() == 0 {
+		return
+	}
+
+	data, err := json.Marshal(counts)
+	if err != nil {
+		logging.Error("Failed to encode task redactions", "task_id", taskID, "error", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = db.UpdateTaskRedactions(ctx, database.UpdateTaskRedactionsParams{
+		ID:         taskID,
+		Redactions: string(data),
+	})
+	if err != nil {
+		logging.Error("Failed to save task redactions", "task_id", taskID, "error", err.Error())
+		return
+	}
+	logging.Info("Redacted task output", "task_id", taskID, "flow_id", flowID, "redactions", counts.Total())
+}
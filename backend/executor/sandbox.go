@@ -0,0 +1,268 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-units"
+)
+
+// SandboxNetworkMode define el acceso de red de un container de flow
+type SandboxNetworkMode string
+
+const (
+	// NetworkNone deshabilita toda la red del container
+	NetworkNone SandboxNetworkMode = "none"
+	// NetworkEgress solo permite salir a través del proxy con allow-list
+	NetworkEgress SandboxNetworkMode = "egress"
+	// NetworkFull deja el container en la red bridge por defecto
+	NetworkFull SandboxNetworkMode = "full"
+)
+
+// networkModeReach ordena los modos de red de menos a más acceso
+var networkModeReach = map[SandboxNetworkMode]int{
+	NetworkNone:   0,
+	NetworkEgress: 1,
+	NetworkFull:   2,
+}
+
+// SandboxWorkDir es el directorio de trabajo que permanece escribible
+// incluso con el root filesystem en modo read-only
+const SandboxWorkDir = "/app"
+
+// SandboxLimits contiene los límites de recursos y la política de red de un sandbox
+// Los valores cero significan "sin límite" (o "usar el default" al hacer Merge)
+type SandboxLimits struct {
+	MemoryMB       int64              `json:"memoryMb,omitempty"`
+	CPUs           float64            `json:"cpus,omitempty"`
+	PidsLimit      int64              `json:"pidsLimit,omitempty"`
+	DiskQuota      string             `json:"diskQuota,omitempty"`
+	ReadOnlyRootfs *bool              `json:"readOnlyRootfs,omitempty"`
+	NetworkMode    SandboxNetworkMode `json:"networkMode,omitempty"`
+}
+
+// DefaultSandboxLimits construye los límites por defecto a partir de la configuración
+func DefaultSandboxLimits() SandboxLimits {
+	readOnly := config.Config.SandboxReadOnlyRootfs
+	return SandboxLimits{
+		MemoryMB:       config.Config.SandboxMemoryMB,
+		CPUs:           config.Config.SandboxCPUs,
+		PidsLimit:      config.Config.SandboxPidsLimit,
+		DiskQuota:      config.Config.SandboxDiskQuota,
+		ReadOnlyRootfs: &readOnly,
+		NetworkMode:    SandboxNetworkMode(config.Config.SandboxNetworkMode),
+	}
+}
+
+// ParseSandboxLimits deserializa los overrides guardados en la columna flows.sandbox
+func ParseSandboxLimits(raw string) (SandboxLimits, error) {
+	var limits SandboxLimits
+	if strings.TrimSpace(raw) == "" {
+		return limits, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &limits); err != nil {
+		return limits, fmt.Errorf("invalid sandbox config: %w", err)
+	}
+	return limits, nil
+}
+
+// Merge devuelve una copia de l con los campos definidos en override aplicados encima
+// Los recursos del override se recortan a los máximos configurados en el servidor
+// y un modo de red no permitido se ignora
+func (l SandboxLimits) Merge(override SandboxLimits) SandboxLimits {
+	merged := l
+	if override.MemoryMB != 0 {
+		merged.MemoryMB = clampLimit(override.MemoryMB, config.Config.SandboxMaxMemoryMB)
+	}
+	if override.CPUs != 0 {
+		merged.CPUs = clampLimit(override.CPUs, config.Config.SandboxMaxCPUs)
+	}
+	if override.PidsLimit != 0 {
+		merged.PidsLimit = clampLimit(override.PidsLimit, config.Config.SandboxMaxPidsLimit)
+	}
+	if override.DiskQuota != "" {
+		merged.DiskQuota = clampDiskQuota(override.DiskQuota, config.Config.SandboxMaxDiskQuota)
+	}
+	if override.ReadOnlyRootfs != nil {
+		readOnly := *override.ReadOnlyRootfs
+		merged.ReadOnlyRootfs = &readOnly
+	}
+	if override.NetworkMode != "" && networkModeAllowed(override.NetworkMode) {
+		merged.NetworkMode = override.NetworkMode
+	}
+	return merged
+}
+
+// clampLimit recorta value a max; un máximo de 0 no pone tope
+func clampLimit[T int64 | float64](value T, max T) T {
+	if max > 0 && value > max {
+		return max
+	}
+	return value
+}
+
+// clampDiskQuota recorta una cuota de disco (p. ej. "10G") a max; un máximo
+// vacío no pone tope y una cuota que no se puede leer se reemplaza por max
+func clampDiskQuota(value string, max string) string {
+	if max == "" {
+		return value
+	}
+	maxBytes, err := units.RAMInBytes(max)
+	if err != nil {
+		logging.Warn("Invalid SANDBOX_MAX_DISK_QUOTA, ignoring it", "value", max, "error", err.Error())
+		return value
+	}
+	bytes, err := units.RAMInBytes(value)
+	if err != nil || bytes > maxBytes {
+		return max
+	}
+	return value
+}
+
+// networkModeAllowed indica si un flow puede pedir el modo de red: el modo por
+// defecto siempre, los de SANDBOX_ALLOWED_NETWORK_MODES si está definido y, si
+// no, los que no dan más acceso que el modo por defecto
+func networkModeAllowed(mode SandboxNetworkMode) bool {
+	defaultMode := SandboxNetworkMode(config.Config.SandboxNetworkMode)
+	if mode == defaultMode {
+		return true
+	}
+
+	if allowed := strings.TrimSpace(config.Config.SandboxAllowedNetworkModes); allowed != "" {
+		for _, name := range strings.Split(allowed, ",") {
+			if SandboxNetworkMode(strings.TrimSpace(name)) == mode {
+				return true
+			}
+		}
+		return false
+	}
+
+	reach, known := networkModeReach[mode]
+	defaultReach, defaultKnown := networkModeReach[defaultMode]
+	return known && defaultKnown && reach <= defaultReach
+}
+
+// Validate verifica que los límites sean coherentes
+func (l SandboxLimits) Validate() error {
+	if l.MemoryMB < 0 {
+		return fmt.Errorf("memory limit cannot be negative")
+	}
+	if l.CPUs < 0 {
+		return fmt.Errorf("cpu limit cannot be negative")
+	}
+	if l.PidsLimit < 0 {
+		return fmt.Errorf("pids limit cannot be negative")
+	}
+	if l.DiskQuota != "" {
+		if _, err := units.RAMInBytes(l.DiskQuota); err != nil {
+			return fmt.Errorf("invalid disk quota %q: %w", l.DiskQuota, err)
+		}
+	}
+
+	switch l.NetworkMode {
+	case "", NetworkNone, NetworkEgress, NetworkFull:
+	default:
+		return fmt.Errorf("unknown network mode: %s. Available: none, egress, full", l.NetworkMode)
+	}
+	if l.NetworkMode != "" && !networkModeAllowed(l.NetworkMode) {
+		return fmt.Errorf("network mode %s is not allowed on this server", l.NetworkMode)
+	}
+
+	return nil
+}
+
+// applySandboxLimits traduce los límites a la configuración del container
+func applySandboxLimits(cfg *container.Config, hostCfg *container.HostConfig, limits SandboxLimits) {
+	if limits.MemoryMB > 0 {
+		hostCfg.Memory = limits.MemoryMB * 1024 * 1024
+		// Sin swap adicional: el límite de memoria es el límite total
+		hostCfg.MemorySwap = hostCfg.Memory
+	}
+
+	if limits.CPUs > 0 {
+		hostCfg.NanoCPUs = int64(limits.CPUs * 1e9)
+	}
+
+	if limits.PidsLimit > 0 {
+		pidsLimit := limits.PidsLimit
+		hostCfg.PidsLimit = &pidsLimit
+	}
+
+	if limits.DiskQuota != "" {
+		if hostCfg.StorageOpt == nil {
+			hostCfg.StorageOpt = map[string]string{}
+		}
+		hostCfg.StorageOpt["size"] = limits.DiskQuota
+	}
+
+	if limits.ReadOnlyRootfs != nil && *limits.ReadOnlyRootfs {
+		hostCfg.ReadonlyRootfs = true
+		// /app vive en un volumen anónimo para que siga siendo escribible
+		hostCfg.Mounts = append(hostCfg.Mounts, mount.Mount{
+			Type:   mount.TypeVolume,
+			Target: SandboxWorkDir,
+		})
+		if hostCfg.Tmpfs == nil {
+			hostCfg.Tmpfs = map[string]string{}
+		}
+		hostCfg.Tmpfs["/tmp"] = "rw,exec,nosuid"
+		if cfg.WorkingDir == "" {
+			cfg.WorkingDir = SandboxWorkDir
+		}
+	}
+
+	switch limits.NetworkMode {
+	case NetworkNone:
+		hostCfg.NetworkMode = container.NetworkMode("none")
+	case NetworkEgress:
+		hostCfg.NetworkMode = container.NetworkMode(config.Config.SandboxEgressNetwork)
+		proxyURL := config.Config.SandboxEgressProxyURL
+		cfg.Env = append(cfg.Env,
+			"HTTP_PROXY="+proxyURL,
+			"HTTPS_PROXY="+proxyURL,
+			"http_proxy="+proxyURL,
+			"https_proxy="+proxyURL,
+			"NO_PROXY=localhost,127.0.0.1",
+			"no_proxy=localhost,127.0.0.1",
+		)
+	}
+}
+
+// ensureEgressNetwork crea la red interna usada por el modo egress si no existe
+func ensureEgressNetwork(ctx context.Context) error {
+	return ensureInternalNetwork(ctx, config.Config.SandboxEgressNetwork)
+}
+
+// ensureInternalNetwork crea una red bridge interna (sin salida a internet) si no existe
+func ensureInternalNetwork(ctx context.Context, name string) error {
+	return ensureNetwork(ctx, name, true)
+}
+
+// ensureNetwork crea la red bridge si no existe; internal la deja sin salida
+func ensureNetwork(ctx context.Context, name string, internal bool) error {
+	_, err := dockerClient.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errdefs.IsNotFound(err) {
+		return fmt.Errorf("error inspecting network %s: %w", name, err)
+	}
+
+	logging.Info("Creating network", "network", name, "internal", internal)
+	if _, err := dockerClient.NetworkCreate(ctx, name, network.CreateOptions{
+		Driver:   "bridge",
+		Internal: internal,
+	}); err != nil {
+		return fmt.Errorf("error creating network %s: %w", name, err)
+	}
+
+	return nil
+}
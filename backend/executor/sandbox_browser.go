@@ -0,0 +1,221 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/security"
+	"github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/network"
+)
+
+// SandboxURLScheme es el esquema con el que el agente abre servicios de su propio sandbox
+// sandbox://3000/path se resuelve a http://<ip del container>:3000/path
+const SandboxURLScheme = "sandbox"
+
+// sandboxConnectTimeout es el tiempo máximo para conectar browser y sandbox a la red
+const sandboxConnectTimeout = 30 * time.Second
+
+// sandboxHosts guarda la IP del sandbox de cada flow en la red del browser
+// Es la única dirección privada que el browser de ese flow puede visitar
+var sandboxHosts sync.Map
+
+// sandboxNetworkName es la red interna que une el browser con el sandbox de un
+// flow. Cada flow tiene la suya para que los sandboxes no se alcancen entre sí
+func sandboxNetworkName(flowID int64) string {
+	return fmt.Sprintf("%s-%d", config.Config.SandboxBrowserNetwork, flowID)
+}
+
+// parseSandboxURL interpreta una URL sandbox://<puerto>/<path>
+// ok es false si la URL no usa el esquema sandbox
+func parseSandboxURL(raw string) (port int, rest string, ok bool, err error) {
+	if !strings.HasPrefix(strings.ToLower(raw), SandboxURLScheme+"://") {
+		return 0, "", false, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return 0, "", true, fmt.Errorf("invalid sandbox URL: %w", err)
+	}
+
+	if u.User != nil || u.Port() != "" {
+		return 0, "", true, fmt.Errorf("invalid sandbox URL %q, expected sandbox://<port>/<path>", raw)
+	}
+
+	port, err = strconv.Atoi(u.Host)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, "", true, fmt.Errorf("invalid sandbox port in %q", raw)
+	}
+
+	rest = u.EscapedPath()
+	if rest == "" {
+		rest = "/"
+	}
+	if u.RawQuery != "" {
+		rest += "?" + u.RawQuery
+	}
+	if u.Fragment != "" {
+		rest += "#" + u.EscapedFragment()
+	}
+
+	return port, rest, true, nil
+}
+
+// ResolveSandboxURL traduce una URL sandbox:// a la dirección del container del flow
+// Las demás URLs se devuelven sin cambios
+func ResolveSandboxURL(flowID int64, raw string, db *database.Queries) (string, error) {
+	port, rest, ok, err := parseSandboxURL(raw)
+	if !ok || err != nil {
+		return raw, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sandboxConnectTimeout)
+	defer cancel()
+
+	host, err := connectSandboxBrowser(ctx, flowID, db)
+	if err != nil {
+		return "", err
+	}
+
+	resolved := fmt.Sprintf("http://%s%s", net.JoinHostPort(host, strconv.Itoa(port)), rest)
+	logging.Debug("Sandbox URL resolved", "flow_id", flowID, "url", raw, "resolved", resolved)
+	return resolved, nil
+}
+
+// connectSandboxBrowser conecta el browser y el container del flow a la red
+// del flow y devuelve la IP del container en esa red
+func connectSandboxBrowser(ctx context.Context, flowID int64, db *database.Queries) (string, error) {
+	if host, ok := sandboxHosts.Load(flowID); ok {
+		return host.(string), nil
+	}
+
+	flow, err := db.ReadFlow(ctx, flowID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get flow: %w", err)
+	}
+
+	limits, err := flowSandboxLimits(flow.Sandbox.String)
+	if err != nil {
+		return "", err
+	}
+	if limits.NetworkMode == NetworkNone {
+		return "", fmt.Errorf("sandbox URLs are not available for flows with network mode %q", NetworkNone)
+	}
+
+	networkName := sandboxNetworkName(flowID)
+	if err := ensureInternalNetwork(ctx, networkName); err != nil {
+		return "", err
+	}
+
+	// Si Chrome no corre en el container del browser (CHROME_DEBUG_URL) se intenta igual
+	if _, err := connectToNetwork(ctx, BrowserName(), networkName); err != nil {
+		logging.Warn("Failed to connect browser to sandbox network", "error", err.Error())
+	}
+
+	host, err := connectToNetwork(ctx, TerminalName(flowID), networkName)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect sandbox to browser network: %w", err)
+	}
+
+	sandboxHosts.Store(flowID, host)
+	return host, nil
+}
+
+// connectToNetwork conecta un container a la red si no lo está y devuelve su IP en ella
+func connectToNetwork(ctx context.Context, containerName string, networkName string) (string, error) {
+	info, err := dockerClient.ContainerInspect(ctx, containerName)
+	if err != nil {
+		return "", fmt.Errorf("error inspecting container: %w", err)
+	}
+
+	if info.NetworkSettings == nil || info.NetworkSettings.Networks[networkName] == nil {
+		if err := dockerClient.NetworkConnect(ctx, networkName, containerName, nil); err != nil {
+			return "", fmt.Errorf("error connecting %s to %s: %w", containerName, networkName, err)
+		}
+		if info, err = dockerClient.ContainerInspect(ctx, containerName); err != nil {
+			return "", fmt.Errorf("error inspecting container: %w", err)
+		}
+	}
+
+	endpoint := info.NetworkSettings.Networks[networkName]
+	if endpoint == nil || endpoint.IPAddress == "" {
+		return "", fmt.Errorf("container %s has no address in network %s", containerName, networkName)
+	}
+	return endpoint.IPAddress, nil
+}
+
+// releaseSandboxHost olvida la IP del sandbox del flow (p.ej. al terminar o restaurar el flow)
+func releaseSandboxHost(flowID int64) {
+	sandboxHosts.Delete(flowID)
+}
+
+// removeSandboxNetwork desconecta el browser y el sandbox de la red del flow y
+// la borra. Se llama al terminar el flow; si nunca usó sandbox:// no hay red
+func removeSandboxNetwork(flowID int64) {
+	releaseSandboxHost(flowID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), sandboxConnectTimeout)
+	defer cancel()
+
+	networkName := sandboxNetworkName(flowID)
+	info, err := dockerClient.NetworkInspect(ctx, networkName, network.InspectOptions{})
+	if err != nil {
+		if !errdefs.IsNotFound(err) {
+			logging.Warn("Failed to inspect sandbox network", "flow_id", flowID, "error", err.Error())
+		}
+		return
+	}
+
+	for id := range info.Containers {
+		if err := dockerClient.NetworkDisconnect(ctx, networkName, id, true); err != nil {
+			logging.Warn("Failed to disconnect container from sandbox network",
+				"flow_id", flowID,
+				"container_id", id,
+				"error", err.Error(),
+			)
+		}
+	}
+	if err := dockerClient.NetworkRemove(ctx, networkName); err != nil {
+		logging.Warn("Failed to remove sandbox network", "flow_id", flowID, "error", err.Error())
+		return
+	}
+	logging.Debug("Sandbox network removed", "flow_id", flowID, "network", networkName)
+}
+
+// validateFlowURL aplica la política de URLs del browser para un flow
+// El host se resuelve y todas sus direcciones tienen que ser públicas; la IP
+// del sandbox del propio flow es la única excepción a la protección SSRF
+func validateFlowURL(flowID int64, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	if host, ok := sandboxHosts.Load(flowID); ok && (u.Scheme == "http" || u.Scheme == "https") && u.Hostname() == host.(string) {
+		return nil
+	}
+
+	if err := validateBrowserSecurity(raw); err != nil {
+		return err
+	}
+
+	// Los nombres sin dominio (p.ej. otros containers) solo se alcanzan vía sandbox://
+	if !strings.Contains(u.Hostname(), ".") && net.ParseIP(u.Hostname()) == nil {
+		return fmt.Errorf("URL security validation failed: internal host %q is not allowed", u.Hostname())
+	}
+
+	if err := security.ValidateHost(u.Hostname()); err != nil {
+		return fmt.Errorf("URL security validation failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package executor
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/security"
+)
+
+// stubHostLookup resuelve los nombres sin DNS: los *.nip.io a la IP que
+// llevan en el nombre y el resto a una dirección pública
+func stubHostLookup(t *testing.T) {
+	t.Helper()
+	lookup := security.LookupHost
+	t.Cleanup(func() { security.LookupHost = lookup })
+	security.LookupHost = func(_ context.Context, host string) ([]netip.Addr, error) {
+		if ip, ok := strings.CutSuffix(host, ".nip.io"); ok {
+			addr, err := netip.ParseAddr(ip)
+			return []netip.Addr{addr}, err
+		}
+		return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+	}
+}
+
+func TestParseSandboxURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantPort int
+		wantRest string
+		wantOK   bool
+		wantErr  bool
+	}{
+		{name: "port only", raw: "sandbox://3000", wantPort: 3000, wantRest: "/", wantOK: true},
+		{name: "path and query", raw: "sandbox://8080/api/items?page=2", wantPort: 8080, wantRest: "/api/items?page=2", wantOK: true},
+		{name: "fragment", raw: "sandbox://5173/#/settings", wantPort: 5173, wantRest: "/#/settings", wantOK: true},
+		{name: "uppercase scheme", raw: "SANDBOX://3000/", wantPort: 3000, wantRest: "/", wantOK: true},
+		{name: "http url", raw: "https://example.com", wantOK: false},
+		{name: "port out of range", raw: "sandbox://70000/", wantOK: true, wantErr: true},
+		{name: "port zero", raw: "sandbox://0/", wantOK: true, wantErr: true},
+		{name: "host name", raw: "sandbox://app/", wantOK: true, wantErr: true},
+		{name: "host and port", raw: "sandbox://app:3000/", wantOK: true, wantErr: true},
+		{name: "credentials", raw: "sandbox://user@3000/", wantOK: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port, rest, ok, err := parseSandboxURL(tt.raw)
+			if ok != tt.wantOK {
+				t.Fatalf("parseSandboxURL() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSandboxURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if port != tt.wantPort || rest != tt.wantRest {
+				t.Errorf("parseSandboxURL() = %d, %q, want %d, %q", port, rest, tt.wantPort, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestValidateFlowURL(t *testing.T) {
+	stubHostLookup(t)
+	sandboxHosts.Store(int64(1), "172.20.0.3")
+	defer releaseSandboxHost(1)
+
+	tests := []struct {
+		name    string
+		flowID  int64
+		raw     string
+		wantErr bool
+	}{
+		{name: "public url", flowID: 1, raw: "https://example.com/docs"},
+		{name: "own sandbox", flowID: 1, raw: "http://172.20.0.3:3000/"},
+		{name: "own sandbox https", flowID: 1, raw: "https://172.20.0.3:8443/"},
+		{name: "other flow sandbox", flowID: 2, raw: "http://172.20.0.3:3000/", wantErr: true},
+		{name: "other private ip", flowID: 1, raw: "http://172.20.0.4:3000/", wantErr: true},
+		{name: "own sandbox other scheme", flowID: 1, raw: "ftp://172.20.0.3/", wantErr: true},
+		{name: "container name", flowID: 1, raw: "http://arandu-terminal-2:3000/", wantErr: true},
+		{name: "localhost", flowID: 1, raw: "http://localhost:8080/", wantErr: true},
+		{name: "metadata", flowID: 1, raw: "http://169.254.169.254/latest", wantErr: true},
+		{name: "private ip over https", flowID: 1, raw: "https://10.0.0.5/", wantErr: true},
+		{name: "localhost over https", flowID: 1, raw: "https://localhost/", wantErr: true},
+		{name: "wildcard dns to private ip", flowID: 1, raw: "https://10.0.0.5.nip.io/", wantErr: true},
+		{name: "wildcard dns to other sandbox", flowID: 1, raw: "http://172.20.0.4.nip.io:3000/", wantErr: true},
+		{name: "ipv6 loopback", flowID: 1, raw: "http://[::1]:8080/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFlowURL(tt.flowID, tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFlowURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSandboxNetworkName(t *testing.T) {
+	if a, b := sandboxNetworkName(1), sandboxNetworkName(2); a == b {
+		t.Errorf("sandboxNetworkName() = %q for two flows, want one network per flow", a)
+	}
+	if got := sandboxNetworkName(7); got != config.Config.SandboxBrowserNetwork+"-7" {
+		t.Errorf("sandboxNetworkName(7) = %q", got)
+	}
+}
+
+func TestValidateFlowRequest(t *testing.T) {
+	stubHostLookup(t)
+	sandboxHosts.Store(int64(1), "172.20.0.3")
+	defer releaseSandboxHost(1)
+
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{name: "public script", raw: "https://cdn.example.com/app.js"},
+		{name: "own sandbox api", raw: "http://172.20.0.3:3000/api"},
+		{name: "inline image", raw: "data:image/png;base64,iVBORw0KGgo="},
+		{name: "blob", raw: "blob:https://example.com/0f3c"},
+		{name: "metadata fetch", raw: "http://169.254.169.254/latest/meta-data/", wantErr: true},
+		{name: "other sandbox", raw: "http://172.20.0.4:3000/", wantErr: true},
+		{name: "server api", raw: "http://arandu:8080/graphql", wantErr: true},
+		{name: "file", raw: "file:///etc/passwd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFlowRequest(1, tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFlowRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChromeCommand(t *testing.T) {
+	// El puerto de debugging no puede escuchar en las redes de los flows
+	if strings.Contains(chromeCommand, "0.0.0.0") {
+		t.Errorf("chromeCommand binds the debugging port to every interface: %s", chromeCommand)
+	}
+	if !strings.Contains(chromeCommand, "--remote-debugging-address=") {
+		t.Errorf("chromeCommand does not set the debugging address: %s", chromeCommand)
+	}
+}
@@ -0,0 +1,265 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestSandboxLimitsMerge(t *testing.T) {
+	defer func(mode string) { config.Config.SandboxNetworkMode = mode }(config.Config.SandboxNetworkMode)
+	config.Config.SandboxNetworkMode = string(NetworkFull)
+
+	readOnly := false
+	base := SandboxLimits{
+		MemoryMB:       2048,
+		CPUs:           2,
+		PidsLimit:      512,
+		ReadOnlyRootfs: &readOnly,
+		NetworkMode:    NetworkFull,
+	}
+
+	enabled := true
+	merged := base.Merge(SandboxLimits{
+		MemoryMB:       512,
+		ReadOnlyRootfs: &enabled,
+		NetworkMode:    NetworkNone,
+	})
+
+	if merged.MemoryMB != 512 {
+		t.Errorf("MemoryMB = %d, want 512", merged.MemoryMB)
+	}
+	if merged.CPUs != 2 {
+		t.Errorf("CPUs = %v, want 2 (kept from base)", merged.CPUs)
+	}
+	if merged.PidsLimit != 512 {
+		t.Errorf("PidsLimit = %d, want 512 (kept from base)", merged.PidsLimit)
+	}
+	if merged.ReadOnlyRootfs == nil || !*merged.ReadOnlyRootfs {
+		t.Error("ReadOnlyRootfs should be overridden to true")
+	}
+	if merged.NetworkMode != NetworkNone {
+		t.Errorf("NetworkMode = %q, want %q", merged.NetworkMode, NetworkNone)
+	}
+	if *base.ReadOnlyRootfs {
+		t.Error("Merge should not modify the base limits")
+	}
+}
+
+func TestSandboxLimitsMerge_ClampsToServerMaximums(t *testing.T) {
+	defer func(memory int64, cpus float64, pids int64) {
+		config.Config.SandboxMaxMemoryMB = memory
+		config.Config.SandboxMaxCPUs = cpus
+		config.Config.SandboxMaxPidsLimit = pids
+	}(config.Config.SandboxMaxMemoryMB, config.Config.SandboxMaxCPUs, config.Config.SandboxMaxPidsLimit)
+
+	config.Config.SandboxMaxMemoryMB = 4096
+	config.Config.SandboxMaxCPUs = 4
+	config.Config.SandboxMaxPidsLimit = 0
+
+	base := SandboxLimits{MemoryMB: 2048, CPUs: 2, PidsLimit: 512}
+
+	merged := base.Merge(SandboxLimits{MemoryMB: 1 << 20, CPUs: 64, PidsLimit: 100000})
+	if merged.MemoryMB != 4096 {
+		t.Errorf("MemoryMB = %d, want 4096 (server maximum)", merged.MemoryMB)
+	}
+	if merged.CPUs != 4 {
+		t.Errorf("CPUs = %v, want 4 (server maximum)", merged.CPUs)
+	}
+	if merged.PidsLimit != 100000 {
+		t.Errorf("PidsLimit = %d, want 100000 (no maximum configured)", merged.PidsLimit)
+	}
+
+	merged = base.Merge(SandboxLimits{MemoryMB: 1024, CPUs: 0.5})
+	if merged.MemoryMB != 1024 || merged.CPUs != 0.5 {
+		t.Errorf("limits below the maximum changed: %+v", merged)
+	}
+}
+
+func TestSandboxLimitsMerge_ClampsDiskQuota(t *testing.T) {
+	defer func(max string) { config.Config.SandboxMaxDiskQuota = max }(config.Config.SandboxMaxDiskQuota)
+
+	base := SandboxLimits{DiskQuota: "5G"}
+
+	tests := []struct {
+		name     string
+		max      string
+		override string
+		want     string
+	}{
+		{name: "above maximum", max: "20G", override: "1T", want: "20G"},
+		{name: "below maximum", max: "20G", override: "10G", want: "10G"},
+		{name: "unparsable quota", max: "20G", override: "lots", want: "20G"},
+		{name: "no maximum", max: "", override: "1T", want: "1T"},
+		{name: "no override", max: "20G", override: "", want: "5G"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Config.SandboxMaxDiskQuota = tt.max
+			if got := base.Merge(SandboxLimits{DiskQuota: tt.override}).DiskQuota; got != tt.want {
+				t.Errorf("DiskQuota = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSandboxLimitsMerge_NetworkModeCeiling(t *testing.T) {
+	defer func(mode, allowed string) {
+		config.Config.SandboxNetworkMode = mode
+		config.Config.SandboxAllowedNetworkModes = allowed
+	}(config.Config.SandboxNetworkMode, config.Config.SandboxAllowedNetworkModes)
+
+	tests := []struct {
+		name        string
+		defaultMode SandboxNetworkMode
+		allowed     string
+		override    SandboxNetworkMode
+		want        SandboxNetworkMode
+	}{
+		{name: "narrower than default", defaultMode: NetworkEgress, override: NetworkNone, want: NetworkNone},
+		{name: "wider than default", defaultMode: NetworkEgress, override: NetworkFull, want: NetworkEgress},
+		{name: "wider than none", defaultMode: NetworkNone, override: NetworkEgress, want: NetworkNone},
+		{name: "explicitly allowed", defaultMode: NetworkEgress, allowed: "none, full", override: NetworkFull, want: NetworkFull},
+		{name: "not in allowed list", defaultMode: NetworkFull, allowed: "egress", override: NetworkNone, want: NetworkFull},
+		{name: "default always allowed", defaultMode: NetworkEgress, allowed: "none", override: NetworkEgress, want: NetworkEgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Config.SandboxNetworkMode = string(tt.defaultMode)
+			config.Config.SandboxAllowedNetworkModes = tt.allowed
+
+			base := SandboxLimits{NetworkMode: tt.defaultMode}
+			if got := base.Merge(SandboxLimits{NetworkMode: tt.override}).NetworkMode; got != tt.want {
+				t.Errorf("NetworkMode = %q, want %q", got, tt.want)
+			}
+
+			err := SandboxLimits{NetworkMode: tt.override}.Validate()
+			if allowed := tt.want == tt.override; (err == nil) != allowed {
+				t.Errorf("Validate() error = %v, want allowed %v", err, allowed)
+			}
+		})
+	}
+}
+
+func TestSandboxLimitsValidate(t *testing.T) {
+	defer func(mode string) { config.Config.SandboxNetworkMode = mode }(config.Config.SandboxNetworkMode)
+	config.Config.SandboxNetworkMode = string(NetworkFull)
+
+	tests := []struct {
+		name    string
+		limits  SandboxLimits
+		wantErr bool
+	}{
+		{name: "empty limits", limits: SandboxLimits{}, wantErr: false},
+		{name: "valid limits", limits: SandboxLimits{MemoryMB: 1024, CPUs: 0.5, PidsLimit: 100, NetworkMode: NetworkEgress}, wantErr: false},
+		{name: "negative memory", limits: SandboxLimits{MemoryMB: -1}, wantErr: true},
+		{name: "negative cpus", limits: SandboxLimits{CPUs: -0.5}, wantErr: true},
+		{name: "negative pids", limits: SandboxLimits{PidsLimit: -1}, wantErr: true},
+		{name: "unknown network mode", limits: SandboxLimits{NetworkMode: "host"}, wantErr: true},
+		{name: "valid disk quota", limits: SandboxLimits{DiskQuota: "10G"}, wantErr: false},
+		{name: "invalid disk quota", limits: SandboxLimits{DiskQuota: "ten gigs"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limits.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSandboxLimits(t *testing.T) {
+	limits, err := ParseSandboxLimits("")
+	if err != nil {
+		t.Fatalf("ParseSandboxLimits(\"\") error = %v", err)
+	}
+	if limits.MemoryMB != 0 || limits.ReadOnlyRootfs != nil {
+		t.Errorf("empty config should produce zero limits, got %+v", limits)
+	}
+
+	limits, err = ParseSandboxLimits(`{"memoryMb":256,"networkMode":"none","readOnlyRootfs":true}`)
+	if err != nil {
+		t.Fatalf("ParseSandboxLimits() error = %v", err)
+	}
+	if limits.MemoryMB != 256 || limits.NetworkMode != NetworkNone || !*limits.ReadOnlyRootfs {
+		t.Errorf("unexpected limits: %+v", limits)
+	}
+
+	if _, err := ParseSandboxLimits("{invalid"); err == nil {
+		t.Error("ParseSandboxLimits() should fail on invalid JSON")
+	}
+}
+
+func TestApplySandboxLimits(t *testing.T) {
+	readOnly := true
+	cfg := &container.Config{}
+	hostCfg := &container.HostConfig{}
+
+	applySandboxLimits(cfg, hostCfg, SandboxLimits{
+		MemoryMB:       256,
+		CPUs:           1.5,
+		PidsLimit:      64,
+		DiskQuota:      "5G",
+		ReadOnlyRootfs: &readOnly,
+		NetworkMode:    NetworkNone,
+	})
+
+	if hostCfg.Memory != 256*1024*1024 || hostCfg.MemorySwap != hostCfg.Memory {
+		t.Errorf("Memory = %d, MemorySwap = %d", hostCfg.Memory, hostCfg.MemorySwap)
+	}
+	if hostCfg.NanoCPUs != 1500000000 {
+		t.Errorf("NanoCPUs = %d, want 1500000000", hostCfg.NanoCPUs)
+	}
+	if hostCfg.PidsLimit == nil || *hostCfg.PidsLimit != 64 {
+		t.Errorf("PidsLimit = %v, want 64", hostCfg.PidsLimit)
+	}
+	if hostCfg.StorageOpt["size"] != "5G" {
+		t.Errorf("StorageOpt[size] = %q, want 5G", hostCfg.StorageOpt["size"])
+	}
+	if !hostCfg.ReadonlyRootfs {
+		t.Error("ReadonlyRootfs should be enabled")
+	}
+	if len(hostCfg.Mounts) != 1 || hostCfg.Mounts[0].Target != SandboxWorkDir {
+		t.Errorf("expected a writable mount at %s, got %+v", SandboxWorkDir, hostCfg.Mounts)
+	}
+	if cfg.WorkingDir != SandboxWorkDir {
+		t.Errorf("WorkingDir = %q, want %q", cfg.WorkingDir, SandboxWorkDir)
+	}
+	if hostCfg.NetworkMode != "none" {
+		t.Errorf("NetworkMode = %q, want none", hostCfg.NetworkMode)
+	}
+}
+
+func TestApplySandboxLimits_Egress(t *testing.T) {
+	config.Config.SandboxEgressNetwork = "arandu-egress"
+	config.Config.SandboxEgressProxyURL = "http://arandu:3128"
+
+	cfg := &container.Config{}
+	hostCfg := &container.HostConfig{}
+
+	applySandboxLimits(cfg, hostCfg, SandboxLimits{NetworkMode: NetworkEgress})
+
+	if hostCfg.NetworkMode != "arandu-egress" {
+		t.Errorf("NetworkMode = %q, want arandu-egress", hostCfg.NetworkMode)
+	}
+
+	found := false
+	for _, env := range cfg.Env {
+		if env == "HTTPS_PROXY=http://arandu:3128" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("HTTPS_PROXY not set in env: %v", cfg.Env)
+	}
+
+	// Zero limits must leave the host config untouched
+	if hostCfg.Memory != 0 || hostCfg.PidsLimit != nil || hostCfg.ReadonlyRootfs {
+		t.Errorf("unexpected limits applied: %+v", hostCfg.Resources)
+	}
+}
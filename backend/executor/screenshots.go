@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+)
+
+// ScreenshotsURLPath es la ruta HTTP desde la que se sirven las capturas
+const ScreenshotsURLPath = "/browser"
+
+// screenshotPath devuelve la ruta relativa de una captura: <flow>/<task>/<sha256>.png
+// El nombre por contenido evita que capturas simultáneas se pisen
+func screenshotPath(flowID int64, taskID int64, png []byte) (string, string) {
+	sum := sha256.Sum256(png)
+	hash := hex.EncodeToString(sum[:])
+	return path.Join(fmt.Sprint(flowID), fmt.Sprint(taskID), hash+".png"), hash
+}
+
+// saveScreenshot guarda la captura de una tarea del browser y registra sus metadatos
+func saveScreenshot(db *database.Queries, task database.Task, pageURL string, png []byte) (database.Screenshot, error) {
+	rel, hash := screenshotPath(task.FlowID.Int64, task.ID, png)
+
+	if err := writeScreenshotFile(filepath.Join(config.Config.ScreenshotsDir, filepath.FromSlash(rel)), png); err != nil {
+		return database.Screenshot{}, err
+	}
+
+	screenshot, err := db.CreateScreenshot(context.Background(), database.CreateScreenshotParams{
+		FlowID: task.FlowID.Int64,
+		TaskID: task.ID,
+		Url:    pageURL,
+		Path:   rel,
+		Sha256: hash,
+		Size:   int64(len(png)),
+	})
+	if err != nil {
+		return database.Screenshot{}, fmt.Errorf("error saving screenshot metadata: %w", err)
+	}
+
+	logging.Debug("Screenshot saved", "flow_id", task.FlowID.Int64, "task_id", task.ID, "path", rel)
+	return screenshot, nil
+}
+
+// writeScreenshotFile escribe la captura si no existe todavía
+// Se escribe a un archivo temporal y se renombra para no servir capturas a medias
+func writeScreenshotFile(dst string, png []byte) error {
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error checking screenshot file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".screenshot-*")
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(png); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing to file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing to file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("error writing to file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("error writing to file: %w", err)
+	}
+	return nil
+}
+
+// ScreenshotURL devuelve la URL desde la que se sirve una captura
+func ScreenshotURL(rel string) string {
+	if rel == "" {
+		return ""
+	}
+	return fmt.Sprintf("http://localhost:%d%s/%s", config.Config.Port, ScreenshotsURLPath, rel)
+}
@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arandu-ai/arandu/config"
+)
+
+func TestScreenshotPath(t *testing.T) {
+	png := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A} // PNG header
+
+	rel, hash := screenshotPath(7, 42, png)
+	if rel != "7/42/"+hash+".png" {
+		t.Errorf("screenshotPath() = %q, want 7/42/<hash>.png", rel)
+	}
+	if len(hash) != 64 {
+		t.Errorf("hash = %q, want a sha256 hex digest", hash)
+	}
+
+	// Mismo contenido, mismo nombre; contenido distinto, nombre distinto
+	if again, _ := screenshotPath(7, 42, png); again != rel {
+		t.Errorf("screenshotPath() is not stable: %q != %q", again, rel)
+	}
+	if other, _ := screenshotPath(7, 42, append(png, 0)); other == rel {
+		t.Error("different screenshots got the same path")
+	}
+}
+
+func TestWriteScreenshotFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "png header", data: []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}},
+		{name: "empty", data: []byte{}},
+		{name: "large", data: bytes.Repeat([]byte{1, 2, 3, 4}, 256*1024)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// El directorio de la tarea no existe todavía
+			dst := filepath.Join(t.TempDir(), "1", "2", "shot.png")
+
+			if err := writeScreenshotFile(dst, tt.data); err != nil {
+				t.Fatalf("writeScreenshotFile() error = %v", err)
+			}
+
+			content, err := os.ReadFile(dst)
+			if err != nil {
+				t.Fatalf("failed to read file: %v", err)
+			}
+			if !bytes.Equal(content, tt.data) {
+				t.Errorf("file content has %d bytes, want %d", len(content), len(tt.data))
+			}
+
+			// No quedan archivos temporales
+			entries, _ := os.ReadDir(filepath.Dir(dst))
+			if len(entries) != 1 {
+				t.Errorf("directory has %d entries, want 1", len(entries))
+			}
+		})
+	}
+}
+
+func TestWriteScreenshotFile_Existing(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "shot.png")
+	if err := os.WriteFile(dst, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeScreenshotFile(dst, []byte("original")); err != nil {
+		t.Fatalf("writeScreenshotFile() error = %v", err)
+	}
+
+	info, err := os.Stat(dst)
+	if err != nil || info.Size() != int64(len("original")) {
+		t.Errorf("existing screenshot was modified: %v, %v", info, err)
+	}
+}
+
+func TestScreenshotURL(t *testing.T) {
+	config.Config.Port = 8080
+
+	if got := ScreenshotURL(""); got != "" {
+		t.Errorf("ScreenshotURL(\"\") = %q, want empty", got)
+	}
+
+	got := ScreenshotURL("3/9/abc.png")
+	if got != "http://localhost:8080/browser/3/9/abc.png" {
+		t.Errorf("ScreenshotURL() = %q", got)
+	}
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/secrets"
+)
+
+// flowRedactors guarda, por flow, los secretos inyectados en su container
+// para quitar sus valores de la salida de los comandos
+var flowRedactors sync.Map
+
+// flowSecretValues descifra los secretos que recibe el container de un flow:
+// los de su dueño y los del equipo con el que está compartido
+// Un secreto del dueño pisa al del equipo con el mismo nombre
+func flowSecretValues(ctx context.Context, flowID int64, db *database.Queries) (map[string]string, error) {
+	if !secrets.Enabled() || db == nil {
+		return nil, nil
+	}
+
+	flow, err := db.ReadFlow(ctx, flowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flow: %w", err)
+	}
+
+	rows, err := db.ReadFlowSecrets(ctx, database.ReadFlowSecretsParams{
+		UserID: flow.OwnerID,
+		TeamID: flow.TeamID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+
+	// Primero vienen los del equipo, así los del dueño los reemplazan
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		value, err := secrets.Decrypt(row.Name, row.Value)
+		if err != nil {
+			return nil, err
+		}
+		values[row.Name] = value
+	}
+	return values, nil
+}
+
+// secretsEnv devuelve los secretos del flow como variables de entorno para un
+// comando de su container, y deja listo el redactor para su salida
+// Se leen en cada comando: un secreto cambiado o borrado aplica al siguiente
+func secretsEnv(ctx context.Context, flowID int64, db *database.Queries) ([]string, error) {
+	values, err := flowSecretValues(ctx, flowID, db)
+	if err != nil {
+		return nil, err
+	}
+	flowRedactors.Store(flowID, secrets.NewRedactor(values))
+
+	env := make([]string, 0, len(values))
+	for name, value := range values {
+		env = append(env, name+"="+value)
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+// flowRedactor devuelve el redactor de los secretos del container del flow
+// Si el servidor se reinició, se arma con los secretos actuales
+func flowRedactor(flowID int64, db *database.Queries) *secrets.Redactor {
+	if r, ok := flowRedactors.Load(flowID); ok {
+		return r.(*secrets.Redactor)
+	}
+
+	values, err := flowSecretValues(context.Background(), flowID, db)
+	if err != nil {
+		logging.Warn("Failed to load flow secrets", "flow_id", flowID, "error", err.Error())
+		return nil
+	}
+	r := secrets.NewRedactor(values)
+	flowRedactors.Store(flowID, r)
+	return r
+}
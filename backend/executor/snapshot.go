@@ -0,0 +1,442 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	gmodel "github.com/arandu-ai/arandu/graph/model"
+	"github.com/arandu-ai/arandu/graph/subscriptions"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/models"
+	"github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+)
+
+// SnapshotRepository es el repositorio local de las imágenes de snapshot
+const SnapshotRepository = "arandu-snapshot"
+
+// SnapshotTimeout es el tiempo máximo para crear o restaurar un snapshot
+const SnapshotTimeout = 5 * time.Minute
+
+// SnapshotReason indica por qué se tomó un snapshot
+type SnapshotReason string
+
+const (
+	SnapshotManual       SnapshotReason = "manual"
+	SnapshotRiskyCommand SnapshotReason = "risky_command"
+)
+
+// ErrSnapshotReadOnly indica que el sandbox del flow no admite snapshots
+// docker commit no incluye volúmenes y con rootfs read-only todo el estado vive en /app
+var ErrSnapshotReadOnly = errors.New("snapshots are not supported for read-only sandboxes")
+
+// riskyCommandPatterns detecta comandos que suelen romper el entorno del sandbox
+var riskyCommandPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(apt|apt-get|aptitude|dpkg|yum|dnf|apk|pacman)\s`),
+	regexp.MustCompile(`\bpip3?\s+(install|uninstall)\b`),
+	regexp.MustCompile(`\b(npm|yarn|pnpm)\s+(install|i|add|remove|rm|uninstall)\b`),
+	regexp.MustCompile(`\brm\s+(-\S*[rRf]\S*\s+)+`),
+	regexp.MustCompile(`\b(chmod|chown)\s+-R\b`),
+	regexp.MustCompile(`\bgit\s+(reset\s+--hard|clean\s+-\S*f)`),
+	regexp.MustCompile(`\b(dd|mkfs(\.\w+)?)\s`),
+	regexp.MustCompile(`>\s*/(etc|usr|bin|lib)/`),
+}
+
+// IsRiskyCommand indica si un comando de terminal debería ir precedido de un snapshot
+func IsRiskyCommand(command string) bool {
+	for _, pattern := range riskyCommandPatterns {
+		if pattern.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotReference genera el nombre de la imagen de un snapshot
+func snapshotReference(flowID int64, taskID int64) string {
+	return fmt.Sprintf("%s:flow-%d-task-%d-%d", SnapshotRepository, flowID, taskID, time.Now().UnixNano())
+}
+
+// flowSandboxLimits devuelve los límites efectivos del sandbox de un flow
+func flowSandboxLimits(raw string) (SandboxLimits, error) {
+	override, err := ParseSandboxLimits(raw)
+	if err != nil {
+		return SandboxLimits{}, err
+	}
+	return DefaultSandboxLimits().Merge(override), nil
+}
+
+// CheckpointFlow toma un snapshot del container del flow asociado a su última tarea
+// Falla con ErrFlowBusy si el flow está ejecutando tareas
+func CheckpointFlow(flowID int64, db *database.Queries) (database.Snapshot, error) {
+	unlock, err := lockIdleFlow(flowID)
+	if err != nil {
+		return database.Snapshot{}, err
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), SnapshotTimeout)
+	defer cancel()
+
+	tasks, err := db.ReadTasksByFlowId(ctx, sql.NullInt64{Int64: flowID, Valid: true})
+	if err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to get tasks by flow id: %w", err)
+	}
+	if len(tasks) == 0 {
+		return database.Snapshot{}, fmt.Errorf("flow %d has no tasks", flowID)
+	}
+
+	return checkpointFlow(ctx, flowID, tasks[len(tasks)-1].ID, SnapshotManual, db)
+}
+
+// checkpointFlow hace commit del container del flow a una imagen y la registra
+// El snapshot refleja el estado del container después de la tarea taskID
+func checkpointFlow(ctx context.Context, flowID int64, taskID int64, reason SnapshotReason, db *database.Queries) (database.Snapshot, error) {
+	flow, err := db.ReadFlow(ctx, flowID)
+	if err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to get flow: %w", err)
+	}
+
+	if flow.ContainerLocalID.String == "" {
+		return database.Snapshot{}, fmt.Errorf("flow %d has no container to snapshot", flowID)
+	}
+
+	limits, err := flowSandboxLimits(flow.Sandbox.String)
+	if err != nil {
+		return database.Snapshot{}, err
+	}
+	if limits.ReadOnlyRootfs != nil && *limits.ReadOnlyRootfs {
+		return database.Snapshot{}, ErrSnapshotReadOnly
+	}
+
+	start := time.Now()
+	reference := snapshotReference(flowID, taskID)
+	localID := flow.ContainerLocalID.String
+
+	info, err := dockerClient.ContainerInspect(ctx, localID)
+	if err != nil {
+		return database.Snapshot{}, fmt.Errorf("error inspecting container: %w", err)
+	}
+	var imageEnv []string
+	if img, err := dockerClient.ImageInspect(ctx, info.Image); err != nil {
+		return database.Snapshot{}, fmt.Errorf("error inspecting container image: %w", err)
+	} else if img.Config != nil {
+		imageEnv = img.Config.Env
+	}
+
+	if _, err := dockerClient.ContainerCommit(ctx, localID, container.CommitOptions{
+		Reference: reference,
+		Comment:   fmt.Sprintf("Arandu snapshot of flow %d after task %d (%s)", flowID, taskID, reason),
+		Pause:     true,
+		Config:    snapshotConfig(info.Config, imageEnv),
+	}); err != nil {
+		return database.Snapshot{}, fmt.Errorf("error committing container: %w", err)
+	}
+
+	logging.LogDockerOp("commit_container", localID, time.Since(start), nil,
+		"image", reference,
+		"reason", reason,
+	)
+
+	snapshot, err := db.CreateSnapshot(ctx, database.CreateSnapshotParams{
+		FlowID:      flowID,
+		TaskID:      taskID,
+		ContainerID: flow.ContainerID,
+		Image:       reference,
+		Reason:      string(reason),
+	})
+	if err != nil {
+		return database.Snapshot{}, fmt.Errorf("error creating snapshot in database: %w", err)
+	}
+
+	msg := fmt.Sprintf("Snapshot created after task %d (%s)", taskID, reason)
+	if err := createAndBroadcastLog(flowID, msg, LogTypeSystem, db); err != nil {
+		return snapshot, err
+	}
+
+	pruneSnapshots(ctx, flowID, db)
+
+	return snapshot, nil
+}
+
+// snapshotConfig devuelve la configuración del container para la imagen del
+// snapshot, vaciando las variables de entorno que no vienen de su imagen: el
+// proxy de egress y los secretos de containers creados cuando se pasaban al
+// container. Docker completa la configuración con la del container, por eso
+// se vacían en lugar de quitarlas. Al restaurar se vuelven a fijar según el flow
+func snapshotConfig(cfg *container.Config, imageEnv []string) *container.Config {
+	if cfg == nil {
+		return nil
+	}
+	fromImage := make(map[string]bool, len(imageEnv))
+	for _, env := range imageEnv {
+		fromImage[env] = true
+	}
+
+	snapshot := *cfg
+	snapshot.Env = make([]string, 0, len(cfg.Env))
+	for _, env := range cfg.Env {
+		if !fromImage[env] {
+			name, _, _ := strings.Cut(env, "=")
+			env = name + "="
+		}
+		snapshot.Env = append(snapshot.Env, env)
+	}
+	return &snapshot
+}
+
+// checkpointBeforeRiskyCommand toma un snapshot antes de ejecutar un comando
+// que puede romper el entorno. Un fallo no impide ejecutar el comando.
+func checkpointBeforeRiskyCommand(task database.Task, command string, db *database.Queries) {
+	if !config.Config.SnapshotBeforeRiskyCommands || !IsRiskyCommand(command) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SnapshotTimeout)
+	defer cancel()
+
+	flowID := task.FlowID.Int64
+	tasks, err := db.ReadTasksByFlowId(ctx, task.FlowID)
+	if err != nil {
+		logging.Error("Failed to get tasks for snapshot", "flow_id", flowID, "error", err.Error())
+		return
+	}
+
+	// El snapshot se asocia a la tarea anterior: es el estado antes de este comando
+	var previousID int64
+	for _, t := range tasks {
+		if t.ID < task.ID && t.ID > previousID {
+			previousID = t.ID
+		}
+	}
+	if previousID == 0 {
+		return
+	}
+
+	if _, err := checkpointFlow(ctx, flowID, previousID, SnapshotRiskyCommand, db); err != nil {
+		if errors.Is(err, ErrSnapshotReadOnly) {
+			logging.Debug("Skipping snapshot for read-only sandbox", "flow_id", flowID)
+			return
+		}
+		logging.Warn("Failed to snapshot before risky command",
+			"flow_id", flowID,
+			"task_id", task.ID,
+			"error", err.Error(),
+		)
+	}
+}
+
+// RollbackFlow restaura el container del flow desde el último snapshot tomado
+// en o antes de la tarea indicada y borra las tareas posteriores al snapshot
+func RollbackFlow(flowID int64, taskID int64, db *database.Queries) (database.Snapshot, error) {
+	unlock, err := lockIdleFlow(flowID)
+	if err != nil {
+		return database.Snapshot{}, err
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), SnapshotTimeout)
+	defer cancel()
+
+	tasks, err := db.ReadTasksByFlowId(ctx, sql.NullInt64{Int64: flowID, Valid: true})
+	if err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to get tasks by flow id: %w", err)
+	}
+	found := false
+	for _, t := range tasks {
+		if t.ID == taskID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return database.Snapshot{}, fmt.Errorf("task %d does not belong to flow %d", taskID, flowID)
+	}
+
+	snapshot, err := db.ReadLatestSnapshotAtTask(ctx, database.ReadLatestSnapshotAtTaskParams{
+		FlowID: flowID,
+		TaskID: taskID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Snapshot{}, fmt.Errorf("no snapshot found at or before task %d", taskID)
+	}
+	if err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	if _, err := dockerClient.ImageInspect(ctx, snapshot.Image); err != nil {
+		return database.Snapshot{}, fmt.Errorf("snapshot image %s is not available: %w", snapshot.Image, err)
+	}
+
+	flow, err := db.ReadFlow(ctx, flowID)
+	if err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to get flow: %w", err)
+	}
+
+	baseImage := flow.ContainerImage.String
+	if flow.ContainerBaseImage.Valid {
+		baseImage = flow.ContainerBaseImage.String
+	}
+
+	limits, err := flowSandboxLimits(flow.Sandbox.String)
+	if err != nil {
+		return database.Snapshot{}, err
+	}
+
+	// El container actual se reemplaza; si ya no existe (flow terminado) se sigue igual
+	if flow.ContainerLocalID.String != "" {
+		if err := DeleteContainer(flow.ContainerLocalID.String, flow.ContainerID.Int64, db); err != nil {
+			logging.Warn("Failed to delete container before rollback",
+				"flow_id", flowID,
+				"error", err.Error(),
+			)
+		}
+	}
+	// El container nuevo tendrá otra IP en la red del browser
+	releaseSandboxHost(flowID)
+
+	containerID, err := SpawnContainer(ctx,
+		TerminalName(flowID),
+		&container.Config{
+			Image: snapshot.Image,
+			Cmd:   []string{"tail", "-f", "/dev/null"},
+		},
+		&container.HostConfig{},
+		&limits,
+		db,
+	)
+	if err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to spawn container from snapshot: %w", err)
+	}
+
+	if _, err := db.UpdateContainerLineage(ctx, database.UpdateContainerLineageParams{
+		ID:        containerID,
+		ParentID:  snapshot.ContainerID,
+		BaseImage: database.StringToNullString(baseImage),
+	}); err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to update container lineage: %w", err)
+	}
+
+	if _, err := db.UpdateFlowContainer(ctx, database.UpdateFlowContainerParams{
+		ID:          flowID,
+		ContainerID: sql.NullInt64{Int64: containerID, Valid: true},
+	}); err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to update flow container: %w", err)
+	}
+
+	// Los snapshots posteriores dejan de tener sentido junto con sus tareas
+	removeSnapshotsAfter(ctx, flowID, snapshot.TaskID, db)
+
+	if err := db.DeleteTasksAfter(ctx, database.DeleteTasksAfterParams{
+		FlowID: sql.NullInt64{Int64: flowID, Valid: true},
+		ID:     snapshot.TaskID,
+	}); err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to truncate tasks: %w", err)
+	}
+
+	if _, err := db.UpdateFlowStatus(ctx, database.UpdateFlowStatusParams{
+		ID:     flowID,
+		Status: database.StringToNullString(string(models.FlowInProgress)),
+	}); err != nil {
+		return database.Snapshot{}, fmt.Errorf("failed to update flow status: %w", err)
+	}
+
+	// Un flow terminado no tiene cola; se vuelve a crear para aceptar nuevas tareas
+	AddQueue(flowID, db)
+
+	msg := fmt.Sprintf("Rolled back to the snapshot taken after task %d", snapshot.TaskID)
+	if err := createAndBroadcastLog(flowID, msg, LogTypeSystem, db); err != nil {
+		return snapshot, err
+	}
+
+	subscriptions.BroadcastFlowUpdated(flowID, &gmodel.Flow{
+		ID:     uint(flowID),
+		Name:   flow.Name.String,
+		Status: gmodel.FlowStatus(models.FlowInProgress),
+		Terminal: &gmodel.Terminal{
+			ContainerName: baseImage,
+			Connected:     true,
+		},
+	})
+
+	logging.Info("Flow rolled back",
+		"flow_id", flowID,
+		"snapshot_id", snapshot.ID,
+		"task_id", snapshot.TaskID,
+	)
+
+	return snapshot, nil
+}
+
+// removeSnapshot borra la imagen de un snapshot y su registro
+// Si la imagen sigue en uso se conserva el registro para reintentar más tarde
+func removeSnapshot(ctx context.Context, snapshot database.Snapshot, db *database.Queries) error {
+	if _, err := dockerClient.ImageRemove(ctx, snapshot.Image, image.RemoveOptions{PruneChildren: true}); err != nil && !errdefs.IsNotFound(err) {
+		return fmt.Errorf("error removing snapshot image: %w", err)
+	}
+
+	if err := db.DeleteSnapshot(ctx, snapshot.ID); err != nil {
+		return fmt.Errorf("error deleting snapshot: %w", err)
+	}
+	return nil
+}
+
+// removeSnapshotsAfter borra los snapshots del flow posteriores a la tarea indicada
+func removeSnapshotsAfter(ctx context.Context, flowID int64, taskID int64, db *database.Queries) {
+	snapshots, err := db.ReadSnapshotsByFlowId(ctx, flowID)
+	if err != nil {
+		logging.Error("Failed to get snapshots", "flow_id", flowID, "error", err.Error())
+		return
+	}
+
+	for _, s := range snapshots {
+		if s.TaskID <= taskID {
+			continue
+		}
+		if err := removeSnapshot(ctx, s, db); err != nil {
+			logging.Warn("Failed to remove snapshot", "snapshot_id", s.ID, "error", err.Error())
+		}
+	}
+}
+
+// pruneSnapshots conserva solo los SnapshotMaxPerFlow snapshots más recientes del flow
+func pruneSnapshots(ctx context.Context, flowID int64, db *database.Queries) {
+	max := config.Config.SnapshotMaxPerFlow
+	if max <= 0 {
+		return
+	}
+
+	snapshots, err := db.ReadSnapshotsByFlowId(ctx, flowID)
+	if err != nil {
+		logging.Error("Failed to get snapshots", "flow_id", flowID, "error", err.Error())
+		return
+	}
+
+	for _, s := range snapshotsToPrune(snapshots, max) {
+		if err := removeSnapshot(ctx, s, db); err != nil {
+			logging.Warn("Failed to prune snapshot", "snapshot_id", s.ID, "error", err.Error())
+		}
+	}
+}
+
+// snapshotsToPrune devuelve los snapshots más antiguos que exceden el máximo
+func snapshotsToPrune(snapshots []database.Snapshot, max int) []database.Snapshot {
+	if len(snapshots) <= max {
+		return nil
+	}
+
+	sorted := make([]database.Snapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	return sorted[:len(sorted)-max]
+}
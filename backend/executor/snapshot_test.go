@@ -0,0 +1,132 @@
+package executor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestIsRiskyCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{command: "apt-get install -y curl", want: true},
+		{command: "apt update && apt install -y git", want: true},
+		{command: "pip install requests", want: true},
+		{command: "pip3 uninstall numpy -y", want: true},
+		{command: "npm install express", want: true},
+		{command: "yarn add react", want: true},
+		{command: "rm -rf /app/project", want: true},
+		{command: "rm -r build", want: true},
+		{command: "chmod -R 777 /", want: true},
+		{command: "git reset --hard HEAD~3", want: true},
+		{command: "git clean -fd", want: true},
+		{command: "echo nameserver 1.1.1.1 > /etc/resolv.conf", want: true},
+		{command: "ls -la /app", want: false},
+		{command: "python main.py", want: false},
+		{command: "rm output.txt", want: false},
+		{command: "cat /etc/os-release", want: false},
+		{command: "git status", want: false},
+		{command: "npm test", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := IsRiskyCommand(tt.command); got != tt.want {
+				t.Errorf("IsRiskyCommand(%q) = %v, want %v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotsToPrune(t *testing.T) {
+	snapshots := []database.Snapshot{
+		{ID: 4, TaskID: 1},
+		{ID: 1, TaskID: 2},
+		{ID: 3, TaskID: 5},
+		{ID: 2, TaskID: 7},
+	}
+
+	pruned := snapshotsToPrune(snapshots, 2)
+	if len(pruned) != 2 {
+		t.Fatalf("snapshotsToPrune() returned %d snapshots, want 2", len(pruned))
+	}
+	if pruned[0].ID != 1 || pruned[1].ID != 2 {
+		t.Errorf("snapshotsToPrune() should return the oldest snapshots, got %+v", pruned)
+	}
+
+	if pruned := snapshotsToPrune(snapshots, 4); len(pruned) != 0 {
+		t.Errorf("snapshotsToPrune() under the limit = %+v, want none", pruned)
+	}
+}
+
+func TestSnapshotReference(t *testing.T) {
+	a := snapshotReference(3, 7)
+	b := snapshotReference(3, 7)
+	if a == b {
+		t.Errorf("snapshotReference() should be unique, got %q twice", a)
+	}
+	if want := SnapshotRepository + ":flow-3-task-7-"; len(a) <= len(want) || a[:len(want)] != want {
+		t.Errorf("snapshotReference() = %q, want prefix %q", a, want)
+	}
+}
+
+func TestLockIdleFlow(t *testing.T) {
+	const flowID = int64(987654)
+
+	unlock, err := lockIdleFlow(flowID)
+	if err != nil {
+		t.Fatalf("lockIdleFlow() on an idle flow returned %v", err)
+	}
+
+	if _, err := lockIdleFlow(flowID); !errors.Is(err, ErrFlowBusy) {
+		t.Errorf("lockIdleFlow() on a locked flow = %v, want ErrFlowBusy", err)
+	}
+
+	unlock()
+
+	unlock, err = lockIdleFlow(flowID)
+	if err != nil {
+		t.Fatalf("lockIdleFlow() after unlock returned %v", err)
+	}
+	unlock()
+}
+
+func TestSnapshotConfig(t *testing.T) {
+	config.Config.SandboxEgressNetwork = "arandu-egress"
+	config.Config.SandboxEgressProxyURL = "http://arandu:3128"
+	imageEnv := []string{"PATH=/usr/local/bin:/usr/bin", "LANG=C.UTF-8"}
+
+	// Así arranca hoy un container de flow: los secretos van en cada comando
+	cfg := &container.Config{
+		Image: "debian:latest",
+		Cmd:   []string{"tail", "-f", "/dev/null"},
+		Env:   append([]string{}, imageEnv...),
+	}
+	applySandboxLimits(cfg, &container.HostConfig{}, SandboxLimits{NetworkMode: NetworkEgress})
+
+	snapshot := snapshotConfig(cfg, imageEnv)
+	for _, env := range snapshot.Env {
+		if strings.Contains(env, "GITHUB_TOKEN") || strings.Contains(env, "arandu:3128") {
+			t.Errorf("snapshot env keeps %q", env)
+		}
+	}
+	if snapshot.Env[0] != imageEnv[0] || snapshot.Env[1] != imageEnv[1] {
+		t.Errorf("snapshot env = %v, want the image variables kept", snapshot.Env)
+	}
+	if len(cfg.Env) == len(imageEnv) || cfg.Env[len(cfg.Env)-1] == "no_proxy=" {
+		t.Errorf("snapshotConfig() should not modify the container config: %v", cfg.Env)
+	}
+
+	// Containers creados cuando los secretos se pasaban al container
+	legacy := &container.Config{Env: append([]string{"GITHUB_TOKEN=ghp_secretvalue"}, imageEnv...)}
+	snapshot = snapshotConfig(legacy, imageEnv)
+	if snapshot.Env[0] != "GITHUB_TOKEN=" {
+		t.Errorf("snapshot env = %v, want the secret value cleared", snapshot.Env)
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"path/filepath"
+	"time"
 
+	"github.com/arandu-ai/arandu/audit"
 	"github.com/arandu-ai/arandu/database"
 	gmodel "github.com/arandu-ai/arandu/graph/model"
 	"github.com/arandu-ai/arandu/graph/subscriptions"
@@ -64,6 +68,22 @@ func createAndBroadcastLog(flowID int64, message string, logType LogType, db *da
 }
 
 func ExecCommand(flowID int64, command string, db *database.Queries) (result string, err error) {
+	// Todo comando queda en el log de auditoría, también los que fallan
+	defer func() {
+		audit.RecordAgent(db, audit.Event{
+			Action:  audit.ActionCommandExecuted,
+			FlowID:  flowID,
+			Target:  command,
+			Details: map[string]any{"outputBytes": len(result)},
+			Err:     err,
+		})
+	}()
+
+	return execCommand(flowID, command, db)
+}
+
+// execCommand ejecuta el comando en el container del flow sin auditarlo
+func execCommand(flowID int64, command string, db *database.Queries) (string, error) {
 	containerName, err := ensureContainerRunning(flowID)
 	if err != nil {
 		return "", err
@@ -76,6 +96,13 @@ func ExecCommand(flowID int64, command string, db *database.Queries) (result str
 		command,
 	}
 
+	// Los secretos se pasan a cada comando y no al container, así no quedan en
+	// su configuración ni en las imágenes de los snapshots
+	env, err := secretsEnv(context.Background(), flowID, db)
+	if err != nil {
+		return "", err
+	}
+
 	// Log input command
 	if err := createAndBroadcastLog(flowID, command, LogTypeInput, db); err != nil {
 		return "", err
@@ -83,6 +110,7 @@ func ExecCommand(flowID int64, command string, db *database.Queries) (result str
 
 	createResp, err := dockerClient.ContainerExecCreate(context.Background(), containerName, container.ExecOptions{
 		Cmd:          cmd,
+		Env:          env,
 		AttachStdout: true,
 		AttachStderr: true,
 		Tty:          true,
@@ -112,15 +140,14 @@ func ExecCommand(flowID int64, command string, db *database.Queries) (result str
 		return "", fmt.Errorf("Error inspecting exec process: %w", err)
 	}
 
-	results := dst.String()
+	// Los secretos y credenciales no llegan ni a los logs ni al modelo
+	result := redactOutput(flowID, dst.String(), db)
 
 	// Log output result
-	if err := createAndBroadcastLog(flowID, results, LogTypeOutput, db); err != nil {
+	if err := createAndBroadcastLog(flowID, result, LogTypeOutput, db); err != nil {
 		return "", err
 	}
 
-	result = dst.String()
-
 	if result == "" {
 		result = "Command executed successfully"
 	}
@@ -128,7 +155,80 @@ func ExecCommand(flowID int64, command string, db *database.Queries) (result str
 	return result, nil
 }
 
+// ExecCommandWithTimeout ejecuta el comando cortándolo con timeout si tarda demasiado
+func ExecCommandWithTimeout(flowID int64, command string, limit time.Duration, db *database.Queries) (string, error) {
+	return ExecCommand(flowID, timeoutCommand(command, limit), db)
+}
+
+// ExecUserCommand ejecuta un comando pedido por un usuario (p. ej. desde el
+// servidor MCP) con las reglas de los comandos del agente: solo con el flow
+// libre y si la política de comandos lo permite sin aprobación
+// Queda auditado a nombre del usuario del contexto
+func ExecUserCommand(ctx context.Context, flowID int64, command string, limit time.Duration, db *database.Queries) (result string, err error) {
+	action := audit.ActionCommandExecuted
+	defer func() {
+		audit.Record(ctx, db, audit.Event{
+			Action:  action,
+			FlowID:  flowID,
+			Target:  command,
+			Details: map[string]any{"outputBytes": len(result)},
+			Err:     err,
+		})
+	}()
+
+	unlock, err := lockIdleFlow(flowID)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	decision, err := commandDecision(db, flowID, command)
+	if err != nil {
+		return "", err
+	}
+	if err := userCommandError(decision); err != nil {
+		action = audit.ActionCommandDenied
+		return "", err
+	}
+
+	return execCommand(flowID, timeoutCommand(command, limit), db)
+}
+
+// timeoutCommand envuelve el comando con timeout para cortarlo tras limit
+func timeoutCommand(command string, limit time.Duration) string {
+	seconds := int(limit.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("timeout %d sh -c %s", seconds, shellQuote(command))
+}
+
+// ReadFile devuelve el contenido de un archivo del container del flow
+func ReadFile(flowID int64, path string, db *database.Queries) (string, error) {
+	if err := validateCodeSecurity(path); err != nil {
+		return "", err
+	}
+
+	results, err := ExecCommand(flowID, "cat "+shellQuote(path), db)
+	if err != nil {
+		return "", fmt.Errorf("error executing cat command: %w", err)
+	}
+	return results, nil
+}
+
 func WriteFile(flowID int64, content string, path string, db *database.Queries) (err error) {
+	// El log de auditoría guarda el hash del contenido, no el contenido
+	defer func() {
+		sum := sha256.Sum256([]byte(content))
+		audit.RecordAgent(db, audit.Event{
+			Action:  audit.ActionFileWritten,
+			FlowID:  flowID,
+			Target:  path,
+			Details: map[string]any{"bytes": len(content), "sha256": hex.EncodeToString(sum[:])},
+			Err:     err,
+		})
+	}()
+
 	containerName, err := ensureContainerRunning(flowID)
 	if err != nil {
 		return err
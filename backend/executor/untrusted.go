@@ -0,0 +1,315 @@
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arandu-ai/arandu/audit"
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/graph/subscriptions"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/models"
+	"github.com/arandu-ai/arandu/providers"
+	"github.com/arandu-ai/arandu/security"
+)
+
+// Políticas de UNTRUSTED_CONTENT_APPROVAL
+const (
+	// ApprovalPolicyOff nunca pide aprobación
+	ApprovalPolicyOff = "off"
+	// ApprovalPolicyFlagged la pide después de contenido marcado por las heurísticas
+	ApprovalPolicyFlagged = "flagged"
+	// ApprovalPolicyAlways la pide después de cualquier contenido no confiable
+	ApprovalPolicyAlways = "always"
+)
+
+// ErrTaskNotPending indica que la tarea no está esperando aprobación
+var ErrTaskNotPending = errors.New("task is not waiting for approval")
+
+// ValidateUntrustedContentPolicy valida UNTRUSTED_CONTENT_APPROVAL al arrancar
+func ValidateUntrustedContentPolicy() error {
+	switch config.Config.UntrustedContentApproval {
+	case ApprovalPolicyOff, ApprovalPolicyFlagged, ApprovalPolicyAlways:
+		return nil
+	default:
+		return fmt.Errorf("invalid UNTRUSTED_CONTENT_APPROVAL %q: use off, flagged or always", config.Config.UntrustedContentApproval)
+	}
+}
+
+// recordUntrustedContent marca la tarea como portadora de contenido externo y
+// guarda las heurísticas de prompt injection que encontraron algo en él
+// Se llama después de guardar los resultados, así la UI recibe ambos
+func recordUntrustedContent(db *database.Queries, task database.Task, content string) {
+	if !config.Config.UntrustedContentDefense {
+		return
+	}
+
+	flags := security.DetectInjection(content)
+	encoded, err := json.Marshal(flags)
+	if err != nil || flags == nil {
+		encoded = []byte("[]")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DBTimeout)
+	defer cancel()
+
+	updated, err := db.UpdateTaskUntrusted(ctx, database.UpdateTaskUntrustedParams{
+		ID:             task.ID,
+		Untrusted:      true,
+		InjectionFlags: string(encoded),
+	})
+	if err != nil {
+		logging.Error("Failed to mark untrusted content", "task_id", task.ID, "error", err.Error())
+		return
+	}
+
+	if len(flags) > 0 {
+		logging.Warn("Possible prompt injection in untrusted content",
+			"flow_id", task.FlowID.Int64,
+			"task_id", task.ID,
+			"flags", strings.Join(flags, ","),
+		)
+		audit.RecordAgent(db, audit.Event{
+			Action:  audit.ActionContentFlagged,
+			FlowID:  task.FlowID.Int64,
+			TaskID:  task.ID,
+			Target:  task.Type.String,
+			Details: map[string]any{"flags": flags},
+		})
+	}
+
+	subscriptions.BroadcastTaskUpdated(task.FlowID.Int64, TaskToGraphQL(updated))
+}
+
+// sandboxBrowserActions no salen a internet: leen la página abierta
+var sandboxBrowserActions = map[providers.BrowserAction]bool{
+	providers.Scroll:  true,
+	providers.WaitFor: true,
+	providers.Back:    true,
+}
+
+// sensitiveAction describe por qué una tarea puede sacar datos del sandbox o
+// tocar el sistema fuera de /app; vacío si no es sensible
+func sensitiveAction(task database.Task) string {
+	switch models.TaskType(task.Type.String) {
+	case models.Terminal:
+		args, err := unmarshalTaskArgs[providers.TerminalArgs](task)
+		if err != nil {
+			return ""
+		}
+		if tool := security.EgressCommand(args.Input); tool != "" {
+			return fmt.Sprintf("network access (%s)", tool)
+		}
+		if paths := security.WritesOutside(args.Input, "/app"); len(paths) > 0 {
+			return fmt.Sprintf("write outside /app (%s)", strings.Join(paths, ", "))
+		}
+	case models.Browser:
+		args, err := unmarshalTaskArgs[providers.BrowserArgs](task)
+		if err != nil || sandboxBrowserActions[args.Action] || strings.HasPrefix(args.Url, "sandbox://") {
+			return ""
+		}
+		if args.Url != "" {
+			return fmt.Sprintf("browser %s %s", args.Action, args.Url)
+		}
+		return fmt.Sprintf("browser %s", args.Action)
+	case models.Code:
+		args, err := unmarshalTaskArgs[providers.CodeArgs](task)
+		if err != nil || args.Action != providers.UpdateFile {
+			return ""
+		}
+		if security.PathOutside(args.Path, "/app") {
+			return fmt.Sprintf("write outside /app (%s)", args.Path)
+		}
+	case models.Search:
+		return "web search"
+	case models.MCP:
+		args, err := unmarshalTaskArgs[providers.MCPArgs](task)
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf("mcp tool %s on %s", args.Tool, args.Server)
+	default:
+		// Los webhooks mandan los argumentos a un servicio externo
+		if tool, ok := providers.GetCustomTool(task.Type.String); ok && tool.Webhook != nil {
+			return fmt.Sprintf("webhook tool %s", tool.Name)
+		}
+	}
+	return ""
+}
+
+// approvalReason devuelve por qué la tarea tiene que esperar aprobación: la
+// política de comandos lo pide, o es una acción sensible y antes entró al flow
+// contenido no confiable. Vacío si puede ejecutarse
+func approvalReason(db *database.Queries, task database.Task) (string, error) {
+	if models.TaskType(task.Type.String) == models.Terminal {
+		args, err := unmarshalTaskArgs[providers.TerminalArgs](task)
+		if err != nil {
+			return "", nil
+		}
+		decision, err := commandDecision(db, task.FlowID.Int64, args.Input)
+		if err != nil {
+			return "", err
+		}
+		switch decision.Action {
+		case security.CommandDeny:
+			// processTerminalTask le devuelve la denegación al modelo
+			return "", nil
+		case security.CommandRequireApproval:
+			return commandApprovalReason(decision), nil
+		}
+	}
+
+	policy := config.Config.UntrustedContentApproval
+	if !config.Config.UntrustedContentDefense || policy == ApprovalPolicyOff {
+		return "", nil
+	}
+
+	action := sensitiveAction(task)
+	if action == "" {
+		return "", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DBTimeout)
+	defer cancel()
+
+	tasks, err := db.ReadTasksByFlowId(ctx, task.FlowID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tasks by flow id: %w", err)
+	}
+
+	// La más reciente es la que más probablemente dispare la acción
+	for i := len(tasks) - 1; i >= 0; i-- {
+		previous := tasks[i]
+		if previous.ID >= task.ID || !previous.Untrusted {
+			continue
+		}
+		flags := taskInjectionFlags(previous.InjectionFlags)
+		if len(flags) > 0 {
+			return fmt.Sprintf("%s after content flagged as %s in task %d",
+				action, strings.Join(flags, ", "), previous.ID), nil
+		}
+		if policy == ApprovalPolicyAlways {
+			return fmt.Sprintf("%s after untrusted content in task %d", action, previous.ID), nil
+		}
+	}
+	return "", nil
+}
+
+// requestApproval deja la tarea esperando al usuario; el flow queda en pausa
+// hasta que la apruebe o la rechace
+func requestApproval(db *database.Queries, task database.Task, reason string) {
+	ctx, cancel := context.WithTimeout(context.Background(), DBTimeout)
+	defer cancel()
+
+	updated, err := db.UpdateTaskApproval(ctx, database.UpdateTaskApprovalParams{
+		ID:              task.ID,
+		Approval:        models.ApprovalPending,
+		CurrentApproval: models.ApprovalNone,
+		Results:         database.StringToNullString("Waiting for approval: " + reason),
+	})
+	if err != nil {
+		logging.Error("Failed to request approval", "task_id", task.ID, "error", err.Error())
+		updateTaskError(db, task.ID, err)
+		return
+	}
+
+	logging.Info("Task waiting for approval", "flow_id", task.FlowID.Int64, "task_id", task.ID, "reason", reason)
+	audit.RecordAgent(db, audit.Event{
+		Action:  audit.ActionApprovalRequested,
+		FlowID:  task.FlowID.Int64,
+		TaskID:  task.ID,
+		Target:  task.Type.String,
+		Details: map[string]any{"reason": reason, "args": task.Args.String},
+	})
+	subscriptions.BroadcastTaskUpdated(task.FlowID.Int64, TaskToGraphQL(updated))
+}
+
+// dismissPendingApprovals rechaza las tareas que esperaban aprobación cuando
+// el usuario escribe un mensaje en lugar de responderlas
+func dismissPendingApprovals(db *database.Queries, flowID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), DBTimeout)
+	defer cancel()
+
+	tasks, err := db.ReadTasksByFlowId(ctx, sql.NullInt64{Int64: flowID, Valid: true})
+	if err != nil {
+		logging.Error("Failed to get tasks by flow id", "flow_id", flowID, "error", err.Error())
+		return
+	}
+
+	for _, task := range tasks {
+		if task.Approval != models.ApprovalPending {
+			continue
+		}
+		updated, err := db.UpdateTaskApproval(ctx, database.UpdateTaskApprovalParams{
+			ID:              task.ID,
+			Approval:        models.ApprovalRejected,
+			CurrentApproval: models.ApprovalPending,
+			Results:         database.StringToNullString("Not run: the user sent a new message instead of approving this action"),
+		})
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				logging.Error("Failed to dismiss approval", "task_id", task.ID, "error", err.Error())
+			}
+			continue
+		}
+		subscriptions.BroadcastTaskUpdated(flowID, TaskToGraphQL(updated))
+	}
+}
+
+// ApproveTask ejecuta una tarea que esperaba aprobación
+func ApproveTask(taskID int64, db *database.Queries) (database.Task, error) {
+	return resolveApproval(taskID, models.ApprovalApproved, "Approved, running", db)
+}
+
+// RejectTask descarta una tarea que esperaba aprobación; el modelo recibe el
+// rechazo y el motivo como resultado y sigue con otra acción
+func RejectTask(taskID int64, reason string, db *database.Queries) (database.Task, error) {
+	results := "The user rejected this action. Do not retry it; find another way or ask the user"
+	if reason = strings.TrimSpace(reason); reason != "" {
+		results = fmt.Sprintf("The user rejected this action: %s", reason)
+	}
+	return resolveApproval(taskID, models.ApprovalRejected, results, db)
+}
+
+// resolveApproval registra la decisión y devuelve la tarea a la cola del flow
+func resolveApproval(taskID int64, approval models.TaskApproval, results string, db *database.Queries) (database.Task, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DBTimeout)
+	defer cancel()
+
+	task, err := db.UpdateTaskApproval(ctx, database.UpdateTaskApprovalParams{
+		ID:              taskID,
+		Approval:        approval,
+		CurrentApproval: models.ApprovalPending,
+		Results:         database.StringToNullString(results),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Task{}, ErrTaskNotPending
+	}
+	if err != nil {
+		return database.Task{}, fmt.Errorf("failed to update task approval: %w", err)
+	}
+
+	subscriptions.BroadcastTaskUpdated(task.FlowID.Int64, TaskToGraphQL(task))
+
+	// Tras un reinicio el flow no tiene cola
+	AddQueue(task.FlowID.Int64, db)
+	AddCommand(task.FlowID.Int64, task)
+	return task, nil
+}
+
+// taskInjectionFlags decodifica las heurísticas guardadas en la tarea
+func taskInjectionFlags(raw string) []string {
+	var flags []string
+	if raw == "" {
+		return []string{}
+	}
+	if err := json.Unmarshal([]byte(raw), &flags); err != nil || flags == nil {
+		return []string{}
+	}
+	return flags
+}
@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/database"
+)
+
+// authorizeTask reads a task and checks that the user can drive its flow
+func (r *Resolver) authorizeTask(ctx context.Context, taskID uint) (database.Task, error) {
+	task, err := r.Db.ReadTask(ctx, int64(taskID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Task{}, fmt.Errorf("task %d not found", taskID)
+	}
+	if err != nil {
+		return database.Task{}, fmt.Errorf("failed to fetch task: %w", err)
+	}
+	if err := r.authorizeFlow(ctx, uint(task.FlowID.Int64), auth.TeamOperator); err != nil {
+		return database.Task{}, err
+	}
+	return task, nil
+}
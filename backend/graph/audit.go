@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/arandu-ai/arandu/database"
+)
+
+const (
+	// defaultAuditEvents is how many audit events are returned without a limit
+	defaultAuditEvents = 100
+	// maxAuditEvents caps a page of audit events; use the JSONL export for more
+	maxAuditEvents = 1000
+)
+
+// auditEventsParams builds the filters of the auditEvents query
+func auditEventsParams(flowID *uint, userID *uint, action *string, before *uint, limit *int) (database.ReadAuditEventsParams, error) {
+	params := database.ReadAuditEventsParams{Limit: defaultAuditEvents}
+	if limit != nil {
+		if *limit < 1 || *limit > maxAuditEvents {
+			return params, fmt.Errorf("limit must be between 1 and %d", maxAuditEvents)
+		}
+		params.Limit = int64(*limit)
+	}
+	if flowID != nil {
+		params.FlowID = sql.NullInt64{Int64: int64(*flowID), Valid: true}
+	}
+	if userID != nil {
+		params.UserID = sql.NullInt64{Int64: int64(*userID), Valid: true}
+	}
+	if action != nil {
+		params.Action = sql.NullString{String: *action, Valid: true}
+	}
+	if before != nil {
+		params.BeforeID = sql.NullInt64{Int64: int64(*before), Valid: true}
+	}
+	return params, nil
+}
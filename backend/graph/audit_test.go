@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"testing"
+)
+
+func TestAuditEventsParams(t *testing.T) {
+	flowID, before := uint(3), uint(40)
+	action := "command.executed"
+
+	tests := []struct {
+		name      string
+		limit     *int
+		wantLimit int64
+		wantErr   bool
+	}{
+		{name: "default limit", wantLimit: defaultAuditEvents},
+		{name: "custom limit", limit: intPtr(10), wantLimit: 10},
+		{name: "max limit", limit: intPtr(maxAuditEvents), wantLimit: maxAuditEvents},
+		{name: "zero limit", limit: intPtr(0), wantErr: true},
+		{name: "limit above max", limit: intPtr(maxAuditEvents + 1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := auditEventsParams(&flowID, nil, &action, &before, tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("auditEventsParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if params.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", params.Limit, tt.wantLimit)
+			}
+			if params.FlowID.Int64 != 3 || !params.FlowID.Valid || params.UserID.Valid {
+				t.Errorf("FlowID = %v, UserID = %v", params.FlowID, params.UserID)
+			}
+			if params.Action.String != action || params.BeforeID.Int64 != 40 {
+				t.Errorf("Action = %v, BeforeID = %v", params.Action, params.BeforeID)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/database"
+)
+
+// authorizeFlow checks that the user of the request has at least the role
+// on the flow. A flow the user cannot see is reported as missing, so ids
+// cannot be probed
+func (r *Resolver) authorizeFlow(ctx context.Context, flowID uint, role string) error {
+	if !auth.Enabled() {
+		return nil
+	}
+
+	flow, err := r.Db.ReadFlow(ctx, int64(flowID))
+	if err != nil {
+		return fmt.Errorf("flow %d not found", flowID)
+	}
+	return checkFlowRole(ctx, r.Db, flowID, flowAccess(flow), role)
+}
+
+// checkFlowRole checks the role of the user of the request on a read flow
+func checkFlowRole(ctx context.Context, db *database.Queries, flowID uint, access auth.FlowAccess, role string) error {
+	current, err := auth.FlowRole(ctx, db, access)
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		return fmt.Errorf("flow %d not found", flowID)
+	}
+	if !auth.HasRole(current, role) {
+		return fmt.Errorf("%w: the %s role on flow %d does not allow this operation", auth.ErrForbidden, current, flowID)
+	}
+	return nil
+}
+
+func flowAccess(flow database.ReadFlowRow) auth.FlowAccess {
+	return auth.FlowAccess{OwnerID: flow.OwnerID, TeamID: flow.TeamID}
+}
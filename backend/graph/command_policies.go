@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/executor"
+	gmodel "github.com/arandu-ai/arandu/graph/model"
+)
+
+// commandPolicyScope is the flow or the team a command policy belongs to
+type commandPolicyScope struct {
+	FlowID sql.NullInt64
+	TeamID sql.NullInt64
+}
+
+// commandPolicyScopeFor checks that the user of the request may read or, with
+// manage, change the command policy of a flow or a team. Flow policies are
+// read by its viewers and managed by its admins, team policies are read by
+// the team's members and managed by its admins
+func (r *Resolver) commandPolicyScopeFor(ctx context.Context, flowID *uint, teamID *uint, manage bool) (commandPolicyScope, error) {
+	if (flowID == nil) == (teamID == nil) {
+		return commandPolicyScope{}, fmt.Errorf("pass either flowId or teamId")
+	}
+
+	if flowID != nil {
+		role := auth.TeamViewer
+		if manage {
+			role = auth.TeamAdmin
+		}
+		if err := r.authorizeFlow(ctx, *flowID, role); err != nil {
+			return commandPolicyScope{}, err
+		}
+		if _, err := r.Db.ReadFlow(ctx, int64(*flowID)); err != nil {
+			return commandPolicyScope{}, fmt.Errorf("flow %d not found", *flowID)
+		}
+		return commandPolicyScope{FlowID: sql.NullInt64{Int64: int64(*flowID), Valid: true}}, nil
+	}
+
+	if manage {
+		if err := auth.RequireTeamAdmin(ctx, r.Db, int64(*teamID)); err != nil {
+			return commandPolicyScope{}, err
+		}
+	} else if err := r.authorizeShare(ctx, *teamID); err != nil {
+		return commandPolicyScope{}, err
+	}
+	if _, err := r.readTeam(ctx, *teamID); err != nil {
+		return commandPolicyScope{}, err
+	}
+	return commandPolicyScope{TeamID: sql.NullInt64{Int64: int64(*teamID), Valid: true}}, nil
+}
+
+// readCommandPolicy returns the policy of a flow or a team, or nil
+func (r *Resolver) readCommandPolicy(ctx context.Context, scope commandPolicyScope) (*gmodel.CommandPolicy, error) {
+	var row database.CommandPolicy
+	var err error
+	label := executor.CommandPolicyFlow
+	if scope.TeamID.Valid {
+		row, err = r.Db.ReadTeamCommandPolicy(ctx, scope.TeamID)
+		label = executor.CommandPolicyTeam
+	} else {
+		row, err = r.Db.ReadFlowCommandPolicy(ctx, scope.FlowID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch command policy: %w", err)
+	}
+
+	policy, err := executor.StoredCommandPolicy(row, label)
+	if err != nil {
+		return nil, err
+	}
+	return executor.CommandPolicyToGraphQL(policy, row.UpdatedAt), nil
+}
+
+// saveCommandPolicy stores an encoded policy, replacing the previous one
+func (r *Resolver) saveCommandPolicy(ctx context.Context, scope commandPolicyScope, policy string) (database.CommandPolicy, error) {
+	if scope.TeamID.Valid {
+		return r.Db.UpsertTeamCommandPolicy(ctx, database.UpsertTeamCommandPolicyParams{TeamID: scope.TeamID, Policy: policy})
+	}
+	return r.Db.UpsertFlowCommandPolicy(ctx, database.UpsertFlowCommandPolicyParams{FlowID: scope.FlowID, Policy: policy})
+}
+
+// deleteCommandPolicy removes the policy of a flow or a team, if any
+func (r *Resolver) deleteCommandPolicy(ctx context.Context, scope commandPolicyScope) error {
+	var err error
+	if scope.TeamID.Valid {
+		_, err = r.Db.DeleteTeamCommandPolicy(ctx, scope.TeamID)
+	} else {
+		_, err = r.Db.DeleteFlowCommandPolicy(ctx, scope.FlowID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete command policy: %w", err)
+	}
+	return nil
+}
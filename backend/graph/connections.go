@@ -0,0 +1,358 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/executor"
+	gmodel "github.com/arandu-ai/arandu/graph/model"
+)
+
+const (
+	// defaultPageSize is how many items a connection returns without first
+	defaultPageSize = 50
+	// maxPageSize caps a page of a connection
+	maxPageSize = 500
+)
+
+// pageCursor is the position of an item in a connection. Name is only set
+// when flows are sorted by name
+type pageCursor struct {
+	ID   int64   `json:"id"`
+	Name *string `json:"name,omitempty"`
+}
+
+// encodeCursor returns the opaque cursor of an item
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses the after argument of a connection, nil for the first page
+func decodeCursor(after *string) (*pageCursor, error) {
+	if after == nil || *after == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(*after)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID < 1 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &c, nil
+}
+
+// pageSize validates the first argument of a connection
+func pageSize(first *int) (int64, error) {
+	if first == nil {
+		return defaultPageSize, nil
+	}
+	if *first < 1 || *first > maxPageSize {
+		return 0, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+	}
+	return int64(*first), nil
+}
+
+// pageInfo trims the extra row read to know whether there is a next page
+// and returns the page info of the remaining n items
+func pageInfo(rows int, size int64, endCursor func(i int) string) (int, *gmodel.PageInfo) {
+	n := min(rows, int(size))
+	info := &gmodel.PageInfo{HasNextPage: rows > n}
+	if n > 0 {
+		cursor := endCursor(n - 1)
+		info.EndCursor = &cursor
+	}
+	return n, info
+}
+
+// cursorID returns the id of the cursor, or null for the first page
+func cursorID(c *pageCursor) sql.NullInt64 {
+	if c == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: c.ID, Valid: true}
+}
+
+// nullTime converts an optional date filter, compared in UTC like the
+// timestamps SQLite stores
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func nullUint(id *uint) sql.NullInt64 {
+	if id == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*id), Valid: true}
+}
+
+// flowsCountParams builds the filters of the flows the user of the request
+// can see, the same flows the flow query allows: all of them without
+// authentication, and otherwise their own, their teams' and, for admins,
+// those without an owner
+func flowsCountParams(ctx context.Context, filter *gmodel.FlowFilter) database.CountFlowsParams {
+	params := database.CountFlowsParams{AllFlows: !auth.Enabled()}
+	if user, ok := auth.UserFromContext(ctx); ok {
+		params.ViewerID = user.ID
+		params.ViewerAdmin = user.Admin
+	}
+	if filter == nil {
+		return params
+	}
+	if filter.Status != nil {
+		params.Status = sql.NullString{String: string(*filter.Status), Valid: true}
+	}
+	params.Model = nullString(filter.Model)
+	params.ModelProvider = nullString(filter.ModelProvider)
+	params.OwnerID = nullUint(filter.OwnerID)
+	params.TeamID = nullUint(filter.TeamID)
+	params.CreatedAfter = nullTime(filter.CreatedAfter)
+	params.CreatedBefore = nullTime(filter.CreatedBefore)
+	return params
+}
+
+// flowsPage reads a page of flows after a cursor that matches the sort
+// Each direction has its own query so SQLite can walk the index both ways
+func (r *Resolver) flowsPage(ctx context.Context, filter database.CountFlowsParams, sort gmodel.FlowSort, after *pageCursor, limit int64) ([]database.ReadAllFlowsRow, error) {
+	descending := sort.Order == gmodel.SortOrderDesc
+
+	if sort.Field == gmodel.FlowSortFieldName {
+		params := database.ReadFlowsPageByNameAscParams{
+			Status:        filter.Status,
+			Model:         filter.Model,
+			ModelProvider: filter.ModelProvider,
+			OwnerID:       filter.OwnerID,
+			TeamID:        filter.TeamID,
+			CreatedAfter:  filter.CreatedAfter,
+			CreatedBefore: filter.CreatedBefore,
+			AllFlows:      filter.AllFlows,
+			ViewerID:      filter.ViewerID,
+			ViewerAdmin:   filter.ViewerAdmin,
+			AfterID:       cursorID(after),
+			Limit:         limit,
+		}
+		if after != nil {
+			params.AfterName = *after.Name
+		}
+		if descending {
+			rows, err := r.Db.ReadFlowsPageByNameDesc(ctx, database.ReadFlowsPageByNameDescParams(params))
+			return flowRows(rows), err
+		}
+		rows, err := r.Db.ReadFlowsPageByNameAsc(ctx, params)
+		return flowRows(rows), err
+	}
+
+	params := database.ReadFlowsPageAscParams{
+		Status:        filter.Status,
+		Model:         filter.Model,
+		ModelProvider: filter.ModelProvider,
+		OwnerID:       filter.OwnerID,
+		TeamID:        filter.TeamID,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+		AllFlows:      filter.AllFlows,
+		ViewerID:      filter.ViewerID,
+		ViewerAdmin:   filter.ViewerAdmin,
+		AfterID:       cursorID(after),
+		Limit:         limit,
+	}
+	if descending {
+		rows, err := r.Db.ReadFlowsPageDesc(ctx, database.ReadFlowsPageDescParams(params))
+		return flowRows(rows), err
+	}
+	rows, err := r.Db.ReadFlowsPageAsc(ctx, params)
+	return flowRows(rows), err
+}
+
+// flowRows converts the rows of the page queries, which share the columns of
+// ReadAllFlows
+func flowRows[T database.ReadFlowsPageAscRow | database.ReadFlowsPageDescRow | database.ReadFlowsPageByNameAscRow | database.ReadFlowsPageByNameDescRow](rows []T) []database.ReadAllFlowsRow {
+	flows := make([]database.ReadAllFlowsRow, len(rows))
+	for i, row := range rows {
+		flows[i] = database.ReadAllFlowsRow(row)
+	}
+	return flows
+}
+
+// flowsConnection returns a page of the flows the user of the request can
+// see. Flows are sorted by id for createdAt, so pages are stable while new
+// flows are created
+func (r *Resolver) flowsConnection(ctx context.Context, first *int, after *string, filter *gmodel.FlowFilter, sort *gmodel.FlowSort) (*gmodel.FlowConnection, error) {
+	size, err := pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := decodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+	order := gmodel.FlowSort{Field: gmodel.FlowSortFieldCreatedAt, Order: gmodel.SortOrderDesc}
+	if sort != nil {
+		order = *sort
+	}
+	if cursor != nil && (cursor.Name != nil) != (order.Field == gmodel.FlowSortFieldName) {
+		return nil, fmt.Errorf("the cursor belongs to a page with another sort")
+	}
+
+	params := flowsCountParams(ctx, filter)
+	flows, err := r.flowsPage(ctx, params, order, cursor, size+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch flows: %w", err)
+	}
+	total, err := r.Db.CountFlows(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count flows: %w", err)
+	}
+
+	flowCursor := func(i int) string {
+		c := pageCursor{ID: flows[i].ID}
+		if order.Field == gmodel.FlowSortFieldName {
+			name := flows[i].Name.String
+			c.Name = &name
+		}
+		return encodeCursor(c)
+	}
+	n, info := pageInfo(len(flows), size, flowCursor)
+	edges := make([]*gmodel.FlowEdge, n)
+	for i := range edges {
+		edges[i] = &gmodel.FlowEdge{Cursor: flowCursor(i), Node: executor.FlowRowToGraphQL(flows[i])}
+	}
+
+	return &gmodel.FlowConnection{Edges: edges, PageInfo: info, TotalCount: int(total)}, nil
+}
+
+// tasksConnection returns a page of the tasks of a flow
+func (r *Resolver) tasksConnection(ctx context.Context, flowID uint, first *int, after *string, filter *gmodel.TaskFilter, order *gmodel.SortOrder) (*gmodel.TaskConnection, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamViewer); err != nil {
+		return nil, err
+	}
+	size, err := pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := decodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+
+	params := database.CountTasksParams{FlowID: sql.NullInt64{Int64: int64(flowID), Valid: true}}
+	if filter != nil {
+		if filter.Status != nil {
+			params.Status = sql.NullString{String: string(*filter.Status), Valid: true}
+		}
+		if filter.Type != nil {
+			params.Type = sql.NullString{String: string(*filter.Type), Valid: true}
+		}
+		params.CreatedAfter = nullTime(filter.CreatedAfter)
+		params.CreatedBefore = nullTime(filter.CreatedBefore)
+	}
+
+	page := database.ReadTasksPageAscParams{
+		FlowID:        params.FlowID,
+		Status:        params.Status,
+		Type:          params.Type,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+		AfterID:       cursorID(cursor),
+		Limit:         size + 1,
+	}
+	var tasks []database.Task
+	if order != nil && *order == gmodel.SortOrderDesc {
+		tasks, err = r.Db.ReadTasksPageDesc(ctx, database.ReadTasksPageDescParams(page))
+	} else {
+		tasks, err = r.Db.ReadTasksPageAsc(ctx, page)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tasks: %w", err)
+	}
+	total, err := r.Db.CountTasks(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	taskCursor := func(i int) string {
+		return encodeCursor(pageCursor{ID: tasks[i].ID})
+	}
+	n, info := pageInfo(len(tasks), size, taskCursor)
+	edges := make([]*gmodel.TaskEdge, n)
+	for i := range edges {
+		edges[i] = &gmodel.TaskEdge{Cursor: taskCursor(i), Node: executor.TaskToGraphQL(tasks[i])}
+	}
+
+	return &gmodel.TaskConnection{Edges: edges, PageInfo: info, TotalCount: int(total)}, nil
+}
+
+// logsConnection returns a page of the terminal logs of a flow
+func (r *Resolver) logsConnection(ctx context.Context, flowID uint, first *int, after *string, filter *gmodel.LogFilter, order *gmodel.SortOrder) (*gmodel.LogConnection, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamViewer); err != nil {
+		return nil, err
+	}
+	size, err := pageSize(first)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := decodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+
+	params := database.CountLogsParams{FlowID: sql.NullInt64{Int64: int64(flowID), Valid: true}}
+	if filter != nil {
+		if filter.Type != nil {
+			params.Type = sql.NullString{String: string(*filter.Type), Valid: true}
+		}
+		params.CreatedAfter = nullTime(filter.CreatedAfter)
+		params.CreatedBefore = nullTime(filter.CreatedBefore)
+	}
+
+	page := database.ReadLogsPageAscParams{
+		FlowID:        params.FlowID,
+		Type:          params.Type,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+		AfterID:       cursorID(cursor),
+		Limit:         size + 1,
+	}
+	var logs []database.Log
+	if order != nil && *order == gmodel.SortOrderDesc {
+		logs, err = r.Db.ReadLogsPageDesc(ctx, database.ReadLogsPageDescParams(page))
+	} else {
+		logs, err = r.Db.ReadLogsPageAsc(ctx, page)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch logs: %w", err)
+	}
+	total, err := r.Db.CountLogs(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count logs: %w", err)
+	}
+
+	logCursor := func(i int) string {
+		return encodeCursor(pageCursor{ID: logs[i].ID})
+	}
+	n, info := pageInfo(len(logs), size, logCursor)
+	edges := make([]*gmodel.LogEdge, n)
+	for i := range edges {
+		edges[i] = &gmodel.LogEdge{Cursor: logCursor(i), Node: executor.LogToGraphQL(logs[i])}
+	}
+
+	return &gmodel.LogConnection{Edges: edges, PageInfo: info, TotalCount: int(total)}, nil
+}
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestPageCursor(t *testing.T) {
+	name := "deploy api"
+	for _, c := range []pageCursor{{ID: 42}, {ID: 7, Name: &name}} {
+		cursor := encodeCursor(c)
+		got, err := decodeCursor(&cursor)
+		if err != nil {
+			t.Fatalf("decodeCursor(%q) error = %v", cursor, err)
+		}
+		if got.ID != c.ID || (got.Name == nil) != (c.Name == nil) || (got.Name != nil && *got.Name != *c.Name) {
+			t.Errorf("decodeCursor(%q) = %+v, want %+v", cursor, got, c)
+		}
+	}
+
+	empty := ""
+	for _, after := range []*string{nil, &empty} {
+		if got, err := decodeCursor(after); got != nil || err != nil {
+			t.Errorf("decodeCursor(%v) = %v, %v, want first page", after, got, err)
+		}
+	}
+
+	for _, after := range []string{"not a cursor!", "bm90IGpzb24", encodeCursor(pageCursor{})} {
+		if _, err := decodeCursor(&after); err == nil {
+			t.Errorf("decodeCursor(%q) expected error", after)
+		}
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	tests := []struct {
+		first   *int
+		want    int64
+		wantErr bool
+	}{
+		{want: defaultPageSize},
+		{first: intPtr(1), want: 1},
+		{first: intPtr(maxPageSize), want: maxPageSize},
+		{first: intPtr(0), wantErr: true},
+		{first: intPtr(maxPageSize + 1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := pageSize(tt.first)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("pageSize(%v) = %d, %v, want %d", tt.first, got, err, tt.want)
+		}
+	}
+}
+
+func TestPageInfo(t *testing.T) {
+	cursor := func(i int) string { return strconv.Itoa(i) }
+
+	tests := []struct {
+		name     string
+		rows     int
+		wantN    int
+		wantNext bool
+		wantEnd  string
+	}{
+		{name: "empty", rows: 0, wantN: 0},
+		{name: "partial page", rows: 3, wantN: 3, wantEnd: "2"},
+		{name: "full page", rows: 5, wantN: 5, wantEnd: "4"},
+		{name: "more pages", rows: 6, wantN: 5, wantNext: true, wantEnd: "4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, info := pageInfo(tt.rows, 5, cursor)
+			if n != tt.wantN || info.HasNextPage != tt.wantNext {
+				t.Errorf("pageInfo() = %d, %+v, want %d items, hasNextPage %v", n, info, tt.wantN, tt.wantNext)
+			}
+			end := ""
+			if info.EndCursor != nil {
+				end = *info.EndCursor
+			}
+			if end != tt.wantEnd {
+				t.Errorf("EndCursor = %q, want %q", end, tt.wantEnd)
+			}
+		})
+	}
+}
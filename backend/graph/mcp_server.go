@@ -0,0 +1,364 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arandu-ai/arandu/audit"
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/executor"
+	"github.com/arandu-ai/arandu/mcp"
+	"github.com/arandu-ai/arandu/models"
+)
+
+const (
+	// mcpDefaultExecTimeout and mcpMaxExecTimeout bound exec_in_sandbox
+	mcpDefaultExecTimeout = time.Minute
+	mcpMaxExecTimeout     = 10 * time.Minute
+	// mcpDefaultStatusTasks is how many recent tasks get_flow_status returns
+	mcpDefaultStatusTasks = 5
+	mcpMaxStatusTasks     = 50
+	// mcpMaxOutput caps the text returned by a tool
+	mcpMaxOutput = 100_000
+)
+
+// NewMCPServer exposes flows and their sandboxes as MCP tools, so other
+// agents can delegate work to Arandu. The tools use the same resolvers as
+// the GraphQL API
+func NewMCPServer(db *database.Queries) *mcp.ToolServer {
+	r := &Resolver{Db: db}
+	s := mcp.NewToolServer("arandu", "1.0.0")
+
+	s.AddTool(mcp.Tool{
+		Name:        "create_flow",
+		Description: "Create a new Arandu flow: an agent with its own Docker sandbox. Send the task in message to start it. Without a model the first available one is used.",
+		InputSchema: objectSchema(map[string]any{
+			"message":        stringProperty("Task for the agent; the flow starts working on it right away"),
+			"model_provider": stringProperty("Model provider, e.g. openai or ollama"),
+			"model_id":       stringProperty("Model ID"),
+			"mcp_servers": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "MCP servers the flow may use; omit for the defaults",
+			},
+		}),
+	}, r.audited("create_flow", r.mcpCreateFlow))
+
+	s.AddTool(mcp.Tool{
+		Name:        "send_message",
+		Description: "Send a message to a running flow: a new task or an answer to a question the agent asked.",
+		InputSchema: objectSchema(map[string]any{
+			"flow_id": integerProperty("Flow ID"),
+			"message": stringProperty("Message for the agent"),
+		}, "flow_id", "message"),
+	}, r.audited("send_message", r.mcpSendMessage))
+
+	s.AddTool(mcp.Tool{
+		Name:        "get_flow_status",
+		Description: "Get the status of a flow and its most recent tasks with their results.",
+		InputSchema: objectSchema(map[string]any{
+			"flow_id": integerProperty("Flow ID"),
+			"tasks":   integerProperty(fmt.Sprintf("Number of recent tasks to return (default %d, max %d)", mcpDefaultStatusTasks, mcpMaxStatusTasks)),
+		}, "flow_id"),
+	}, r.audited("get_flow_status", r.mcpGetFlowStatus))
+
+	s.AddTool(mcp.Tool{
+		Name:        "read_workspace_file",
+		Description: "Read a file from the sandbox of a running flow.",
+		InputSchema: objectSchema(map[string]any{
+			"flow_id": integerProperty("Flow ID"),
+			"path":    stringProperty("File path, relative to the /app working directory"),
+		}, "flow_id", "path"),
+	}, r.audited("read_workspace_file", r.mcpReadWorkspaceFile))
+
+	s.AddTool(mcp.Tool{
+		Name:        "exec_in_sandbox",
+		Description: "Run a shell command in the sandbox of a running flow and return its output. Fails while the flow is processing tasks or when the command policy denies the command or requires approval.",
+		InputSchema: objectSchema(map[string]any{
+			"flow_id":         integerProperty("Flow ID"),
+			"command":         stringProperty("Command, run with sh -c"),
+			"timeout_seconds": integerProperty(fmt.Sprintf("Maximum run time (default %d, max %d)", int(mcpDefaultExecTimeout.Seconds()), int(mcpMaxExecTimeout.Seconds()))),
+		}, "flow_id", "command"),
+	}, r.audited("exec_in_sandbox", r.mcpExecInSandbox))
+
+	return s
+}
+
+// audited records every call of an MCP tool in the audit log
+func (r *Resolver) audited(name string, handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, raw json.RawMessage) (mcp.CallResult, error) {
+		result, err := handler(ctx, raw)
+
+		var args struct {
+			FlowID int64 `json:"flow_id"`
+		}
+		_ = json.Unmarshal(raw, &args)
+		var details map[string]any
+		_ = json.Unmarshal(raw, &details)
+
+		event := audit.Event{Action: audit.ActionMCPToolCalled, FlowID: args.FlowID, Target: name, Details: details, Err: err}
+		if err == nil && result.IsError {
+			event.Err = errors.New(result.Text())
+		}
+		audit.Record(ctx, r.Db, event)
+
+		return result, err
+	}
+}
+
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+func integerProperty(description string) map[string]any {
+	return map[string]any{"type": "integer", "description": description}
+}
+
+// decodeMCPArgs decodes the arguments of a tool call
+func decodeMCPArgs[T any](raw json.RawMessage) (T, error) {
+	var args T
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return args, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return args, nil
+}
+
+// jsonResult returns v as indented JSON text
+func jsonResult(v any) (mcp.CallResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+	return mcp.TextResult(string(data)), nil
+}
+
+// truncateOutput keeps the start of long outputs
+func truncateOutput(s string) string {
+	if len(s) <= mcpMaxOutput {
+		return s
+	}
+	return s[:mcpMaxOutput] + fmt.Sprintf("\n[output truncated, %d bytes omitted]", len(s)-mcpMaxOutput)
+}
+
+// runningFlow returns the flow if it can still receive work and the user of
+// the request has at least the role on it
+func (r *Resolver) runningFlow(ctx context.Context, flowID uint, role string) (database.ReadFlowRow, error) {
+	if flowID == 0 {
+		return database.ReadFlowRow{}, fmt.Errorf("flow_id is required")
+	}
+	flow, err := r.Db.ReadFlow(ctx, int64(flowID))
+	if err != nil {
+		return database.ReadFlowRow{}, fmt.Errorf("flow %d not found", flowID)
+	}
+	if auth.Enabled() {
+		if err := checkFlowRole(ctx, r.Db, flowID, flowAccess(flow), role); err != nil {
+			return database.ReadFlowRow{}, err
+		}
+	}
+	if flow.Status.String != string(models.FlowInProgress) {
+		return database.ReadFlowRow{}, fmt.Errorf("flow %d is %s", flowID, flow.Status.String)
+	}
+	return flow, nil
+}
+
+type mcpFlowSummary struct {
+	FlowID uint   `json:"flowId"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Model  string `json:"model"`
+	TaskID uint   `json:"taskId,omitempty"`
+}
+
+func (r *Resolver) mcpCreateFlow(ctx context.Context, raw json.RawMessage) (mcp.CallResult, error) {
+	args, err := decodeMCPArgs[struct {
+		Message       string   `json:"message"`
+		ModelProvider string   `json:"model_provider"`
+		ModelID       string   `json:"model_id"`
+		MCPServers    []string `json:"mcp_servers"`
+	}](raw)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+
+	if args.ModelProvider == "" && args.ModelID == "" {
+		available, err := r.Query().AvailableModels(ctx)
+		if err != nil {
+			return mcp.CallResult{}, err
+		}
+		if len(available) == 0 {
+			return mcp.CallResult{}, fmt.Errorf("no models are configured")
+		}
+		args.ModelProvider = available[0].Provider
+		args.ModelID = available[0].ID
+	}
+
+	flow, err := r.Mutation().CreateFlow(ctx, args.ModelProvider, args.ModelID, nil, args.MCPServers)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+
+	summary := mcpFlowSummary{
+		FlowID: flow.ID,
+		Name:   flow.Name,
+		Status: string(flow.Status),
+		Model:  flow.Model.Provider + "/" + flow.Model.ID,
+	}
+	if args.Message != "" {
+		task, err := r.Mutation().CreateTask(ctx, flow.ID, args.Message)
+		if err != nil {
+			return mcp.CallResult{}, err
+		}
+		summary.TaskID = task.ID
+	}
+	return jsonResult(summary)
+}
+
+func (r *Resolver) mcpSendMessage(ctx context.Context, raw json.RawMessage) (mcp.CallResult, error) {
+	args, err := decodeMCPArgs[struct {
+		FlowID  uint   `json:"flow_id"`
+		Message string `json:"message"`
+	}](raw)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+	if args.Message == "" {
+		return mcp.CallResult{}, fmt.Errorf("message is required")
+	}
+	if _, err := r.runningFlow(ctx, args.FlowID, auth.TeamOperator); err != nil {
+		return mcp.CallResult{}, err
+	}
+
+	task, err := r.Mutation().CreateTask(ctx, args.FlowID, args.Message)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+	return jsonResult(map[string]uint{"flowId": args.FlowID, "taskId": task.ID})
+}
+
+type mcpTaskStatus struct {
+	ID      uint   `json:"id"`
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Results string `json:"results,omitempty"`
+}
+
+func (r *Resolver) mcpGetFlowStatus(ctx context.Context, raw json.RawMessage) (mcp.CallResult, error) {
+	args, err := decodeMCPArgs[struct {
+		FlowID uint `json:"flow_id"`
+		Tasks  int  `json:"tasks"`
+	}](raw)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+	if args.FlowID == 0 {
+		return mcp.CallResult{}, fmt.Errorf("flow_id is required")
+	}
+	if args.Tasks <= 0 {
+		args.Tasks = mcpDefaultStatusTasks
+	}
+	args.Tasks = min(args.Tasks, mcpMaxStatusTasks)
+
+	flow, err := r.Query().Flow(ctx, args.FlowID)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+
+	tasks := flow.Tasks[max(0, len(flow.Tasks)-args.Tasks):]
+	status := struct {
+		mcpFlowSummary
+		Sandbox    string          `json:"sandbox,omitempty"`
+		BrowserURL string          `json:"browserUrl,omitempty"`
+		Tasks      []mcpTaskStatus `json:"tasks"`
+	}{
+		mcpFlowSummary: mcpFlowSummary{
+			FlowID: flow.ID,
+			Name:   flow.Name,
+			Status: string(flow.Status),
+			Model:  flow.Model.Provider + "/" + flow.Model.ID,
+		},
+		Tasks: make([]mcpTaskStatus, len(tasks)),
+	}
+	if flow.Terminal != nil {
+		status.Sandbox = flow.Terminal.ContainerName
+	}
+	if flow.Browser != nil {
+		status.BrowserURL = flow.Browser.URL
+	}
+	for i, t := range tasks {
+		results := t.Results
+		if len(results) > executor.MaxResultsLength {
+			results = results[len(results)-executor.MaxResultsLength:]
+		}
+		status.Tasks[i] = mcpTaskStatus{
+			ID:      t.ID,
+			Type:    string(t.Type),
+			Status:  string(t.Status),
+			Message: t.Message,
+			Results: results,
+		}
+	}
+	return jsonResult(status)
+}
+
+func (r *Resolver) mcpReadWorkspaceFile(ctx context.Context, raw json.RawMessage) (mcp.CallResult, error) {
+	args, err := decodeMCPArgs[struct {
+		FlowID uint   `json:"flow_id"`
+		Path   string `json:"path"`
+	}](raw)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+	if args.Path == "" {
+		return mcp.CallResult{}, fmt.Errorf("path is required")
+	}
+	if _, err := r.runningFlow(ctx, args.FlowID, auth.TeamViewer); err != nil {
+		return mcp.CallResult{}, err
+	}
+
+	content, err := executor.ReadFile(int64(args.FlowID), args.Path, r.Db)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+	return mcp.TextResult(truncateOutput(content)), nil
+}
+
+func (r *Resolver) mcpExecInSandbox(ctx context.Context, raw json.RawMessage) (mcp.CallResult, error) {
+	args, err := decodeMCPArgs[struct {
+		FlowID         uint   `json:"flow_id"`
+		Command        string `json:"command"`
+		TimeoutSeconds int    `json:"timeout_seconds"`
+	}](raw)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+	if args.Command == "" {
+		return mcp.CallResult{}, fmt.Errorf("command is required")
+	}
+	if _, err := r.runningFlow(ctx, args.FlowID, auth.TeamOperator); err != nil {
+		return mcp.CallResult{}, err
+	}
+
+	timeout := mcpDefaultExecTimeout
+	if args.TimeoutSeconds > 0 {
+		timeout = min(time.Duration(args.TimeoutSeconds)*time.Second, mcpMaxExecTimeout)
+	}
+
+	output, err := executor.ExecUserCommand(ctx, int64(args.FlowID), args.Command, timeout, r.Db)
+	if err != nil {
+		return mcp.CallResult{}, err
+	}
+	return mcp.TextResult(truncateOutput(output)), nil
+}
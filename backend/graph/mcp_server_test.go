@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/mcp"
+)
+
+func TestNewMCPServerTools(t *testing.T) {
+	s := NewMCPServer(nil)
+
+	var names []string
+	for _, tool := range s.Tools() {
+		names = append(names, tool.Name)
+		if tool.Description == "" || tool.InputSchema["type"] != "object" {
+			t.Errorf("tool %s has no description or schema", tool.Name)
+		}
+	}
+
+	want := "create_flow,exec_in_sandbox,get_flow_status,read_workspace_file,send_message"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("Tools() = %s, want %s", got, want)
+	}
+}
+
+// TestMCPToolArgsValidation checks the arguments rejected before touching the database
+func TestMCPToolArgsValidation(t *testing.T) {
+	r := &Resolver{Db: nil}
+
+	tests := []struct {
+		name    string
+		handler mcp.ToolHandler
+		args    string
+		wantErr string
+	}{
+		{name: "create_flow model without provider", handler: r.mcpCreateFlow, args: `{"model_id": "gpt-4o"}`, wantErr: "model is required"},
+		{name: "create_flow unknown mcp server", handler: r.mcpCreateFlow, args: `{"model_provider": "openai", "model_id": "gpt-4o", "mcp_servers": ["nope"]}`, wantErr: "invalid mcp servers"},
+		{name: "send_message without message", handler: r.mcpSendMessage, args: `{"flow_id": 1}`, wantErr: "message is required"},
+		{name: "send_message without flow", handler: r.mcpSendMessage, args: `{"message": "hi"}`, wantErr: "flow_id is required"},
+		{name: "get_flow_status without flow", handler: r.mcpGetFlowStatus, args: `{}`, wantErr: "flow_id is required"},
+		{name: "read_workspace_file without path", handler: r.mcpReadWorkspaceFile, args: `{"flow_id": 1}`, wantErr: "path is required"},
+		{name: "exec_in_sandbox without command", handler: r.mcpExecInSandbox, args: `{"flow_id": 1}`, wantErr: "command is required"},
+		{name: "wrong argument type", handler: r.mcpExecInSandbox, args: `{"flow_id": "one"}`, wantErr: "invalid arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.handler(context.Background(), json.RawMessage(tt.args))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	if got := truncateOutput("short"); got != "short" {
+		t.Errorf("truncateOutput(short) = %q", got)
+	}
+
+	long := strings.Repeat("a", mcpMaxOutput+10)
+	got := truncateOutput(long)
+	if !strings.HasPrefix(got, strings.Repeat("a", mcpMaxOutput)) || !strings.HasSuffix(got, "[output truncated, 10 bytes omitted]") {
+		t.Errorf("truncateOutput(long) suffix = %q", got[mcpMaxOutput:])
+	}
+}
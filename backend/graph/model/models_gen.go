@@ -10,19 +10,114 @@ import (
 	"time"
 )
 
+type APIToken struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Scope      TokenScope `json:"scope"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+type AuditEvent struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UserID    *uint     `json:"userId,omitempty"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	FlowID    *uint     `json:"flowId,omitempty"`
+	TaskID    *uint     `json:"taskId,omitempty"`
+	Target    string    `json:"target"`
+	Details   string    `json:"details"`
+	Error     *string   `json:"error,omitempty"`
+	PrevHash  string    `json:"prevHash"`
+	Hash      string    `json:"hash"`
+}
+
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Events   int    `json:"events"`
+	BrokenAt *uint  `json:"brokenAt,omitempty"`
+	Head     string `json:"head"`
+}
+
 type Browser struct {
 	URL           string `json:"url"`
 	ScreenshotURL string `json:"screenshotUrl"`
 }
 
+type CommandPolicy struct {
+	Default   CommandAction  `json:"default"`
+	Rules     []*CommandRule `json:"rules"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+type CommandPolicyInput struct {
+	Default *CommandAction      `json:"default,omitempty"`
+	Rules   []*CommandRuleInput `json:"rules"`
+}
+
+type CommandRule struct {
+	Name    string        `json:"name"`
+	Command string        `json:"command"`
+	Args    *string       `json:"args,omitempty"`
+	PipedTo *string       `json:"pipedTo,omitempty"`
+	Action  CommandAction `json:"action"`
+	Reason  *string       `json:"reason,omitempty"`
+}
+
+type CommandRuleInput struct {
+	Name    *string       `json:"name,omitempty"`
+	Command string        `json:"command"`
+	Args    *string       `json:"args,omitempty"`
+	PipedTo *string       `json:"pipedTo,omitempty"`
+	Action  CommandAction `json:"action"`
+	Reason  *string       `json:"reason,omitempty"`
+}
+
+type ContainerPoolStatus struct {
+	Image     string  `json:"image"`
+	Target    int     `json:"target"`
+	Idle      int     `json:"idle"`
+	Starting  int     `json:"starting"`
+	LastError *string `json:"lastError,omitempty"`
+}
+
 type Flow struct {
-	ID       uint       `json:"id"`
-	Name     string     `json:"name"`
-	Tasks    []*Task    `json:"tasks"`
-	Terminal *Terminal  `json:"terminal"`
-	Browser  *Browser   `json:"browser"`
-	Status   FlowStatus `json:"status"`
-	Model    *Model     `json:"model"`
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Tasks     []*Task    `json:"tasks"`
+	Terminal  *Terminal  `json:"terminal"`
+	Browser   *Browser   `json:"browser"`
+	Status    FlowStatus `json:"status"`
+	Model     *Model     `json:"model"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	TeamID    *uint      `json:"teamId,omitempty"`
+}
+
+type FlowConnection struct {
+	Edges      []*FlowEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type FlowEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Flow  `json:"node"`
+}
+
+type FlowFilter struct {
+	Status        *FlowStatus `json:"status,omitempty"`
+	ModelProvider *string     `json:"modelProvider,omitempty"`
+	Model         *string     `json:"model,omitempty"`
+	OwnerID       *uint       `json:"ownerId,omitempty"`
+	TeamID        *uint       `json:"teamId,omitempty"`
+	CreatedAfter  *time.Time  `json:"createdAfter,omitempty"`
+	CreatedBefore *time.Time  `json:"createdBefore,omitempty"`
+}
+
+type FlowSort struct {
+	Field FlowSortField `json:"field"`
+	Order SortOrder     `json:"order"`
 }
 
 type Log struct {
@@ -30,6 +125,29 @@ type Log struct {
 	Text string `json:"text"`
 }
 
+type LogConnection struct {
+	Edges      []*LogEdge `json:"edges"`
+	PageInfo   *PageInfo  `json:"pageInfo"`
+	TotalCount int        `json:"totalCount"`
+}
+
+type LogEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Log   `json:"node"`
+}
+
+type LogFilter struct {
+	Type          *LogType   `json:"type,omitempty"`
+	CreatedAfter  *time.Time `json:"createdAfter,omitempty"`
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+type McpServer struct {
+	Name      string `json:"name"`
+	Transport string `json:"transport"`
+	Default   bool   `json:"default"`
+}
+
 type Model struct {
 	Provider string `json:"provider"`
 	ID       string `json:"id"`
@@ -38,20 +156,99 @@ type Model struct {
 type Mutation struct {
 }
 
+type NewAPIToken struct {
+	Token    string    `json:"token"`
+	APIToken *APIToken `json:"apiToken"`
+}
+
+type PageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor,omitempty"`
+}
+
 type Query struct {
 }
 
+type SandboxInput struct {
+	MemoryMb       *int                `json:"memoryMb,omitempty"`
+	Cpus           *float64            `json:"cpus,omitempty"`
+	PidsLimit      *int                `json:"pidsLimit,omitempty"`
+	DiskQuota      *string             `json:"diskQuota,omitempty"`
+	ReadOnlyRootfs *bool               `json:"readOnlyRootfs,omitempty"`
+	NetworkMode    *SandboxNetworkMode `json:"networkMode,omitempty"`
+}
+
+type Screenshot struct {
+	ID            uint      `json:"id"`
+	TaskID        uint      `json:"taskId"`
+	URL           string    `json:"url"`
+	ScreenshotURL string    `json:"screenshotUrl"`
+	Sha256        string    `json:"sha256"`
+	Size          int       `json:"size"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type Secret struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	TeamID    *uint     `json:"teamId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Snapshot struct {
+	ID        uint      `json:"id"`
+	TaskID    uint      `json:"taskId"`
+	Image     string    `json:"image"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type Subscription struct {
 }
 
 type Task struct {
-	ID        uint       `json:"id"`
-	Message   string     `json:"message"`
-	CreatedAt time.Time  `json:"createdAt"`
-	Type      TaskType   `json:"type"`
-	Status    TaskStatus `json:"status"`
-	Args      string     `json:"args"`
-	Results   string     `json:"results"`
+	ID             uint          `json:"id"`
+	Message        string        `json:"message"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	Type           TaskType      `json:"type"`
+	Status         TaskStatus    `json:"status"`
+	Args           string        `json:"args"`
+	Results        string        `json:"results"`
+	Redactions     string        `json:"redactions"`
+	Untrusted      bool          `json:"untrusted"`
+	InjectionFlags []string      `json:"injectionFlags"`
+	Approval       *TaskApproval `json:"approval,omitempty"`
+}
+
+type TaskConnection struct {
+	Edges      []*TaskEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type TaskEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Task  `json:"node"`
+}
+
+type TaskFilter struct {
+	Status        *TaskStatus `json:"status,omitempty"`
+	Type          *TaskType   `json:"type,omitempty"`
+	CreatedAfter  *time.Time  `json:"createdAfter,omitempty"`
+	CreatedBefore *time.Time  `json:"createdBefore,omitempty"`
+}
+
+type Team struct {
+	ID        uint          `json:"id"`
+	Name      string        `json:"name"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Members   []*TeamMember `json:"members"`
+}
+
+type TeamMember struct {
+	User *User    `json:"user"`
+	Role TeamRole `json:"role"`
 }
 
 type Terminal struct {
@@ -60,6 +257,125 @@ type Terminal struct {
 	Logs          []*Log `json:"logs"`
 }
 
+type User struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Admin     bool      `json:"admin"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CommandAction string
+
+const (
+	CommandActionAllow           CommandAction = "allow"
+	CommandActionDeny            CommandAction = "deny"
+	CommandActionRequireApproval CommandAction = "requireApproval"
+)
+
+var AllCommandAction = []CommandAction{
+	CommandActionAllow,
+	CommandActionDeny,
+	CommandActionRequireApproval,
+}
+
+func (e CommandAction) IsValid() bool {
+	switch e {
+	case CommandActionAllow, CommandActionDeny, CommandActionRequireApproval:
+		return true
+	}
+	return false
+}
+
+func (e CommandAction) String() string {
+	return string(e)
+}
+
+func (e *CommandAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommandAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommandAction", str)
+	}
+	return nil
+}
+
+func (e CommandAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CommandAction) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CommandAction) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type FlowSortField string
+
+const (
+	FlowSortFieldCreatedAt FlowSortField = "createdAt"
+	FlowSortFieldName      FlowSortField = "name"
+)
+
+var AllFlowSortField = []FlowSortField{
+	FlowSortFieldCreatedAt,
+	FlowSortFieldName,
+}
+
+func (e FlowSortField) IsValid() bool {
+	switch e {
+	case FlowSortFieldCreatedAt, FlowSortFieldName:
+		return true
+	}
+	return false
+}
+
+func (e FlowSortField) String() string {
+	return string(e)
+}
+
+func (e *FlowSortField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FlowSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FlowSortField", str)
+	}
+	return nil
+}
+
+func (e FlowSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FlowSortField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FlowSortField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type FlowStatus string
 
 const (
@@ -115,6 +431,230 @@ func (e FlowStatus) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type LogType string
+
+const (
+	LogTypeInput  LogType = "input"
+	LogTypeOutput LogType = "output"
+)
+
+var AllLogType = []LogType{
+	LogTypeInput,
+	LogTypeOutput,
+}
+
+func (e LogType) IsValid() bool {
+	switch e {
+	case LogTypeInput, LogTypeOutput:
+		return true
+	}
+	return false
+}
+
+func (e LogType) String() string {
+	return string(e)
+}
+
+func (e *LogType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LogType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LogType", str)
+	}
+	return nil
+}
+
+func (e LogType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *LogType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e LogType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SandboxNetworkMode string
+
+const (
+	SandboxNetworkModeNone   SandboxNetworkMode = "none"
+	SandboxNetworkModeEgress SandboxNetworkMode = "egress"
+	SandboxNetworkModeFull   SandboxNetworkMode = "full"
+)
+
+var AllSandboxNetworkMode = []SandboxNetworkMode{
+	SandboxNetworkModeNone,
+	SandboxNetworkModeEgress,
+	SandboxNetworkModeFull,
+}
+
+func (e SandboxNetworkMode) IsValid() bool {
+	switch e {
+	case SandboxNetworkModeNone, SandboxNetworkModeEgress, SandboxNetworkModeFull:
+		return true
+	}
+	return false
+}
+
+func (e SandboxNetworkMode) String() string {
+	return string(e)
+}
+
+func (e *SandboxNetworkMode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SandboxNetworkMode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SandboxNetworkMode", str)
+	}
+	return nil
+}
+
+func (e SandboxNetworkMode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SandboxNetworkMode) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SandboxNetworkMode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+var AllSortOrder = []SortOrder{
+	SortOrderAsc,
+	SortOrderDesc,
+}
+
+func (e SortOrder) IsValid() bool {
+	switch e {
+	case SortOrderAsc, SortOrderDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortOrder) String() string {
+	return string(e)
+}
+
+func (e *SortOrder) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortOrder", str)
+	}
+	return nil
+}
+
+func (e SortOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SortOrder) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SortOrder) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TaskApproval string
+
+const (
+	TaskApprovalPending  TaskApproval = "pending"
+	TaskApprovalApproved TaskApproval = "approved"
+	TaskApprovalRejected TaskApproval = "rejected"
+)
+
+var AllTaskApproval = []TaskApproval{
+	TaskApprovalPending,
+	TaskApprovalApproved,
+	TaskApprovalRejected,
+}
+
+func (e TaskApproval) IsValid() bool {
+	switch e {
+	case TaskApprovalPending, TaskApprovalApproved, TaskApprovalRejected:
+		return true
+	}
+	return false
+}
+
+func (e TaskApproval) String() string {
+	return string(e)
+}
+
+func (e *TaskApproval) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TaskApproval(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TaskApproval", str)
+	}
+	return nil
+}
+
+func (e TaskApproval) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TaskApproval) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TaskApproval) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type TaskStatus string
 
 const (
@@ -180,23 +720,29 @@ const (
 	TaskTypeInput    TaskType = "input"
 	TaskTypeTerminal TaskType = "terminal"
 	TaskTypeBrowser  TaskType = "browser"
+	TaskTypeSearch   TaskType = "search"
 	TaskTypeCode     TaskType = "code"
 	TaskTypeAsk      TaskType = "ask"
 	TaskTypeDone     TaskType = "done"
+	TaskTypeCustom   TaskType = "custom"
+	TaskTypeMcp      TaskType = "mcp"
 )
 
 var AllTaskType = []TaskType{
 	TaskTypeInput,
 	TaskTypeTerminal,
 	TaskTypeBrowser,
+	TaskTypeSearch,
 	TaskTypeCode,
 	TaskTypeAsk,
 	TaskTypeDone,
+	TaskTypeCustom,
+	TaskTypeMcp,
 }
 
 func (e TaskType) IsValid() bool {
 	switch e {
-	case TaskTypeInput, TaskTypeTerminal, TaskTypeBrowser, TaskTypeCode, TaskTypeAsk, TaskTypeDone:
+	case TaskTypeInput, TaskTypeTerminal, TaskTypeBrowser, TaskTypeSearch, TaskTypeCode, TaskTypeAsk, TaskTypeDone, TaskTypeCustom, TaskTypeMcp:
 		return true
 	}
 	return false
@@ -236,3 +782,115 @@ func (e TaskType) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type TeamRole string
+
+const (
+	TeamRoleViewer   TeamRole = "viewer"
+	TeamRoleOperator TeamRole = "operator"
+	TeamRoleAdmin    TeamRole = "admin"
+)
+
+var AllTeamRole = []TeamRole{
+	TeamRoleViewer,
+	TeamRoleOperator,
+	TeamRoleAdmin,
+}
+
+func (e TeamRole) IsValid() bool {
+	switch e {
+	case TeamRoleViewer, TeamRoleOperator, TeamRoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e TeamRole) String() string {
+	return string(e)
+}
+
+func (e *TeamRole) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TeamRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TeamRole", str)
+	}
+	return nil
+}
+
+func (e TeamRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TeamRole) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TeamRole) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TokenScope string
+
+const (
+	TokenScopeReadOnly TokenScope = "readOnly"
+	TokenScopeOperator TokenScope = "operator"
+)
+
+var AllTokenScope = []TokenScope{
+	TokenScopeReadOnly,
+	TokenScopeOperator,
+}
+
+func (e TokenScope) IsValid() bool {
+	switch e {
+	case TokenScopeReadOnly, TokenScopeOperator:
+		return true
+	}
+	return false
+}
+
+func (e TokenScope) String() string {
+	return string(e)
+}
+
+func (e *TokenScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TokenScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TokenScope", str)
+	}
+	return nil
+}
+
+func (e TokenScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TokenScope) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TokenScope) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -3,6 +3,8 @@ package graph
 import (
 	"context"
 	"testing"
+
+	gmodel "github.com/arandu-ai/arandu/graph/model"
 )
 
 func TestResolverNotNil(t *testing.T) {
@@ -63,22 +65,35 @@ func TestCreateFlowValidation(t *testing.T) {
 	ctx := context.Background()
 
 	// Test with empty model
-	_, err := mutationResolver.CreateFlow(ctx, "", "")
+	_, err := mutationResolver.CreateFlow(ctx, "", "", nil, nil)
 	if err == nil {
 		t.Error("CreateFlow should return error for empty model")
 	}
 
 	// Test with empty provider
-	_, err = mutationResolver.CreateFlow(ctx, "", "gpt-4o")
+	_, err = mutationResolver.CreateFlow(ctx, "", "gpt-4o", nil, nil)
 	if err == nil {
 		t.Error("CreateFlow should return error for empty provider")
 	}
 
 	// Test with empty model id
-	_, err = mutationResolver.CreateFlow(ctx, "openai", "")
+	_, err = mutationResolver.CreateFlow(ctx, "openai", "", nil, nil)
 	if err == nil {
 		t.Error("CreateFlow should return error for empty model id")
 	}
+
+	// Test with invalid sandbox limits
+	memory := -1
+	_, err = mutationResolver.CreateFlow(ctx, "openai", "gpt-4o", &gmodel.SandboxInput{MemoryMb: &memory}, nil)
+	if err == nil {
+		t.Error("CreateFlow should return error for negative memory limit")
+	}
+
+	// Test with an MCP server that is not configured
+	_, err = mutationResolver.CreateFlow(ctx, "openai", "gpt-4o", nil, []string{"not-configured"})
+	if err == nil {
+		t.Error("CreateFlow should return error for unknown mcp servers")
+	}
 }
 
 // Integration tests would require a test database
@@ -10,28 +10,63 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/arandu-ai/arandu/audit"
+	"github.com/arandu-ai/arandu/auth"
 	"github.com/arandu-ai/arandu/config"
 	"github.com/arandu-ai/arandu/database"
 	"github.com/arandu-ai/arandu/executor"
 	gmodel "github.com/arandu-ai/arandu/graph/model"
 	"github.com/arandu-ai/arandu/graph/subscriptions"
 	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/mcp"
 	"github.com/arandu-ai/arandu/models"
+	"github.com/arandu-ai/arandu/secrets"
 )
 
 // CreateFlow is the resolver for the createFlow field.
-func (r *mutationResolver) CreateFlow(ctx context.Context, modelProvider string, modelID string) (*gmodel.Flow, error) {
+func (r *mutationResolver) CreateFlow(ctx context.Context, modelProvider string, modelID string, sandbox *gmodel.SandboxInput, mcpServers []string) (*gmodel.Flow, error) {
 	if modelID == "" || modelProvider == "" {
 		return nil, fmt.Errorf("model is required")
 	}
 
+	// Per-flow sandbox overrides; defaults are applied when the container is spawned
+	var sandboxConfig sql.NullString
+	if sandbox != nil {
+		limits := executor.SandboxInputToLimits(sandbox)
+		if err := limits.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid sandbox config: %w", err)
+		}
+		raw, err := json.Marshal(limits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode sandbox config: %w", err)
+		}
+		sandboxConfig = database.StringToNullString(string(raw))
+	}
+
+	// MCP servers the flow may use; without a list the default servers are used
+	var mcpConfig sql.NullString
+	if mcpServers != nil {
+		if err := mcp.ValidateServerNames(mcpServers); err != nil {
+			return nil, fmt.Errorf("invalid mcp servers: %w", err)
+		}
+		raw, err := json.Marshal(mcpServers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode mcp servers: %w", err)
+		}
+		mcpConfig = database.StringToNullString(string(raw))
+	}
+
 	flow, err := r.Db.CreateFlow(ctx, database.CreateFlowParams{
 		Name:          database.StringToNullString("New Task"),
 		Status:        database.StringToNullString(string(models.FlowInProgress)),
 		Model:         database.StringToNullString(modelID),
 		ModelProvider: database.StringToNullString(modelProvider),
+		Sandbox:       sandboxConfig,
+		McpServers:    mcpConfig,
+		OwnerID:       auth.OwnerID(ctx),
 	})
 
 	if err != nil {
@@ -40,6 +75,12 @@ func (r *mutationResolver) CreateFlow(ctx context.Context, modelProvider string,
 
 	executor.AddQueue(int64(flow.ID), r.Db)
 
+	audit.Record(ctx, r.Db, audit.Event{
+		Action: audit.ActionFlowCreated,
+		FlowID: flow.ID,
+		Target: modelProvider + "/" + modelID,
+	})
+
 	return &gmodel.Flow{
 		ID:     uint(flow.ID),
 		Name:   flow.Name.String,
@@ -53,6 +94,10 @@ func (r *mutationResolver) CreateFlow(ctx context.Context, modelProvider string,
 
 // CreateTask is the resolver for the createTask field.
 func (r *mutationResolver) CreateTask(ctx context.Context, flowID uint, query string) (*gmodel.Task, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamOperator); err != nil {
+		return nil, err
+	}
+
 	type InputTaskArgs struct {
 		Query string `json:"query"`
 	}
@@ -77,20 +122,37 @@ func (r *mutationResolver) CreateTask(ctx context.Context, flowID uint, query st
 
 	executor.AddCommand(int64(flowID), task)
 
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionTaskCreated,
+		FlowID:  int64(flowID),
+		TaskID:  task.ID,
+		Details: map[string]any{"message": query},
+	})
+
 	return &gmodel.Task{
-		ID:        uint(task.ID),
-		Message:   task.Message.String,
-		Type:      gmodel.TaskType(task.Type.String),
-		Status:    gmodel.TaskStatus(task.Status.String),
-		Args:      database.StringToNullString(string(arg)).String,
-		CreatedAt: task.CreatedAt.Time,
+		ID:             uint(task.ID),
+		Message:        task.Message.String,
+		Type:           gmodel.TaskType(task.Type.String),
+		Status:         gmodel.TaskStatus(task.Status.String),
+		Args:           database.StringToNullString(string(arg)).String,
+		CreatedAt:      task.CreatedAt.Time,
+		Redactions:     task.Redactions,
+		InjectionFlags: []string{},
 	}, nil
 }
 
 // FinishFlow is the resolver for the finishFlow field.
 func (r *mutationResolver) FinishFlow(ctx context.Context, flowID uint) (*gmodel.Flow, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamOperator); err != nil {
+		return nil, err
+	}
+
 	// Remove all tasks from the queue
 	executor.CleanQueue(int64(flowID))
+	executor.CloseBrowserContext(int64(flowID))
+	executor.CloseMCPSessions(int64(flowID))
+	// Waits for the task in progress, if any, before dropping the flow lock
+	go executor.ReleaseFlowLock(int64(flowID))
 
 	go func() {
 		// Delete the docker container
@@ -113,6 +175,8 @@ func (r *mutationResolver) FinishFlow(ctx context.Context, flowID uint) (*gmodel
 		ID:     int64(flowID),
 	})
 
+	audit.Record(ctx, r.Db, audit.Event{Action: audit.ActionFlowFinished, FlowID: int64(flowID)})
+
 	// Broadcast flow update
 	subscriptions.BroadcastFlowUpdated(int64(flowID), &gmodel.Flow{
 		ID:     flowID,
@@ -125,8 +189,477 @@ func (r *mutationResolver) FinishFlow(ctx context.Context, flowID uint) (*gmodel
 	}, nil
 }
 
+// ApproveTask is the resolver for the approveTask field.
+func (r *mutationResolver) ApproveTask(ctx context.Context, taskID uint) (*gmodel.Task, error) {
+	task, err := r.authorizeTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err = executor.ApproveTask(task.ID, r.Db)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action: audit.ActionTaskApproved,
+		FlowID: task.FlowID.Int64,
+		TaskID: task.ID,
+		Target: task.Type.String,
+	})
+
+	return executor.TaskToGraphQL(task), nil
+}
+
+// RejectTask is the resolver for the rejectTask field.
+func (r *mutationResolver) RejectTask(ctx context.Context, taskID uint, reason *string) (*gmodel.Task, error) {
+	task, err := r.authorizeTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	var why string
+	if reason != nil {
+		why = *reason
+	}
+	task, err = executor.RejectTask(task.ID, why, r.Db)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionTaskRejected,
+		FlowID:  task.FlowID.Int64,
+		TaskID:  task.ID,
+		Target:  task.Type.String,
+		Details: map[string]any{"reason": why},
+	})
+
+	return executor.TaskToGraphQL(task), nil
+}
+
+// CheckpointFlow is the resolver for the checkpointFlow field.
+func (r *mutationResolver) CheckpointFlow(ctx context.Context, flowID uint) (*gmodel.Snapshot, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamOperator); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := executor.CheckpointFlow(int64(flowID), r.Db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkpoint flow: %w", err)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionFlowCheckpoint,
+		FlowID:  int64(flowID),
+		TaskID:  snapshot.TaskID,
+		Target:  snapshot.Image,
+		Details: map[string]any{"snapshotId": snapshot.ID},
+	})
+
+	return executor.SnapshotToGraphQL(snapshot), nil
+}
+
+// RollbackFlow is the resolver for the rollbackFlow field.
+func (r *mutationResolver) RollbackFlow(ctx context.Context, flowID uint, taskID uint) (*gmodel.Flow, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamOperator); err != nil {
+		return nil, err
+	}
+
+	if _, err := executor.RollbackFlow(int64(flowID), int64(taskID), r.Db); err != nil {
+		return nil, fmt.Errorf("failed to rollback flow: %w", err)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{Action: audit.ActionFlowRolledBack, FlowID: int64(flowID), TaskID: int64(taskID)})
+
+	return r.Query().Flow(ctx, flowID)
+}
+
+// ForkFlow is the resolver for the forkFlow field.
+func (r *mutationResolver) ForkFlow(ctx context.Context, flowID uint, fromTaskID uint, modelProvider *string, modelID *string) (*gmodel.Flow, error) {
+	// The fork keeps the owner and team of the source flow
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamOperator); err != nil {
+		return nil, err
+	}
+
+	var provider, model string
+	if modelProvider != nil {
+		provider = *modelProvider
+	}
+	if modelID != nil {
+		model = *modelID
+	}
+
+	flow, err := executor.ForkFlow(int64(flowID), int64(fromTaskID), provider, model, r.Db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork flow: %w", err)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionFlowForked,
+		FlowID:  flow.ID,
+		Target:  flow.ModelProvider.String + "/" + flow.Model.String,
+		Details: map[string]any{"sourceFlowId": flowID, "fromTaskId": fromTaskID},
+	})
+
+	return r.Query().Flow(ctx, uint(flow.ID))
+}
+
+// CreateUser is the resolver for the createUser field.
+func (r *mutationResolver) CreateUser(ctx context.Context, username string, password string, admin *bool) (*gmodel.User, error) {
+	if _, err := auth.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := auth.CreateUser(ctx, r.Db, username, password, admin != nil && *admin)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionUserCreated,
+		Target:  user.Username,
+		Details: map[string]any{"userId": user.ID, "admin": user.Admin},
+	})
+
+	return executor.UserToGraphQL(user), nil
+}
+
+// ChangePassword is the resolver for the changePassword field.
+func (r *mutationResolver) ChangePassword(ctx context.Context, currentPassword string, newPassword string) (bool, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := auth.ChangePassword(ctx, r.Db, user.ID, currentPassword, newPassword); err != nil {
+		return false, err
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{Action: audit.ActionPasswordChanged, Target: user.Username})
+
+	return true, nil
+}
+
+// CreateAPIToken is the resolver for the createApiToken field.
+func (r *mutationResolver) CreateAPIToken(ctx context.Context, name string, scope *gmodel.TokenScope) (*gmodel.NewAPIToken, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenScope := auth.ScopeOperator
+	if scope != nil {
+		tokenScope = string(*scope)
+	}
+
+	token, apiToken, err := auth.CreateToken(ctx, r.Db, user.ID, name, tokenScope)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionTokenCreated,
+		Target:  apiToken.Name,
+		Details: map[string]any{"tokenId": apiToken.ID, "scope": apiToken.Scope},
+	})
+
+	return &gmodel.NewAPIToken{
+		Token:    token,
+		APIToken: executor.APITokenToGraphQL(apiToken),
+	}, nil
+}
+
+// RotateAPIToken is the resolver for the rotateApiToken field.
+func (r *mutationResolver) RotateAPIToken(ctx context.Context, id uint) (*gmodel.NewAPIToken, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, apiToken, err := auth.RotateToken(ctx, r.Db, user.ID, int64(id))
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionTokenRotated,
+		Target:  apiToken.Name,
+		Details: map[string]any{"tokenId": apiToken.ID},
+	})
+
+	return &gmodel.NewAPIToken{
+		Token:    token,
+		APIToken: executor.APITokenToGraphQL(apiToken),
+	}, nil
+}
+
+// RevokeAPIToken is the resolver for the revokeApiToken field.
+func (r *mutationResolver) RevokeAPIToken(ctx context.Context, id uint) (bool, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := r.Db.DeleteApiToken(ctx, database.DeleteApiTokenParams{
+		ID:     int64(id),
+		UserID: user.ID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke api token: %w", err)
+	}
+	if deleted == 0 {
+		return false, fmt.Errorf("api token %d not found", id)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{Action: audit.ActionTokenRevoked, Details: map[string]any{"tokenId": id}})
+
+	return true, nil
+}
+
+// CreateTeam is the resolver for the createTeam field.
+func (r *mutationResolver) CreateTeam(ctx context.Context, name string) (*gmodel.Team, error) {
+	if _, err := auth.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := auth.ValidateTeamName(name); err != nil {
+		return nil, err
+	}
+
+	team, err := r.Db.CreateTeam(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create team %s: %w", name, err)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{Action: audit.ActionTeamCreated, Target: team.Name, Details: map[string]any{"teamId": team.ID}})
+
+	return executor.TeamToGraphQL(team, nil), nil
+}
+
+// DeleteTeam is the resolver for the deleteTeam field.
+func (r *mutationResolver) DeleteTeam(ctx context.Context, id uint) (bool, error) {
+	if _, err := auth.RequireAdmin(ctx); err != nil {
+		return false, err
+	}
+
+	// Flows shared with the team go back to being private to their owners
+	deleted, err := r.Db.DeleteTeam(ctx, int64(id))
+	if err != nil {
+		return false, fmt.Errorf("failed to delete team: %w", err)
+	}
+	if deleted == 0 {
+		return false, fmt.Errorf("team %d not found", id)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{Action: audit.ActionTeamDeleted, Details: map[string]any{"teamId": id}})
+
+	return true, nil
+}
+
+// SetTeamMember is the resolver for the setTeamMember field.
+func (r *mutationResolver) SetTeamMember(ctx context.Context, teamID uint, userID uint, role gmodel.TeamRole) (*gmodel.Team, error) {
+	if err := auth.RequireTeamAdmin(ctx, r.Db, int64(teamID)); err != nil {
+		return nil, err
+	}
+	if _, err := r.readTeam(ctx, teamID); err != nil {
+		return nil, err
+	}
+	if err := auth.ValidateTeamRole(string(role)); err != nil {
+		return nil, err
+	}
+	if _, err := r.Db.ReadUser(ctx, int64(userID)); err != nil {
+		return nil, fmt.Errorf("user %d not found", userID)
+	}
+
+	err := r.Db.UpsertTeamMember(ctx, database.UpsertTeamMemberParams{
+		TeamID: int64(teamID),
+		UserID: int64(userID),
+		Role:   string(role),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set team member: %w", err)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionTeamMemberSet,
+		Details: map[string]any{"teamId": teamID, "userId": userID, "role": role},
+	})
+
+	return r.readTeam(ctx, teamID)
+}
+
+// RemoveTeamMember is the resolver for the removeTeamMember field.
+func (r *mutationResolver) RemoveTeamMember(ctx context.Context, teamID uint, userID uint) (*gmodel.Team, error) {
+	if err := auth.RequireTeamAdmin(ctx, r.Db, int64(teamID)); err != nil {
+		return nil, err
+	}
+	if _, err := r.readTeam(ctx, teamID); err != nil {
+		return nil, err
+	}
+
+	deleted, err := r.Db.DeleteTeamMember(ctx, database.DeleteTeamMemberParams{
+		TeamID: int64(teamID),
+		UserID: int64(userID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if deleted == 0 {
+		return nil, fmt.Errorf("user %d is not a member of team %d", userID, teamID)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionTeamMemberRemoved,
+		Details: map[string]any{"teamId": teamID, "userId": userID},
+	})
+
+	return r.readTeam(ctx, teamID)
+}
+
+// ShareFlow is the resolver for the shareFlow field.
+func (r *mutationResolver) ShareFlow(ctx context.Context, flowID uint, teamID *uint) (*gmodel.Flow, error) {
+	// Sharing is up to the owner of the flow and the admins of its team
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamAdmin); err != nil {
+		return nil, err
+	}
+
+	var team sql.NullInt64
+	if teamID != nil {
+		if _, err := r.readTeam(ctx, *teamID); err != nil {
+			return nil, err
+		}
+		if auth.Enabled() {
+			if err := r.authorizeShare(ctx, *teamID); err != nil {
+				return nil, err
+			}
+		}
+		team = sql.NullInt64{Int64: int64(*teamID), Valid: true}
+	}
+
+	if _, err := r.Db.UpdateFlowTeam(ctx, database.UpdateFlowTeamParams{TeamID: team, ID: int64(flowID)}); err != nil {
+		return nil, fmt.Errorf("failed to share flow: %w", err)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{Action: audit.ActionFlowShared, FlowID: int64(flowID), Details: map[string]any{"teamId": teamID}})
+
+	flow, err := r.Db.ReadFlow(ctx, int64(flowID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch flow: %w", err)
+	}
+
+	return executor.FlowToGraphQL(flow), nil
+}
+
+// SetSecret is the resolver for the setSecret field.
+func (r *mutationResolver) SetSecret(ctx context.Context, name string, value string, teamID *uint) (*gmodel.Secret, error) {
+	if err := secrets.ValidateName(name); err != nil {
+		return nil, err
+	}
+	if err := secrets.ValidateValue(value); err != nil {
+		return nil, err
+	}
+
+	owner, err := r.secretOwnerFor(ctx, teamID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := secrets.Encrypt(name, value)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := r.saveSecret(ctx, owner, name, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save secret %s: %w", name, err)
+	}
+
+	// The value never reaches the audit log
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionSecretSet,
+		Target:  name,
+		Details: map[string]any{"secretId": secret.ID, "teamId": teamID},
+	})
+
+	return executor.SecretToGraphQL(secret), nil
+}
+
+// DeleteSecret is the resolver for the deleteSecret field.
+func (r *mutationResolver) DeleteSecret(ctx context.Context, id uint) (bool, error) {
+	secret, err := r.Db.ReadSecret(ctx, int64(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("secret %d not found", id)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch secret: %w", err)
+	}
+	if err := r.authorizeSecretDelete(ctx, secret); err != nil {
+		return false, err
+	}
+
+	if _, err := r.Db.DeleteSecret(ctx, secret.ID); err != nil {
+		return false, fmt.Errorf("failed to delete secret: %w", err)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionSecretDeleted,
+		Target:  secret.Name,
+		Details: map[string]any{"secretId": secret.ID},
+	})
+
+	return true, nil
+}
+
+// SetCommandPolicy is the resolver for the setCommandPolicy field.
+func (r *mutationResolver) SetCommandPolicy(ctx context.Context, flowID *uint, teamID *uint, policy *gmodel.CommandPolicyInput) (*gmodel.CommandPolicy, error) {
+	scope, err := r.commandPolicyScopeFor(ctx, flowID, teamID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == nil {
+		if err := r.deleteCommandPolicy(ctx, scope); err != nil {
+			return nil, err
+		}
+		audit.Record(ctx, r.Db, audit.Event{
+			Action:  audit.ActionCommandPolicyDeleted,
+			FlowID:  scope.FlowID.Int64,
+			Details: map[string]any{"teamId": teamID},
+		})
+		return nil, nil
+	}
+
+	commandPolicy := executor.CommandPolicyInputToPolicy(policy)
+	if err := commandPolicy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid command policy: %w", err)
+	}
+	raw, err := json.Marshal(commandPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode command policy: %w", err)
+	}
+
+	row, err := r.saveCommandPolicy(ctx, scope, string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save command policy: %w", err)
+	}
+
+	audit.Record(ctx, r.Db, audit.Event{
+		Action:  audit.ActionCommandPolicySet,
+		FlowID:  scope.FlowID.Int64,
+		Details: map[string]any{"teamId": teamID, "policy": commandPolicy},
+	})
+
+	return executor.CommandPolicyToGraphQL(&commandPolicy, row.UpdatedAt), nil
+}
+
 // Exec is the resolver for the _exec field.
 func (r *mutationResolver) Exec(ctx context.Context, containerID string, command string) (string, error) {
+	if auth.Enabled() {
+		if _, err := auth.RequireAdmin(ctx); err != nil {
+			return "", err
+		}
+	}
+
 	b := bytes.Buffer{}
 	// executor.ExecCommand(containerID, command, &b)
 
@@ -187,9 +720,18 @@ func (r *queryResolver) Flows(ctx context.Context) ([]*gmodel.Flow, error) {
 		return nil, fmt.Errorf("failed to fetch flows: %w", err)
 	}
 
-	gFlows := make([]*gmodel.Flow, len(flows))
-	for i, flow := range flows {
-		gFlows[i] = executor.FlowRowToGraphQL(flow)
+	teams, err := auth.TeamRoles(ctx, r.Db)
+	if err != nil {
+		return nil, err
+	}
+
+	gFlows := make([]*gmodel.Flow, 0, len(flows))
+	for _, flow := range flows {
+		access := auth.FlowAccess{OwnerID: flow.OwnerID, TeamID: flow.TeamID}
+		if !auth.HasRole(auth.RoleOnFlow(ctx, access, teams), auth.TeamViewer) {
+			continue
+		}
+		gFlows = append(gFlows, executor.FlowRowToGraphQL(flow))
 	}
 
 	return gFlows, nil
@@ -201,6 +743,9 @@ func (r *queryResolver) Flow(ctx context.Context, id uint) (*gmodel.Flow, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch flow: %w", err)
 	}
+	if err := checkFlowRole(ctx, r.Db, id, flowAccess(flow), auth.TeamViewer); err != nil {
+		return nil, err
+	}
 
 	tasks, err := r.Db.ReadTasksByFlowId(ctx, sql.NullInt64{Int64: int64(id), Valid: true})
 	if err != nil {
@@ -215,28 +760,291 @@ func (r *queryResolver) Flow(ctx context.Context, id uint) (*gmodel.Flow, error)
 	return executor.FlowToGraphQLFull(flow, tasks, logs), nil
 }
 
+// FlowsConnection is the resolver for the flowsConnection field.
+func (r *queryResolver) FlowsConnection(ctx context.Context, first *int, after *string, filter *gmodel.FlowFilter, sort *gmodel.FlowSort) (*gmodel.FlowConnection, error) {
+	return r.flowsConnection(ctx, first, after, filter, sort)
+}
+
+// TasksConnection is the resolver for the tasksConnection field.
+func (r *queryResolver) TasksConnection(ctx context.Context, flowID uint, first *int, after *string, filter *gmodel.TaskFilter, order *gmodel.SortOrder) (*gmodel.TaskConnection, error) {
+	return r.tasksConnection(ctx, flowID, first, after, filter, order)
+}
+
+// LogsConnection is the resolver for the logsConnection field.
+func (r *queryResolver) LogsConnection(ctx context.Context, flowID uint, first *int, after *string, filter *gmodel.LogFilter, order *gmodel.SortOrder) (*gmodel.LogConnection, error) {
+	return r.logsConnection(ctx, flowID, first, after, filter, order)
+}
+
+// ContainerPool is the resolver for the containerPool field.
+func (r *queryResolver) ContainerPool(ctx context.Context) ([]*gmodel.ContainerPoolStatus, error) {
+	if auth.Enabled() {
+		if _, err := auth.RequireAdmin(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return executor.PoolStatusToGraphQL(executor.ContainerPoolStatus()), nil
+}
+
+// Snapshots is the resolver for the snapshots field.
+func (r *queryResolver) Snapshots(ctx context.Context, flowID uint) ([]*gmodel.Snapshot, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamViewer); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := r.Db.ReadSnapshotsByFlowId(ctx, int64(flowID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch snapshots: %w", err)
+	}
+
+	return executor.SnapshotsToGraphQL(snapshots), nil
+}
+
+// Screenshots is the resolver for the screenshots field.
+func (r *queryResolver) Screenshots(ctx context.Context, flowID uint) ([]*gmodel.Screenshot, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamViewer); err != nil {
+		return nil, err
+	}
+
+	screenshots, err := r.Db.ReadScreenshotsByFlowId(ctx, int64(flowID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch screenshots: %w", err)
+	}
+
+	return executor.ScreenshotsToGraphQL(screenshots), nil
+}
+
+// McpServers is the resolver for the mcpServers field.
+func (r *queryResolver) McpServers(ctx context.Context) ([]*gmodel.McpServer, error) {
+	if auth.Enabled() {
+		if _, err := auth.RequireAdmin(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return executor.MCPServersToGraphQL(mcp.Servers()), nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*gmodel.User, error) {
+	current, err := auth.RequireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := r.Db.ReadUser(ctx, current.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return executor.UserToGraphQL(user), nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context) ([]*gmodel.User, error) {
+	if _, err := auth.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	users, err := r.Db.ReadAllUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	gUsers := make([]*gmodel.User, len(users))
+	for i, user := range users {
+		gUsers[i] = executor.UserToGraphQL(user)
+	}
+
+	return gUsers, nil
+}
+
+// APITokens is the resolver for the apiTokens field.
+func (r *queryResolver) APITokens(ctx context.Context) ([]*gmodel.APIToken, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := r.Db.ReadApiTokensByUserId(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api tokens: %w", err)
+	}
+
+	gTokens := make([]*gmodel.APIToken, len(tokens))
+	for i, token := range tokens {
+		gTokens[i] = executor.APITokenToGraphQL(token)
+	}
+
+	return gTokens, nil
+}
+
+// Teams is the resolver for the teams field.
+func (r *queryResolver) Teams(ctx context.Context) ([]*gmodel.Team, error) {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Admins see every team, other users the teams they belong to
+	var teams []database.Team
+	if user.Admin {
+		teams, err = r.Db.ReadAllTeams(ctx)
+	} else {
+		teams, err = r.Db.ReadTeamsByUserId(ctx, user.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch teams: %w", err)
+	}
+
+	gTeams := make([]*gmodel.Team, len(teams))
+	for i, team := range teams {
+		if gTeams[i], err = r.teamToGraphQL(ctx, team); err != nil {
+			return nil, err
+		}
+	}
+
+	return gTeams, nil
+}
+
+// Secrets is the resolver for the secrets field.
+func (r *queryResolver) Secrets(ctx context.Context, teamID *uint) ([]*gmodel.Secret, error) {
+	owner, err := r.secretOwnerFor(ctx, teamID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []database.Secret
+	if owner.TeamID.Valid {
+		rows, err = r.Db.ReadTeamSecrets(ctx, owner.TeamID)
+	} else {
+		rows, err = r.Db.ReadUserSecrets(ctx, owner.UserID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secrets: %w", err)
+	}
+
+	gSecrets := make([]*gmodel.Secret, len(rows))
+	for i, secret := range rows {
+		gSecrets[i] = executor.SecretToGraphQL(secret)
+	}
+
+	return gSecrets, nil
+}
+
+// CommandPolicy is the resolver for the commandPolicy field.
+func (r *queryResolver) CommandPolicy(ctx context.Context, flowID *uint, teamID *uint) (*gmodel.CommandPolicy, error) {
+	scope, err := r.commandPolicyScopeFor(ctx, flowID, teamID, false)
+	if err != nil {
+		return nil, err
+	}
+	return r.readCommandPolicy(ctx, scope)
+}
+
+// AuditEvents is the resolver for the auditEvents field.
+func (r *queryResolver) AuditEvents(ctx context.Context, flowID *uint, userID *uint, action *string, before *uint, limit *int) ([]*gmodel.AuditEvent, error) {
+	// Admins read the whole log, other users the events of flows they can see
+	if auth.Enabled() {
+		user, err := auth.RequireUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !user.Admin {
+			if flowID == nil {
+				return nil, fmt.Errorf("%w: flowId is required", auth.ErrForbidden)
+			}
+			if err := r.authorizeFlow(ctx, *flowID, auth.TeamViewer); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	params, err := auditEventsParams(flowID, userID, action, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := r.Db.ReadAuditEvents(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit events: %w", err)
+	}
+
+	gEvents := make([]*gmodel.AuditEvent, len(events))
+	for i, event := range events {
+		gEvents[i] = executor.AuditEventToGraphQL(event)
+	}
+
+	return gEvents, nil
+}
+
+// VerifyAuditLog is the resolver for the verifyAuditLog field.
+func (r *queryResolver) VerifyAuditLog(ctx context.Context) (*gmodel.AuditVerification, error) {
+	if auth.Enabled() {
+		if _, err := auth.RequireAdmin(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := audit.Verify(ctx, r.Db)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &gmodel.AuditVerification{
+		Valid:  v.Valid,
+		Events: v.Events,
+		Head:   v.Head,
+	}
+	if !v.Valid {
+		brokenAt := uint(v.BrokenAt)
+		verification.BrokenAt = &brokenAt
+	}
+
+	return verification, nil
+}
+
 // TaskAdded is the resolver for the taskAdded field.
 func (r *subscriptionResolver) TaskAdded(ctx context.Context, flowID uint) (<-chan *gmodel.Task, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamViewer); err != nil {
+		return nil, err
+	}
+
 	return subscriptions.TaskAdded(ctx, int64(flowID))
 }
 
 // TaskUpdated is the resolver for the taskUpdated field.
 func (r *subscriptionResolver) TaskUpdated(ctx context.Context, flowID uint) (<-chan *gmodel.Task, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamViewer); err != nil {
+		return nil, err
+	}
+
 	return subscriptions.TaskUpdated(ctx, int64(flowID))
 }
 
 // FlowUpdated is the resolver for the flowUpdated field.
 func (r *subscriptionResolver) FlowUpdated(ctx context.Context, flowID uint) (<-chan *gmodel.Flow, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamViewer); err != nil {
+		return nil, err
+	}
+
 	return subscriptions.FlowUpdated(ctx, int64(flowID))
 }
 
 // BrowserUpdated is the resolver for the browserUpdated field.
 func (r *subscriptionResolver) BrowserUpdated(ctx context.Context, flowID uint) (<-chan *gmodel.Browser, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamViewer); err != nil {
+		return nil, err
+	}
+
 	return subscriptions.BrowserUpdated(ctx, int64(flowID))
 }
 
 // TerminalLogsAdded is the resolver for the terminalLogsAdded field.
 func (r *subscriptionResolver) TerminalLogsAdded(ctx context.Context, flowID uint) (<-chan *gmodel.Log, error) {
+	if err := r.authorizeFlow(ctx, flowID, auth.TeamViewer); err != nil {
+		return nil, err
+	}
+
 	return subscriptions.TerminalLogsAdded(ctx, int64(flowID))
 }
 
@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/database"
+)
+
+// secretOwner is who a set of secrets belongs to: a team, a user, or without
+// accounts the server, whose secrets go to every flow without an owner
+type secretOwner struct {
+	UserID sql.NullInt64
+	TeamID sql.NullInt64
+}
+
+// secretOwnerFor returns the owner of the secrets a request works with
+// Team secrets are listed by the team's members and managed by its admins
+func (r *Resolver) secretOwnerFor(ctx context.Context, teamID *uint, manage bool) (secretOwner, error) {
+	if teamID == nil {
+		if auth.Enabled() {
+			if _, err := auth.RequireUser(ctx); err != nil {
+				return secretOwner{}, err
+			}
+		}
+		return secretOwner{UserID: auth.OwnerID(ctx)}, nil
+	}
+
+	if manage {
+		if err := auth.RequireTeamAdmin(ctx, r.Db, int64(*teamID)); err != nil {
+			return secretOwner{}, err
+		}
+	} else if err := r.authorizeShare(ctx, *teamID); err != nil {
+		return secretOwner{}, err
+	}
+	if _, err := r.readTeam(ctx, *teamID); err != nil {
+		return secretOwner{}, err
+	}
+	return secretOwner{TeamID: sql.NullInt64{Int64: int64(*teamID), Valid: true}}, nil
+}
+
+// saveSecret stores a sealed value, replacing the owner's secret with the same name
+func (r *Resolver) saveSecret(ctx context.Context, owner secretOwner, name string, sealed []byte) (database.Secret, error) {
+	var existing database.Secret
+	var err error
+	if owner.TeamID.Valid {
+		existing, err = r.Db.ReadTeamSecretByName(ctx, database.ReadTeamSecretByNameParams{TeamID: owner.TeamID, Name: name})
+	} else {
+		existing, err = r.Db.ReadUserSecretByName(ctx, database.ReadUserSecretByNameParams{UserID: owner.UserID, Name: name})
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return r.Db.CreateSecret(ctx, database.CreateSecretParams{
+			Name:   name,
+			Value:  sealed,
+			UserID: owner.UserID,
+			TeamID: owner.TeamID,
+		})
+	case err != nil:
+		return database.Secret{}, fmt.Errorf("failed to read secret: %w", err)
+	}
+	return r.Db.UpdateSecretValue(ctx, database.UpdateSecretValueParams{Value: sealed, ID: existing.ID})
+}
+
+// authorizeSecretDelete checks that the user of the request may delete a
+// secret: personal secrets by their owner, team secrets by the team's admins
+// Secrets of others are reported as missing
+func (r *Resolver) authorizeSecretDelete(ctx context.Context, secret database.Secret) error {
+	notFound := fmt.Errorf("secret %d not found", secret.ID)
+	if secret.TeamID.Valid {
+		if auth.RequireTeamAdmin(ctx, r.Db, secret.TeamID.Int64) != nil {
+			return notFound
+		}
+		return nil
+	}
+
+	owner, err := r.secretOwnerFor(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	if owner.UserID != secret.UserID {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/executor"
+	gmodel "github.com/arandu-ai/arandu/graph/model"
+)
+
+// readTeam returns a team with its members
+func (r *Resolver) readTeam(ctx context.Context, teamID uint) (*gmodel.Team, error) {
+	team, err := r.Db.ReadTeam(ctx, int64(teamID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("team %d not found", teamID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch team: %w", err)
+	}
+	return r.teamToGraphQL(ctx, team)
+}
+
+func (r *Resolver) teamToGraphQL(ctx context.Context, team database.Team) (*gmodel.Team, error) {
+	members, err := r.Db.ReadTeamMembers(ctx, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch team members: %w", err)
+	}
+	return executor.TeamToGraphQL(team, members), nil
+}
+
+// authorizeShare checks that the user of the request may share flows with a
+// team: admins with any team, other users with the teams they belong to
+func (r *Resolver) authorizeShare(ctx context.Context, teamID uint) error {
+	user, err := auth.RequireUser(ctx)
+	if err != nil {
+		return err
+	}
+	if user.Admin {
+		return nil
+	}
+
+	teams, err := auth.TeamRoles(ctx, r.Db)
+	if err != nil {
+		return err
+	}
+	if _, ok := teams[int64(teamID)]; !ok {
+		return fmt.Errorf("%w: you are not a member of team %d", auth.ErrForbidden, teamID)
+	}
+	return nil
+}
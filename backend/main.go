@@ -8,15 +8,23 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/arandu-ai/arandu/assets"
+	"github.com/arandu-ai/arandu/auth"
 	"github.com/arandu-ai/arandu/config"
 	"github.com/arandu-ai/arandu/database"
 	"github.com/arandu-ai/arandu/executor"
 	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/mcp"
+	"github.com/arandu-ai/arandu/oidc"
+	"github.com/arandu-ai/arandu/providers"
 	"github.com/arandu-ai/arandu/router"
+	"github.com/arandu-ai/arandu/search"
+	"github.com/arandu-ai/arandu/secrets"
+	"github.com/arandu-ai/arandu/security"
 	"github.com/arandu-ai/arandu/websocket"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/pressly/goose/v3"
@@ -38,6 +46,13 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	// "arandu egress-proxy" only runs the allow-list proxy, so it can live on
+	// the internal sandbox network without the rest of the server
+	if len(os.Args) > 1 && os.Args[1] == "egress-proxy" {
+		runEgressProxy(sigChan)
+		return
+	}
+
 	// Initialize database with connection pooling
 	db, err := initDatabase()
 	if err != nil {
@@ -54,6 +69,81 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Create the first admin account; without MULTI_USER it can still log in
+	// to create scoped API tokens
+	if config.Config.MultiUser || config.Config.AdminUsername != "" {
+		if err := auth.Bootstrap(context.Background(), queries, config.Config.AdminUsername, config.Config.AdminPassword); err != nil {
+			logging.Error("Failed to create admin user", "error", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	// Discover the identity provider used for single sign-on
+	if config.Config.OIDCIssuerURL != "" {
+		if _, err := auth.ParseRoleMapping(config.Config.OIDCRoleMapping); err != nil {
+			logging.Error("Invalid OIDC role mapping", "error", err.Error())
+			os.Exit(1)
+		}
+		var scopes []string
+		for _, scope := range strings.Split(config.Config.OIDCScopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+		if err := oidc.Init(context.Background(), oidc.Config{
+			IssuerURL:    config.Config.OIDCIssuerURL,
+			ClientID:     config.Config.OIDCClientID,
+			ClientSecret: config.Config.OIDCClientSecret,
+			RedirectURL:  config.Config.OIDCRedirectURL,
+			Scopes:       scopes,
+		}); err != nil {
+			logging.Error("Failed to initialize single sign-on", "error", err.Error())
+			os.Exit(1)
+		}
+		logging.Info("Single sign-on enabled", "issuer", config.Config.OIDCIssuerURL)
+	}
+
+	// Check the key of the secrets vault
+	if err := secrets.ValidateKey(); err != nil {
+		logging.Error("Invalid secrets key", "error", err.Error())
+		os.Exit(1)
+	}
+
+	// Load the Docker image allow-list
+	if config.Config.DockerImagesFile != "" {
+		if err := security.LoadDockerImages(config.Config.DockerImagesFile); err != nil {
+			logging.Error("Failed to load docker images file", "error", err.Error())
+			os.Exit(1)
+		}
+		logging.Info("Docker image allow-list loaded",
+			"file", config.Config.DockerImagesFile,
+			"custom_images", len(security.DescribedDockerImages()),
+		)
+	}
+
+	// Load the command policy of every flow
+	if config.Config.CommandPolicyFile != "" {
+		if err := executor.LoadCommandPolicy(config.Config.CommandPolicyFile); err != nil {
+			logging.Error("Failed to load command policy file", "error", err.Error())
+			os.Exit(1)
+		}
+		logging.Info("Command policy loaded",
+			"file", config.Config.CommandPolicyFile,
+			"rules", len(executor.ServerCommandPolicy().Rules),
+		)
+	}
+
+	if err := executor.ValidateUntrustedContentPolicy(); err != nil {
+		logging.Error("Invalid untrusted content policy", "error", err.Error())
+		os.Exit(1)
+	}
+
+	// Build the output redaction pipeline
+	if err := executor.InitRedaction(); err != nil {
+		logging.Error("Failed to load redaction patterns", "error", err.Error())
+		os.Exit(1)
+	}
+
 	// Initialize assets
 	assets.Init(promptTemplates, scriptTemplates)
 
@@ -69,6 +159,65 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Register the tools declared in the custom tools file
+	if config.Config.CustomToolsFile != "" {
+		if err := providers.LoadCustomTools(config.Config.CustomToolsFile); err != nil {
+			logging.Error("Failed to load custom tools file", "error", err.Error())
+			os.Exit(1)
+		}
+		logging.Info("Custom tools loaded",
+			"file", config.Config.CustomToolsFile,
+			"tools", providers.CustomToolNames(),
+		)
+	}
+
+	// Register the MCP servers flows can use
+	if config.Config.MCPServersFile != "" {
+		if err := mcp.LoadServers(config.Config.MCPServersFile); err != nil {
+			logging.Error("Failed to load MCP servers file", "error", err.Error())
+			os.Exit(1)
+		}
+		logging.Info("MCP servers loaded",
+			"file", config.Config.MCPServersFile,
+			"servers", len(mcp.Servers()),
+			"default", mcp.DefaultServerNames(),
+		)
+	}
+
+	// Configure the search tool backend
+	if config.Config.SearchProvider != "" {
+		if err := search.Init(search.ProviderType(config.Config.SearchProvider), search.Options{
+			URL:    config.Config.SearchURL,
+			APIKey: config.Config.SearchAPIKey,
+		}); err != nil {
+			logging.Error("Failed to initialize search provider", "error", err.Error())
+			os.Exit(1)
+		}
+		logging.Info("Search provider initialized", "provider", config.Config.SearchProvider)
+	}
+
+	// Start warming the container pool
+	if config.Config.ContainerPool != "" {
+		sizes, err := executor.ParsePoolSizes(config.Config.ContainerPool)
+		if err != nil {
+			logging.Error("Invalid container pool configuration", "error", err.Error())
+			os.Exit(1)
+		}
+		if err := executor.InitContainerPool(sizes, queries); err != nil {
+			logging.Error("Failed to initialize container pool", "error", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	// Start the egress allow-list proxy for sandboxes in egress network mode
+	var egressProxy *http.Server
+	if config.Config.SandboxEgressProxyAddr != "" {
+		egressProxy = executor.StartEgressProxy(
+			config.Config.SandboxEgressProxyAddr,
+			executor.ParseEgressAllowList(config.Config.SandboxEgressAllowList),
+		)
+	}
+
 	// Setup HTTP server
 	port := strconv.Itoa(config.Config.Port)
 	r := router.New(queries)
@@ -104,6 +253,12 @@ func main() {
 		logging.Error("HTTP server shutdown error", "error", err.Error())
 	}
 
+	if egressProxy != nil {
+		if err := egressProxy.Shutdown(ctx); err != nil {
+			logging.Error("Egress proxy shutdown error", "error", err.Error())
+		}
+	}
+
 	// Close all WebSocket connections
 	websocket.CloseAll()
 
@@ -115,6 +270,28 @@ func main() {
 	logging.Info("Shutdown complete")
 }
 
+// runEgressProxy sirve solo el proxy de egress hasta recibir una señal de parada
+func runEgressProxy(sigChan chan os.Signal) {
+	if config.Config.SandboxEgressProxyAddr == "" {
+		logging.Error("SANDBOX_EGRESS_PROXY_ADDR is required to run the egress proxy")
+		os.Exit(1)
+	}
+
+	proxy := executor.StartEgressProxy(
+		config.Config.SandboxEgressProxyAddr,
+		executor.ParseEgressAllowList(config.Config.SandboxEgressAllowList),
+	)
+
+	<-sigChan
+	logging.Info("Shutdown signal received")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := proxy.Shutdown(ctx); err != nil {
+		logging.Error("Egress proxy shutdown error", "error", err.Error())
+	}
+}
+
 // initDatabase configura la conexión a SQLite con pooling optimizado
 func initDatabase() (*sql.DB, error) {
 	// SQLite con WAL mode para mejor concurrencia
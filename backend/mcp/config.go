@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TransportType is how the client talks to a server
+type TransportType string
+
+const (
+	// TransportStdio runs the server as a child process of the backend
+	TransportStdio TransportType = "stdio"
+	// TransportHTTP uses the streamable HTTP transport
+	TransportHTTP TransportType = "http"
+)
+
+// DefaultCallTimeout limits a tool call when the server sets no timeout
+const DefaultCallTimeout = 60 * time.Second
+
+// ServerConfig is a server declared in MCP_SERVERS_FILE
+type ServerConfig struct {
+	Name      string            `json:"name"`
+	Transport TransportType     `json:"transport"`
+	Command   string            `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	URL       string            `json:"url,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	// Default servers are attached to flows that do not pick their own
+	Default bool `json:"default,omitempty"`
+	// Timeout is a Go duration ("30s", "2m") for each tool call
+	Timeout string `json:"timeout,omitempty"`
+
+	timeout time.Duration
+}
+
+// CallTimeout returns how long a single tool call may take
+func (s ServerConfig) CallTimeout() time.Duration {
+	if s.timeout == 0 {
+		return DefaultCallTimeout
+	}
+	return s.timeout
+}
+
+// ServersFile is the format of the file referenced by MCP_SERVERS_FILE
+type ServersFile struct {
+	Servers []ServerConfig `json:"servers"`
+}
+
+// Server names end up in tool names (mcp__<server>__<tool>), so "_" is not allowed
+var serverName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// servers holds the configured servers by name
+var servers = map[string]ServerConfig{}
+
+// LoadServers registers the servers declared in a JSON file, replacing
+// any servers loaded before
+// It must be called at startup, before any flow is processed
+func LoadServers(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading mcp servers file: %w", err)
+	}
+
+	var file ServersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("error parsing mcp servers file: %w", err)
+	}
+
+	loaded := map[string]ServerConfig{}
+	for _, s := range file.Servers {
+		if err := s.validate(); err != nil {
+			return err
+		}
+		if _, ok := loaded[s.Name]; ok {
+			return fmt.Errorf("mcp server %s is declared twice", s.Name)
+		}
+		if s.Timeout != "" {
+			s.timeout, _ = time.ParseDuration(s.Timeout)
+		}
+		loaded[s.Name] = s
+	}
+
+	servers = loaded
+	return nil
+}
+
+func (s ServerConfig) validate() error {
+	if !serverName.MatchString(s.Name) {
+		return fmt.Errorf("mcp server %q: name must match %s", s.Name, serverName)
+	}
+
+	switch s.Transport {
+	case TransportStdio:
+		if strings.TrimSpace(s.Command) == "" {
+			return fmt.Errorf("mcp server %s: command is required for stdio", s.Name)
+		}
+	case TransportHTTP:
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("mcp server %s: url must be an http(s) URL", s.Name)
+		}
+	default:
+		return fmt.Errorf("mcp server %s: transport must be stdio or http", s.Name)
+	}
+
+	if s.Timeout != "" {
+		d, err := time.ParseDuration(s.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("mcp server %s: invalid timeout %q", s.Name, s.Timeout)
+		}
+	}
+	return nil
+}
+
+// Server returns the configured server with the given name
+func Server(name string) (ServerConfig, bool) {
+	s, ok := servers[name]
+	return s, ok
+}
+
+// Servers returns the configured servers sorted by name
+func Servers() []ServerConfig {
+	list := make([]ServerConfig, 0, len(servers))
+	for _, s := range servers {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// DefaultServerNames returns the servers attached to flows that do not pick their own
+func DefaultServerNames() []string {
+	var names []string
+	for _, s := range Servers() {
+		if s.Default {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// ValidateServerNames checks that every name is a configured server
+func ValidateServerNames(names []string) error {
+	for _, name := range names {
+		if _, ok := servers[name]; !ok {
+			return fmt.Errorf("unknown mcp server: %s", name)
+		}
+	}
+	return nil
+}
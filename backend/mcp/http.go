@@ -0,0 +1,184 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxHTTPResponse limits the body read from an HTTP server
+const maxHTTPResponse = 10 << 20
+
+// httpTransport implements the streamable HTTP transport: every message is
+// POSTed to the server URL, which answers with JSON or an SSE stream
+type httpTransport struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+func newHTTPTransport(cfg ServerConfig) *httpTransport {
+	return &httpTransport{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+func (t *httpTransport) post(ctx context.Context, req *request) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(httpReq)
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return resp, nil
+}
+
+func (t *httpTransport) setHeaders(req *http.Request) {
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionID)
+	}
+}
+
+func (t *httpTransport) roundTrip(ctx context.Context, req *request) (*message, error) {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	body := io.LimitReader(resp.Body, maxHTTPResponse)
+
+	if mediaType == "text/event-stream" {
+		return readSSEResponse(body, *req.ID)
+	}
+
+	var msg message
+	if err := json.NewDecoder(body).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return &msg, nil
+}
+
+// readSSEResponse reads events until the response to the request arrives
+// Server notifications sent on the same stream are skipped
+func readSSEResponse(r io.Reader, id int64) (*message, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxHTTPResponse)
+
+	var data strings.Builder
+	flush := func() (*message, bool) {
+		defer data.Reset()
+		if data.Len() == 0 {
+			return nil, false
+		}
+		var msg message
+		if err := json.Unmarshal([]byte(data.String()), &msg); err != nil || !msg.isResponse() {
+			return nil, false
+		}
+		if got, ok := responseID(msg.ID); !ok || got != id {
+			return nil, false
+		}
+		return &msg, true
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if msg, ok := flush(); ok {
+				return msg, nil
+			}
+			continue
+		}
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(value, " "))
+		}
+	}
+	if msg, ok := flush(); ok {
+		return msg, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading event stream: %w", err)
+	}
+	return nil, fmt.Errorf("event stream ended without a response")
+}
+
+func (t *httpTransport) notify(ctx context.Context, req *request) error {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// close ends the session on the server, if it created one
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	sessionID := t.sessionID
+	t.mu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.url, nil)
+	if err != nil {
+		return err
+	}
+	t.setHeaders(req)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,248 @@
+// Package mcp is a minimal Model Context Protocol client and server.
+// The client connects to tool servers over stdio or streamable HTTP, lists
+// their tools and calls them on behalf of the agent. The server exposes
+// Arandu's own tools to other agents over streamable HTTP.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// ProtocolVersion is the MCP revision the client and server speak
+const ProtocolVersion = "2025-03-26"
+
+// ErrClosed is returned by calls on a closed client or a server that exited
+var ErrClosed = errors.New("mcp: connection closed")
+
+// Tool is a tool offered by an MCP server
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema,omitempty"`
+}
+
+// Content is one item of a tool call result
+type Content struct {
+	Type     string           `json:"type"`
+	Text     string           `json:"text,omitempty"`
+	MimeType string           `json:"mimeType,omitempty"`
+	Resource *ResourceContent `json:"resource,omitempty"`
+}
+
+// ResourceContent is an embedded resource in a tool call result
+type ResourceContent struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
+
+// CallResult is the result of tools/call
+type CallResult struct {
+	Content []Content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// Text flattens the result into the text shown to the model
+// Binary content is replaced by a short placeholder
+func (r CallResult) Text() string {
+	var parts []string
+	for _, c := range r.Content {
+		switch {
+		case c.Type == "text":
+			parts = append(parts, c.Text)
+		case c.Type == "resource" && c.Resource != nil && c.Resource.Text != "":
+			parts = append(parts, fmt.Sprintf("[%s]\n%s", c.Resource.URI, c.Resource.Text))
+		case c.Type == "resource" && c.Resource != nil:
+			parts = append(parts, fmt.Sprintf("[resource %s]", c.Resource.URI))
+		default:
+			parts = append(parts, fmt.Sprintf("[%s content %s omitted]", c.Type, c.MimeType))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Implementation identifies a client or server
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// request is a JSON-RPC 2.0 request or, without ID, a notification
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int64 `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// message is any JSON-RPC 2.0 message received from the server
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// isResponse reports whether the message answers a request
+func (m *message) isResponse() bool {
+	return m.Method == "" && len(m.ID) > 0
+}
+
+// RPCError is a JSON-RPC error returned by the server
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("mcp: server error %d: %s", e.Code, e.Message)
+}
+
+// transport sends JSON-RPC messages to a server
+type transport interface {
+	// roundTrip sends a request and waits for its response
+	roundTrip(ctx context.Context, req *request) (*message, error)
+	// notify sends a notification, which has no response
+	notify(ctx context.Context, req *request) error
+	close() error
+}
+
+// Client is a connection to one MCP server
+type Client struct {
+	name   string
+	t      transport
+	nextID atomic.Int64
+	server Implementation
+}
+
+// Connect starts or dials the server and runs the initialize handshake
+func Connect(ctx context.Context, cfg ServerConfig) (*Client, error) {
+	var t transport
+	var err error
+
+	switch cfg.Transport {
+	case TransportStdio:
+		t, err = newStdioTransport(cfg)
+	case TransportHTTP:
+		t, err = newHTTPTransport(cfg), nil
+	default:
+		err = fmt.Errorf("unknown transport %q", cfg.Transport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s: %w", cfg.Name, err)
+	}
+
+	c := &Client{name: cfg.Name, t: t}
+	if err := c.initialize(ctx); err != nil {
+		t.close()
+		return nil, fmt.Errorf("mcp server %s: %w", cfg.Name, err)
+	}
+	return c, nil
+}
+
+// Name returns the configured name of the server
+func (c *Client) Name() string {
+	return c.name
+}
+
+// ServerInfo returns what the server reported about itself
+func (c *Client) ServerInfo() Implementation {
+	return c.server
+}
+
+func (c *Client) initialize(ctx context.Context) error {
+	var result struct {
+		ProtocolVersion string         `json:"protocolVersion"`
+		ServerInfo      Implementation `json:"serverInfo"`
+	}
+	err := c.call(ctx, "initialize", map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      Implementation{Name: "arandu", Version: "1.0.0"},
+	}, &result)
+	if err != nil {
+		return fmt.Errorf("initialize failed: %w", err)
+	}
+	c.server = result.ServerInfo
+
+	return c.t.notify(ctx, &request{JSONRPC: "2.0", Method: "notifications/initialized"})
+}
+
+// ListTools returns every tool of the server, following pagination
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	cursor := ""
+
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		var page struct {
+			Tools      []Tool `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return nil, fmt.Errorf("mcp server %s: tools/list failed: %w", c.name, err)
+		}
+		tools = append(tools, page.Tools...)
+
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool runs a tool. A result with IsError set is a tool failure the
+// model should see, not a protocol error
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (CallResult, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+
+	var result CallResult
+	if err := c.call(ctx, "tools/call", map[string]any{"name": name, "arguments": args}, &result); err != nil {
+		return CallResult{}, fmt.Errorf("mcp server %s: tools/call %s failed: %w", c.name, name, err)
+	}
+	return result, nil
+}
+
+// Close stops the server process or ends the HTTP session
+func (c *Client) Close() error {
+	return c.t.close()
+}
+
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	id := c.nextID.Add(1)
+	resp, err := c.t.roundTrip(ctx, &request{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("invalid %s result: %w", method, err)
+	}
+	return nil
+}
+
+// responseID parses the numeric ID of a response
+func responseID(raw json.RawMessage) (int64, bool) {
+	var id int64
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,332 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The test binary doubles as a tiny stdio MCP server
+func TestMain(m *testing.M) {
+	if os.Getenv("ARANDU_MCP_TEST_SERVER") == "1" {
+		serveStdio(os.Stdin, os.Stdout)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testServerResponse answers a request the way a small MCP server would
+func testServerResponse(msg map[string]any) map[string]any {
+	params, _ := msg["params"].(map[string]any)
+	resp := map[string]any{"jsonrpc": "2.0", "id": msg["id"]}
+
+	switch msg["method"] {
+	case "initialize":
+		resp["result"] = map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "test-server", "version": "0.1.0"},
+		}
+	case "tools/list":
+		// Two pages to exercise the cursor
+		if params["cursor"] == "page-2" {
+			resp["result"] = map[string]any{"tools": []any{
+				map[string]any{"name": "add", "description": "Adds two numbers", "inputSchema": map[string]any{"type": "object"}},
+			}}
+		} else {
+			resp["result"] = map[string]any{"tools": []any{
+				map[string]any{"name": "echo", "description": "Echoes the text", "inputSchema": map[string]any{"type": "object"}},
+			}, "nextCursor": "page-2"}
+		}
+	case "tools/call":
+		args, _ := params["arguments"].(map[string]any)
+		switch params["name"] {
+		case "echo":
+			resp["result"] = map[string]any{"content": []any{map[string]any{"type": "text", "text": args["text"]}}}
+		case "add":
+			a, _ := args["a"].(float64)
+			b, _ := args["b"].(float64)
+			resp["result"] = map[string]any{"content": []any{map[string]any{"type": "text", "text": fmt.Sprint(a + b)}}}
+		case "fail":
+			resp["result"] = map[string]any{"isError": true, "content": []any{map[string]any{"type": "text", "text": "boom"}}}
+		default:
+			resp["error"] = map[string]any{"code": -32602, "message": "unknown tool"}
+		}
+	default:
+		resp["error"] = map[string]any{"code": -32601, "message": "method not found"}
+	}
+	return resp
+}
+
+func serveStdio(in io.Reader, out io.Writer) {
+	enc := json.NewEncoder(out)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		var msg map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if _, ok := msg["id"]; !ok {
+			continue
+		}
+		if msg["method"] == "tools/call" {
+			// Server-initiated messages the client has to skip or answer
+			enc.Encode(map[string]any{"jsonrpc": "2.0", "method": "notifications/message", "params": map[string]any{"data": "working"}})
+			enc.Encode(map[string]any{"jsonrpc": "2.0", "id": "srv-1", "method": "ping"})
+		}
+		if params, _ := msg["params"].(map[string]any); params["name"] == "crash" {
+			fmt.Fprintln(os.Stderr, "fatal: crashed on purpose")
+			os.Exit(1)
+		}
+		enc.Encode(testServerResponse(msg))
+	}
+}
+
+func stdioTestConfig(t *testing.T) ServerConfig {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ServerConfig{
+		Name:      "local",
+		Transport: TransportStdio,
+		Command:   exe,
+		Env:       map[string]string{"ARANDU_MCP_TEST_SERVER": "1"},
+	}
+}
+
+func TestStdioClient(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c, err := Connect(ctx, stdioTestConfig(t))
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	if c.ServerInfo().Name != "test-server" {
+		t.Errorf("ServerInfo() = %+v", c.ServerInfo())
+	}
+
+	tools, err := c.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	if len(tools) != 2 || tools[0].Name != "echo" || tools[1].Name != "add" {
+		t.Errorf("ListTools() = %+v", tools)
+	}
+
+	result, err := c.CallTool(ctx, "add", map[string]any{"a": 2, "b": 3})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if result.IsError || result.Text() != "5" {
+		t.Errorf("CallTool(add) = %+v", result)
+	}
+
+	result, err = c.CallTool(ctx, "fail", nil)
+	if err != nil || !result.IsError || result.Text() != "boom" {
+		t.Errorf("CallTool(fail) = %+v, %v", result, err)
+	}
+
+	var rpcErr *RPCError
+	if _, err := c.CallTool(ctx, "missing", nil); !errors.As(err, &rpcErr) || rpcErr.Code != -32602 {
+		t.Errorf("CallTool(missing) error = %v, want RPC error -32602", err)
+	}
+}
+
+func TestStdioEnv(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("HOME", "/home/arandu")
+	t.Setenv("SECRETS_KEY", "server-secret")
+	t.Setenv("OPENAI_API_KEY", "sk-server")
+
+	env := stdioEnv(ServerConfig{Env: map[string]string{"HOME": "/srv/mcp", "GITHUB_TOKEN": "ghp_x"}})
+	joined := strings.Join(env, "\n")
+
+	for _, want := range []string{"PATH=/usr/bin", "HOME=/srv/mcp", "GITHUB_TOKEN=ghp_x"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("stdioEnv() = %v, want %s", env, want)
+		}
+	}
+	for _, leaked := range []string{"SECRETS_KEY", "OPENAI_API_KEY", "HOME=/home/arandu"} {
+		if strings.Contains(joined, leaked) {
+			t.Errorf("stdioEnv() = %v, must not contain %s", env, leaked)
+		}
+	}
+}
+
+func TestStdioClient_ServerExits(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c, err := Connect(ctx, stdioTestConfig(t))
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	_, err = c.CallTool(ctx, "crash", nil)
+	if !errors.Is(err, ErrClosed) || !strings.Contains(err.Error(), "crashed on purpose") {
+		t.Errorf("CallTool(crash) error = %v, want ErrClosed with stderr", err)
+	}
+
+	if _, err := c.ListTools(ctx); !errors.Is(err, ErrClosed) {
+		t.Errorf("ListTools() after exit error = %v, want ErrClosed", err)
+	}
+}
+
+func TestStdioClient_BadCommand(t *testing.T) {
+	_, err := Connect(context.Background(), ServerConfig{Name: "missing", Transport: TransportStdio, Command: "/does/not/exist"})
+	if err == nil {
+		t.Error("Connect() expected error")
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	var closed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			closed.Store(r.Header.Get("Mcp-Session-Id") == "session-1")
+			return
+		}
+
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+
+		if msg["method"] == "initialize" {
+			w.Header().Set("Mcp-Session-Id", "session-1")
+		} else if r.Header.Get("Mcp-Session-Id") != "session-1" {
+			http.Error(w, "missing session", http.StatusBadRequest)
+			return
+		}
+
+		if _, ok := msg["id"]; !ok {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		resp, _ := json.Marshal(testServerResponse(msg))
+		if msg["method"] == "tools/call" {
+			// Answer through an event stream with a notification first
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", resp)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resp)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c, err := Connect(ctx, ServerConfig{
+		Name:      "remote",
+		Transport: TransportHTTP,
+		URL:       server.URL,
+		Headers:   map[string]string{"Authorization": "Bearer token"},
+	})
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	tools, err := c.ListTools(ctx)
+	if err != nil || len(tools) != 2 {
+		t.Fatalf("ListTools() = %+v, %v", tools, err)
+	}
+
+	result, err := c.CallTool(ctx, "echo", map[string]any{"text": "hola"})
+	if err != nil || result.Text() != "hola" {
+		t.Errorf("CallTool(echo) = %+v, %v", result, err)
+	}
+
+	c.Close()
+	if !closed.Load() {
+		t.Error("Close() should end the HTTP session")
+	}
+
+	if _, err := Connect(ctx, ServerConfig{Name: "remote", Transport: TransportHTTP, URL: server.URL}); err == nil {
+		t.Error("Connect() without credentials expected error")
+	}
+}
+
+func TestCallResultText(t *testing.T) {
+	result := CallResult{Content: []Content{
+		{Type: "text", Text: "first"},
+		{Type: "image", MimeType: "image/png"},
+		{Type: "resource", Resource: &ResourceContent{URI: "file:///a.txt", Text: "content"}},
+	}}
+
+	want := "first\n[image content image/png omitted]\n[file:///a.txt]\ncontent"
+	if got := result.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadServers(t *testing.T) {
+	defer func() { servers = map[string]ServerConfig{} }()
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "valid", data: `{"servers": [
+			{"name": "github", "transport": "stdio", "command": "npx", "args": ["-y", "server-github"], "default": true},
+			{"name": "docs", "transport": "http", "url": "https://mcp.example.com/mcp", "timeout": "2m"}
+		]}`},
+		{name: "underscore in name", data: `{"servers": [{"name": "my_server", "transport": "stdio", "command": "x"}]}`, wantErr: true},
+		{name: "no command", data: `{"servers": [{"name": "a", "transport": "stdio"}]}`, wantErr: true},
+		{name: "bad url", data: `{"servers": [{"name": "a", "transport": "http", "url": "mcp.example.com"}]}`, wantErr: true},
+		{name: "bad transport", data: `{"servers": [{"name": "a", "transport": "sse", "url": "https://x.example"}]}`, wantErr: true},
+		{name: "bad timeout", data: `{"servers": [{"name": "a", "transport": "stdio", "command": "x", "timeout": "0s"}]}`, wantErr: true},
+		{name: "duplicate", data: `{"servers": [{"name": "a", "transport": "stdio", "command": "x"}, {"name": "a", "transport": "stdio", "command": "y"}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mcp.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := LoadServers(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadServers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := DefaultServerNames(); len(got) != 1 || got[0] != "github" {
+				t.Errorf("DefaultServerNames() = %v", got)
+			}
+			docs, _ := Server("docs")
+			if docs.CallTimeout() != 2*time.Minute {
+				t.Errorf("CallTimeout() = %v", docs.CallTimeout())
+			}
+			if err := ValidateServerNames([]string{"docs", "github"}); err != nil {
+				t.Errorf("ValidateServerNames() error = %v", err)
+			}
+			if err := ValidateServerNames([]string{"slack"}); err == nil {
+				t.Error("ValidateServerNames() expected error for unknown server")
+			}
+		})
+	}
+}
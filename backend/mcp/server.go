@@ -0,0 +1,303 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JSON-RPC error codes used by the server
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// maxRequestSize limits the body of a request sent to the server
+const maxRequestSize = 4 << 20
+
+// SessionTTL is how long an idle HTTP session is kept
+const SessionTTL = time.Hour
+
+// ToolHandler runs a tool with the raw arguments sent by the client
+// A returned error is reported to the client as a tool failure (isError)
+type ToolHandler func(ctx context.Context, args json.RawMessage) (CallResult, error)
+
+// ToolServer answers MCP requests with a fixed set of tools
+type ToolServer struct {
+	info     Implementation
+	tools    map[string]Tool
+	handlers map[string]ToolHandler
+
+	mu       sync.Mutex
+	sessions map[string]time.Time
+}
+
+// NewToolServer creates a server that reports itself as name/version
+func NewToolServer(name string, version string) *ToolServer {
+	return &ToolServer{
+		info:     Implementation{Name: name, Version: version},
+		tools:    map[string]Tool{},
+		handlers: map[string]ToolHandler{},
+		sessions: map[string]time.Time{},
+	}
+}
+
+// AddTool registers a tool. It must be called before the server is used
+func (s *ToolServer) AddTool(tool Tool, handler ToolHandler) {
+	if tool.InputSchema == nil {
+		tool.InputSchema = map[string]any{"type": "object"}
+	}
+	s.tools[tool.Name] = tool
+	s.handlers[tool.Name] = handler
+}
+
+// Tools returns the registered tools sorted by name
+func (s *ToolServer) Tools() []Tool {
+	tools := make([]Tool, 0, len(s.tools))
+	for _, t := range s.tools {
+		tools = append(tools, t)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// TextResult is a successful tool result with a single text item
+func TextResult(text string) CallResult {
+	return CallResult{Content: []Content{{Type: "text", Text: text}}}
+}
+
+// incoming is a JSON-RPC request or notification received by the server
+type incoming struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response sent by the server
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// handle answers one message; notifications return nil
+func (s *ToolServer) handle(ctx context.Context, msg incoming) *response {
+	if len(msg.ID) == 0 {
+		return nil
+	}
+
+	resp := &response{JSONRPC: "2.0", ID: msg.ID}
+	if msg.JSONRPC != "2.0" || msg.Method == "" {
+		resp.Error = &RPCError{Code: codeInvalidRequest, Message: "invalid request"}
+		return resp
+	}
+
+	switch msg.Method {
+	case "initialize":
+		resp.Result = map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      s.info,
+		}
+	case "ping":
+		resp.Result = map[string]any{}
+	case "tools/list":
+		resp.Result = map[string]any{"tools": s.Tools()}
+	case "tools/call":
+		result, rpcErr := s.callTool(ctx, msg.Params)
+		if rpcErr != nil {
+			resp.Error = rpcErr
+		} else {
+			resp.Result = result
+		}
+	default:
+		resp.Error = &RPCError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+	return resp
+}
+
+func (s *ToolServer) callTool(ctx context.Context, raw json.RawMessage) (CallResult, *RPCError) {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return CallResult{}, &RPCError{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+
+	handler, ok := s.handlers[params.Name]
+	if !ok {
+		return CallResult{}, &RPCError{Code: codeInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+	if len(params.Arguments) == 0 || string(params.Arguments) == "null" {
+		params.Arguments = json.RawMessage("{}")
+	}
+
+	result, err := handler(ctx, params.Arguments)
+	if err != nil {
+		return CallResult{Content: []Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	if result.Content == nil {
+		result.Content = []Content{}
+	}
+	return result, nil
+}
+
+// parseBody decodes a single message or a batch
+func parseBody(body []byte) (msgs []incoming, batch bool, err error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &msgs); err != nil {
+			return nil, true, err
+		}
+		if len(msgs) == 0 {
+			return nil, true, fmt.Errorf("empty batch")
+		}
+		return msgs, true, nil
+	}
+
+	var msg incoming
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, false, err
+	}
+	return []incoming{msg}, false, nil
+}
+
+// hasInitialize reports whether the messages open a new session
+func hasInitialize(msgs []incoming) bool {
+	for _, msg := range msgs {
+		if msg.Method == "initialize" {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch answers the messages; it returns nil when there is nothing to send back
+func (s *ToolServer) dispatch(ctx context.Context, msgs []incoming, batch bool) any {
+	var responses []*response
+	for _, msg := range msgs {
+		if resp := s.handle(ctx, msg); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+
+	switch {
+	case len(responses) == 0:
+		return nil
+	case batch:
+		return responses
+	default:
+		return responses[0]
+	}
+}
+
+// newSession registers an HTTP session and drops the expired ones
+func (s *ToolServer) newSession() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for sid, seen := range s.sessions {
+		if now.Sub(seen) > SessionTTL {
+			delete(s.sessions, sid)
+		}
+	}
+	s.sessions[id] = now
+	return id
+}
+
+// touchSession reports whether the session exists and marks it as used
+func (s *ToolServer) touchSession(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen, ok := s.sessions[id]
+	if !ok || time.Since(seen) > SessionTTL {
+		delete(s.sessions, id)
+		return false
+	}
+	s.sessions[id] = time.Now()
+	return true
+}
+
+// ServeHTTP implements the streamable HTTP transport
+// Responses are always plain JSON; the server never opens an event stream
+func (s *ToolServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get("Mcp-Session-Id")
+
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.sessions, sessionID)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize+1))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxRequestSize {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	msgs, batch, err := parseBody(body)
+	if err != nil {
+		writeJSON(w, &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &RPCError{Code: codeParseError, Message: "parse error"}})
+		return
+	}
+
+	// Every request after initialize must carry the session the server assigned
+	if hasInitialize(msgs) {
+		w.Header().Set("Mcp-Session-Id", s.newSession())
+	} else if sessionID == "" {
+		http.Error(w, "missing Mcp-Session-Id header", http.StatusBadRequest)
+		return
+	} else if !s.touchSession(sessionID) {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	resp := s.dispatch(r.Context(), msgs, batch)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	writeJSON(w, resp)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestToolServer() *ToolServer {
+	s := NewToolServer("test", "1.0.0")
+	s.AddTool(Tool{Name: "echo", Description: "Echoes text"}, func(ctx context.Context, args json.RawMessage) (CallResult, error) {
+		var in struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(args, &in); err != nil {
+			return CallResult{}, err
+		}
+		if in.Text == "" {
+			return CallResult{}, fmt.Errorf("text is required")
+		}
+		return TextResult(in.Text), nil
+	})
+	return s
+}
+
+func TestToolServer_Client(t *testing.T) {
+	server := httptest.NewServer(newTestToolServer())
+	defer server.Close()
+
+	ctx := context.Background()
+	c, err := Connect(ctx, ServerConfig{Name: "arandu", Transport: TransportHTTP, URL: server.URL})
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer c.Close()
+
+	if c.ServerInfo().Name != "test" {
+		t.Errorf("ServerInfo() = %+v", c.ServerInfo())
+	}
+
+	tools, err := c.ListTools(ctx)
+	if err != nil || len(tools) != 1 || tools[0].InputSchema["type"] != "object" {
+		t.Fatalf("ListTools() = %+v, %v", tools, err)
+	}
+
+	result, err := c.CallTool(ctx, "echo", map[string]any{"text": "hola"})
+	if err != nil || result.IsError || result.Text() != "hola" {
+		t.Errorf("CallTool(echo) = %+v, %v", result, err)
+	}
+
+	// Handler errors are tool failures, not protocol errors
+	result, err = c.CallTool(ctx, "echo", nil)
+	if err != nil || !result.IsError || result.Text() != "text is required" {
+		t.Errorf("CallTool(echo) without text = %+v, %v", result, err)
+	}
+
+	if _, err := c.CallTool(ctx, "missing", nil); err == nil || !strings.Contains(err.Error(), "unknown tool") {
+		t.Errorf("CallTool(missing) error = %v", err)
+	}
+}
+
+func TestToolServer_HTTP(t *testing.T) {
+	server := httptest.NewServer(newTestToolServer())
+	defer server.Close()
+
+	post := func(body string, session string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if session != "" {
+			req.Header.Set("Mcp-Session-Id", session)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		session    bool
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "missing session", body: `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`, wantStatus: http.StatusBadRequest},
+		{name: "notification", body: `{"jsonrpc":"2.0","method":"notifications/initialized"}`, session: true, wantStatus: http.StatusAccepted},
+		{name: "unknown method", body: `{"jsonrpc":"2.0","id":2,"method":"resources/list"}`, session: true, wantStatus: http.StatusOK, wantBody: `"code":-32601`},
+		{name: "batch", body: `[{"jsonrpc":"2.0","id":3,"method":"ping"},{"jsonrpc":"2.0","method":"notifications/initialized"}]`, session: true, wantStatus: http.StatusOK, wantBody: `[{"jsonrpc":"2.0","id":3,"result":{}}]`},
+		{name: "parse error", body: `{`, session: true, wantStatus: http.StatusOK, wantBody: `"code":-32700`},
+	}
+
+	init := post(`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}`, "")
+	session := init.Header.Get("Mcp-Session-Id")
+	if init.StatusCode != http.StatusOK || session == "" {
+		t.Fatalf("initialize: status %d, session %q", init.StatusCode, session)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sid := ""
+			if tt.session {
+				sid = session
+			}
+			resp := post(tt.body, sid)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+
+	// After DELETE the session is gone
+	req, _ := http.NewRequest(http.MethodDelete, server.URL, nil)
+	req.Header.Set("Mcp-Session-Id", session)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("DELETE: %v, %v", resp, err)
+	}
+	if resp := post(`{"jsonrpc":"2.0","id":4,"method":"ping"}`, session); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("request after DELETE: status %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,256 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arandu-ai/arandu/logging"
+)
+
+// stderrTailSize is how much of the server stderr is kept for error messages
+const stderrTailSize = 2048
+
+// inheritedEnv are the only server variables a stdio MCP server inherits
+// Secrets such as SECRETS_KEY or provider API keys must be set in its env explicitly
+var inheritedEnv = []string{"PATH", "HOME", "LANG", "TMPDIR"}
+
+// stdioTransport runs the server as a child process and exchanges
+// newline-delimited JSON-RPC messages over its stdin and stdout
+type stdioTransport struct {
+	name  string
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[int64]chan *message
+	err     error
+	stderr  *tailBuffer
+	exited  chan struct{}
+}
+
+func newStdioTransport(cfg ServerConfig) (*stdioTransport, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	// Bounds Wait when a grandchild (e.g. under npx) keeps stderr open
+	cmd.WaitDelay = 2 * time.Second
+	cmd.Env = stdioEnv(cfg)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &stdioTransport{
+		name:    cfg.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: map[int64]chan *message{},
+		stderr:  &tailBuffer{max: stderrTailSize},
+		exited:  make(chan struct{}),
+	}
+	cmd.Stderr = t.stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting %s: %w", cfg.Command, err)
+	}
+
+	go t.readLoop(stdout)
+	return t, nil
+}
+
+// stdioEnv builds the minimal environment of a stdio server: a few variables
+// inherited from the server and the ones in its configuration
+func stdioEnv(cfg ServerConfig) []string {
+	var env []string
+	for _, name := range inheritedEnv {
+		if _, ok := cfg.Env[name]; ok {
+			continue
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+
+	names := make([]string, 0, len(cfg.Env))
+	for name := range cfg.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+cfg.Env[name])
+	}
+	return env
+}
+
+// readLoop dispatches responses to their callers and answers server requests
+func (t *stdioTransport) readLoop(stdout io.Reader) {
+	reader := bufio.NewReaderSize(stdout, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			t.handle(line)
+		}
+		if err != nil {
+			// Wait after the last read so stderr is fully collected
+			t.cmd.Wait()
+			close(t.exited)
+			t.fail(err)
+			return
+		}
+	}
+}
+
+func (t *stdioTransport) handle(line []byte) {
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		logging.Debug("Ignoring invalid MCP message", "server", t.name, "error", err.Error())
+		return
+	}
+
+	if msg.isResponse() {
+		id, ok := responseID(msg.ID)
+		if !ok {
+			return
+		}
+		t.mu.Lock()
+		ch := t.pending[id]
+		delete(t.pending, id)
+		t.mu.Unlock()
+		if ch != nil {
+			ch <- &msg
+		}
+		return
+	}
+
+	// Requests from the server: only ping is supported
+	if len(msg.ID) > 0 {
+		reply := map[string]any{"jsonrpc": "2.0", "id": msg.ID}
+		if msg.Method == "ping" {
+			reply["result"] = map[string]any{}
+		} else {
+			reply["error"] = RPCError{Code: -32601, Message: "method not found"}
+		}
+		t.write(reply)
+	}
+}
+
+// fail ends every pending call once the server stops answering
+func (t *stdioTransport) fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.err != nil {
+		return
+	}
+	t.err = ErrClosed
+	if tail := strings.TrimSpace(t.stderr.String()); tail != "" {
+		t.err = fmt.Errorf("%w: %s", ErrClosed, tail)
+	}
+	logging.Debug("MCP server stopped", "server", t.name, "error", err.Error())
+
+	for id, ch := range t.pending {
+		close(ch)
+		delete(t.pending, id)
+	}
+}
+
+func (t *stdioTransport) write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.stdin.Write(append(data, '\n'))
+	return err
+}
+
+func (t *stdioTransport) roundTrip(ctx context.Context, req *request) (*message, error) {
+	ch := make(chan *message, 1)
+
+	t.mu.Lock()
+	if t.err != nil {
+		t.mu.Unlock()
+		return nil, t.err
+	}
+	t.pending[*req.ID] = ch
+	t.mu.Unlock()
+
+	if err := t.write(req); err != nil {
+		t.mu.Lock()
+		delete(t.pending, *req.ID)
+		t.mu.Unlock()
+		return nil, fmt.Errorf("error writing to server: %w", err)
+	}
+
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return nil, t.err
+		}
+		return msg, nil
+	case <-ctx.Done():
+		t.mu.Lock()
+		delete(t.pending, *req.ID)
+		t.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (t *stdioTransport) notify(_ context.Context, req *request) error {
+	return t.write(req)
+}
+
+// close ends stdin so the server can exit, and kills it if it does not
+func (t *stdioTransport) close() error {
+	t.stdin.Close()
+
+	select {
+	case <-t.exited:
+	case <-time.After(2 * time.Second):
+		t.cmd.Process.Kill()
+		<-t.exited
+	}
+	return nil
+}
+
+// tailBuffer keeps the last bytes written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
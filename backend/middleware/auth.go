@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"context"
 	"crypto/subtle"
 	"net/http"
 
+	"github.com/arandu-ai/arandu/auth"
 	"github.com/arandu-ai/arandu/config"
 	"github.com/gin-gonic/gin"
 )
@@ -75,7 +77,8 @@ func OptionalAPIKeyAuth() gin.HandlerFunc {
 		apiKey := c.GetHeader(APIKeyHeader)
 		if apiKey == "" {
 			authHeader := c.GetHeader(AuthorizationHeader)
-			if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+			// User tokens are checked by UserAuth
+			if len(authHeader) > 7 && authHeader[:7] == "Bearer " && !auth.IsToken(authHeader[7:]) {
 				apiKey = authHeader[7:]
 			}
 		}
@@ -97,21 +100,43 @@ func OptionalAPIKeyAuth() gin.HandlerFunc {
 
 		// Mark request as authenticated
 		c.Set("authenticated", true)
+		c.Request = c.Request.WithContext(auth.WithAPIKey(c.Request.Context()))
 		c.Next()
 	}
 }
 
+// deferredAuthKey marks requests whose authentication is checked later
+const deferredAuthKey = "authDeferred"
+
+// AuthRequired reports whether requests must be authenticated
+func AuthRequired() bool {
+	return config.Config.RequireAPIKey || auth.Enabled()
+}
+
+// CheckAuthenticated checks that a request context meets the authentication
+// requirement: a user token with MULTI_USER, else the API key or a user token
+// when REQUIRE_API_KEY is set
+func CheckAuthenticated(ctx context.Context) error {
+	if auth.Enabled() {
+		_, err := auth.RequireUser(ctx)
+		return err
+	}
+	if config.Config.RequireAPIKey && !auth.Authenticated(ctx) {
+		return auth.ErrUnauthenticated
+	}
+	return nil
+}
+
 // RequireAuth returns a middleware that requires authentication
-// Use this for sensitive endpoints
+// Use this for sensitive endpoints, after OptionalAPIKeyAuth and UserAuth
 func RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !config.Config.RequireAPIKey {
+		if c.GetBool(deferredAuthKey) {
 			c.Next()
 			return
 		}
 
-		authenticated, exists := c.Get("authenticated")
-		if !exists || !authenticated.(bool) {
+		if err := CheckAuthenticated(c.Request.Context()); err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error": "Authentication required",
 				"code":  "UNAUTHORIZED",
@@ -122,3 +147,30 @@ func RequireAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// DeferWebsocketAuth lets websocket upgrades through RequireAuth
+// Browsers cannot set headers on websockets, so the GraphQL transport checks
+// the token sent in the connection_init payload instead
+func DeferWebsocketAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.IsWebsocket() {
+			c.Set(deferredAuthKey, true)
+		}
+		c.Next()
+	}
+}
+
+// RequireScope returns a middleware that rejects user tokens without the scope
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := auth.RequireScope(c.Request.Context(), scope); err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Token scope does not allow this operation",
+				"code":  "FORBIDDEN",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/arandu-ai/arandu/auth"
 	"github.com/arandu-ai/arandu/config"
 	"github.com/gin-gonic/gin"
 )
@@ -204,3 +205,96 @@ func TestOptionalAPIKeyAuth_ValidKey(t *testing.T) {
 		t.Errorf("Expected 'authenticated', got %s", w.Body.String())
 	}
 }
+
+func TestRequireAuth(t *testing.T) {
+	defer func(requireKey bool, key string, multiUser bool) {
+		config.Config.RequireAPIKey = requireKey
+		config.Config.APIKey = key
+		config.Config.MultiUser = multiUser
+	}(config.Config.RequireAPIKey, config.Config.APIKey, config.Config.MultiUser)
+
+	tests := []struct {
+		name       string
+		requireKey bool
+		multiUser  bool
+		apiKey     string
+		websocket  bool
+		want       int
+	}{
+		{name: "auth disabled", want: http.StatusOK},
+		{name: "api key required, none sent", requireKey: true, want: http.StatusUnauthorized},
+		{name: "api key required, valid key", requireKey: true, apiKey: "test-api-key-12345", want: http.StatusOK},
+		{name: "api key required, user bearer is not an api key", requireKey: true, apiKey: "Bearer arandu_abc", want: http.StatusUnauthorized},
+		{name: "multi user, api key is not a user", requireKey: true, multiUser: true, apiKey: "test-api-key-12345", want: http.StatusUnauthorized},
+		{name: "websocket upgrade is deferred", requireKey: true, websocket: true, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Config.RequireAPIKey = tt.requireKey
+			config.Config.APIKey = "test-api-key-12345"
+			config.Config.MultiUser = tt.multiUser
+
+			router := gin.New()
+			router.Use(DeferWebsocketAuth(), OptionalAPIKeyAuth(), RequireAuth())
+			router.GET("/test", func(c *gin.Context) {
+				c.String(http.StatusOK, "success")
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			switch {
+			case len(tt.apiKey) > 7 && tt.apiKey[:7] == "Bearer ":
+				req.Header.Set(AuthorizationHeader, tt.apiKey)
+			case tt.apiKey != "":
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			if tt.websocket {
+				req.Header.Set("Connection", "upgrade")
+				req.Header.Set("Upgrade", "websocket")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		user  *auth.User
+		scope string
+		want  int
+	}{
+		{name: "no user", scope: auth.ScopeOperator, want: http.StatusOK},
+		{name: "operator token", user: &auth.User{ID: 1, Scope: auth.ScopeOperator}, scope: auth.ScopeOperator, want: http.StatusOK},
+		{name: "read-only token", user: &auth.User{ID: 1, Scope: auth.ScopeReadOnly}, scope: auth.ScopeOperator, want: http.StatusForbidden},
+		{name: "read-only token, read-only route", user: &auth.User{ID: 1, Scope: auth.ScopeReadOnly}, scope: auth.ScopeReadOnly, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.user != nil {
+					c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), tt.user))
+				}
+				c.Next()
+			}, RequireScope(tt.scope))
+			router.GET("/test", func(c *gin.Context) {
+				c.String(http.StatusOK, "success")
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// SessionCookie holds the login token of browser sessions
+const SessionCookie = "arandu_session"
+
+// RequestToken returns the user token sent with the request
+// API clients use "Authorization: Bearer arandu_..."; the web app and the
+// websockets it opens rely on the session cookie
+func RequestToken(r *http.Request) string {
+	if header := r.Header.Get(AuthorizationHeader); strings.HasPrefix(header, "Bearer ") {
+		if token := strings.TrimPrefix(header, "Bearer "); auth.IsToken(token) {
+			return token
+		}
+	}
+	if cookie, err := r.Cookie(SessionCookie); err == nil && auth.IsToken(cookie.Value) {
+		return cookie.Value
+	}
+	return ""
+}
+
+// UserAuth returns a middleware that resolves the user of the request
+// A request without a token goes on anonymous; RequireAuth decides whether
+// that is allowed. An invalid token is always rejected
+func UserAuth(db *database.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := RequestToken(c.Request)
+		if token == "" {
+			c.Next()
+			return
+		}
+		// Already resolved by Identify
+		if _, ok := auth.UserFromContext(c.Request.Context()); ok {
+			c.Next()
+			return
+		}
+
+		user, err := auth.Authenticate(c.Request.Context(), db, token)
+		if err != nil {
+			if !errors.Is(err, auth.ErrInvalidCredentials) {
+				logging.Error("Failed to authenticate user token", "error", err.Error())
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid or expired token",
+				"code":  "UNAUTHORIZED",
+			})
+			return
+		}
+
+		c.Set("authenticated", true)
+		c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), user))
+		c.Next()
+	}
+}
+
+// Identify resolves the user of every request that carries a valid token, so
+// identity-based features (rate limits, flow owners, audit) see the same user
+// as the resolvers. Unlike UserAuth it never rejects: an invalid token is
+// left for UserAuth on the protected routes
+func Identify(db *database.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := RequestToken(c.Request)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		user, err := auth.Authenticate(c.Request.Context(), db, token)
+		if err == nil {
+			c.Set("authenticated", true)
+			c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), user))
+		} else if !errors.Is(err, auth.ErrInvalidCredentials) {
+			logging.Error("Failed to authenticate user token", "error", err.Error())
+		}
+		c.Next()
+	}
+}
+
+// AuthenticateToken checks a token sent outside the HTTP headers, such as in
+// a websocket connection_init payload, and returns the context it grants
+func AuthenticateToken(ctx context.Context, db *database.Queries, token string) (context.Context, error) {
+	token = strings.TrimPrefix(token, "Bearer ")
+	if auth.IsToken(token) {
+		user, err := auth.Authenticate(ctx, db, token)
+		if err != nil {
+			return nil, err
+		}
+		return auth.WithUser(ctx, user), nil
+	}
+	if config.Config.APIKey != "" && secureCompare(token, config.Config.APIKey) {
+		return auth.WithAPIKey(ctx), nil
+	}
+	return nil, auth.ErrInvalidCredentials
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		cookie string
+		want   string
+	}{
+		{name: "no token", want: ""},
+		{name: "bearer user token", header: "Bearer arandu_abc", want: "arandu_abc"},
+		{name: "bearer global api key", header: "Bearer some-api-key", want: ""},
+		{name: "session cookie", cookie: "arandu_def", want: "arandu_def"},
+		{name: "header wins over cookie", header: "Bearer arandu_abc", cookie: "arandu_def", want: "arandu_abc"},
+		{name: "cookie without prefix", cookie: "garbage", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(AuthorizationHeader, tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: SessionCookie, Value: tt.cookie})
+			}
+			if got := RequestToken(req); got != tt.want {
+				t.Errorf("RequestToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserAuth_NoToken(t *testing.T) {
+	router := gin.New()
+	router.Use(UserAuth(nil))
+	router.GET("/test", func(c *gin.Context) {
+		if _, ok := auth.UserFromContext(c.Request.Context()); ok {
+			t.Error("request without token should have no user")
+		}
+		c.String(http.StatusOK, "success")
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestAuthenticateToken_APIKey(t *testing.T) {
+	config.Config.APIKey = "test-api-key-12345"
+
+	ctx, err := AuthenticateToken(context.Background(), nil, "Bearer test-api-key-12345")
+	if err != nil || !auth.Authenticated(ctx) {
+		t.Errorf("AuthenticateToken(valid key) = %v", err)
+	}
+
+	if _, err := AuthenticateToken(context.Background(), nil, "wrong-key"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("AuthenticateToken(wrong key) error = %v", err)
+	}
+
+	config.Config.APIKey = ""
+	if _, err := AuthenticateToken(context.Background(), nil, ""); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("AuthenticateToken(empty) error = %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE containers
+ADD COLUMN parent_id INTEGER REFERENCES containers (id);
+
+ALTER TABLE containers
+ADD COLUMN base_image TEXT;
+
+CREATE TABLE snapshots (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  flow_id INTEGER NOT NULL REFERENCES flows (id) ON DELETE CASCADE,
+  task_id INTEGER NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+  container_id INTEGER REFERENCES containers (id),
+  image TEXT NOT NULL,
+  reason TEXT NOT NULL
+);
+
+CREATE INDEX snapshots_flow_task_idx ON snapshots (flow_id, task_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE snapshots;
+
+ALTER TABLE containers
+DROP COLUMN base_image;
+
+ALTER TABLE containers
+DROP COLUMN parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE flows
+ADD COLUMN mcp_servers TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE flows
+DROP COLUMN mcp_servers;
+-- +goose StatementEnd
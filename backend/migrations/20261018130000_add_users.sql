@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  username TEXT NOT NULL UNIQUE,
+  password_hash TEXT NOT NULL,
+  admin BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE api_tokens (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_used_at TIMESTAMP,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  token_hash TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX api_tokens_user_idx ON api_tokens (user_id);
+
+ALTER TABLE flows
+ADD COLUMN owner_id INTEGER REFERENCES users (id);
+
+CREATE INDEX flows_owner_idx ON flows (owner_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX flows_owner_idx;
+
+ALTER TABLE flows
+DROP COLUMN owner_id;
+
+DROP TABLE api_tokens;
+DROP TABLE users;
+-- +goose StatementEnd
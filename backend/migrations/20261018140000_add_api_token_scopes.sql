@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE api_tokens
+ADD COLUMN scope TEXT NOT NULL DEFAULT 'operator';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_tokens
+DROP COLUMN scope;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN oidc_subject TEXT;
+
+CREATE UNIQUE INDEX users_oidc_subject_idx ON users (oidc_subject);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_oidc_subject_idx;
+
+ALTER TABLE users
+DROP COLUMN oidc_subject;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE teams (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE team_members (
+  team_id INTEGER NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  role TEXT NOT NULL,
+  PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX team_members_user_idx ON team_members (user_id);
+
+ALTER TABLE flows
+ADD COLUMN team_id INTEGER REFERENCES teams (id) ON DELETE SET NULL;
+
+CREATE INDEX flows_team_idx ON flows (team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX flows_team_idx;
+
+ALTER TABLE flows
+DROP COLUMN team_id;
+
+DROP TABLE team_members;
+DROP TABLE teams;
+-- +goose StatementEnd
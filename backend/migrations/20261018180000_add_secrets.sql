@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE secrets (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  name TEXT NOT NULL,
+  value BLOB NOT NULL,
+  user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+  team_id INTEGER REFERENCES teams (id) ON DELETE CASCADE
+);
+
+-- A name is unique per user (or per server without accounts) and per team
+CREATE UNIQUE INDEX secrets_user_name_idx ON secrets (COALESCE(user_id, 0), name) WHERE team_id IS NULL;
+CREATE UNIQUE INDEX secrets_team_name_idx ON secrets (team_id, name) WHERE team_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX secrets_team_name_idx;
+DROP INDEX secrets_user_name_idx;
+DROP TABLE secrets;
+-- +goose StatementEnd
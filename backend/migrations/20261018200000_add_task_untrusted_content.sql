@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks
+ADD COLUMN untrusted BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE tasks
+ADD COLUMN injection_flags TEXT NOT NULL DEFAULT '[]';
+
+ALTER TABLE tasks
+ADD COLUMN approval TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks
+DROP COLUMN approval;
+
+ALTER TABLE tasks
+DROP COLUMN injection_flags;
+
+ALTER TABLE tasks
+DROP COLUMN untrusted;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE command_policies (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  flow_id INTEGER UNIQUE REFERENCES flows (id) ON DELETE CASCADE,
+  team_id INTEGER UNIQUE REFERENCES teams (id) ON DELETE CASCADE,
+  policy TEXT NOT NULL,
+  -- A policy belongs to either a flow or a team
+  CHECK ((flow_id IS NULL) != (team_id IS NULL))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE command_policies;
+-- +goose StatementEnd
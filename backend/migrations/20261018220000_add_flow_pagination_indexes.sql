@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX tasks_flow_idx ON tasks (flow_id, id);
+CREATE INDEX logs_flow_idx ON logs (flow_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX logs_flow_idx;
+DROP INDEX tasks_flow_idx;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  created_at, user_id, actor, action, flow_id, task_id, target, details, error, prev_hash, hash
+)
+VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ReadLastAuditEvent :one
+SELECT * FROM audit_events
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ReadAuditEvents :many
+SELECT * FROM audit_events
+WHERE flow_id IS COALESCE(sqlc.narg(flow_id), flow_id)
+  AND user_id IS COALESCE(sqlc.narg(user_id), user_id)
+  AND action IS COALESCE(sqlc.narg(action), action)
+  AND id < COALESCE(sqlc.narg(before_id), 9223372036854775807)
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ReadAuditEventsAfter :many
+SELECT * FROM audit_events
+WHERE id > ?
+ORDER BY id ASC
+LIMIT ?;
@@ -0,0 +1,43 @@
+-- name: ReadFlowCommandPolicy :one
+SELECT * FROM command_policies
+WHERE flow_id = ?;
+
+-- name: ReadTeamCommandPolicy :one
+SELECT * FROM command_policies
+WHERE team_id = ?;
+
+-- name: UpsertFlowCommandPolicy :one
+INSERT INTO command_policies (
+  flow_id, policy
+)
+VALUES (
+  ?, ?
+)
+ON CONFLICT (flow_id) DO UPDATE SET policy = excluded.policy, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: UpsertTeamCommandPolicy :one
+INSERT INTO command_policies (
+  team_id, policy
+)
+VALUES (
+  ?, ?
+)
+ON CONFLICT (team_id) DO UPDATE SET policy = excluded.policy, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: CopyCommandPolicyToFlow :exec
+INSERT INTO command_policies (
+  flow_id, policy
+)
+SELECT CAST(sqlc.arg(target_flow_id) AS INTEGER), policy
+FROM command_policies
+WHERE flow_id = sqlc.arg(source_flow_id);
+
+-- name: DeleteFlowCommandPolicy :execrows
+DELETE FROM command_policies
+WHERE flow_id = ?;
+
+-- name: DeleteTeamCommandPolicy :execrows
+DELETE FROM command_policies
+WHERE team_id = ?;
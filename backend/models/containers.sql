@@ -21,3 +21,15 @@ UPDATE containers
 SET local_id = ?
 WHERE id = ?
 RETURNING *;
+
+-- name: UpdateContainerName :one
+UPDATE containers
+SET name = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateContainerLineage :one
+UPDATE containers
+SET parent_id = ?, base_image = ?
+WHERE id = ?
+RETURNING *;
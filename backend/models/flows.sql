@@ -1,18 +1,23 @@
 -- name: CreateFlow :one
 INSERT INTO flows (
-  name, status, container_id, model, model_provider
+  name, status, container_id, model, model_provider, sandbox, mcp_servers, owner_id, team_id
 )
 VALUES (
-  ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 RETURNING *;
 
 -- name: ReadAllFlows :many
 SELECT
   f.*,
-  c.name AS container_name
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
 FROM flows f
 LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
 ORDER BY f.created_at DESC;
 
 -- name: ReadFlow :one
@@ -21,9 +26,15 @@ SELECT
   c.name AS container_name,
   c.image AS container_image,
   c.status AS container_status,
-  c.local_id AS container_local_id
+  c.local_id AS container_local_id,
+  c.base_image AS container_base_image,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
 FROM flows f
 LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
 WHERE f.id = ?;
 
 -- name: UpdateFlowStatus :one
@@ -43,3 +54,147 @@ UPDATE flows
 SET container_id = ?
 WHERE id = ?
 RETURNING *;
+
+-- name: UpdateFlowTeam :one
+UPDATE flows
+SET team_id = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: ReadFlowOwner :one
+SELECT owner_id FROM flows
+WHERE id = ?;
+
+-- name: ReadFlowsPageAsc :many
+SELECT
+  f.*,
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
+FROM flows f
+LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
+WHERE f.status IS COALESCE(sqlc.narg(status), f.status)
+  AND f.model IS COALESCE(sqlc.narg(model), f.model)
+  AND f.model_provider IS COALESCE(sqlc.narg(model_provider), f.model_provider)
+  AND f.owner_id IS COALESCE(sqlc.narg(owner_id), f.owner_id)
+  AND f.team_id IS COALESCE(sqlc.narg(team_id), f.team_id)
+  AND f.created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'))
+  AND (
+    CAST(sqlc.arg(all_flows) AS BOOLEAN)
+    OR f.owner_id = CAST(sqlc.arg(viewer_id) AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(sqlc.arg(viewer_admin) AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(sqlc.arg(viewer_id) AS INTEGER))
+  )
+  AND f.id > COALESCE(sqlc.narg(after_id), 0)
+ORDER BY f.id ASC
+LIMIT sqlc.arg(limit);
+
+-- name: ReadFlowsPageDesc :many
+SELECT
+  f.*,
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
+FROM flows f
+LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
+WHERE f.status IS COALESCE(sqlc.narg(status), f.status)
+  AND f.model IS COALESCE(sqlc.narg(model), f.model)
+  AND f.model_provider IS COALESCE(sqlc.narg(model_provider), f.model_provider)
+  AND f.owner_id IS COALESCE(sqlc.narg(owner_id), f.owner_id)
+  AND f.team_id IS COALESCE(sqlc.narg(team_id), f.team_id)
+  AND f.created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'))
+  AND (
+    CAST(sqlc.arg(all_flows) AS BOOLEAN)
+    OR f.owner_id = CAST(sqlc.arg(viewer_id) AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(sqlc.arg(viewer_admin) AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(sqlc.arg(viewer_id) AS INTEGER))
+  )
+  AND f.id < COALESCE(sqlc.narg(after_id), 9223372036854775807)
+ORDER BY f.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: ReadFlowsPageByNameAsc :many
+SELECT
+  f.*,
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
+FROM flows f
+LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
+WHERE f.status IS COALESCE(sqlc.narg(status), f.status)
+  AND f.model IS COALESCE(sqlc.narg(model), f.model)
+  AND f.model_provider IS COALESCE(sqlc.narg(model_provider), f.model_provider)
+  AND f.owner_id IS COALESCE(sqlc.narg(owner_id), f.owner_id)
+  AND f.team_id IS COALESCE(sqlc.narg(team_id), f.team_id)
+  AND f.created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'))
+  AND (
+    CAST(sqlc.arg(all_flows) AS BOOLEAN)
+    OR f.owner_id = CAST(sqlc.arg(viewer_id) AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(sqlc.arg(viewer_admin) AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(sqlc.arg(viewer_id) AS INTEGER))
+  )
+  AND (
+    sqlc.narg(after_id) IS NULL
+    OR (COALESCE(f.name, ''), f.id) > (CAST(sqlc.arg(after_name) AS TEXT), sqlc.narg(after_id))
+  )
+ORDER BY COALESCE(f.name, '') ASC, f.id ASC
+LIMIT sqlc.arg(limit);
+
+-- name: ReadFlowsPageByNameDesc :many
+SELECT
+  f.*,
+  c.name AS container_name,
+  s.url AS browser_url,
+  s.path AS browser_screenshot
+FROM flows f
+LEFT JOIN containers c ON f.container_id = c.id
+LEFT JOIN screenshots s ON s.id = (
+  SELECT MAX(id) FROM screenshots WHERE flow_id = f.id
+)
+WHERE f.status IS COALESCE(sqlc.narg(status), f.status)
+  AND f.model IS COALESCE(sqlc.narg(model), f.model)
+  AND f.model_provider IS COALESCE(sqlc.narg(model_provider), f.model_provider)
+  AND f.owner_id IS COALESCE(sqlc.narg(owner_id), f.owner_id)
+  AND f.team_id IS COALESCE(sqlc.narg(team_id), f.team_id)
+  AND f.created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'))
+  AND (
+    CAST(sqlc.arg(all_flows) AS BOOLEAN)
+    OR f.owner_id = CAST(sqlc.arg(viewer_id) AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(sqlc.arg(viewer_admin) AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(sqlc.arg(viewer_id) AS INTEGER))
+  )
+  AND (
+    sqlc.narg(after_id) IS NULL
+    OR (COALESCE(f.name, ''), f.id) < (CAST(sqlc.arg(after_name) AS TEXT), sqlc.narg(after_id))
+  )
+ORDER BY COALESCE(f.name, '') DESC, f.id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: CountFlows :one
+SELECT COUNT(*) FROM flows f
+WHERE f.status IS COALESCE(sqlc.narg(status), f.status)
+  AND f.model IS COALESCE(sqlc.narg(model), f.model)
+  AND f.model_provider IS COALESCE(sqlc.narg(model_provider), f.model_provider)
+  AND f.owner_id IS COALESCE(sqlc.narg(owner_id), f.owner_id)
+  AND f.team_id IS COALESCE(sqlc.narg(team_id), f.team_id)
+  AND f.created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND f.created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'))
+  AND (
+    CAST(sqlc.arg(all_flows) AS BOOLEAN)
+    OR f.owner_id = CAST(sqlc.arg(viewer_id) AS INTEGER)
+    OR (f.owner_id IS NULL AND CAST(sqlc.arg(viewer_admin) AS BOOLEAN))
+    OR f.team_id IN (SELECT team_id FROM team_members WHERE user_id = CAST(sqlc.arg(viewer_id) AS INTEGER))
+  );
@@ -12,3 +12,46 @@ SELECT *
 FROM logs
 WHERE flow_id = ?
 ORDER BY created_at ASC;
+
+-- name: CopyLogsToFlow :exec
+INSERT INTO logs (
+  message, created_at, flow_id, type
+)
+SELECT
+  message,
+  created_at,
+  CAST(sqlc.arg(target_flow_id) AS INTEGER),
+  type
+FROM logs
+WHERE flow_id = sqlc.arg(source_flow_id) AND created_at <= sqlc.arg(until)
+ORDER BY id ASC;
+
+-- name: ReadLogsPageAsc :many
+SELECT *
+FROM logs
+WHERE flow_id = sqlc.arg(flow_id)
+  AND type = COALESCE(sqlc.narg(type), type)
+  AND created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'))
+  AND id > COALESCE(sqlc.narg(after_id), 0)
+ORDER BY id ASC
+LIMIT sqlc.arg(limit);
+
+-- name: ReadLogsPageDesc :many
+SELECT *
+FROM logs
+WHERE flow_id = sqlc.arg(flow_id)
+  AND type = COALESCE(sqlc.narg(type), type)
+  AND created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'))
+  AND id < COALESCE(sqlc.narg(after_id), 9223372036854775807)
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: CountLogs :one
+SELECT COUNT(*)
+FROM logs
+WHERE flow_id = sqlc.arg(flow_id)
+  AND type = COALESCE(sqlc.narg(type), type)
+  AND created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'));
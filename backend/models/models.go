@@ -25,6 +25,8 @@ const (
 	Code     TaskType = "code"
 	Ask      TaskType = "ask"
 	Done     TaskType = "done"
+	Search   TaskType = "search"
+	MCP      TaskType = "mcp"
 )
 
 type TaskStatus = string
@@ -36,6 +38,16 @@ const (
 	TaskFailed     TaskStatus = "failed"
 )
 
+// TaskApproval tracks actions that wait for the user after untrusted content
+type TaskApproval = string
+
+const (
+	ApprovalNone     TaskApproval = ""
+	ApprovalPending  TaskApproval = "pending"
+	ApprovalApproved TaskApproval = "approved"
+	ApprovalRejected TaskApproval = "rejected"
+)
+
 type Task struct {
 	ID      uint
 	Message string
@@ -0,0 +1,13 @@
+-- name: CreateScreenshot :one
+INSERT INTO screenshots (
+  flow_id, task_id, url, path, sha256, size
+)
+VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ReadScreenshotsByFlowId :many
+SELECT * FROM screenshots
+WHERE flow_id = ?
+ORDER BY id ASC;
@@ -0,0 +1,45 @@
+-- name: CreateSecret :one
+INSERT INTO secrets (
+  name, value, user_id, team_id
+)
+VALUES (
+  ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: UpdateSecretValue :one
+UPDATE secrets
+SET value = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: ReadSecret :one
+SELECT * FROM secrets
+WHERE id = ?;
+
+-- name: ReadUserSecrets :many
+SELECT * FROM secrets
+WHERE team_id IS NULL AND user_id IS ?
+ORDER BY name ASC;
+
+-- name: ReadUserSecretByName :one
+SELECT * FROM secrets
+WHERE team_id IS NULL AND user_id IS ? AND name = ?;
+
+-- name: ReadTeamSecrets :many
+SELECT * FROM secrets
+WHERE team_id = ?
+ORDER BY name ASC;
+
+-- name: ReadTeamSecretByName :one
+SELECT * FROM secrets
+WHERE team_id = ? AND name = ?;
+
+-- name: ReadFlowSecrets :many
+SELECT * FROM secrets
+WHERE (team_id IS NULL AND user_id IS ?) OR team_id = ?
+ORDER BY team_id IS NULL ASC, name ASC;
+
+-- name: DeleteSecret :execrows
+DELETE FROM secrets
+WHERE id = ?;
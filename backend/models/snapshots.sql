@@ -0,0 +1,23 @@
+-- name: CreateSnapshot :one
+INSERT INTO snapshots (
+  flow_id, task_id, container_id, image, reason
+)
+VALUES (
+  ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ReadSnapshotsByFlowId :many
+SELECT * FROM snapshots
+WHERE flow_id = ?
+ORDER BY task_id ASC, id ASC;
+
+-- name: ReadLatestSnapshotAtTask :one
+SELECT * FROM snapshots
+WHERE flow_id = ? AND task_id <= ?
+ORDER BY task_id DESC, id DESC
+LIMIT 1;
+
+-- name: DeleteSnapshot :exec
+DELETE FROM snapshots
+WHERE id = ?;
@@ -12,6 +12,10 @@ INSERT INTO tasks (
 )
 RETURNING *;
 
+-- name: ReadTask :one
+SELECT * FROM tasks
+WHERE id = ?;
+
 -- name: ReadTasksByFlowId :many
 SELECT * FROM tasks
 WHERE flow_id = ?
@@ -29,8 +33,93 @@ SET results = ?
 WHERE id = ?
 RETURNING *;
 
+-- name: UpdateTaskApproval :one
+UPDATE tasks
+SET approval = sqlc.arg(approval), results = sqlc.arg(results)
+WHERE id = sqlc.arg(id) AND approval = sqlc.arg(current_approval)
+RETURNING *;
+
+-- name: UpdateTaskRedactions :exec
+UPDATE tasks
+SET redactions = ?
+WHERE id = ?;
+
+-- name: UpdateTaskUntrusted :one
+UPDATE tasks
+SET untrusted = ?, injection_flags = ?
+WHERE id = ?
+RETURNING *;
+
 -- name: UpdateTaskToolCallId :one
 UPDATE tasks
 SET tool_call_id = ?
 WHERE id = ?
 RETURNING *;
+
+-- name: DeleteTasksAfter :exec
+DELETE FROM tasks
+WHERE flow_id = ? AND id > ?;
+
+-- name: CopyTasksToFlow :exec
+INSERT INTO tasks (
+  created_at,
+  updated_at,
+  type,
+  status,
+  args,
+  results,
+  message,
+  flow_id,
+  tool_call_id,
+  redactions,
+  untrusted,
+  injection_flags,
+  approval
+)
+SELECT
+  created_at,
+  updated_at,
+  type,
+  status,
+  args,
+  results,
+  message,
+  CAST(sqlc.arg(target_flow_id) AS INTEGER),
+  tool_call_id,
+  redactions,
+  untrusted,
+  injection_flags,
+  approval
+FROM tasks
+WHERE flow_id = sqlc.arg(source_flow_id) AND id <= sqlc.arg(until_task_id)
+ORDER BY id ASC;
+
+-- name: ReadTasksPageAsc :many
+SELECT * FROM tasks
+WHERE flow_id = sqlc.arg(flow_id)
+  AND status IS COALESCE(sqlc.narg(status), status)
+  AND type IS COALESCE(sqlc.narg(type), type)
+  AND created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'))
+  AND id > COALESCE(sqlc.narg(after_id), 0)
+ORDER BY id ASC
+LIMIT sqlc.arg(limit);
+
+-- name: ReadTasksPageDesc :many
+SELECT * FROM tasks
+WHERE flow_id = sqlc.arg(flow_id)
+  AND status IS COALESCE(sqlc.narg(status), status)
+  AND type IS COALESCE(sqlc.narg(type), type)
+  AND created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'))
+  AND id < COALESCE(sqlc.narg(after_id), 9223372036854775807)
+ORDER BY id DESC
+LIMIT sqlc.arg(limit);
+
+-- name: CountTasks :one
+SELECT COUNT(*) FROM tasks
+WHERE flow_id = sqlc.arg(flow_id)
+  AND status IS COALESCE(sqlc.narg(status), status)
+  AND type IS COALESCE(sqlc.narg(type), type)
+  AND created_at >= datetime(COALESCE(sqlc.narg(created_after), '0001-01-01'))
+  AND created_at < datetime(COALESCE(sqlc.narg(created_before), '9999-12-31'));
@@ -0,0 +1,56 @@
+-- name: CreateTeam :one
+INSERT INTO teams (
+  name
+)
+VALUES (
+  ?
+)
+RETURNING *;
+
+-- name: ReadTeam :one
+SELECT * FROM teams
+WHERE id = ?;
+
+-- name: ReadAllTeams :many
+SELECT * FROM teams
+ORDER BY name ASC;
+
+-- name: ReadTeamsByUserId :many
+SELECT t.* FROM teams t
+JOIN team_members m ON m.team_id = t.id
+WHERE m.user_id = ?
+ORDER BY t.name ASC;
+
+-- name: DeleteTeam :execrows
+DELETE FROM teams
+WHERE id = ?;
+
+-- name: UpsertTeamMember :exec
+INSERT INTO team_members (
+  team_id, user_id, role
+)
+VALUES (
+  ?, ?, ?
+)
+ON CONFLICT (team_id, user_id) DO UPDATE SET role = excluded.role;
+
+-- name: ReadTeamMember :one
+SELECT * FROM team_members
+WHERE team_id = ? AND user_id = ?;
+
+-- name: ReadTeamMembers :many
+SELECT
+  m.role,
+  u.id, u.created_at, u.username, u.admin
+FROM team_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.team_id = ?
+ORDER BY u.username ASC;
+
+-- name: ReadTeamMembershipsByUserId :many
+SELECT * FROM team_members
+WHERE user_id = ?;
+
+-- name: DeleteTeamMember :execrows
+DELETE FROM team_members
+WHERE team_id = ? AND user_id = ?;
@@ -0,0 +1,89 @@
+-- name: CreateUser :one
+INSERT INTO users (
+  username, password_hash, admin
+)
+VALUES (
+  ?, ?, ?
+)
+RETURNING *;
+
+-- name: ReadUser :one
+SELECT * FROM users
+WHERE id = ?;
+
+-- name: ReadUserByUsername :one
+SELECT * FROM users
+WHERE username = ?;
+
+-- name: ReadUserByOidcSubject :one
+SELECT * FROM users
+WHERE oidc_subject = ?;
+
+-- name: ReadAllUsers :many
+SELECT * FROM users
+ORDER BY username ASC;
+
+-- name: CreateOidcUser :one
+INSERT INTO users (
+  username, password_hash, admin, oidc_subject
+)
+VALUES (
+  ?, '', ?, ?
+)
+RETURNING *;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = ?
+WHERE id = ?;
+
+-- name: UpdateUserAdmin :exec
+UPDATE users
+SET admin = ?
+WHERE id = ?;
+
+-- name: CreateApiToken :one
+INSERT INTO api_tokens (
+  user_id, name, token_hash, scope
+)
+VALUES (
+  ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ReadApiTokenUser :one
+SELECT
+  t.id AS token_id,
+  t.scope AS token_scope,
+  t.last_used_at AS token_last_used_at,
+  u.id, u.created_at, u.username, u.password_hash, u.admin
+FROM api_tokens t
+JOIN users u ON u.id = t.user_id
+WHERE t.token_hash = ?;
+
+-- name: ReadApiTokensByUserId :many
+SELECT * FROM api_tokens
+WHERE user_id = ?
+ORDER BY id ASC;
+
+-- name: UpdateApiTokenLastUsed :exec
+UPDATE api_tokens
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: RotateApiToken :one
+UPDATE api_tokens
+SET token_hash = ?, created_at = CURRENT_TIMESTAMP, last_used_at = NULL
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: DeleteApiToken :execrows
+DELETE FROM api_tokens
+WHERE id = ? AND user_id = ?;
+
+-- name: DeleteApiTokenByHash :exec
+DELETE FROM api_tokens
+WHERE token_hash = ?;
@@ -0,0 +1,252 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockSkew is the tolerance applied to the time claims of ID tokens
+const clockSkew = time.Minute
+
+// keyRefreshInterval limits how often an unknown key id triggers a JWKS refetch
+const keyRefreshInterval = time.Minute
+
+var errUnknownKey = errors.New("no matching signing key")
+
+// Claims are the claims of an ID token or a userinfo response
+type Claims map[string]any
+
+// String returns a string claim, or "" if it is missing or not a string
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns a claim holding a list of strings; a single string is
+// returned as a list of one
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// time returns a NumericDate claim
+func (c Claims) time(name string) (time.Time, bool) {
+	v, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// verifyIDToken checks the signature and claims of an ID token
+func (c *Client) verifyIDToken(ctx context.Context, raw string, nonce string, now time.Time) (Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %w", err)
+	}
+	if err := c.keys.verify(ctx, header, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed claims: %w", err)
+	}
+
+	if claims.String("iss") != c.meta.Issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.String("iss"))
+	}
+	audience := claims.Strings("aud")
+	if !slices.Contains(audience, c.cfg.ClientID) {
+		return nil, fmt.Errorf("token is not issued for this client")
+	}
+	if azp := claims.String("azp"); len(audience) > 1 && azp != c.cfg.ClientID {
+		return nil, fmt.Errorf("token is authorized for another party")
+	}
+	if claims.String("sub") == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+	exp, ok := claims.time("exp")
+	if !ok || now.After(exp.Add(clockSkew)) {
+		return nil, fmt.Errorf("token is expired")
+	}
+	if iat, ok := claims.time("iat"); ok && iat.After(now.Add(clockSkew)) {
+		return nil, fmt.Errorf("token is issued in the future")
+	}
+	if claims.String("nonce") != nonce {
+		return nil, fmt.Errorf("nonce does not match")
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// jsonWebKey is a public key of the provider's JWKS
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// keySet caches the provider's signing keys and refetches them when a token
+// names a key it does not know, which is how providers rotate keys
+type keySet struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    []jsonWebKey
+	fetched time.Time
+}
+
+// verify checks a signature with the provider key named by the header
+func (s *keySet) verify(ctx context.Context, header jwtHeader, signed []byte, signature []byte) error {
+	if header.Alg != "RS256" && header.Alg != "ES256" {
+		return fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys != nil {
+		err := verifyWithKeys(s.keys, header, signed, signature)
+		if !errors.Is(err, errUnknownKey) || time.Since(s.fetched) < keyRefreshInterval {
+			return err
+		}
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, s.client, s.url, "", &jwks); err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	s.keys = jwks.Keys
+	s.fetched = time.Now()
+
+	return verifyWithKeys(s.keys, header, signed, signature)
+}
+
+// verifyWithKeys tries the keys matching the header's key id and algorithm
+func verifyWithKeys(keys []jsonWebKey, header jwtHeader, signed []byte, signature []byte) error {
+	kty := "RSA"
+	if header.Alg == "ES256" {
+		kty = "EC"
+	}
+
+	found := false
+	for _, key := range keys {
+		if key.Kty != kty || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		if header.Kid != "" && key.Kid != header.Kid {
+			continue
+		}
+		found = true
+		if err := verifySignature(key, signed, signature); err == nil {
+			return nil
+		}
+	}
+	if !found {
+		return errUnknownKey
+	}
+	return fmt.Errorf("invalid signature")
+}
+
+func verifySignature(key jsonWebKey, signed []byte, signature []byte) error {
+	digest := sha256.Sum256(signed)
+
+	switch key.Kty {
+	case "RSA":
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return err
+		}
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return err
+		}
+		pub := &rsa.PublicKey{N: n, E: int(e.Int64())}
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature)
+
+	case "EC":
+		if key.Crv != "P-256" || len(signature) != 64 {
+			return fmt.Errorf("unsupported EC key")
+		}
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return err
+		}
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		r := new(big.Int).SetBytes(signature[:32])
+		sigS := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, sigS) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported key type %q", key.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("malformed key")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
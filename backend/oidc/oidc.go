@@ -0,0 +1,332 @@
+// Package oidc implements the OpenID Connect authorization code flow used for
+// single sign-on: provider discovery, PKCE, the code exchange and ID token
+// verification against the provider's published keys.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotConfigured is returned when single sign-on is disabled
+	ErrNotConfigured = errors.New("single sign-on is not configured")
+	// ErrInvalidState is returned for a callback that does not match a started login
+	ErrInvalidState = errors.New("login expired or was not started here")
+)
+
+// LoginTTL is how long a started login may take to come back to the callback
+const LoginTTL = 10 * time.Minute
+
+// maxResponseSize caps the responses read from the provider
+const maxResponseSize = 1 << 20
+
+// Config holds the client registration at the identity provider
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// metadata is the subset of the discovery document used by the client
+type metadata struct {
+	Issuer                   string   `json:"issuer"`
+	AuthorizationEndpoint    string   `json:"authorization_endpoint"`
+	TokenEndpoint            string   `json:"token_endpoint"`
+	UserinfoEndpoint         string   `json:"userinfo_endpoint"`
+	JWKSURI                  string   `json:"jwks_uri"`
+	TokenEndpointAuthMethods []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+type pendingLogin struct {
+	nonce    string
+	verifier string
+	expires  time.Time
+}
+
+// Identity is the user returned by a finished login
+type Identity struct {
+	Issuer  string
+	Subject string
+	// Claims of the ID token, completed with the userinfo endpoint
+	Claims Claims
+}
+
+// Client runs logins against one identity provider
+type Client struct {
+	cfg  Config
+	meta metadata
+	http *http.Client
+	keys *keySet
+
+	mu      sync.Mutex
+	pending map[string]pendingLogin
+}
+
+// NewClient discovers the provider and returns a client for it
+func NewClient(ctx context.Context, cfg Config) (*Client, error) {
+	if cfg.IssuerURL == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("issuer URL, client ID and redirect URL are required")
+	}
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+
+	c := &Client{
+		cfg:     cfg,
+		http:    &http.Client{Timeout: 15 * time.Second},
+		pending: make(map[string]pendingLogin),
+	}
+
+	discoveryURL := strings.TrimSuffix(cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := c.getJSON(ctx, discoveryURL, "", &c.meta); err != nil {
+		return nil, fmt.Errorf("failed to discover provider: %w", err)
+	}
+	if strings.TrimSuffix(c.meta.Issuer, "/") != strings.TrimSuffix(cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("provider issuer %q does not match %q", c.meta.Issuer, cfg.IssuerURL)
+	}
+	if c.meta.AuthorizationEndpoint == "" || c.meta.TokenEndpoint == "" || c.meta.JWKSURI == "" {
+		return nil, fmt.Errorf("provider discovery document is missing endpoints")
+	}
+
+	c.keys = &keySet{url: c.meta.JWKSURI, client: c.http}
+	return c, nil
+}
+
+// StartLogin returns the provider URL the browser is sent to, and the state
+// that must come back with the callback
+func (c *Client) StartLogin() (authURL string, state string, err error) {
+	state, err = randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	u, err := url.Parse(c.meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.cfg.ClientID)
+	q.Set("redirect_uri", c.cfg.RedirectURL)
+	q.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for s, p := range c.pending {
+		if now.After(p.expires) {
+			delete(c.pending, s)
+		}
+	}
+	c.pending[state] = pendingLogin{nonce: nonce, verifier: verifier, expires: now.Add(LoginTTL)}
+
+	return u.String(), state, nil
+}
+
+// FinishLogin exchanges the code of a callback and returns the verified identity
+// Each state can be used once
+func (c *Client) FinishLogin(ctx context.Context, state string, code string) (*Identity, error) {
+	c.mu.Lock()
+	pending, ok := c.pending[state]
+	delete(c.pending, state)
+	c.mu.Unlock()
+	if !ok || time.Now().After(pending.expires) {
+		return nil, ErrInvalidState
+	}
+	if code == "" {
+		return nil, fmt.Errorf("callback has no code")
+	}
+
+	tokens, err := c.exchange(ctx, code, pending.verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := c.verifyIDToken(ctx, tokens.IDToken, pending.nonce, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	// Many providers only return groups and profile claims from userinfo
+	if c.meta.UserinfoEndpoint != "" && tokens.AccessToken != "" {
+		var info Claims
+		if err := c.getJSON(ctx, c.meta.UserinfoEndpoint, tokens.AccessToken, &info); err != nil {
+			return nil, fmt.Errorf("failed to read userinfo: %w", err)
+		}
+		if info.String("sub") != claims.String("sub") {
+			return nil, fmt.Errorf("userinfo subject does not match the id token")
+		}
+		for name, value := range info {
+			if _, ok := claims[name]; !ok {
+				claims[name] = value
+			}
+		}
+	}
+
+	return &Identity{
+		Issuer:  claims.String("iss"),
+		Subject: claims.String("sub"),
+		Claims:  claims,
+	}, nil
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchange trades an authorization code for the provider's tokens
+func (c *Client) exchange(ctx context.Context, code string, verifier string) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+
+	// client_secret_basic is the default; fall back to client_secret_post
+	// for providers that only accept that
+	basic := len(c.meta.TokenEndpointAuthMethods) == 0 || slices.Contains(c.meta.TokenEndpointAuthMethods, "client_secret_basic")
+	if !basic || c.cfg.ClientSecret == "" {
+		form.Set("client_id", c.cfg.ClientID)
+		if c.cfg.ClientSecret != "" {
+			form.Set("client_secret", c.cfg.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if basic && c.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokens tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("token endpoint error: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("token endpoint returned no id token")
+	}
+	return &tokens, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into v
+func (c *Client) getJSON(ctx context.Context, rawURL string, bearer string, v any) error {
+	return getJSON(ctx, c.http, rawURL, bearer, v)
+}
+
+func getJSON(ctx context.Context, client *http.Client, rawURL string, bearer string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", rawURL, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", rawURL, err)
+	}
+	return nil
+}
+
+// randomString returns 32 random bytes, base64url encoded
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultClient *Client
+)
+
+// Init discovers the provider used for single sign-on
+func Init(ctx context.Context, cfg Config) error {
+	c, err := NewClient(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	defaultMu.Lock()
+	defaultClient = c
+	defaultMu.Unlock()
+	return nil
+}
+
+// Default returns the single sign-on client, or ErrNotConfigured
+func Default() (*Client, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	if defaultClient == nil {
+		return nil, ErrNotConfigured
+	}
+	return defaultClient, nil
+}
+
+// Enabled reports whether single sign-on is configured
+func Enabled() bool {
+	_, err := Default()
+	return err == nil
+}
@@ -0,0 +1,426 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockIdP is a minimal OpenID provider: it approves every authorization
+// request and issues an ID token for a fixed user
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu         sync.Mutex
+	rsaKey     *rsa.PrivateKey
+	ecKey      *ecdsa.PrivateKey
+	kid        string
+	alg        string
+	codes      map[string]url.Values
+	jwksHits   int
+	claims     map[string]any
+	userinfo   map[string]any
+	tokenError string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &mockIdP{
+		t:      t,
+		rsaKey: rsaKey,
+		ecKey:  ecKey,
+		kid:    "key-1",
+		alg:    "RS256",
+		codes:  make(map[string]url.Values),
+		claims: map[string]any{"preferred_username": "alice"},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/userinfo", idp.userinfoHandler)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	base := idp.server.URL
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                base,
+		"authorization_endpoint":                base + "/authorize",
+		"token_endpoint":                        base + "/token",
+		"userinfo_endpoint":                     base + "/userinfo",
+		"jwks_uri":                              base + "/jwks",
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic"},
+	})
+}
+
+// authorize approves the login and redirects back with a code
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	code := "code-" + q.Get("state")[:8]
+
+	idp.mu.Lock()
+	idp.codes[code] = q
+	idp.mu.Unlock()
+
+	http.Redirect(w, r, q.Get("redirect_uri")+"?code="+code+"&state="+url.QueryEscape(q.Get("state")), http.StatusFound)
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	if idp.tokenError != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": idp.tokenError})
+		return
+	}
+
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != "arandu" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	auth, ok := idp.codes[r.FormValue("code")]
+	delete(idp.codes, r.FormValue("code"))
+	if !ok || r.FormValue("redirect_uri") != auth.Get("redirect_uri") {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	// PKCE: the verifier must hash to the challenge of the authorization request
+	sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.Get("code_challenge") {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "pkce"})
+		return
+	}
+
+	claims := map[string]any{
+		"iss":   idp.server.URL,
+		"sub":   "user-123",
+		"aud":   "arandu",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": auth.Get("nonce"),
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     idp.sign(claims),
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.jwksHits++
+
+	enc := base64.RawURLEncoding.EncodeToString
+	_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+		{
+			"kty": "RSA", "kid": idp.kid, "use": "sig",
+			"n": enc(idp.rsaKey.N.Bytes()),
+			"e": enc(big.NewInt(int64(idp.rsaKey.E)).Bytes()),
+		},
+		{
+			"kty": "EC", "kid": idp.kid + "-ec", "crv": "P-256",
+			"x": enc(idp.ecKey.X.FillBytes(make([]byte, 32))),
+			"y": enc(idp.ecKey.Y.FillBytes(make([]byte, 32))),
+		},
+	}})
+}
+
+func (idp *mockIdP) userinfoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer access-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	info := map[string]any{"sub": "user-123"}
+	for k, v := range idp.userinfo {
+		info[k] = v
+	}
+	_ = json.NewEncoder(w).Encode(info)
+}
+
+// sign builds a compact JWS with the current key and algorithm
+func (idp *mockIdP) sign(claims map[string]any) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	kid := idp.kid
+	if idp.alg == "ES256" {
+		kid += "-ec"
+	}
+	header, _ := json.Marshal(map[string]string{"alg": idp.alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := enc(header) + "." + enc(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch idp.alg {
+	case "RS256":
+		s, err := rsa.SignPKCS1v15(rand.Reader, idp.rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			idp.t.Fatal(err)
+		}
+		sig = s
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, idp.ecKey, digest[:])
+		if err != nil {
+			idp.t.Fatal(err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	default:
+		sig = []byte("unsigned")
+	}
+	return signed + "." + enc(sig)
+}
+
+func (idp *mockIdP) client(t *testing.T) *Client {
+	c, err := NewClient(context.Background(), Config{
+		IssuerURL:    idp.server.URL,
+		ClientID:     "arandu",
+		ClientSecret: "secret",
+		RedirectURL:  "http://arandu.test/auth/oidc/callback",
+		Scopes:       []string{"profile", "groups"},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+// login runs the browser side of the flow: follow the authorization URL and
+// return the state and code of the callback
+func login(t *testing.T, c *Client) (state string, code string) {
+	authURL, state, err := c.StartLogin()
+	if err != nil {
+		t.Fatalf("StartLogin() error = %v", err)
+	}
+
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirect.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := callback.Query().Get("state"); got != state {
+		t.Fatalf("callback state = %q, want %q", got, state)
+	}
+	return state, callback.Query().Get("code")
+}
+
+func TestStartLogin(t *testing.T) {
+	c := newMockIdP(t).client(t)
+
+	authURL, state, err := c.StartLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "arandu",
+		"redirect_uri":          "http://arandu.test/auth/oidc/callback",
+		"scope":                 "openid profile groups",
+		"state":                 state,
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+	if q.Get("nonce") == "" || q.Get("code_challenge") == "" {
+		t.Error("authorization URL has no nonce or PKCE challenge")
+	}
+}
+
+func TestFinishLogin(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.userinfo = map[string]any{"groups": []string{"dev", "ops"}, "preferred_username": "ignored"}
+	c := idp.client(t)
+
+	state, code := login(t, c)
+	identity, err := c.FinishLogin(context.Background(), state, code)
+	if err != nil {
+		t.Fatalf("FinishLogin() error = %v", err)
+	}
+
+	if identity.Subject != "user-123" || identity.Issuer != idp.server.URL {
+		t.Errorf("identity = %s %s", identity.Issuer, identity.Subject)
+	}
+	// ID token claims win over userinfo; missing ones are filled in
+	if got := identity.Claims.String("preferred_username"); got != "alice" {
+		t.Errorf("username = %q, want alice", got)
+	}
+	if got := strings.Join(identity.Claims.Strings("groups"), ","); got != "dev,ops" {
+		t.Errorf("groups = %q, want dev,ops", got)
+	}
+
+	// A state can only be used once
+	if _, err := c.FinishLogin(context.Background(), state, code); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("replayed state error = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestFinishLoginES256(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.alg = "ES256"
+	c := idp.client(t)
+
+	state, code := login(t, c)
+	if _, err := c.FinishLogin(context.Background(), state, code); err != nil {
+		t.Fatalf("FinishLogin() error = %v", err)
+	}
+}
+
+func TestFinishLoginRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(idp *mockIdP)
+		wantErr string
+	}{
+		{name: "wrong audience", setup: func(idp *mockIdP) { idp.claims["aud"] = "other-app" }, wantErr: "not issued for this client"},
+		{name: "wrong issuer", setup: func(idp *mockIdP) { idp.claims["iss"] = "https://evil.example" }, wantErr: "unexpected issuer"},
+		{name: "expired", setup: func(idp *mockIdP) { idp.claims["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: "expired"},
+		{name: "wrong nonce", setup: func(idp *mockIdP) { idp.claims["nonce"] = "replayed" }, wantErr: "nonce"},
+		{name: "unsigned", setup: func(idp *mockIdP) { idp.alg = "none" }, wantErr: "unsupported signing algorithm"},
+		{name: "token endpoint error", setup: func(idp *mockIdP) { idp.tokenError = "invalid_grant" }, wantErr: "invalid_grant"},
+		{name: "userinfo for another subject", setup: func(idp *mockIdP) { idp.userinfo = map[string]any{"sub": "someone-else"} }, wantErr: "userinfo subject"},
+		{
+			name: "signed with another key",
+			setup: func(idp *mockIdP) {
+				other, _ := rsa.GenerateKey(rand.Reader, 2048)
+				// The JWKS was already fetched with the original key
+				idp.rsaKey = other
+			},
+			wantErr: "invalid signature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			c := idp.client(t)
+
+			// Fetch the keys once with the original key
+			state, code := login(t, c)
+			if _, err := c.FinishLogin(context.Background(), state, code); err != nil {
+				t.Fatalf("first login error = %v", err)
+			}
+
+			tt.setup(idp)
+			state, code = login(t, c)
+			_, err := c.FinishLogin(context.Background(), state, code)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FinishLogin() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFinishLoginUnknownState(t *testing.T) {
+	c := newMockIdP(t).client(t)
+	if _, err := c.FinishLogin(context.Background(), "forged", "code"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("FinishLogin(forged state) error = %v, want ErrInvalidState", err)
+	}
+}
+
+// TestKeyRotation checks that a token signed with a new key id triggers a JWKS refetch
+func TestKeyRotation(t *testing.T) {
+	idp := newMockIdP(t)
+	c := idp.client(t)
+
+	state, code := login(t, c)
+	if _, err := c.FinishLogin(context.Background(), state, code); err != nil {
+		t.Fatal(err)
+	}
+
+	idp.mu.Lock()
+	idp.kid = "key-2"
+	idp.rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	idp.mu.Unlock()
+	// Let the refresh interval pass
+	c.keys.fetched = time.Now().Add(-2 * keyRefreshInterval)
+
+	state, code = login(t, c)
+	if _, err := c.FinishLogin(context.Background(), state, code); err != nil {
+		t.Fatalf("login after rotation error = %v", err)
+	}
+	if idp.jwksHits != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", idp.jwksHits)
+	}
+}
+
+func TestNewClientIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	_, err := NewClient(context.Background(), Config{
+		IssuerURL:   idp.server.URL + "/tenant",
+		ClientID:    "arandu",
+		RedirectURL: "http://arandu.test/cb",
+	})
+	if err == nil {
+		t.Error("NewClient() should fail when discovery is missing or the issuer differs")
+	}
+}
+
+func TestClaimsStrings(t *testing.T) {
+	claims := Claims{"one": "a", "many": []any{"a", 1.0, "b"}, "num": 1.0}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "one", want: "a"},
+		{name: "many", want: "a,b"},
+		{name: "num", want: ""},
+		{name: "missing", want: ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(claims.Strings(tt.name), ","); got != tt.want {
+			t.Errorf("Strings(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/arandu-ai/arandu/assets"
+	"github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/security"
 	"github.com/arandu-ai/arandu/templates"
 	"github.com/tmc/langchaingo/llms"
 )
@@ -32,8 +34,15 @@ func Summary(llm llms.Model, model string, query string, n int) (string, error)
 }
 
 func DockerImageName(llm llms.Model, model string, task string) (string, error) {
+	var allowedImages []string
+	if !config.Config.AllowAnyDockerImage {
+		allowedImages = security.AllowedDockerImageNames()
+	}
+
 	prompt, err := templates.Render(assets.PromptTemplates, "prompts/docker.tmpl", map[string]any{
-		"Task": task,
+		"Task":          task,
+		"CustomImages":  security.DescribedDockerImages(),
+		"AllowedImages": allowedImages,
 	})
 	if err != nil {
 		return "", err
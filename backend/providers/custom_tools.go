@@ -0,0 +1,231 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// CustomToolCommand runs a shell command in the flow container
+// Command is a text/template rendered with the tool arguments; every
+// value is inserted already shell-quoted
+type CustomToolCommand struct {
+	Command string `json:"command"`
+}
+
+// CustomToolWebhook posts the tool call to an HTTP endpoint and returns its response body
+type CustomToolWebhook struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// CustomTool is a tool declared in CUSTOM_TOOLS_FILE
+// Exactly one of Exec or Webhook must be set
+type CustomTool struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Parameters  map[string]any     `json:"parameters"`
+	Exec        *CustomToolCommand `json:"exec,omitempty"`
+	Webhook     *CustomToolWebhook `json:"webhook,omitempty"`
+	// Timeout is a Go duration ("30s", "2m"); empty uses DefaultCustomToolTimeout
+	Timeout string `json:"timeout,omitempty"`
+
+	template *template.Template
+	timeout  time.Duration
+}
+
+// CustomToolsFile is the format of the file referenced by CUSTOM_TOOLS_FILE
+type CustomToolsFile struct {
+	Tools []CustomTool `json:"tools"`
+}
+
+// DefaultCustomToolTimeout limits a custom tool call when the tool sets no timeout
+const DefaultCustomToolTimeout = 60 * time.Second
+
+var customToolName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// builtinTools are the tool names handled by the executor itself
+var builtinTools = map[string]bool{
+	"input": true, "terminal": true, "browser": true, "search": true,
+	"code": true, "ask": true, "done": true,
+}
+
+// customTools maps the name of each registered custom tool to its definition
+var customTools = map[string]*CustomTool{}
+
+// Template returns the parsed command template of an exec tool
+func (t *CustomTool) Template() *template.Template {
+	return t.template
+}
+
+// CallTimeout returns how long a single call of the tool may take
+func (t *CustomTool) CallTimeout() time.Duration {
+	return t.timeout
+}
+
+// LoadCustomTools registers the tools declared in a JSON file, replacing
+// any custom tools loaded before
+// It must be called at startup, before any flow is processed
+func LoadCustomTools(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading custom tools file: %w", err)
+	}
+
+	var file CustomToolsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("error parsing custom tools file: %w", err)
+	}
+
+	loaded := map[string]*CustomTool{}
+	for i := range file.Tools {
+		tool := &file.Tools[i]
+		if err := tool.validate(); err != nil {
+			return err
+		}
+		if _, ok := loaded[tool.Name]; ok {
+			return fmt.Errorf("custom tool %s is declared twice", tool.Name)
+		}
+		loaded[tool.Name] = tool
+	}
+
+	names := make([]string, 0, len(loaded))
+	for name := range loaded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Drop the previous custom tools so a reload does not duplicate them
+	tools := make([]llms.Tool, 0, len(Tools)+len(names))
+	for _, t := range Tools {
+		if _, ok := customTools[t.Function.Name]; !ok {
+			tools = append(tools, t)
+		}
+	}
+
+	for _, name := range names {
+		tool := loaded[name]
+		tools = append(tools, llms.Tool{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	Tools = tools
+	customTools = loaded
+	return nil
+}
+
+// validate checks a tool definition and prepares its template and timeout
+func (t *CustomTool) validate() error {
+	if !customToolName.MatchString(t.Name) {
+		return fmt.Errorf("custom tool %q: name must match %s", t.Name, customToolName)
+	}
+	if builtinTools[t.Name] || strings.HasPrefix(t.Name, MCPToolPrefix) {
+		return fmt.Errorf("custom tool %s: name is reserved for a built-in tool", t.Name)
+	}
+	if strings.TrimSpace(t.Description) == "" {
+		return fmt.Errorf("custom tool %s: description is required", t.Name)
+	}
+
+	if (t.Exec == nil) == (t.Webhook == nil) {
+		return fmt.Errorf("custom tool %s: set exactly one of exec or webhook", t.Name)
+	}
+	if t.Exec != nil {
+		if strings.TrimSpace(t.Exec.Command) == "" {
+			return fmt.Errorf("custom tool %s: exec.command is required", t.Name)
+		}
+		tmpl, err := template.New(t.Name).Option("missingkey=zero").Parse(t.Exec.Command)
+		if err != nil {
+			return fmt.Errorf("custom tool %s: invalid command template: %w", t.Name, err)
+		}
+		t.template = tmpl
+	}
+	if t.Webhook != nil {
+		u, err := url.Parse(t.Webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("custom tool %s: webhook.url must be an http(s) URL", t.Name)
+		}
+	}
+
+	t.timeout = DefaultCustomToolTimeout
+	if t.Timeout != "" {
+		d, err := time.ParseDuration(t.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("custom tool %s: invalid timeout %q", t.Name, t.Timeout)
+		}
+		t.timeout = d
+	}
+
+	params, err := customToolParameters(t.Parameters)
+	if err != nil {
+		return fmt.Errorf("custom tool %s: %w", t.Name, err)
+	}
+	t.Parameters = params
+
+	return nil
+}
+
+// customToolParameters validates the JSON schema of a tool and adds the
+// message property every tool call carries
+func customToolParameters(schema map[string]any) (map[string]any, error) {
+	if schema == nil {
+		schema = map[string]any{"type": "object"}
+	}
+	if schema["type"] != "object" {
+		return nil, fmt.Errorf(`parameters must be a JSON schema with "type": "object"`)
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	if props == nil {
+		if _, ok := schema["properties"]; ok {
+			return nil, fmt.Errorf("parameters.properties must be an object")
+		}
+		props = map[string]any{}
+	}
+	if _, ok := props["message"]; !ok {
+		props["message"] = map[string]any{
+			"type":        "string",
+			"description": "Message shown to the user",
+		}
+	}
+	schema["properties"] = props
+
+	return schema, nil
+}
+
+// GetCustomTool returns the custom tool registered with the given name
+func GetCustomTool(name string) (*CustomTool, bool) {
+	tool, ok := customTools[name]
+	return tool, ok
+}
+
+// CustomToolNames returns the sorted names of the registered custom tools
+func CustomToolNames() []string {
+	names := make([]string, 0, len(customTools))
+	for name := range customTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CustomArgs are the arguments of a custom tool call
+type CustomArgs map[string]any
+
+func (a *CustomArgs) GetMessage() Message {
+	msg, _ := (*a)["message"].(string)
+	return Message(msg)
+}
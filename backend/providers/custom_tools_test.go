@@ -0,0 +1,146 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// resetCustomTools restores the built-in tool set after a test
+func resetCustomTools(t *testing.T) {
+	builtin := append([]llms.Tool(nil), Tools...)
+	t.Cleanup(func() {
+		Tools = builtin
+		customTools = map[string]*CustomTool{}
+	})
+}
+
+func writeCustomTools(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "tools.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCustomTools(t *testing.T) {
+	resetCustomTools(t)
+
+	path := writeCustomTools(t, `{"tools": [
+		{
+			"name": "run_tests",
+			"description": "Runs the test suite",
+			"parameters": {"type": "object", "properties": {"pattern": {"type": "string"}}},
+			"exec": {"command": "go test -run {{.pattern}} ./..."},
+			"timeout": "5m"
+		},
+		{
+			"name": "open_jira_ticket",
+			"description": "Opens a Jira ticket",
+			"webhook": {"url": "https://hooks.example.com/jira", "headers": {"Authorization": "Bearer x"}}
+		}
+	]}`)
+
+	builtin := len(Tools)
+	if err := LoadCustomTools(path); err != nil {
+		t.Fatalf("LoadCustomTools() error = %v", err)
+	}
+
+	if len(Tools) != builtin+2 {
+		t.Fatalf("Tools has %d entries, want %d", len(Tools), builtin+2)
+	}
+	if got := strings.Join(CustomToolNames(), ","); got != "open_jira_ticket,run_tests" {
+		t.Errorf("CustomToolNames() = %s", got)
+	}
+
+	tool, ok := GetCustomTool("run_tests")
+	if !ok {
+		t.Fatal("GetCustomTool(run_tests) not found")
+	}
+	if tool.CallTimeout().Minutes() != 5 || tool.Template() == nil {
+		t.Errorf("run_tests timeout = %v, template = %v", tool.CallTimeout(), tool.Template())
+	}
+
+	jira, _ := GetCustomTool("open_jira_ticket")
+	if jira.CallTimeout() != DefaultCustomToolTimeout {
+		t.Errorf("open_jira_ticket timeout = %v, want default", jira.CallTimeout())
+	}
+	props := jira.Parameters["properties"].(map[string]any)
+	if _, ok := props["message"]; !ok {
+		t.Error("parameters should include the message property")
+	}
+
+	// The JSON placeholder path lists the same tools
+	if placeholder := getToolPlaceholder(nil); !strings.Contains(placeholder, "open_jira_ticket") {
+		t.Error("tool placeholder should include custom tools")
+	}
+}
+
+func TestLoadCustomTools_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+	}{
+		{name: "builtin name", tool: `{"name": "terminal", "description": "d", "exec": {"command": "ls"}}`},
+		{name: "invalid name", tool: `{"name": "Run Tests", "description": "d", "exec": {"command": "ls"}}`},
+		{name: "no description", tool: `{"name": "t", "exec": {"command": "ls"}}`},
+		{name: "no executor", tool: `{"name": "t", "description": "d"}`},
+		{name: "two executors", tool: `{"name": "t", "description": "d", "exec": {"command": "ls"}, "webhook": {"url": "https://x.example"}}`},
+		{name: "bad template", tool: `{"name": "t", "description": "d", "exec": {"command": "ls {{.x"}}`},
+		{name: "bad webhook url", tool: `{"name": "t", "description": "d", "webhook": {"url": "ftp://x.example"}}`},
+		{name: "bad timeout", tool: `{"name": "t", "description": "d", "exec": {"command": "ls"}, "timeout": "soon"}`},
+		{name: "non object schema", tool: `{"name": "t", "description": "d", "exec": {"command": "ls"}, "parameters": {"type": "string"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCustomTools(t)
+			builtin := len(Tools)
+
+			if err := LoadCustomTools(writeCustomTools(t, `{"tools": [`+tt.tool+`]}`)); err == nil {
+				t.Error("LoadCustomTools() expected error")
+			}
+			if len(Tools) != builtin {
+				t.Error("an invalid file should not register any tool")
+			}
+		})
+	}
+}
+
+func TestToolToTask_CustomTool(t *testing.T) {
+	resetCustomTools(t)
+
+	path := writeCustomTools(t, `{"tools": [{"name": "query_staging_db", "description": "Runs a read-only query", "webhook": {"url": "https://db.example.com/query"}}]}`)
+	if err := LoadCustomTools(path); err != nil {
+		t.Fatal(err)
+	}
+
+	choices := []*llms.ContentChoice{{
+		ToolCalls: []llms.ToolCall{{
+			ID: "call_1",
+			FunctionCall: &llms.FunctionCall{
+				Name:      "query_staging_db",
+				Arguments: `{"sql": "select 1", "message": "Checking the database"}`,
+			},
+		}},
+	}}
+
+	task, err := toolToTask(choices)
+	if err != nil {
+		t.Fatalf("toolToTask() error = %v", err)
+	}
+	if task.Type.String != "query_staging_db" || task.Message.String != "Checking the database" {
+		t.Errorf("toolToTask() = type %q, message %q", task.Type.String, task.Message.String)
+	}
+	if !strings.Contains(task.Args.String, `"sql":"select 1"`) {
+		t.Errorf("toolToTask() args = %s", task.Args.String)
+	}
+
+	choices[0].ToolCalls[0].FunctionCall.Name = "not_registered"
+	if _, err := toolToTask(choices); err == nil {
+		t.Error("toolToTask() should reject unknown tools")
+	}
+}
@@ -189,6 +189,8 @@ func localModelNextTask(client *openai.LLM, model string, args NextTaskOptions,
 	prepared, err := PreparePrompt(PromptConfig{
 		DockerImage:  args.DockerImage,
 		Tasks:        args.Tasks,
+		Tools:        args.Tools,
+		Secrets:      args.Secrets,
 		UseToolCalls: useToolCalls,
 	})
 
@@ -201,6 +203,7 @@ func localModelNextTask(client *openai.LLM, model string, args NextTaskOptions,
 		Client:       client,
 		Model:        model,
 		Messages:     prepared.Messages,
+		Tools:        args.Tools,
 		UseToolCalls: useToolCalls,
 		Temperature:  0.1, // Slightly higher for local models
 		TopP:         0.9,
@@ -0,0 +1,137 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/models"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// MCPToolPrefix marks the tools that come from MCP servers: mcp__<server>__<tool>
+const MCPToolPrefix = "mcp__"
+
+// maxToolNameLength is the longest function name accepted by OpenAI-style APIs
+const maxToolNameLength = 64
+
+var unsafeToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// MCPArgs are the arguments of an mcp task
+// The model calls mcp__<server>__<tool>; the task keeps the parts separately
+type MCPArgs struct {
+	Server    string
+	Tool      string
+	Arguments map[string]any `json:",omitempty"`
+	Message
+}
+
+func (m *MCPArgs) GetMessage() Message {
+	return m.Message
+}
+
+// MCPToolName builds the name the model sees for a server tool
+// Characters function names do not allow are replaced with "_"
+func MCPToolName(server string, tool string) string {
+	name := MCPToolPrefix + server + "__" + unsafeToolNameChars.ReplaceAllString(tool, "_")
+	if len(name) > maxToolNameLength {
+		name = name[:maxToolNameLength]
+	}
+	return name
+}
+
+// ParseMCPToolName splits a name built by MCPToolName
+// The tool part is the sanitized name; the executor maps it back to the server tool
+func ParseMCPToolName(name string) (server string, tool string, ok bool) {
+	rest, ok := strings.CutPrefix(name, MCPToolPrefix)
+	if !ok {
+		return "", "", false
+	}
+	server, tool, ok = strings.Cut(rest, "__")
+	if !ok || server == "" || tool == "" {
+		return "", "", false
+	}
+	return server, tool, true
+}
+
+// MCPTool converts a server tool into a tool definition for the model
+func MCPTool(server string, name string, description string, schema map[string]any) llms.Tool {
+	params := map[string]any{"type": "object"}
+	for k, v := range schema {
+		params[k] = v
+	}
+
+	props := map[string]any{}
+	if p, ok := params["properties"].(map[string]any); ok {
+		for k, v := range p {
+			props[k] = v
+		}
+	}
+	if _, ok := props["message"]; !ok {
+		props["message"] = map[string]any{
+			"type":        "string",
+			"description": "Message shown to the user",
+		}
+	}
+	params["properties"] = props
+
+	return llms.Tool{
+		Type: "function",
+		Function: &llms.FunctionDefinition{
+			Name:        MCPToolName(server, name),
+			Description: fmt.Sprintf("[%s] %s", server, description),
+			Parameters:  params,
+		},
+	}
+}
+
+// mcpCallToTask converts a call of an MCP tool into an mcp task
+func mcpCallToTask(name string, arguments map[string]any, message string) (*database.Task, error) {
+	server, tool, ok := ParseMCPToolName(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid mcp tool name: %s", name)
+	}
+
+	if msg, ok := arguments["message"].(string); ok {
+		if message == "" {
+			message = msg
+		}
+		delete(arguments, "message")
+	}
+
+	args, err := json.Marshal(MCPArgs{Server: server, Tool: tool, Arguments: arguments, Message: Message(message)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mcp args: %v", err)
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Calling %s on %s", tool, server)
+	}
+
+	return &database.Task{
+		Type:    database.StringToNullString("mcp"),
+		Args:    database.StringToNullString(string(args)),
+		Message: database.StringToNullString(message),
+		Status:  database.StringToNullString(models.TaskInProgress),
+	}, nil
+}
+
+// taskToolCall returns the function name and arguments of a past task as
+// the model originally sent them
+func taskToolCall(task database.Task) (string, string) {
+	if task.Type.String != "mcp" {
+		return task.Type.String, task.Args.String
+	}
+
+	var args MCPArgs
+	if err := json.Unmarshal([]byte(task.Args.String), &args); err != nil {
+		return task.Type.String, task.Args.String
+	}
+	arguments, err := json.Marshal(args.Arguments)
+	if err != nil || args.Arguments == nil {
+		arguments = []byte("{}")
+	}
+	return MCPToolName(args.Server, args.Tool), string(arguments)
+}
@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/database"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestMCPToolName(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		tool   string
+		want   string
+	}{
+		{name: "plain", server: "github", tool: "search_issues", want: "mcp__github__search_issues"},
+		{name: "unsafe characters", server: "docs", tool: "read.file/v2", want: "mcp__docs__read_file_v2"},
+		{name: "truncated", server: "s", tool: strings.Repeat("a", 80), want: "mcp__s__" + strings.Repeat("a", 56)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MCPToolName(tt.server, tt.tool)
+			if got != tt.want {
+				t.Errorf("MCPToolName() = %q, want %q", got, tt.want)
+			}
+
+			server, tool, ok := ParseMCPToolName(got)
+			if !ok || server != tt.server || MCPToolName(server, tool) != got {
+				t.Errorf("ParseMCPToolName(%q) = %q, %q, %v", got, server, tool, ok)
+			}
+		})
+	}
+
+	for _, name := range []string{"terminal", "mcp__github", "mcp____tool", "mcp__github__"} {
+		if _, _, ok := ParseMCPToolName(name); ok {
+			t.Errorf("ParseMCPToolName(%q) should fail", name)
+		}
+	}
+}
+
+func TestMCPTool(t *testing.T) {
+	tool := MCPTool("github", "search_issues", "Search issues", map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"q": map[string]any{"type": "string"}},
+		"required":   []any{"q"},
+	})
+
+	if tool.Function.Name != "mcp__github__search_issues" || tool.Function.Description != "[github] Search issues" {
+		t.Errorf("MCPTool() = %s: %s", tool.Function.Name, tool.Function.Description)
+	}
+	props := tool.Function.Parameters.(map[string]any)["properties"].(map[string]any)
+	if _, ok := props["q"]; !ok {
+		t.Error("MCPTool() should keep the server schema")
+	}
+	if _, ok := props["message"]; !ok {
+		t.Error("MCPTool() should add the message property")
+	}
+
+	// A tool without schema still gets an object with a message
+	empty := MCPTool("docs", "list", "", nil)
+	if empty.Function.Parameters.(map[string]any)["type"] != "object" {
+		t.Error("MCPTool() without schema should be an object")
+	}
+}
+
+func TestToolToTask_MCPTool(t *testing.T) {
+	choices := []*llms.ContentChoice{{
+		ToolCalls: []llms.ToolCall{{
+			ID: "call_1",
+			FunctionCall: &llms.FunctionCall{
+				Name:      "mcp__github__search_issues",
+				Arguments: `{"q": "is:open", "message": "Looking for open issues"}`,
+			},
+		}},
+	}}
+
+	task, err := toolToTask(choices)
+	if err != nil {
+		t.Fatalf("toolToTask() error = %v", err)
+	}
+	if task.Type.String != "mcp" || task.Message.String != "Looking for open issues" || task.ToolCallID.String != "call_1" {
+		t.Errorf("toolToTask() = type %q, message %q, tool call %q", task.Type.String, task.Message.String, task.ToolCallID.String)
+	}
+
+	var args MCPArgs
+	if err := json.Unmarshal([]byte(task.Args.String), &args); err != nil {
+		t.Fatal(err)
+	}
+	if args.Server != "github" || args.Tool != "search_issues" || args.Arguments["q"] != "is:open" {
+		t.Errorf("toolToTask() args = %+v", args)
+	}
+	if _, ok := args.Arguments["message"]; ok {
+		t.Error("the message should not be sent to the server")
+	}
+
+	// The history shows the call as the model made it
+	name, arguments := taskToolCall(database.Task{Type: task.Type, Args: task.Args})
+	if name != "mcp__github__search_issues" || arguments != `{"q":"is:open"}` {
+		t.Errorf("taskToolCall() = %s, %s", name, arguments)
+	}
+}
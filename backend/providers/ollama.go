@@ -10,6 +10,7 @@ import (
 	"github.com/arandu-ai/arandu/database"
 	"github.com/arandu-ai/arandu/logging"
 
+	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/ollama"
 )
 
@@ -73,9 +74,9 @@ func (p OllamaProvider) DockerImageName(task string) (string, error) {
 
 // Call represents a tool call from a JSON-responding model
 type Call struct {
-	Tool    string            `json:"tool"`
-	Input   map[string]string `json:"tool_input"`
-	Message string            `json:"message"`
+	Tool    string         `json:"tool"`
+	Input   map[string]any `json:"tool_input"`
+	Message string         `json:"message"`
 }
 
 func (p OllamaProvider) NextTask(args NextTaskOptions) *database.Task {
@@ -84,6 +85,8 @@ func (p OllamaProvider) NextTask(args NextTaskOptions) *database.Task {
 	prepared, err := PreparePrompt(PromptConfig{
 		DockerImage:  args.DockerImage,
 		Tasks:        args.Tasks,
+		Tools:        args.Tools,
+		Secrets:      args.Secrets,
 		UseToolCalls: false, // Ollama uses JSON format
 	})
 
@@ -96,6 +99,7 @@ func (p OllamaProvider) NextTask(args NextTaskOptions) *database.Task {
 		Client:       p.client,
 		Model:        p.model,
 		Messages:     prepared.Messages,
+		Tools:        args.Tools,
 		UseToolCalls: false,
 		Temperature:  0.0,
 		TopP:         0.2,
@@ -110,8 +114,9 @@ func (p OllamaProvider) NextTask(args NextTaskOptions) *database.Task {
 }
 
 // getToolPlaceholder generates the tool description for JSON-based models
-func getToolPlaceholder() string {
-	bs, err := json.Marshal(Tools)
+// extra are the tools of the flow's MCP servers
+func getToolPlaceholder(extra []llms.Tool) string {
+	bs, err := json.Marshal(append(append([]llms.Tool{}, Tools...), extra...))
 	if err != nil {
 		logging.Error("Failed to marshal tools for placeholder", "error", err.Error())
 		os.Exit(1)
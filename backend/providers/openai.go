@@ -59,6 +59,8 @@ func (p OpenAIProvider) NextTask(args NextTaskOptions) *database.Task {
 	prepared, err := PreparePrompt(PromptConfig{
 		DockerImage:  args.DockerImage,
 		Tasks:        args.Tasks,
+		Tools:        args.Tools,
+		Secrets:      args.Secrets,
 		UseToolCalls: true,
 	})
 
@@ -71,6 +73,7 @@ func (p OpenAIProvider) NextTask(args NextTaskOptions) *database.Task {
 		Client:       p.client,
 		Model:        p.model,
 		Messages:     prepared.Messages,
+		Tools:        args.Tools,
 		UseToolCalls: true,
 		Temperature:  0.0,
 		TopP:         0.2,
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/arandu-ai/arandu/assets"
 	"github.com/arandu-ai/arandu/database"
@@ -48,6 +49,11 @@ type Provider interface {
 type NextTaskOptions struct {
 	Tasks       []database.Task
 	DockerImage string
+	// Tools are offered in addition to the global Tools (the flow's MCP tools)
+	Tools []llms.Tool
+	// Secrets are the names of the environment variables holding the flow's
+	// secrets; the model never sees their values
+	Secrets []string
 }
 
 var Tools = []llms.Tool{
@@ -67,6 +73,14 @@ var Tools = []llms.Tool{
 			Parameters:  jsonschema.Reflect(&BrowserArgs{}).Definitions["BrowserArgs"],
 		},
 	},
+	{
+		Type: "function",
+		Function: &llms.FunctionDefinition{
+			Name:        "search",
+			Description: "Searches the web and returns results with title, URL and snippet",
+			Parameters:  jsonschema.Reflect(&SearchArgs{}).Definitions["SearchArgs"],
+		},
+	},
 	{
 		Type: "function",
 		Function: &llms.FunctionDefinition{
@@ -144,14 +158,15 @@ func tasksToMessages(tasks []database.Task, prompt string) []llms.MessageContent
 		}
 
 		if task.ToolCallID.String != "" {
+			name, arguments := taskToolCall(task)
 			messages = append(messages, llms.MessageContent{
 				Role: llms.ChatMessageTypeAI,
 				Parts: []llms.ContentPart{
 					llms.ToolCall{
 						ID: task.ToolCallID.String,
 						FunctionCall: &llms.FunctionCall{
-							Name:      task.Type.String,
-							Arguments: task.Args.String,
+							Name:      name,
+							Arguments: arguments,
 						},
 						Type: "function",
 					},
@@ -163,7 +178,7 @@ func tasksToMessages(tasks []database.Task, prompt string) []llms.MessageContent
 				Parts: []llms.ContentPart{
 					llms.ToolCallResponse{
 						ToolCallID: task.ToolCallID.String,
-						Name:       task.Type.String,
+						Name:       name,
 						Content:    task.Results.String,
 					},
 				},
@@ -191,6 +206,10 @@ func textToTask(text string) (*database.Task, error) {
 		return nil, fmt.Errorf("can't unmarshalCall %s", text)
 	}
 
+	if strings.HasPrefix(c.Tool, MCPToolPrefix) {
+		return mcpCallToTask(c.Tool, c.Input, c.Message)
+	}
+
 	task := database.Task{
 		// TODO validate tool name
 		Type: database.StringToNullString(c.Tool),
@@ -258,6 +277,19 @@ func toolToTask(choices []*llms.ContentChoice) (*database.Task, error) {
 		return nil, fmt.Errorf("no tool name found, asking user")
 	}
 
+	if strings.HasPrefix(tool.FunctionCall.Name, MCPToolPrefix) {
+		var arguments map[string]any
+		if err := json.Unmarshal([]byte(tool.FunctionCall.Arguments), &arguments); err != nil {
+			return nil, fmt.Errorf("failed to extract args: %v", err)
+		}
+		mcpTask, err := mcpCallToTask(tool.FunctionCall.Name, arguments, "")
+		if err != nil {
+			return nil, err
+		}
+		mcpTask.ToolCallID = database.StringToNullString(tool.ID)
+		return mcpTask, nil
+	}
+
 	// We use AskArgs to extract the message
 	var toolType Messanger
 
@@ -268,6 +300,8 @@ func toolToTask(choices []*llms.ContentChoice) (*database.Task, error) {
 		toolType = &TerminalArgs{}
 	case "browser":
 		toolType = &BrowserArgs{}
+	case "search":
+		toolType = &SearchArgs{}
 	case "code":
 		toolType = &CodeArgs{}
 	case "ask":
@@ -275,6 +309,10 @@ func toolToTask(choices []*llms.ContentChoice) (*database.Task, error) {
 	case "done":
 		toolType = &DoneArgs{}
 	default:
+		if _, ok := GetCustomTool(tool.FunctionCall.Name); ok {
+			toolType = &CustomArgs{}
+			break
+		}
 		return nil, fmt.Errorf("unknown tool name: %s", tool.FunctionCall.Name)
 	}
 
@@ -355,6 +393,8 @@ func truncateTaskResults(tasks []database.Task, maxLength int) []database.Task {
 type PromptConfig struct {
 	DockerImage     string
 	Tasks           []database.Task
+	Tools           []llms.Tool
+	Secrets         []string
 	UseToolCalls    bool
 	MaxPromptLength int
 }
@@ -379,13 +419,15 @@ func PreparePrompt(cfg PromptConfig) (*PreparedPrompt, error) {
 	if cfg.UseToolCalls {
 		toolPlaceholder = "Always use your function calling functionality, instead of returning a text result."
 	} else {
-		toolPlaceholder = getToolPlaceholder()
+		toolPlaceholder = getToolPlaceholder(cfg.Tools)
 	}
 
 	promptArgs := map[string]interface{}{
 		"DockerImage":     cfg.DockerImage,
 		"ToolPlaceholder": toolPlaceholder,
-		"Tasks":           tasks,
+		"Secrets":         cfg.Secrets,
+		"Tasks":           delimitUntrusted(tasks),
+		"Untrusted":       hasUntrusted(tasks),
 	}
 
 	prompt, err := renderPrompt(promptArgs)
@@ -400,7 +442,7 @@ func PreparePrompt(cfg PromptConfig) (*PreparedPrompt, error) {
 			"max_length", cfg.MaxPromptLength,
 		)
 		tasks = truncateTasks(cfg.Tasks, ModerateTruncateLength)
-		promptArgs["Tasks"] = tasks
+		promptArgs["Tasks"] = delimitUntrusted(tasks)
 		prompt, err = renderPrompt(promptArgs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to render truncated prompt: %w", err)
@@ -414,7 +456,7 @@ func PreparePrompt(cfg PromptConfig) (*PreparedPrompt, error) {
 			"max_length", cfg.MaxPromptLength,
 		)
 		tasks = truncateTasks(cfg.Tasks, AggressiveTruncateLength)
-		promptArgs["Tasks"] = tasks
+		promptArgs["Tasks"] = delimitUntrusted(tasks)
 		prompt, err = renderPrompt(promptArgs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to render aggressively truncated prompt: %w", err)
@@ -428,7 +470,7 @@ func PreparePrompt(cfg PromptConfig) (*PreparedPrompt, error) {
 
 	return &PreparedPrompt{
 		Prompt:   prompt,
-		Messages: tasksToMessages(tasks, prompt),
+		Messages: tasksToMessages(delimitUntrusted(tasks), prompt),
 		Tasks:    tasks,
 	}, nil
 }
@@ -448,6 +490,7 @@ type GenerateTaskConfig struct {
 	Client       LLMClient
 	Model        string
 	Messages     []llms.MessageContent
+	Tools        []llms.Tool
 	UseToolCalls bool
 	Temperature  float64
 	TopP         float64
@@ -471,7 +514,7 @@ func GenerateNextTask(ctx context.Context, cfg GenerateTaskConfig) (*database.Ta
 	}
 
 	if cfg.UseToolCalls {
-		opts = append(opts, llms.WithTools(Tools))
+		opts = append(opts, llms.WithTools(append(append([]llms.Tool{}, Tools...), cfg.Tools...)))
 	}
 
 	resp, err := cfg.Client.GenerateContent(ctx, cfg.Messages, opts...)
@@ -27,13 +27,33 @@ func (t *TerminalArgs) GetMessage() Message {
 type BrowserAction string
 
 const (
-	Read BrowserAction = "read"
-	Url  BrowserAction = "url"
+	Read     BrowserAction = "read"
+	Url      BrowserAction = "url"
+	Navigate BrowserAction = "navigate"
+	Click    BrowserAction = "click"
+	Type     BrowserAction = "type"
+	Select   BrowserAction = "select"
+	Scroll   BrowserAction = "scroll"
+	WaitFor  BrowserAction = "wait_for"
+	Back     BrowserAction = "back"
+	Evaluate BrowserAction = "evaluate"
+	Download BrowserAction = "download"
 )
 
+// BrowserArgs are the arguments of the browser tool
+// read and url load a fresh page; the other actions share a page per flow
+// download fetches the file server-side and copies it into the sandbox
 type BrowserArgs struct {
-	Url    string
-	Action BrowserAction
+	Url       string        `json:",omitempty" jsonschema:"description=URL for read/url/navigate/download"`
+	Action    BrowserAction `jsonschema:"enum=read,enum=url,enum=navigate,enum=click,enum=type,enum=select,enum=scroll,enum=wait_for,enum=back,enum=evaluate,enum=download"`
+	Selector  string        `json:",omitempty" jsonschema:"description=CSS selector of the target element"`
+	Text      string        `json:",omitempty" jsonschema:"description=Visible text of the element to click or wait for / text to type / option to select"`
+	Submit    bool          `json:",omitempty" jsonschema:"description=Press Enter after typing to submit the form"`
+	Direction string        `json:",omitempty" jsonschema:"enum=up,enum=down,description=Scroll direction when no selector is given"`
+	Script    string        `json:",omitempty" jsonschema:"description=JavaScript to evaluate in the page (evaluate only)"`
+	Page      int           `json:",omitempty" jsonschema:"description=Page of the page content to return for long pages (starts at 1)"`
+	Offset    int           `json:",omitempty" jsonschema:"description=Character offset to continue reading from instead of page"`
+	Path      string        `json:",omitempty" jsonschema:"description=Destination of the downloaded file in the sandbox (download only; defaults to /app/downloads/)"`
 	Message
 }
 
@@ -41,6 +61,17 @@ func (b *BrowserArgs) GetMessage() Message {
 	return b.Message
 }
 
+// SearchArgs are the arguments of the search tool
+type SearchArgs struct {
+	Query string `jsonschema:"description=Search query"`
+	Limit int    `json:",omitempty" jsonschema:"description=Maximum number of results (default 5, max 20)"`
+	Message
+}
+
+func (s *SearchArgs) GetMessage() Message {
+	return s.Message
+}
+
 type CodeAction string
 
 const (
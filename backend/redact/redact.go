@@ -0,0 +1,126 @@
+// Package redact removes credentials and other sensitive data from tool
+// output before it is stored or sent to the model. A Pipeline runs a set of
+// detectors over the text: built-in regular expressions, an entropy check for
+// random-looking tokens and patterns defined by the operator
+package redact
+
+import (
+	"sort"
+	"strings"
+)
+
+// Match is a range of text [Start, End) to redact
+type Match struct {
+	Start int
+	End   int
+	// Replacement overrides the placeholder of the detector
+	Replacement string
+}
+
+// Detector finds sensitive data in a text
+type Detector interface {
+	// Name identifies the detector in placeholders and counts
+	Name() string
+	Find(text string) []Match
+}
+
+// Counts is the number of redactions per detector
+type Counts map[string]int
+
+// Add adds other to the counts
+func (c Counts) Add(other Counts) {
+	for name, n := range other {
+		c[name] += n
+	}
+}
+
+// Total returns the number of redactions
+func (c Counts) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// Placeholder is what a detector's matches are replaced with
+func Placeholder(name string) string {
+	return "[REDACTED:" + name + "]"
+}
+
+// Pipeline runs detectors over a text and replaces what they find
+// A nil Pipeline redacts nothing
+type Pipeline struct {
+	detectors []Detector
+}
+
+// New returns a pipeline running the detectors
+func New(detectors ...Detector) *Pipeline {
+	return &Pipeline{detectors: detectors}
+}
+
+// Detectors returns the names of the pipeline's detectors
+func (p *Pipeline) Detectors() []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, len(p.detectors))
+	for i, d := range p.detectors {
+		names[i] = d.Name()
+	}
+	return names
+}
+
+type found struct {
+	Match
+	detector string
+}
+
+// Redact replaces every match in text. Overlapping matches are merged into
+// the one that starts first, the longest on ties; for the same range the
+// first detector wins
+func (p *Pipeline) Redact(text string) (string, Counts) {
+	counts := Counts{}
+	if p == nil || text == "" {
+		return text, counts
+	}
+
+	var matches []found
+	for _, d := range p.detectors {
+		for _, m := range d.Find(text) {
+			if m.Start < 0 || m.End > len(text) || m.Start >= m.End {
+				continue
+			}
+			matches = append(matches, found{m, d.Name()})
+		}
+	}
+	if len(matches) == 0 {
+		return text, counts
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End > matches[j].End
+	})
+
+	var b strings.Builder
+	b.Grow(len(text))
+	last := 0
+	for _, m := range matches {
+		if m.Start < last {
+			continue
+		}
+		b.WriteString(text[last:m.Start])
+		if m.Replacement != "" {
+			b.WriteString(m.Replacement)
+		} else {
+			b.WriteString(Placeholder(m.detector))
+		}
+		counts[m.detector]++
+		last = m.End
+	}
+	b.WriteString(text[last:])
+	return b.String(), counts
+}
@@ -0,0 +1,58 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/arandu-ai/arandu/audit"
+	"github.com/arandu-ai/arandu/auth"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+)
+
+// auditExportHandler streams the audit log as JSON lines, oldest first
+// Admins export the whole log or one flow; other users only the flows they can see
+func auditExportHandler(db *database.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var flowID int64
+		if param := c.Query("flowId"); param != "" {
+			id, err := strconv.ParseInt(param, 10, 64)
+			if err != nil || id <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid flowId"})
+				return
+			}
+			flowID = id
+		}
+
+		if auth.Enabled() {
+			user, ok := auth.UserFromContext(ctx)
+			if !ok {
+				c.JSON(http.StatusForbidden, gin.H{"error": "a user token is required"})
+				return
+			}
+			if !user.Admin {
+				if flowID == 0 {
+					c.JSON(http.StatusForbidden, gin.H{"error": "flowId is required"})
+					return
+				}
+				flow, err := db.ReadFlow(ctx, flowID)
+				access := auth.FlowAccess{OwnerID: flow.OwnerID, TeamID: flow.TeamID}
+				if err != nil || auth.CanAccessFlow(ctx, db, access, auth.TeamViewer) != nil {
+					c.JSON(http.StatusNotFound, gin.H{"error": "flow not found"})
+					return
+				}
+			}
+		}
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+		c.Status(http.StatusOK)
+		if err := audit.Export(ctx, db, c.Writer, flowID); err != nil {
+			logging.Error("Failed to export audit log", "flow_id", flowID, "error", err.Error())
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/arandu-ai/arandu/audit"
+	"github.com/arandu-ai/arandu/auth"
+	appConfig "github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/executor"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/middleware"
+)
+
+// sessionMaxAge is how long the browser keeps the session cookie, in seconds
+const sessionMaxAge = 30 * 24 * 60 * 60
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// loginHandler checks a username and password and starts a browser session
+// The token is also returned so API clients can use it as a bearer token
+func loginHandler(db *database.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req loginRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.Username == "" || req.Password == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "username and password are required"})
+			return
+		}
+
+		user, token, err := auth.Login(c.Request.Context(), db, req.Username, req.Password)
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid username or password",
+				"code":  "UNAUTHORIZED",
+			})
+			return
+		}
+		if err != nil {
+			logging.Error("Failed to log in", "username", req.Username, "error", err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "login failed"})
+			return
+		}
+
+		audit.Record(auth.WithUser(c.Request.Context(), user), db, audit.Event{Action: audit.ActionLogin, Target: "password"})
+
+		setSessionCookie(c, token, sessionMaxAge)
+		c.JSON(http.StatusOK, gin.H{
+			"token": token,
+			"user": gin.H{
+				"id":       user.ID,
+				"username": user.Username,
+				"admin":    user.Admin,
+			},
+		})
+	}
+}
+
+// logoutHandler revokes the token of the request and clears the cookie
+func logoutHandler(db *database.Queries) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := middleware.RequestToken(c.Request); token != "" {
+			if err := auth.Logout(c.Request.Context(), db, token); err != nil {
+				logging.Error("Failed to revoke session token", "error", err.Error())
+			}
+		}
+
+		setSessionCookie(c, "", -1)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func setSessionCookie(c *gin.Context, token string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     middleware.SessionCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   appConfig.Config.ProductionMode,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// screenshotsHandler serves the stored screenshots of the flows the user can access
+// Files live under <flowId>/<taskId>/, so the first path segment names the flow
+func screenshotsHandler(db *database.Queries) gin.HandlerFunc {
+	files := http.StripPrefix(executor.ScreenshotsURLPath, http.FileServer(http.Dir(appConfig.Config.ScreenshotsDir)))
+
+	return func(c *gin.Context) {
+		rel := strings.TrimPrefix(path.Clean(c.Param("filepath")), "/")
+		flowParam, _, _ := strings.Cut(rel, "/")
+
+		flowID, err := strconv.ParseInt(flowParam, 10, 64)
+		if err != nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		if auth.Enabled() {
+			flow, err := db.ReadFlow(c.Request.Context(), flowID)
+			access := auth.FlowAccess{OwnerID: flow.OwnerID, TeamID: flow.TeamID}
+			if err != nil || auth.CanAccessFlow(c.Request.Context(), db, access, auth.TeamViewer) != nil {
+				c.Status(http.StatusNotFound)
+				return
+			}
+		}
+
+		files.ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/arandu-ai/arandu/audit"
+	"github.com/arandu-ai/arandu/auth"
+	appConfig "github.com/arandu-ai/arandu/config"
+	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/oidc"
+)
+
+// oidcStateCookie binds a started single sign-on login to the browser that
+// started it, so a callback cannot be replayed in another browser
+const oidcStateCookie = "arandu_oidc_state"
+
+// authMethodsHandler tells the login page which sign-in methods are available
+func authMethodsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		methods := gin.H{"password": true}
+		if oidc.Enabled() {
+			methods["oidc"] = gin.H{"name": appConfig.Config.OIDCProviderName}
+		}
+		c.JSON(http.StatusOK, methods)
+	}
+}
+
+// oidcLoginHandler sends the browser to the identity provider
+func oidcLoginHandler(client *oidc.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authURL, state, err := client.StartLogin()
+		if err != nil {
+			logging.Error("Failed to start single sign-on", "error", err.Error())
+			c.Redirect(http.StatusFound, "/login?error=sso")
+			return
+		}
+
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     oidcStateCookie,
+			Value:    state,
+			Path:     "/auth/oidc",
+			MaxAge:   int(oidc.LoginTTL.Seconds()),
+			HttpOnly: true,
+			Secure:   appConfig.Config.ProductionMode,
+			SameSite: http.SameSiteLaxMode,
+		})
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// oidcCallbackHandler finishes a single sign-on login and starts a session
+func oidcCallbackHandler(db *database.Queries, client *oidc.Client, roles map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		http.SetCookie(c.Writer, &http.Cookie{Name: oidcStateCookie, Path: "/auth/oidc", MaxAge: -1})
+
+		if providerErr := c.Query("error"); providerErr != "" {
+			logging.Warn("Single sign-on refused by the provider", "error", providerErr, "description", c.Query("error_description"))
+			c.Redirect(http.StatusFound, "/login?error=sso")
+			return
+		}
+
+		state := c.Query("state")
+		cookie, err := c.Cookie(oidcStateCookie)
+		if err != nil || cookie != state {
+			c.Redirect(http.StatusFound, "/login?error=sso")
+			return
+		}
+
+		identity, err := client.FinishLogin(c.Request.Context(), state, c.Query("code"))
+		if err != nil {
+			logging.Warn("Single sign-on failed", "error", err.Error())
+			c.Redirect(http.StatusFound, "/login?error=sso")
+			return
+		}
+
+		groups := identity.Claims.Strings(appConfig.Config.OIDCGroupsClaim)
+		role, err := auth.RoleForGroups(roles, groups)
+		if errors.Is(err, auth.ErrForbidden) {
+			logging.Warn("Single sign-on user has no mapped group", "subject", identity.Subject, "groups", groups)
+			c.Redirect(http.StatusFound, "/login?error=forbidden")
+			return
+		}
+
+		username := identity.Claims.String(appConfig.Config.OIDCUsernameClaim)
+		if username == "" {
+			username = identity.Claims.String("email")
+		}
+
+		user, token, err := auth.SSOLogin(c.Request.Context(), db, auth.SSOIdentity{
+			Subject:  identity.Issuer + "|" + identity.Subject,
+			Username: username,
+			Groups:   groups,
+		}, role)
+		if err != nil {
+			logging.Error("Failed to sign in single sign-on user", "subject", identity.Subject, "error", err.Error())
+			c.Redirect(http.StatusFound, "/login?error=sso")
+			return
+		}
+
+		logging.Info("Single sign-on login", "username", user.Username, "role", role)
+		audit.Record(auth.WithUser(c.Request.Context(), user), db, audit.Event{
+			Action:  audit.ActionLogin,
+			Target:  "oidc",
+			Details: map[string]any{"role": role},
+		})
+		setSessionCookie(c, token, sessionMaxAge)
+		c.Redirect(http.StatusFound, "/")
+	}
+}
@@ -22,11 +22,15 @@ import (
 	gorillaWs "github.com/gorilla/websocket"
 	"github.com/vektah/gqlparser/v2/ast"
 
+	"github.com/arandu-ai/arandu/auth"
 	appConfig "github.com/arandu-ai/arandu/config"
 	"github.com/arandu-ai/arandu/database"
+	"github.com/arandu-ai/arandu/executor"
 	"github.com/arandu-ai/arandu/graph"
 	"github.com/arandu-ai/arandu/logging"
+	"github.com/arandu-ai/arandu/middleware"
 	"github.com/arandu-ai/arandu/models"
+	"github.com/arandu-ai/arandu/oidc"
 	"github.com/arandu-ai/arandu/websocket"
 )
 
@@ -100,10 +104,15 @@ func (rl *RateLimiter) cleanup() {
 }
 
 // RateLimitMiddleware creates a gin middleware for rate limiting
+// Requests of an identified user share one budget, wherever they come from;
+// anonymous requests are limited per IP
 func RateLimitMiddleware(limiter *RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		if !limiter.Allow(ip) {
+		key := c.ClientIP()
+		if user, ok := auth.UserFromContext(c.Request.Context()); ok {
+			key = fmt.Sprintf("user:%d", user.ID)
+		}
+		if !limiter.Allow(key) {
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error": "Rate limit exceeded. Please try again later.",
 			})
@@ -188,6 +197,9 @@ func New(db *database.Queries) *gin.Engine {
 
 	r.Use(cors.New(corsConfig))
 
+	// Resolve the user before rate limiting, which is per user when known
+	r.Use(middleware.Identify(db))
+
 	// Security: Rate limiting middleware
 	rateLimiter := NewRateLimiter(appConfig.Config.RateLimitPerMinute, time.Minute)
 	r.Use(RateLimitMiddleware(rateLimiter))
@@ -198,17 +210,54 @@ func New(db *database.Queries) *gin.Engine {
 
 	r.Use(static.Serve("/", static.LocalFile("./fe", true)))
 
-	// GraphQL endpoint
-	r.Any("/graphql", graphqlHandler(db))
+	// User sessions
+	r.POST("/auth/login", loginHandler(db))
+	r.POST("/auth/logout", logoutHandler(db))
+	r.GET("/auth/methods", authMethodsHandler())
+	if client, err := oidc.Default(); err == nil {
+		roles, err := auth.ParseRoleMapping(appConfig.Config.OIDCRoleMapping)
+		if err != nil {
+			logging.Error("Single sign-on disabled: invalid OIDC_ROLE_MAPPING", "error", err.Error())
+		} else {
+			r.GET("/auth/oidc/login", oidcLoginHandler(client))
+			r.GET("/auth/oidc/callback", oidcCallbackHandler(db, client, roles))
+		}
+	}
+
+	// Every route below needs the API key or a user token when
+	// REQUIRE_API_KEY or MULTI_USER is set
+	if appConfig.Config.RequireAPIKey && appConfig.Config.APIKey == "" {
+		logging.Warn("REQUIRE_API_KEY is set without API_KEY - only user tokens are accepted")
+	}
+	apiKeyAuth := middleware.OptionalAPIKeyAuth()
+	userAuth := middleware.UserAuth(db)
+	requireAuth := middleware.RequireAuth()
+
+	// GraphQL endpoint; websockets authenticate in connection_init
+	r.Any("/graphql", middleware.DeferWebsocketAuth(), apiKeyAuth, userAuth, requireAuth, graphqlHandler(db))
 
 	// GraphQL playground route
-	r.GET("/playground", playgroundHandler())
+	r.GET("/playground", apiKeyAuth, userAuth, requireAuth, playgroundHandler())
+
+	// MCP endpoint for other agents; it runs commands in sandboxes, so it
+	// needs an operator token
+	if appConfig.Config.MCPServerEnabled {
+		if !middleware.AuthRequired() {
+			logging.Warn("MCP server is enabled without authentication - any client can run commands in the sandboxes")
+		}
+		r.Any("/mcp", apiKeyAuth, userAuth, requireAuth, middleware.RequireScope(auth.ScopeOperator), gin.WrapH(graph.NewMCPServer(db)))
+		logging.Info("MCP server enabled", "path", "/mcp")
+	}
 
 	// WebSocket endpoint for Docker daemon
-	r.GET("/terminal/:id", wsHandler(db))
+	r.GET("/terminal/:id", apiKeyAuth, userAuth, requireAuth, wsHandler(db))
+
+	// Browser screenshots, only for the flows the user can access
+	r.GET(executor.ScreenshotsURLPath+"/*filepath", apiKeyAuth, userAuth, requireAuth, screenshotsHandler(db))
+	r.HEAD(executor.ScreenshotsURLPath+"/*filepath", apiKeyAuth, userAuth, requireAuth, screenshotsHandler(db))
 
-	// Static file server
-	r.Static("/browser", "./tmp/browser")
+	// Audit log export, as JSON lines
+	r.GET("/audit/export", apiKeyAuth, userAuth, requireAuth, auditExportHandler(db))
 
 	r.NoRoute(func(c *gin.Context) {
 		c.Redirect(301, "/")
@@ -224,6 +273,17 @@ func graphqlHandler(db *database.Queries) gin.HandlerFunc {
 		Db: db,
 	}}))
 
+	// Read-only tokens may query and subscribe, but not change anything
+	h.AroundOperations(func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		oc := graphql.GetOperationContext(ctx)
+		if oc.Operation != nil && oc.Operation.Operation == ast.Mutation {
+			if err := auth.RequireScope(ctx, auth.ScopeOperator); err != nil {
+				return graphql.OneShot(graphql.ErrorResponse(ctx, "%s", err.Error()))
+			}
+		}
+		return next(ctx)
+	})
+
 	h.AroundResponses(func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
 		res := next(ctx)
 		if res == nil {
@@ -276,8 +336,26 @@ func graphqlHandler(db *database.Queries) gin.HandlerFunc {
 				return allowed
 			},
 		},
+		// The upgrade request may already be authenticated by header or cookie;
+		// otherwise the client sends its token in the connection_init payload.
+		// The ack carries no payload so the token is not echoed back
 		InitFunc: func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
-			return ctx, &initPayload, nil
+			if middleware.CheckAuthenticated(ctx) == nil {
+				return ctx, nil, nil
+			}
+
+			token := initPayload.Authorization()
+			if token == "" {
+				token = initPayload.GetString("apiKey")
+			}
+			authCtx, err := middleware.AuthenticateToken(ctx, db, token)
+			if err != nil {
+				return nil, nil, auth.ErrUnauthenticated
+			}
+			if err := middleware.CheckAuthenticated(authCtx); err != nil {
+				return nil, nil, err
+			}
+			return authCtx, nil, nil
 		},
 	})
 
@@ -311,6 +389,13 @@ func wsHandler(db *database.Queries) gin.HandlerFunc {
 			return
 		}
 
+		// The terminal only streams output, so viewers may watch it
+		access := auth.FlowAccess{OwnerID: flow.OwnerID, TeamID: flow.TeamID}
+		if err := auth.CanAccessFlow(c.Request.Context(), db, access, auth.TeamViewer); err != nil {
+			_ = c.AbortWithError(404, fmt.Errorf("flow not found"))
+			return
+		}
+
 		if flow.Status.String != string(models.FlowInProgress) {
 			_ = c.AbortWithError(404, fmt.Errorf("flow is not in progress"))
 			return
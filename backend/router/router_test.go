@@ -127,3 +127,67 @@ func TestUnknownOriginBlocked(t *testing.T) {
 		t.Error("CORS should not allow unknown origins")
 	}
 }
+
+func TestProtectedEndpointsRequireAPIKey(t *testing.T) {
+	defer func(requireKey bool, key string) {
+		config.Config.RequireAPIKey = requireKey
+		config.Config.APIKey = key
+	}(config.Config.RequireAPIKey, config.Config.APIKey)
+
+	config.Config.RequireAPIKey = true
+	config.Config.APIKey = "test-api-key-12345"
+
+	var db *database.Queries
+	r := New(db)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		apiKey string
+		want   int
+	}{
+		{name: "graphql without key", method: "POST", path: "/graphql", want: http.StatusUnauthorized},
+		{name: "playground without key", method: "GET", path: "/playground", want: http.StatusUnauthorized},
+		{name: "terminal without key", method: "GET", path: "/terminal/1", want: http.StatusUnauthorized},
+		{name: "screenshot without key", method: "GET", path: "/browser/1/1/a.png", want: http.StatusUnauthorized},
+		{name: "playground with key", method: "GET", path: "/playground", apiKey: "test-api-key-12345", want: http.StatusOK},
+		{name: "playground with wrong key", method: "GET", path: "/playground", apiKey: "wrong", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("%s %s returned %v, want %v", tt.method, tt.path, rr.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthMethodsEndpoint(t *testing.T) {
+	var db *database.Queries
+	r := New(db)
+
+	req, err := http.NewRequest("GET", "/auth/methods", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	// Single sign-on is not configured in tests
+	if rr.Code != http.StatusOK || rr.Body.String() != `{"password":true}` {
+		t.Errorf("/auth/methods = %d %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FileProvider serves canned results from a JSON file, for tests and offline setups
+// The file maps queries (case-insensitive) to results; "*" is used for any other query:
+//
+//	{"golang generics": [{"title": "...", "url": "...", "snippet": "..."}], "*": []}
+type FileProvider struct {
+	results map[string][]Result
+}
+
+// NewFileProvider loads the results file
+func NewFileProvider(path string) (*FileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading search results file: %w", err)
+	}
+
+	var raw map[string][]Result
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing search results file: %w", err)
+	}
+
+	results := make(map[string][]Result, len(raw))
+	for query, r := range raw {
+		results[normalizeQuery(query)] = r
+	}
+	return &FileProvider{results: results}, nil
+}
+
+func (p *FileProvider) Name() ProviderType {
+	return ProviderFile
+}
+
+func (p *FileProvider) Search(_ context.Context, query string, limit int) ([]Result, error) {
+	results, ok := p.results[normalizeQuery(query)]
+	if !ok {
+		results = p.results["*"]
+	}
+	return cleanResults(results, limit), nil
+}
+
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
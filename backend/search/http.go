@@ -0,0 +1,114 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// SearXNGProvider queries a SearXNG instance through its JSON API
+// The instance must have the json format enabled in settings.yml
+type SearXNGProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+type searxngResponse struct {
+	Results []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+func (p *SearXNGProvider) Name() ProviderType {
+	return ProviderSearXNG
+}
+
+func (p *SearXNGProvider) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "json")
+
+	var resp searxngResponse
+	if err := getJSON(ctx, p.client, p.baseURL+"/search?"+params.Encode(), p.apiKey, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, Result{Title: r.Title, URL: r.URL, Snippet: r.Content})
+	}
+	return cleanResults(results, limit), nil
+}
+
+// JSONProvider queries a generic HTTP endpoint with ?q=<query>&limit=<n>
+// The response must be {"results": [{"title", "url", "snippet"}]} or a bare array
+type JSONProvider struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func (p *JSONProvider) Name() ProviderType {
+	return ProviderJSON
+}
+
+func (p *JSONProvider) Search(ctx context.Context, query string, limit int) ([]Result, error) {
+	u, err := url.Parse(p.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search endpoint: %w", err)
+	}
+	params := u.Query()
+	params.Set("q", query)
+	params.Set("limit", fmt.Sprint(limit))
+	u.RawQuery = params.Encode()
+
+	var raw json.RawMessage
+	if err := getJSON(ctx, p.client, u.String(), p.apiKey, &raw); err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	if err := json.Unmarshal(raw, &results); err != nil {
+		var wrapped struct {
+			Results []Result `json:"results"`
+		}
+		if err := json.Unmarshal(raw, &wrapped); err != nil {
+			return nil, fmt.Errorf("unexpected search response: %w", err)
+		}
+		results = wrapped.Results
+	}
+	return cleanResults(results, limit), nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into v
+func getJSON(ctx context.Context, client *http.Client, rawURL string, apiKey string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating search request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling search backend: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("search backend returned %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("error decoding search response: %w", err)
+	}
+	return nil
+}
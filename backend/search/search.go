@@ -0,0 +1,155 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ProviderType string
+
+const (
+	ProviderNone    ProviderType = ""
+	ProviderSearXNG ProviderType = "searxng"
+	ProviderJSON    ProviderType = "json"
+	ProviderFile    ProviderType = "file"
+)
+
+const (
+	// DefaultLimit is the number of results returned when the agent does not ask for a limit
+	DefaultLimit = 5
+	// MaxLimit is the maximum number of results returned for a single query
+	MaxLimit = 20
+	// RequestTimeout is the maximum duration of a request to a search backend
+	RequestTimeout = 20 * time.Second
+	// maxResponseSize caps the body read from a search backend
+	maxResponseSize = 5 << 20
+)
+
+// ErrNotConfigured is returned when no search backend is configured
+var ErrNotConfigured = fmt.Errorf("web search is not configured")
+
+// Result is a single web search result
+type Result struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchProvider is a web search backend
+type SearchProvider interface {
+	Name() ProviderType
+	Search(ctx context.Context, query string, limit int) ([]Result, error)
+}
+
+// Options configures a search backend
+type Options struct {
+	// URL is the SearXNG instance, the JSON endpoint or the results file
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// SearchProviderFactory creates the search backend of the given type
+func SearchProviderFactory(provider ProviderType, opts Options) (SearchProvider, error) {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: RequestTimeout}
+	}
+
+	if provider != ProviderNone && opts.URL == "" {
+		return nil, fmt.Errorf("search provider %s requires SEARCH_URL", provider)
+	}
+
+	switch provider {
+	case ProviderNone:
+		return nil, ErrNotConfigured
+	case ProviderSearXNG:
+		return &SearXNGProvider{baseURL: strings.TrimSuffix(opts.URL, "/"), apiKey: opts.APIKey, client: opts.Client}, nil
+	case ProviderJSON:
+		return &JSONProvider{endpoint: opts.URL, apiKey: opts.APIKey, client: opts.Client}, nil
+	case ProviderFile:
+		return NewFileProvider(opts.URL)
+	default:
+		return nil, fmt.Errorf("unknown search provider: %s. Available: searxng, json, file", provider)
+	}
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultProvider SearchProvider
+)
+
+// Init configures the search backend used by the search tool
+func Init(provider ProviderType, opts Options) error {
+	p, err := SearchProviderFactory(provider, opts)
+	if err != nil {
+		return err
+	}
+
+	defaultMu.Lock()
+	defaultProvider = p
+	defaultMu.Unlock()
+	return nil
+}
+
+// Default returns the configured search backend, or ErrNotConfigured
+func Default() (SearchProvider, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	if defaultProvider == nil {
+		return nil, ErrNotConfigured
+	}
+	return defaultProvider, nil
+}
+
+// ClampLimit applies the default and maximum number of results
+func ClampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	return min(limit, MaxLimit)
+}
+
+// FormatResults renders results as a numbered Markdown list for the model
+func FormatResults(query string, results []Result) string {
+	if len(results) == 0 {
+		return fmt.Sprintf("No results found for %q", query)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Search results for %q:\n", query)
+	for i, r := range results {
+		fmt.Fprintf(&b, "\n%d. [%s](%s)\n", i+1, singleLine(r.Title), r.URL)
+		if snippet := singleLine(r.Snippet); snippet != "" {
+			fmt.Fprintf(&b, "   %s\n", snippet)
+		}
+	}
+	return b.String()
+}
+
+// singleLine collapses whitespace so a field cannot break the list layout
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// cleanResults drops results without a URL and applies the limit
+func cleanResults(results []Result, limit int) []Result {
+	cleaned := make([]Result, 0, min(len(results), limit))
+	for _, r := range results {
+		if r.URL == "" {
+			continue
+		}
+		if r.Title == "" {
+			r.Title = r.URL
+		}
+		cleaned = append(cleaned, r)
+		if len(cleaned) == limit {
+			break
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,221 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSearchProviderFactory(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider ProviderType
+		opts     Options
+		wantErr  bool
+	}{
+		{name: "searxng", provider: ProviderSearXNG, opts: Options{URL: "http://searxng:8080"}},
+		{name: "json", provider: ProviderJSON, opts: Options{URL: "https://search.example.com/api"}},
+		{name: "missing url", provider: ProviderSearXNG, wantErr: true},
+		{name: "missing file", provider: ProviderFile, opts: Options{URL: "/does/not/exist.json"}, wantErr: true},
+		{name: "unknown", provider: "bing", opts: Options{URL: "https://bing.com"}, wantErr: true},
+		{name: "none", provider: ProviderNone, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := SearchProviderFactory(tt.provider, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchProviderFactory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && p.Name() != tt.provider {
+				t.Errorf("Name() = %s, want %s", p.Name(), tt.provider)
+			}
+		})
+	}
+}
+
+func TestSearXNGProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" || r.URL.Query().Get("q") != "go generics" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"results": [
+			{"title": "Tutorial", "url": "https://go.dev/doc/tutorial/generics", "content": "Getting started"},
+			{"title": "No URL", "url": "", "content": "dropped"},
+			{"title": "Spec", "url": "https://go.dev/ref/spec", "content": "Type parameters"}
+		]}`))
+	}))
+	defer server.Close()
+
+	p, err := SearchProviderFactory(ProviderSearXNG, Options{URL: server.URL + "/", APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("SearchProviderFactory() error = %v", err)
+	}
+
+	results, err := p.Search(context.Background(), "go generics", 5)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Search() returned %d results, want 2", len(results))
+	}
+	if results[0].Snippet != "Getting started" || results[1].URL != "https://go.dev/ref/spec" {
+		t.Errorf("Search() = %+v", results)
+	}
+}
+
+func TestJSONProvider(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "wrapped", body: `{"results": [{"title": "A", "url": "https://a.example"}, {"title": "B", "url": "https://b.example"}, {"title": "C", "url": "https://c.example"}]}`, want: 2},
+		{name: "bare array", body: `[{"title": "A", "url": "https://a.example", "snippet": "a"}]`, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("q") != "arandu" || r.URL.Query().Get("limit") != "2" || r.URL.Query().Get("lang") != "es" {
+					http.Error(w, "bad request", http.StatusBadRequest)
+					return
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p, err := SearchProviderFactory(ProviderJSON, Options{URL: server.URL + "/api?lang=es"})
+			if err != nil {
+				t.Fatalf("SearchProviderFactory() error = %v", err)
+			}
+
+			results, err := p.Search(context.Background(), "arandu", 2)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("Search() returned %d results, want %d", len(results), tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONProvider_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "server error", status: http.StatusInternalServerError, body: `{}`},
+		{name: "invalid json", status: http.StatusOK, body: `not json`},
+		{name: "unexpected shape", status: http.StatusOK, body: `{"results": "none"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p, _ := SearchProviderFactory(ProviderJSON, Options{URL: server.URL})
+			if _, err := p.Search(context.Background(), "q", 5); err == nil {
+				t.Error("Search() expected error")
+			}
+		})
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	data := `{
+		"Go Generics": [{"title": "Tutorial", "url": "https://go.dev/doc/tutorial/generics"}],
+		"*": [{"title": "Fallback", "url": "https://example.com"}]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := SearchProviderFactory(ProviderFile, Options{URL: path})
+	if err != nil {
+		t.Fatalf("SearchProviderFactory() error = %v", err)
+	}
+
+	results, _ := p.Search(context.Background(), "  go   generics ", 5)
+	if len(results) != 1 || results[0].Title != "Tutorial" {
+		t.Errorf("Search() = %+v, want the Tutorial result", results)
+	}
+
+	results, _ = p.Search(context.Background(), "something else", 5)
+	if len(results) != 1 || results[0].Title != "Fallback" {
+		t.Errorf("Search() = %+v, want the fallback result", results)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	defaultProvider = nil
+	if _, err := Default(); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Default() error = %v, want ErrNotConfigured", err)
+	}
+
+	if err := Init(ProviderSearXNG, Options{URL: "http://searxng:8080"}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer func() { defaultProvider = nil }()
+
+	p, err := Default()
+	if err != nil || p.Name() != ProviderSearXNG {
+		t.Errorf("Default() = %v, %v", p, err)
+	}
+}
+
+func TestClampLimit(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{0, DefaultLimit},
+		{-3, DefaultLimit},
+		{3, 3},
+		{100, MaxLimit},
+	}
+
+	for _, tt := range tests {
+		if got := ClampLimit(tt.limit); got != tt.want {
+			t.Errorf("ClampLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestFormatResults(t *testing.T) {
+	got := FormatResults("go", []Result{
+		{Title: "Go\nhome", URL: "https://go.dev", Snippet: "The Go\n\nprogramming language"},
+		{Title: "Spec", URL: "https://go.dev/ref/spec"},
+	})
+
+	for _, want := range []string{
+		`Search results for "go"`,
+		"1. [Go home](https://go.dev)\n   The Go programming language\n",
+		"2. [Spec](https://go.dev/ref/spec)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatResults() = %q, missing %q", got, want)
+		}
+	}
+
+	if got := FormatResults("nothing", nil); !strings.Contains(got, "No results") {
+		t.Errorf("FormatResults() = %q", got)
+	}
+}
@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/arandu-ai/arandu/redact"
+)
+
+// Placeholder is what a secret's value is replaced with
+func Placeholder(name string) string {
+	return "[secret:" + name + "]"
+}
+
+type secretValue struct {
+	name  string
+	value string
+}
+
+// Redactor replaces the values of a set of secrets with their placeholders
+// It is also a redact.Detector, so secrets go through the output pipeline
+// The zero value and nil redact nothing
+type Redactor struct {
+	names  []string
+	values []secretValue
+}
+
+// NewRedactor builds a redactor for the values, keyed by secret name
+func NewRedactor(values map[string]string) *Redactor {
+	r := &Redactor{}
+	for name, value := range values {
+		r.names = append(r.names, name)
+		if value == "" {
+			continue
+		}
+		r.values = append(r.values, secretValue{name, value})
+		// Terminals turn \n into \r\n, multi-line values show up that way
+		if strings.Contains(value, "\n") {
+			r.values = append(r.values, secretValue{name, strings.ReplaceAll(value, "\n", "\r\n")})
+		}
+	}
+	sort.Strings(r.names)
+
+	// Longest values first, so a secret containing another one is redacted whole
+	sort.Slice(r.values, func(i, j int) bool {
+		if len(r.values[i].value) != len(r.values[j].value) {
+			return len(r.values[i].value) > len(r.values[j].value)
+		}
+		return r.values[i].name < r.values[j].name
+	})
+	return r
+}
+
+// Names returns the names of the secrets, sorted
+func (r *Redactor) Names() []string {
+	if r == nil {
+		return nil
+	}
+	return r.names
+}
+
+// Name identifies secrets in redaction counts
+func (r *Redactor) Name() string {
+	return "secret"
+}
+
+// Find returns every occurrence of a secret value
+func (r *Redactor) Find(text string) []redact.Match {
+	if r == nil {
+		return nil
+	}
+	var matches []redact.Match
+	for _, v := range r.values {
+		for offset := 0; ; {
+			i := strings.Index(text[offset:], v.value)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			matches = append(matches, redact.Match{
+				Start:       start,
+				End:         start + len(v.value),
+				Replacement: Placeholder(v.name),
+			})
+			offset = start + len(v.value)
+		}
+	}
+	return matches
+}
+
+// Redact replaces every secret value in s
+func (r *Redactor) Redact(s string) string {
+	out, _ := redact.New(r).Redact(s)
+	return out
+}
@@ -0,0 +1,131 @@
+// Package secrets keeps the credentials of the sandboxes encrypted at rest.
+// Secrets reach a flow's container as environment variables; the model only
+// learns their names, and their values are redacted from what it reads back
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arandu-ai/arandu/config"
+)
+
+const (
+	// MinValueLength keeps short values, which would redact unrelated output, out of the vault
+	MinValueLength = 8
+	// MaxValueLength caps a value; environment variables are not meant for files
+	MaxValueLength = 64 * 1024
+)
+
+// ErrDisabled is returned when no SECRETS_KEY is configured
+var ErrDisabled = errors.New("secrets are disabled: set SECRETS_KEY")
+
+var namePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]{0,63}$`)
+
+// reservedNames are set by the sandbox itself; overriding them would break it
+// or bypass the egress proxy
+var reservedNames = map[string]bool{
+	"PATH":        true,
+	"HOME":        true,
+	"HOSTNAME":    true,
+	"TERM":        true,
+	"HTTP_PROXY":  true,
+	"HTTPS_PROXY": true,
+	"NO_PROXY":    true,
+}
+
+// Enabled reports whether the vault has a key
+func Enabled() bool {
+	return config.Config.SecretsKey != ""
+}
+
+// ValidateKey checks the configured SECRETS_KEY, if any
+func ValidateKey() error {
+	if !Enabled() {
+		return nil
+	}
+	_, err := key()
+	return err
+}
+
+func key() ([]byte, error) {
+	if !Enabled() {
+		return nil, ErrDisabled
+	}
+	k, err := base64.StdEncoding.DecodeString(strings.TrimSpace(config.Config.SecretsKey))
+	if err != nil || len(k) != 32 {
+		return nil, fmt.Errorf("SECRETS_KEY must be 32 bytes encoded in base64")
+	}
+	return k, nil
+}
+
+// ValidateName checks that a name is a usable environment variable
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("secret name must be an environment variable name: 1-64 uppercase letters, digits and _, not starting with a digit")
+	}
+	if reservedNames[name] {
+		return fmt.Errorf("%s is set by the sandbox and cannot be a secret", name)
+	}
+	return nil
+}
+
+// ValidateValue checks a value before it is stored
+func ValidateValue(value string) error {
+	if len(value) < MinValueLength || len(value) > MaxValueLength {
+		return fmt.Errorf("secret value must have %d to %d bytes", MinValueLength, MaxValueLength)
+	}
+	if strings.ContainsRune(value, 0) {
+		return fmt.Errorf("secret value cannot contain NUL bytes")
+	}
+	return nil
+}
+
+// Encrypt seals a value with AES-256-GCM. The name is authenticated too, so
+// a value copied onto another secret fails to decrypt
+func Encrypt(name string, value string) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, []byte(value), []byte(name)), nil
+}
+
+// Decrypt opens a value sealed by Encrypt
+func Decrypt(name string, sealed []byte) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("secret %s is corrupted", name)
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	value, err := gcm.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s: wrong SECRETS_KEY or corrupted value", name)
+	}
+	return string(value), nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	k, err := key()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,149 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/config"
+)
+
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestEncryptDecrypt(t *testing.T) {
+	defer func(key string) { config.Config.SecretsKey = key }(config.Config.SecretsKey)
+	config.Config.SecretsKey = testKey
+
+	sealed, err := Encrypt("NPM_TOKEN", "npm_abcdef123456")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if strings.Contains(string(sealed), "npm_abcdef123456") {
+		t.Fatal("Encrypt() stored the value in clear")
+	}
+
+	value, err := Decrypt("NPM_TOKEN", sealed)
+	if err != nil || value != "npm_abcdef123456" {
+		t.Errorf("Decrypt() = %q, %v", value, err)
+	}
+	if _, err := Decrypt("OTHER_TOKEN", sealed); err == nil {
+		t.Error("Decrypt() with another name should fail")
+	}
+
+	config.Config.SecretsKey = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+	if _, err := Decrypt("NPM_TOKEN", sealed); err == nil {
+		t.Error("Decrypt() with another key should fail")
+	}
+}
+
+func TestKey(t *testing.T) {
+	defer func(key string) { config.Config.SecretsKey = key }(config.Config.SecretsKey)
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "disabled", key: ""},
+		{name: "valid key", key: testKey},
+		{name: "short key", key: "c2hvcnQ=", wantErr: true},
+		{name: "not base64", key: "not a key!", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Config.SecretsKey = tt.key
+			if err := ValidateKey(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	config.Config.SecretsKey = ""
+	if _, err := Encrypt("NPM_TOKEN", "npm_abcdef123456"); err != ErrDisabled {
+		t.Errorf("Encrypt() without key error = %v, want ErrDisabled", err)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "NPM_TOKEN"},
+		{name: "_PRIVATE"},
+		{name: "DB2_URL"},
+		{name: "", wantErr: true},
+		{name: "npm_token", wantErr: true},
+		{name: "2FA_CODE", wantErr: true},
+		{name: "MY-TOKEN", wantErr: true},
+		{name: "PATH", wantErr: true},
+		{name: "HTTPS_PROXY", wantErr: true},
+		{name: strings.Repeat("A", 65), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "token", value: "ghp_0123456789"},
+		{name: "too short", value: "secret", wantErr: true},
+		{name: "too long", value: strings.Repeat("a", MaxValueLength+1), wantErr: true},
+		{name: "nul byte", value: "abc\x00defghij", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateValue(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor(map[string]string{
+		"GITHUB_TOKEN": "ghp_0123456789",
+		"DATABASE_URL": "postgres://app:ghp_0123456789@db/app",
+		"DEPLOY_KEY":   "-----BEGIN KEY-----\nabc\n-----END KEY-----",
+	})
+
+	if got := strings.Join(r.Names(), ","); got != "DATABASE_URL,DEPLOY_KEY,GITHUB_TOKEN" {
+		t.Errorf("Names() = %s", got)
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "no secrets", in: "npm install done", want: "npm install done"},
+		{name: "token", in: "token=ghp_0123456789\n", want: "token=[secret:GITHUB_TOKEN]\n"},
+		{name: "twice", in: "ghp_0123456789 ghp_0123456789", want: "[secret:GITHUB_TOKEN] [secret:GITHUB_TOKEN]"},
+		{name: "longest first", in: "url: postgres://app:ghp_0123456789@db/app", want: "url: [secret:DATABASE_URL]"},
+		{name: "terminal newlines", in: "-----BEGIN KEY-----\r\nabc\r\n-----END KEY-----\r\n", want: "[secret:DEPLOY_KEY]\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.in); got != tt.want {
+				t.Errorf("Redact() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var none *Redactor
+	if got := none.Redact("ghp_0123456789"); got != "ghp_0123456789" {
+		t.Errorf("nil Redact() = %q", got)
+	}
+}
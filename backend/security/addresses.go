@@ -0,0 +1,104 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+)
+
+// hostLookupTimeout bounds the DNS lookup of ValidateHost
+const hostLookupTimeout = 5 * time.Second
+
+// LookupHost resolves the addresses checked by ValidateHost
+// Tests replace it to avoid depending on DNS
+var LookupHost = func(ctx context.Context, host string) ([]netip.Addr, error) {
+	return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+}
+
+// blockedPrefixes are the ranges that outbound requests must never reach
+// Private, loopback and link-local ranges are checked with the netip helpers
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // TEST-NET-1
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // TEST-NET-2
+	netip.MustParsePrefix("203.0.113.0/24"),  // TEST-NET-3
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved and broadcast
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // NAT64 local-use prefix
+	netip.MustParsePrefix("100::/64"),        // Discard-only
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation
+}
+
+// nat64Prefix carries an IPv4 address in its last 32 bits
+// A public-looking IPv6 address in it can still route to an internal IPv4 host
+var nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// ValidateIP checks that an address is safe for server-side requests (SSRF)
+// IPv4-mapped and NAT64 addresses are checked against the IPv4 address they carry
+func ValidateIP(ip netip.Addr) error {
+	if !ip.IsValid() {
+		return fmt.Errorf("invalid IP address")
+	}
+	if isBlockedIP(ip) {
+		return fmt.Errorf("%s is an internal address", ip)
+	}
+	return nil
+}
+
+// ValidateIPString parses an IP address and checks it with ValidateIP
+func ValidateIPString(raw string) error {
+	ip, err := netip.ParseAddr(raw)
+	if err != nil {
+		return fmt.Errorf("invalid IP address: %s", raw)
+	}
+	return ValidateIP(ip)
+}
+
+// ValidateHost resolves a host name and checks every address it points to, so
+// names like 10.0.0.5.nip.io cannot reach internal addresses
+func ValidateHost(host string) error {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return ValidateIP(ip)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hostLookupTimeout)
+	defer cancel()
+
+	addrs, err := LookupHost(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("cannot resolve %s", host)
+	}
+	for _, addr := range addrs {
+		if err := ValidateIP(addr); err != nil {
+			return fmt.Errorf("%s resolves to %w", host, err)
+		}
+	}
+	return nil
+}
+
+func isBlockedIP(ip netip.Addr) bool {
+	ip = ip.WithZone("").Unmap()
+
+	if nat64Prefix.Contains(ip) {
+		b := ip.As16()
+		return isBlockedIP(netip.AddrFrom4([4]byte{b[12], b[13], b[14], b[15]}))
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"testing"
+)
+
+func TestValidateIPString(t *testing.T) {
+	tests := []struct {
+		ip      string
+		wantErr bool
+	}{
+		{ip: "93.184.216.34", wantErr: false},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", wantErr: false},
+		{ip: "64:ff9b::5db8:d822", wantErr: false}, // NAT64 for 93.184.216.34
+		{ip: "127.0.0.1", wantErr: true},
+		{ip: "10.0.0.5", wantErr: true},
+		{ip: "172.17.0.2", wantErr: true},
+		{ip: "192.168.1.1", wantErr: true},
+		{ip: "169.254.169.254", wantErr: true},
+		{ip: "0.0.0.0", wantErr: true},
+		{ip: "0.1.2.3", wantErr: true},
+		{ip: "100.64.0.1", wantErr: true},
+		{ip: "100.127.255.254", wantErr: true},
+		{ip: "198.18.0.1", wantErr: true},
+		{ip: "255.255.255.255", wantErr: true},
+		{ip: "224.0.0.1", wantErr: true},
+		{ip: "::", wantErr: true},
+		{ip: "::1", wantErr: true},
+		{ip: "fd00::1", wantErr: true},
+		{ip: "fe80::1%eth0", wantErr: true},
+		{ip: "::ffff:127.0.0.1", wantErr: true},
+		{ip: "::ffff:169.254.169.254", wantErr: true},
+		{ip: "64:ff9b::7f00:1", wantErr: true},    // NAT64 for 127.0.0.1
+		{ip: "64:ff9b::a9fe:a9fe", wantErr: true}, // NAT64 for 169.254.169.254
+		{ip: "64:ff9b:1::a00:5", wantErr: true},   // Local-use NAT64
+		{ip: "not-an-ip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if err := ValidateIPString(tt.ip); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateIPString(%q) error = %v, wantErr %v", tt.ip, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateHost(t *testing.T) {
+	lookup := LookupHost
+	t.Cleanup(func() { LookupHost = lookup })
+	LookupHost = func(_ context.Context, host string) ([]netip.Addr, error) {
+		switch host {
+		case "example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		case "10.0.0.5.nip.io":
+			return []netip.Addr{netip.MustParseAddr("10.0.0.5")}, nil
+		case "mixed.example":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("127.0.0.1")}, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+
+	tests := []struct {
+		host    string
+		wantErr bool
+	}{
+		{host: "example.com", wantErr: false},
+		{host: "93.184.216.34", wantErr: false},
+		{host: "10.0.0.5.nip.io", wantErr: true},
+		{host: "mixed.example", wantErr: true},
+		{host: "10.1.2.3", wantErr: true},
+		{host: "::1", wantErr: true},
+		{host: "unknown.invalid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if err := ValidateHost(tt.host); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHost(%q) error = %v, wantErr %v", tt.host, err, tt.wantErr)
+			}
+		})
+	}
+}
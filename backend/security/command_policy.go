@@ -0,0 +1,185 @@
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// CommandAction is what a command policy does with a command
+type CommandAction string
+
+const (
+	CommandAllow           CommandAction = "allow"
+	CommandDeny            CommandAction = "deny"
+	CommandRequireApproval CommandAction = "requireApproval"
+)
+
+// severity orders the actions: the most restrictive one wins
+func (a CommandAction) severity() int {
+	switch a {
+	case CommandDeny:
+		return 2
+	case CommandRequireApproval:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CommandRule matches simple commands by program, arguments and the program
+// their output is piped to
+type CommandRule struct {
+	Name string `json:"name,omitempty"`
+	// Command is a glob on the program name, e.g. "docker", "pip*" or "*"
+	Command string `json:"command"`
+	// Args is a regular expression searched in the arguments joined by spaces
+	Args string `json:"args,omitempty"`
+	// PipedTo is a glob on the program that reads the output, e.g. "*sh"
+	PipedTo string        `json:"pipedTo,omitempty"`
+	Action  CommandAction `json:"action"`
+	// Reason is told to the model when the rule denies a command
+	Reason string `json:"reason,omitempty"`
+
+	args *regexp.Regexp
+}
+
+// CommandPolicy is an ordered list of rules. The first rule that matches a
+// simple command decides for it; commands no rule matches get Default
+type CommandPolicy struct {
+	Default CommandAction `json:"default,omitempty"`
+	Rules   []CommandRule `json:"rules"`
+	// Scope names where the policy comes from in decisions, e.g. "team"
+	Scope string `json:"-"`
+}
+
+// CommandDecision is the outcome of checking a command line
+type CommandDecision struct {
+	Action CommandAction
+	// Command is the simple command that decided, empty for allowed lines
+	Command string
+	// Scope and Rule identify the policy and rule: its name, or its position
+	// for unnamed rules. Rule is empty for defaults
+	Scope  string
+	Rule   string
+	Reason string
+}
+
+// ParseCommandPolicy decodes and validates a policy in JSON
+func ParseCommandPolicy(data []byte) (*CommandPolicy, error) {
+	var policy CommandPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("error parsing command policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Validate checks the actions, globs and expressions of the policy and
+// compiles its rules
+func (p *CommandPolicy) Validate() error {
+	if p.Default == "" {
+		p.Default = CommandAllow
+	}
+	if !validCommandAction(p.Default) {
+		return fmt.Errorf("invalid default action %q: use allow, deny or requireApproval", p.Default)
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		label := rule.label(i)
+		if strings.TrimSpace(rule.Command) == "" {
+			return fmt.Errorf("command rule %s: command is required, use * for any", label)
+		}
+		if _, err := path.Match(rule.Command, ""); err != nil {
+			return fmt.Errorf("command rule %s: invalid command pattern %q", label, rule.Command)
+		}
+		if _, err := path.Match(rule.PipedTo, ""); err != nil {
+			return fmt.Errorf("command rule %s: invalid pipedTo pattern %q", label, rule.PipedTo)
+		}
+		if !validCommandAction(rule.Action) {
+			return fmt.Errorf("command rule %s: invalid action %q: use allow, deny or requireApproval", label, rule.Action)
+		}
+		rule.args = nil
+		if rule.Args != "" {
+			re, err := regexp.Compile(rule.Args)
+			if err != nil {
+				return fmt.Errorf("command rule %s: invalid args pattern: %w", label, err)
+			}
+			rule.args = re
+		}
+	}
+	return nil
+}
+
+// label names the rule in errors and decisions
+func (r *CommandRule) label(index int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%d", index+1)
+}
+
+func validCommandAction(action CommandAction) bool {
+	switch action {
+	case CommandAllow, CommandDeny, CommandRequireApproval:
+		return true
+	}
+	return false
+}
+
+// matches reports whether the rule applies to a simple command
+func (r *CommandRule) matches(c ShellCommand) bool {
+	if ok, _ := path.Match(r.Command, c.Name); !ok {
+		return false
+	}
+	if r.PipedTo != "" {
+		if ok, _ := path.Match(r.PipedTo, c.PipedTo); !ok || c.PipedTo == "" {
+			return false
+		}
+	}
+	if r.args != nil && !r.args.MatchString(strings.Join(c.Args, " ")) {
+		return false
+	}
+	return true
+}
+
+// decide returns the decision of the policy for a simple command
+func (p *CommandPolicy) decide(c ShellCommand) CommandDecision {
+	for i, rule := range p.Rules {
+		if rule.matches(c) {
+			return CommandDecision{
+				Action:  rule.Action,
+				Command: c.String(),
+				Scope:   p.Scope,
+				Rule:    rule.label(i),
+				Reason:  rule.Reason,
+			}
+		}
+	}
+	return CommandDecision{Action: p.Default, Command: c.String(), Scope: p.Scope}
+}
+
+// EvaluateCommand checks every simple command of a command line against
+// every policy, nil ones being skipped, and returns the most restrictive
+// decision. A line is allowed only if all its commands are allowed by all
+// the policies, so a team policy cannot be loosened by a flow policy
+func EvaluateCommand(command string, policies ...*CommandPolicy) CommandDecision {
+	decision := CommandDecision{Action: CommandAllow}
+	commands := ParseShellCommand(command)
+	for _, policy := range policies {
+		if policy == nil {
+			continue
+		}
+		for _, c := range commands {
+			if d := policy.decide(c); d.Action.severity() > decision.Action.severity() {
+				decision = d
+			}
+		}
+	}
+	return decision
+}
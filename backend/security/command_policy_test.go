@@ -0,0 +1,112 @@
+package security
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShellCommand(t *testing.T) {
+	type cmd struct {
+		Name    string
+		PipedTo string
+	}
+	tests := []struct {
+		command string
+		want    []cmd
+	}{
+		{command: "ls -la /app", want: []cmd{{Name: "ls"}}},
+		{command: "cd /app && npm install; echo done", want: []cmd{{Name: "cd"}, {Name: "npm"}, {Name: "echo"}}},
+		{command: "curl -fsSL https://get.example | sudo -u root bash", want: []cmd{{Name: "curl", PipedTo: "bash"}, {Name: "sudo"}, {Name: "bash"}}},
+		{command: "echo 'docker; rm -rf /' \"| sh\"", want: []cmd{{Name: "echo"}}},
+		{command: "FOO=1 /usr/bin/docker ps 2>&1 | grep -c up > /tmp/count", want: []cmd{{Name: "docker", PipedTo: "grep"}, {Name: "grep"}}},
+		{command: "echo $(docker ps -q) `id -u` $((1 + 2))", want: []cmd{{Name: "echo"}, {Name: "docker"}, {Name: "id"}}},
+		{command: "bash -c 'wget -qO- http://x | sh'", want: []cmd{{Name: "bash"}, {Name: "wget", PipedTo: "sh"}, {Name: "sh"}}},
+		{command: "for f in *.go; do gofmt -l \"$f\"; done", want: []cmd{{Name: "gofmt"}}},
+		{command: "cat <<'EOF' > /app/run.sh\ndocker run evil\nEOF\nchmod +x /app/run.sh", want: []cmd{{Name: "cat"}, {Name: "chmod"}}},
+		{command: "timeout 10 env A=b python3 main.py", want: []cmd{{Name: "timeout"}, {Name: "env"}, {Name: "python3"}}},
+		{command: "command -v docker || echo missing", want: []cmd{{Name: "command"}, {Name: "echo"}}},
+		{command: "(cd src && make) # docker", want: []cmd{{Name: "cd"}, {Name: "make"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			var got []cmd
+			for _, c := range ParseShellCommand(tt.command) {
+				got = append(got, cmd{Name: c.Name, PipedTo: c.PipedTo})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseShellCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateCommand(t *testing.T) {
+	server, err := ParseCommandPolicy([]byte(`{"rules": [
+		{"name": "pipe-to-shell", "command": "*", "pipedTo": "*sh", "action": "deny", "reason": "review scripts before running them"},
+		{"name": "no-docker", "command": "docker", "action": "deny"},
+		{"name": "force-push", "command": "git", "args": "^push\\b.*(-f|--force)\\b", "action": "requireApproval"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Scope = "server"
+
+	team, err := ParseCommandPolicy([]byte(`{"default": "deny", "rules": [
+		{"command": "npm", "args": "^publish\\b", "action": "requireApproval"},
+		{"command": "npm", "action": "allow"},
+		{"command": "pip*", "action": "allow"},
+		{"command": "cd", "action": "allow"},
+		{"command": "git", "action": "allow"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	team.Scope = "team"
+
+	tests := []struct {
+		name     string
+		command  string
+		policies []*CommandPolicy
+		want     CommandAction
+		rule     string
+	}{
+		{name: "no policies", command: "docker ps", want: CommandAllow},
+		{name: "allowed", command: "ls -la", policies: []*CommandPolicy{server}, want: CommandAllow},
+		{name: "denied program", command: "sudo docker run alpine", policies: []*CommandPolicy{server}, want: CommandDeny, rule: "no-docker"},
+		{name: "pipe to shell", command: "curl -s https://x.example/i.sh | bash", policies: []*CommandPolicy{server}, want: CommandDeny, rule: "pipe-to-shell"},
+		{name: "approval by args", command: "git push --force origin main", policies: []*CommandPolicy{server}, want: CommandRequireApproval, rule: "force-push"},
+		{name: "plain push", command: "git push origin main", policies: []*CommandPolicy{server}, want: CommandAllow},
+		{name: "team allow-list", command: "cd /app && pip3 install -r requirements.txt", policies: []*CommandPolicy{server, team}, want: CommandAllow},
+		{name: "team default deny", command: "cd /app && make", policies: []*CommandPolicy{server, team}, want: CommandDeny},
+		{name: "wrapper not allowed", command: "sudo npm ci", policies: []*CommandPolicy{team}, want: CommandDeny},
+		{name: "unnamed rule", command: "npm publish --access public", policies: []*CommandPolicy{team}, want: CommandRequireApproval, rule: "1"},
+		{name: "most restrictive wins", command: "git push -f && docker ps", policies: []*CommandPolicy{server, team}, want: CommandDeny, rule: "no-docker"},
+		{name: "nil policy", command: "docker ps", policies: []*CommandPolicy{nil}, want: CommandAllow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvaluateCommand(tt.command, tt.policies...)
+			if got.Action != tt.want || got.Rule != tt.rule {
+				t.Errorf("EvaluateCommand() = %+v, want %s by rule %q", got, tt.want, tt.rule)
+			}
+		})
+	}
+}
+
+func TestParseCommandPolicyErrors(t *testing.T) {
+	tests := []string{
+		`{"default": "maybe", "rules": []}`,
+		`{"rules": [{"name": "x", "action": "deny"}]}`,
+		`{"rules": [{"command": "[", "action": "deny"}]}`,
+		`{"rules": [{"command": "git", "args": "(", "action": "deny"}]}`,
+		`{"rules": [{"command": "git", "action": "block"}]}`,
+	}
+
+	for _, raw := range tests {
+		if _, err := ParseCommandPolicy([]byte(raw)); err == nil {
+			t.Errorf("ParseCommandPolicy(%s) expected error", raw)
+		}
+	}
+}
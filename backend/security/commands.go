@@ -0,0 +1,453 @@
+package security
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ShellCommand is a simple command of a shell command line
+type ShellCommand struct {
+	// Name is the program, without its directory
+	Name string
+	Args []string
+	// PipedTo is the program that reads the output of this one, if any
+	PipedTo string
+}
+
+// String returns the command as it would be typed, without quoting
+func (c ShellCommand) String() string {
+	return strings.TrimSpace(c.Name + " " + strings.Join(c.Args, " "))
+}
+
+// commandWrappers run the command that follows them. The value holds the
+// options of each wrapper that take an argument
+var commandWrappers = map[string]map[string]bool{
+	"sudo":    {"-u": true, "-g": true, "-C": true, "-D": true},
+	"doas":    {"-u": true, "-C": true},
+	"env":     {"-u": true, "-C": true, "-S": true},
+	"nice":    {"-n": true},
+	"nohup":   {},
+	"time":    {},
+	"exec":    {"-a": true},
+	"command": {},
+	"stdbuf":  {"-i": true, "-o": true, "-e": true},
+	"timeout": {"-s": true, "-k": true},
+	"xargs":   {"-I": true, "-n": true, "-P": true, "-d": true, "-L": true, "-s": true, "-E": true, "-a": true},
+}
+
+// shellInterpreters run the script passed with -c
+var shellInterpreters = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "ash": true}
+
+// shellKeywords open or close compound commands and are skipped
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"while": true, "until": true, "do": true, "done": true,
+	"{": true, "}": true, "!": true, "esac": true,
+}
+
+var assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\[[^]]*\])?\+?=`)
+
+// ParseShellCommand splits a command line into its simple commands: those
+// joined by pipes, lists and subshells, the ones inside command substitutions
+// and the scripts given to sh -c or eval. Wrappers such as sudo or env are
+// reported along with the command they run
+// It parses the common shell syntax, not all of it; unknown constructs end up
+// as commands, so policies err on the restrictive side
+func ParseShellCommand(command string) []ShellCommand {
+	return parseShell(command, 0)
+}
+
+// maxShellDepth bounds the nesting of substitutions and sh -c scripts
+const maxShellDepth = 8
+
+func parseShell(command string, depth int) []ShellCommand {
+	if depth > maxShellDepth {
+		return nil
+	}
+
+	lexer := &shellLexer{input: []rune(command)}
+	lexer.run()
+
+	var commands []ShellCommand
+	var previous []int
+	pipedFrom := false
+	for _, segment := range lexer.segments {
+		chain, nested := segmentCommands(segment.words, depth)
+		if pipedFrom && len(chain) > 0 {
+			program := chain[len(chain)-1].Name
+			for _, i := range previous {
+				commands[i].PipedTo = program
+			}
+		}
+
+		previous = previous[:0]
+		for _, c := range chain {
+			previous = append(previous, len(commands))
+			commands = append(commands, c)
+		}
+		commands = append(commands, nested...)
+		for _, script := range segment.nested {
+			commands = append(commands, parseShell(script, depth+1)...)
+		}
+		pipedFrom = segment.pipe
+	}
+	return commands
+}
+
+// segmentCommands turns the words of a simple command into the chain of
+// wrappers and the program they run, the last one being the program, and the
+// commands of the script it runs with sh -c or eval
+func segmentCommands(words []string, depth int) (chain []ShellCommand, nested []ShellCommand) {
+	// Variable assignments before the command
+	for len(words) > 0 && assignmentPattern.MatchString(words[0]) {
+		words = words[1:]
+	}
+	for len(words) > 0 && shellKeywords[words[0]] {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+	switch words[0] {
+	case "for", "case", "select", "function":
+		// The header lists words, not commands; the body is another segment
+		return nil, nil
+	}
+
+	for len(words) > 0 {
+		name := path.Base(words[0])
+		args := words[1:]
+		chain = append(chain, ShellCommand{Name: name, Args: args})
+
+		options, wrapper := commandWrappers[name]
+		if !wrapper {
+			break
+		}
+		// command -v only looks the program up
+		if name == "command" && len(args) > 0 && (args[0] == "-v" || args[0] == "-V") {
+			break
+		}
+		words = skipWrapperOptions(name, args, options)
+	}
+
+	last := chain[len(chain)-1]
+	switch {
+	case shellInterpreters[last.Name]:
+		for i, arg := range last.Args {
+			if arg == "-c" && i+1 < len(last.Args) {
+				nested = parseShell(last.Args[i+1], depth+1)
+				break
+			}
+		}
+	case last.Name == "eval":
+		nested = parseShell(strings.Join(last.Args, " "), depth+1)
+	}
+	return chain, nested
+}
+
+// skipWrapperOptions returns the words of the command a wrapper runs
+func skipWrapperOptions(name string, args []string, options map[string]bool) []string {
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--":
+			return args[1:]
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			args = args[1:]
+			if options[arg] && len(args) > 0 {
+				args = args[1:]
+			}
+		case name == "env" && assignmentPattern.MatchString(arg):
+			args = args[1:]
+		default:
+			if name == "timeout" {
+				// The duration comes before the command
+				return args[1:]
+			}
+			return args
+		}
+	}
+	return nil
+}
+
+// shellSegment is a simple command as read by the lexer
+type shellSegment struct {
+	words []string
+	// nested holds the scripts of its command and process substitutions
+	nested []string
+	// pipe is set when the output goes to the next segment
+	pipe bool
+}
+
+// shellLexer splits a command line into segments of words
+type shellLexer struct {
+	input    []rune
+	pos      int
+	segments []shellSegment
+	current  shellSegment
+	word     strings.Builder
+	inWord   bool
+	// redirect is set when the next word is the target of a redirection
+	redirect bool
+	// heredocs holds the delimiters of the here-documents that start on the next line
+	heredocs []string
+	// heredocPending is set after << until its delimiter is read
+	heredocPending bool
+}
+
+func (l *shellLexer) run() {
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		switch {
+		case r == '\\':
+			if l.pos+1 < len(l.input) {
+				if l.input[l.pos+1] != '\n' {
+					l.add(l.input[l.pos+1])
+				}
+				l.pos += 2
+				continue
+			}
+			l.pos++
+		case r == '\'':
+			l.inWord = true
+			end := l.indexFrom(l.pos+1, '\'')
+			l.word.WriteString(string(l.input[l.pos+1 : end]))
+			l.pos = end + 1
+		case r == '"':
+			l.inWord = true
+			l.pos++
+			l.readDoubleQuoted()
+		case r == '`':
+			l.inWord = true
+			end := l.indexFrom(l.pos+1, '`')
+			l.current.nested = append(l.current.nested, string(l.input[l.pos+1:end]))
+			l.word.WriteString(string(l.input[l.pos:min(end+1, len(l.input))]))
+			l.pos = end + 1
+		case r == '$' && l.peek(1) == '(':
+			l.inWord = true
+			l.readSubstitution(l.pos + 1)
+		case (r == '<' || r == '>') && l.peek(1) == '(':
+			// Process substitution
+			l.inWord = true
+			l.readSubstitution(l.pos + 1)
+		case r == '#' && !l.inWord:
+			for l.pos < len(l.input) && l.input[l.pos] != '\n' {
+				l.pos++
+			}
+		case r == ' ' || r == '\t':
+			l.endWord()
+			l.pos++
+		case r == '\n':
+			l.endSegment(false)
+			l.pos++
+			l.skipHeredocs()
+		case r == ';' || r == '(' || r == ')':
+			l.endSegment(false)
+			l.pos++
+		case r == '|':
+			switch l.peek(1) {
+			case '|':
+				l.endSegment(false)
+				l.pos += 2
+			case '&':
+				l.endSegment(true)
+				l.pos += 2
+			default:
+				l.endSegment(true)
+				l.pos++
+			}
+		case r == '&':
+			switch l.peek(1) {
+			case '&':
+				l.endSegment(false)
+				l.pos += 2
+			case '>':
+				l.endWord()
+				l.readRedirection()
+			default:
+				l.endSegment(false)
+				l.pos++
+			}
+		case r == '<' || r == '>':
+			// A number right before the operator is a file descriptor
+			if l.inWord && isDigits(l.word.String()) {
+				l.word.Reset()
+				l.inWord = false
+			}
+			l.endWord()
+			l.readRedirection()
+		default:
+			l.add(r)
+			l.pos++
+		}
+	}
+	l.endSegment(false)
+}
+
+// readRedirection consumes a redirection operator; the word after it is its
+// target, or the delimiter of a here-document
+func (l *shellLexer) readRedirection() {
+	start := l.pos
+	for l.pos < len(l.input) && strings.ContainsRune("<>&|-", l.input[l.pos]) {
+		l.pos++
+	}
+	op := string(l.input[start:l.pos])
+	// >&2 and <&0 duplicate a descriptor
+	if strings.HasSuffix(op, "&") {
+		for l.pos < len(l.input) && (l.input[l.pos] >= '0' && l.input[l.pos] <= '9' || l.input[l.pos] == '-') {
+			l.pos++
+		}
+		if l.pos < len(l.input) && l.input[l.pos] != ' ' && l.input[l.pos] != '\t' {
+			l.redirect = true
+		}
+		return
+	}
+	l.redirect = true
+	l.heredocPending = strings.HasPrefix(op, "<<") && !strings.HasPrefix(op, "<<<")
+}
+
+// readDoubleQuoted reads up to the closing quote; substitutions inside run too
+func (l *shellLexer) readDoubleQuoted() {
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		switch {
+		case r == '"':
+			l.pos++
+			return
+		case r == '\\' && l.pos+1 < len(l.input) && strings.ContainsRune("\"\\$`\n", l.input[l.pos+1]):
+			if l.input[l.pos+1] != '\n' {
+				l.add(l.input[l.pos+1])
+			}
+			l.pos += 2
+		case r == '`':
+			end := l.indexFrom(l.pos+1, '`')
+			l.current.nested = append(l.current.nested, string(l.input[l.pos+1:end]))
+			l.word.WriteString(string(l.input[l.pos:min(end+1, len(l.input))]))
+			l.pos = end + 1
+		case r == '$' && l.peek(1) == '(':
+			l.readSubstitution(l.pos + 1)
+		default:
+			l.add(r)
+			l.pos++
+		}
+	}
+}
+
+// readSubstitution reads the parenthesized script that starts at open
+// Arithmetic expansions, $((...)), hold no commands
+func (l *shellLexer) readSubstitution(open int) {
+	arithmetic := open+1 < len(l.input) && l.input[open+1] == '('
+
+	level := 0
+	quote := rune(0)
+	end := len(l.input)
+	for i := open; i < len(l.input); i++ {
+		r := l.input[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				i++
+			}
+		case r == '\\':
+			i++
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			level++
+		case r == ')':
+			level--
+		}
+		if level == 0 {
+			end = i
+			break
+		}
+	}
+
+	if !arithmetic {
+		l.current.nested = append(l.current.nested, string(l.input[open+1:min(end, len(l.input))]))
+	}
+	l.word.WriteString(string(l.input[l.pos:min(end+1, len(l.input))]))
+	l.pos = end + 1
+}
+
+// skipHeredocs drops the bodies of the here-documents opened on the last line
+func (l *shellLexer) skipHeredocs() {
+	for _, delimiter := range l.heredocs {
+		for l.pos < len(l.input) {
+			end := l.indexFrom(l.pos, '\n')
+			line := strings.TrimLeft(string(l.input[l.pos:end]), "\t")
+			l.pos = end + 1
+			if line == delimiter {
+				break
+			}
+		}
+	}
+	l.heredocs = nil
+}
+
+func (l *shellLexer) add(r rune) {
+	l.inWord = true
+	l.word.WriteRune(r)
+}
+
+func (l *shellLexer) endWord() {
+	if !l.inWord {
+		return
+	}
+	word := l.word.String()
+	l.word.Reset()
+	l.inWord = false
+
+	switch {
+	case l.heredocPending:
+		l.heredocs = append(l.heredocs, word)
+		l.heredocPending = false
+		l.redirect = false
+	case l.redirect:
+		l.redirect = false
+	default:
+		l.current.words = append(l.current.words, word)
+	}
+}
+
+func (l *shellLexer) endSegment(pipe bool) {
+	l.endWord()
+	l.redirect = false
+	if len(l.current.words) > 0 || len(l.current.nested) > 0 {
+		l.current.pipe = pipe
+		l.segments = append(l.segments, l.current)
+	}
+	l.current = shellSegment{}
+}
+
+func (l *shellLexer) peek(offset int) rune {
+	if l.pos+offset < len(l.input) {
+		return l.input[l.pos+offset]
+	}
+	return 0
+}
+
+// indexFrom returns the position of r from start, or the end of the input
+func (l *shellLexer) indexFrom(start int, r rune) int {
+	for i := start; i < len(l.input); i++ {
+		if l.input[i] == r {
+			return i
+		}
+	}
+	return len(l.input)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DockerImage describes an allowed image, optionally pinned to a digest
+type DockerImage struct {
+	Name        string `json:"name"`
+	Digest      string `json:"digest,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// DockerImagesFile is the format of the file referenced by DOCKER_IMAGES_FILE
+type DockerImagesFile struct {
+	// ReplaceDefaults drops the built-in AllowedDockerImages when true
+	ReplaceDefaults bool          `json:"replaceDefaults"`
+	Images          []DockerImage `json:"images"`
+}
+
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// pinnedDockerImages maps an allowed image name to the digest it must run at
+var pinnedDockerImages = map[string]string{}
+
+// customDockerImages are the images registered with a description,
+// offered to the model when it picks the image for a flow
+var customDockerImages []DockerImage
+
+// LoadDockerImages loads the image allow-list from a JSON file
+// It must be called at startup, before any flow is processed
+func LoadDockerImages(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading docker images file: %w", err)
+	}
+
+	var file DockerImagesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("error parsing docker images file: %w", err)
+	}
+
+	for _, img := range file.Images {
+		if strings.TrimSpace(img.Name) == "" {
+			return fmt.Errorf("docker image entry without name in %s", path)
+		}
+		if strings.Contains(img.Name, "@") {
+			return fmt.Errorf("docker image %s: use the digest field instead of name@digest", img.Name)
+		}
+		if img.Digest != "" && !digestPattern.MatchString(img.Digest) {
+			return fmt.Errorf("docker image %s: invalid digest %q", img.Name, img.Digest)
+		}
+	}
+
+	if file.ReplaceDefaults {
+		AllowedDockerImages = map[string]bool{}
+	}
+
+	for _, img := range file.Images {
+		name := strings.TrimSpace(img.Name)
+		AllowedDockerImages[name] = true
+		if img.Digest != "" {
+			pinnedDockerImages[name] = img.Digest
+		}
+		if img.Description != "" {
+			customDockerImages = append(customDockerImages, DockerImage{
+				Name:        name,
+				Digest:      img.Digest,
+				Description: img.Description,
+			})
+		}
+	}
+
+	return nil
+}
+
+// ResolveDockerImage validates an image chosen for a flow and returns the
+// reference that should be run, pinned to its digest when one is configured
+func ResolveDockerImage(image string) (string, error) {
+	// Models sometimes wrap the name in quotes or backticks
+	image = strings.Trim(strings.TrimSpace(image), "`\"'")
+
+	if err := ValidateDockerImage(image); err != nil {
+		return "", err
+	}
+
+	name, _ := splitImageDigest(image)
+	if digest, ok := pinnedDockerImages[name]; ok {
+		return name + "@" + digest, nil
+	}
+
+	return image, nil
+}
+
+// splitImageDigest splits "name@sha256:..." into its name and digest
+func splitImageDigest(image string) (name string, digest string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
+
+// DescribedDockerImages returns the registered images that have a description
+func DescribedDockerImages() []DockerImage {
+	images := make([]DockerImage, len(customDockerImages))
+	copy(images, customDockerImages)
+	sort.Slice(images, func(i, j int) bool { return images[i].Name < images[j].Name })
+	return images
+}
+
+// AllowedDockerImageNames returns the sorted names of all allowed images
+func AllowedDockerImageNames() []string {
+	names := make([]string, 0, len(AllowedDockerImages))
+	for name, allowed := range AllowedDockerImages {
+		if allowed {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,139 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arandu-ai/arandu/config"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// resetDockerImages restores the built-in allow-list after a test
+func resetDockerImages(t *testing.T) {
+	original := make(map[string]bool, len(AllowedDockerImages))
+	for k, v := range AllowedDockerImages {
+		original[k] = v
+	}
+	allowAny := config.Config.AllowAnyDockerImage
+
+	t.Cleanup(func() {
+		AllowedDockerImages = original
+		pinnedDockerImages = map[string]string{}
+		customDockerImages = nil
+		config.Config.AllowAnyDockerImage = allowAny
+	})
+}
+
+func writeImagesFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "images.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write images file: %v", err)
+	}
+	return path
+}
+
+func TestLoadDockerImages(t *testing.T) {
+	resetDockerImages(t)
+	config.Config.AllowAnyDockerImage = false
+
+	path := writeImagesFile(t, `{
+		"images": [
+			{"name": "registry.example.com/ml/base:1.4", "description": "PyTorch with CUDA"},
+			{"name": "python:3.12", "digest": "`+testDigest+`"}
+		]
+	}`)
+
+	if err := LoadDockerImages(path); err != nil {
+		t.Fatalf("LoadDockerImages() error = %v", err)
+	}
+
+	if err := ValidateDockerImage("registry.example.com/ml/base:1.4"); err != nil {
+		t.Errorf("custom image should be allowed: %v", err)
+	}
+	if err := ValidateDockerImage("node:latest"); err != nil {
+		t.Errorf("built-in images should be kept: %v", err)
+	}
+
+	described := DescribedDockerImages()
+	if len(described) != 1 || described[0].Description != "PyTorch with CUDA" {
+		t.Errorf("DescribedDockerImages() = %+v", described)
+	}
+}
+
+func TestLoadDockerImages_ReplaceDefaults(t *testing.T) {
+	resetDockerImages(t)
+	config.Config.AllowAnyDockerImage = false
+
+	path := writeImagesFile(t, `{"replaceDefaults": true, "images": [{"name": "debian:latest"}]}`)
+	if err := LoadDockerImages(path); err != nil {
+		t.Fatalf("LoadDockerImages() error = %v", err)
+	}
+
+	if err := ValidateDockerImage("node:latest"); err == nil {
+		t.Error("built-in images should be dropped with replaceDefaults")
+	}
+	if names := AllowedDockerImageNames(); len(names) != 1 || names[0] != "debian:latest" {
+		t.Errorf("AllowedDockerImageNames() = %v", names)
+	}
+}
+
+func TestLoadDockerImages_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "invalid JSON", content: `{images`},
+		{name: "missing name", content: `{"images": [{"description": "no name"}]}`},
+		{name: "invalid digest", content: `{"images": [{"name": "python:3.12", "digest": "sha256:abc"}]}`},
+		{name: "digest in name", content: `{"images": [{"name": "python@` + testDigest + `"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetDockerImages(t)
+			if err := LoadDockerImages(writeImagesFile(t, tt.content)); err == nil {
+				t.Error("LoadDockerImages() should fail")
+			}
+		})
+	}
+
+	if err := LoadDockerImages(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadDockerImages() should fail for a missing file")
+	}
+}
+
+func TestResolveDockerImage(t *testing.T) {
+	resetDockerImages(t)
+	config.Config.AllowAnyDockerImage = false
+	pinnedDockerImages["python:3.12"] = testDigest
+
+	tests := []struct {
+		name    string
+		image   string
+		want    string
+		wantErr bool
+	}{
+		{name: "unpinned image", image: "node:20", want: "node:20"},
+		{name: "trims model formatting", image: " `node:latest`\n", want: "node:latest"},
+		{name: "pinned image", image: "python:3.12", want: "python:3.12@" + testDigest},
+		{name: "matching digest", image: "python:3.12@" + testDigest, want: "python:3.12@" + testDigest},
+		{name: "mismatching digest", image: "python:3.12@sha256:" + strings.Repeat("f", 64), wantErr: true},
+		{name: "hallucinated image", image: "totally-real/agent-image:9", wantErr: true},
+		{name: "empty image", image: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveDockerImage(tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveDockerImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveDockerImage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package security
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// injectionPatterns match text that addresses the agent instead of the reader
+// They are heuristics: a page about prompt injection is flagged too
+var injectionPatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"ignore_instructions", regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override|bypass)\b[^.\n]{0,40}\b(?:previous|prior|above|earlier|all|any|your|the|system)\b[^.\n]{0,20}\b(?:instructions?|prompts?|rules|directions|guidelines)\b`)},
+	{"role_override", regexp.MustCompile(`(?i)\b(?:you are now|from now on,? you|new instructions|updated instructions|developer mode|jailbreak|act as an? (?:unrestricted|unfiltered|jailbroken))`)},
+	{"fake_role_marker", regexp.MustCompile(`(?i)<\|im_(?:start|end)\|>|\[/?INST\]|<</?SYS>>|</?(?:system|assistant)>|(?m:^[ \t#*]*(?:system|assistant|developer)[ \t]+(?:prompt|message|instructions?)[ \t]*:)`)},
+	{"addresses_agent", regexp.MustCompile(`(?i)\b(?:note|message|instructions?|attention)\s+(?:to|for)\s+(?:the\s+)?(?:ai|assistant|agent|llm|language model|chatbot)\b|\bif you are an? (?:ai|llm|language model|assistant|agent)\b`)},
+	{"exfiltration", regexp.MustCompile(`(?i)\b(?:send|post|upload|exfiltrate|forward|leak|transmit|email)\b[^\n]{0,60}(?:\b(?:passwords?|secrets?|tokens?|credentials?|api[ _-]?keys?|private keys?|ssh keys?|environment variables|cookies)\b|\.env\b)`)},
+	{"command_injection", regexp.MustCompile(`(?i)\b(?:curl|wget)\b[^\n]{0,200}\|\s*(?:ba|z)?sh\b|\b(?:run|execute)\s+(?:the following|this)\s+(?:command|script|code)\b`)},
+}
+
+// DetectInjection returns the names of the heuristics that find
+// instruction-like text in untrusted content, sorted
+func DetectInjection(text string) []string {
+	var flags []string
+	for _, p := range injectionPatterns {
+		if p.re.MatchString(text) {
+			flags = append(flags, p.name)
+		}
+	}
+	if hasHiddenText(text) {
+		flags = append(flags, "hidden_text")
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// hasHiddenText reports characters that render as nothing but models read:
+// Unicode tags, bidirectional overrides and runs of zero-width characters
+func hasHiddenText(text string) bool {
+	zeroWidth := 0
+	for _, r := range text {
+		switch {
+		case r >= 0xE0000 && r <= 0xE007F:
+			return true
+		case r >= 0x202A && r <= 0x202E, r >= 0x2066 && r <= 0x2069:
+			return true
+		case r == 0x200B, r == 0x200C, r == 0x200D, r == 0x2060, r == 0xFEFF:
+			zeroWidth++
+			if zeroWidth >= 3 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// egressCommandPattern matches tools that open connections to other hosts
+var egressCommandPattern = regexp.MustCompile("(?:^|[\\s;&|(`$])(curl|wget|nc|ncat|netcat|socat|ssh|scp|sftp|rsync|ftp|tftp|telnet)(?:\\s|$)|\\bgit\\s+(push)\\b|/dev/(tcp|udp)/")
+
+// EgressCommand returns the network tool a shell command uses, or ""
+func EgressCommand(command string) string {
+	match := egressCommandPattern.FindStringSubmatch(command)
+	if match == nil {
+		return ""
+	}
+	for _, group := range match[1:] {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+var (
+	redirectPattern = regexp.MustCompile(`(?:\d?>>?|&>)\s*([^\s;&|<>()]+)`)
+	teePattern      = regexp.MustCompile(`\btee\s+(?:-\S+\s+)*([^\s;&|<>()]+)`)
+	commandSplit    = regexp.MustCompile(`&&|\|\||[;|&\n]`)
+)
+
+// copyCommands write to their last argument
+var copyCommands = map[string]bool{"cp": true, "mv": true, "install": true, "ln": true, "rsync": true}
+
+// WritesOutside returns the absolute paths outside dir that a shell command
+// writes to through redirections, tee, cp, mv, install, ln, rsync or dd
+// Relative paths are assumed to be inside dir
+func WritesOutside(command string, dir string) []string {
+	var targets []string
+	for _, m := range redirectPattern.FindAllStringSubmatch(command, -1) {
+		targets = append(targets, m[1])
+	}
+	for _, m := range teePattern.FindAllStringSubmatch(command, -1) {
+		targets = append(targets, m[1])
+	}
+	for _, segment := range commandSplit.Split(command, -1) {
+		fields := strings.Fields(segment)
+		if len(fields) > 0 && fields[0] == "sudo" {
+			fields = fields[1:]
+		}
+		if len(fields) < 3 {
+			continue
+		}
+		if copyCommands[fields[0]] {
+			targets = append(targets, fields[len(fields)-1])
+		}
+		if fields[0] == "dd" {
+			for _, f := range fields[1:] {
+				if strings.HasPrefix(f, "of=") {
+					targets = append(targets, strings.TrimPrefix(f, "of="))
+				}
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	var outside []string
+	for _, target := range targets {
+		target = strings.Trim(target, `"'`)
+		if !isOutside(target, dir) || seen[target] {
+			continue
+		}
+		seen[target] = true
+		outside = append(outside, target)
+	}
+	sort.Strings(outside)
+	return outside
+}
+
+// PathOutside reports whether a file path points outside dir
+// Relative paths are resolved against dir
+func PathOutside(target string, dir string) bool {
+	if !strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "~") {
+		target = path.Join(dir, target)
+	}
+	return isOutside(target, dir)
+}
+
+// isOutside reports whether an absolute or home path is outside dir
+// Standard streams and /dev/null are not files
+func isOutside(target string, dir string) bool {
+	if strings.HasPrefix(target, "~") {
+		return true
+	}
+	if !strings.HasPrefix(target, "/") {
+		return false
+	}
+	target = path.Clean(target)
+	switch {
+	case target == "/dev/null", target == "/dev/stdout", target == "/dev/stderr",
+		strings.HasPrefix(target, "/dev/fd/"), strings.HasPrefix(target, "/proc/self/fd/"):
+		return false
+	}
+	dir = path.Clean(dir)
+	return target != dir && !strings.HasPrefix(target, dir+"/")
+}
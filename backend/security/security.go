@@ -2,6 +2,7 @@ package security
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"path/filepath"
 	"regexp"
@@ -145,6 +146,12 @@ func ValidateDockerImage(image string) error {
 		return fmt.Errorf("docker image cannot be empty")
 	}
 
+	// A digest reference must match the pinned digest, if there is one
+	image, digest := splitImageDigest(image)
+	if pinned, ok := pinnedDockerImages[image]; ok && digest != "" && digest != pinned {
+		return fmt.Errorf("docker image %s is pinned to %s, got %s", image, pinned, digest)
+	}
+
 	// Allow any image in development mode
 	if config.Config.AllowAnyDockerImage {
 		return nil
@@ -164,6 +171,36 @@ func ValidateDockerImage(image string) error {
 	return fmt.Errorf("docker image not in whitelist: %s. Set ALLOW_ANY_DOCKER_IMAGE=true to allow any image", image)
 }
 
+// IsHostAllowed checks if a host is covered by an egress allow-list
+// Entries match the exact host; entries starting with "." also match any subdomain
+func IsHostAllowed(host string, allowList []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" {
+		return false
+	}
+
+	for _, entry := range allowList {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if strings.HasPrefix(entry, ".") {
+			if host == entry[1:] || strings.HasSuffix(host, entry) {
+				return true
+			}
+			continue
+		}
+		if host == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
 // SanitizeLogMessage removes sensitive data from log messages
 func SanitizeLogMessage(message string) string {
 	// Patterns for sensitive data
@@ -255,6 +255,34 @@ func TestSanitizeLogMessage(t *testing.T) {
 	}
 }
 
+func TestIsHostAllowed(t *testing.T) {
+	allowList := []string{"pypi.org", ".npmjs.org", " GitHub.com "}
+
+	tests := []struct {
+		name    string
+		host    string
+		allowed bool
+	}{
+		{name: "exact match", host: "pypi.org", allowed: true},
+		{name: "exact match with port", host: "pypi.org:443", allowed: true},
+		{name: "exact entry does not match subdomain", host: "files.pypi.org", allowed: false},
+		{name: "wildcard entry matches subdomain", host: "registry.npmjs.org", allowed: true},
+		{name: "wildcard entry matches apex", host: "npmjs.org", allowed: true},
+		{name: "suffix without dot boundary", host: "evilnpmjs.org", allowed: false},
+		{name: "case insensitive", host: "GITHUB.COM", allowed: true},
+		{name: "unlisted host", host: "example.com", allowed: false},
+		{name: "empty host", host: "", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsHostAllowed(tt.host, allowList); got != tt.allowed {
+				t.Errorf("IsHostAllowed(%q) = %v, want %v", tt.host, got, tt.allowed)
+			}
+		})
+	}
+}
+
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
 		(len(s) > 0 && len(substr) > 0 && findSubstring(s, substr)))
@@ -0,0 +1,16 @@
+// This script runs the code requested by the agent and serializes its result
+
+async (source) => {
+  const result = await (0, eval)(source);
+  if (result === undefined) {
+    return "undefined";
+  }
+  if (typeof result === "string") {
+    return result;
+  }
+  try {
+    return JSON.stringify(result, null, 2);
+  } catch (e) {
+    return String(result);
+  }
+};
@@ -0,0 +1,166 @@
+// This script is injected into the page and converts its main content to Markdown
+
+() => {
+  const SKIP = new Set([
+    "SCRIPT", "STYLE", "NOSCRIPT", "TEMPLATE", "SVG", "CANVAS", "IFRAME",
+    "NAV", "FOOTER", "ASIDE", "FORM", "BUTTON", "SELECT", "OPTION", "DIALOG",
+  ]);
+  const BOILERPLATE = /(^|[\s_-])(nav|navbar|menu|sidebar|footer|header|breadcrumb|cookie|banner|advert|ads|social|share|related|comments?|toc)($|[\s_-])/i;
+
+  function hidden(el) {
+    if (el.hidden || el.getAttribute("aria-hidden") === "true") return true;
+    const style = window.getComputedStyle(el);
+    return style.display === "none" || style.visibility === "hidden";
+  }
+
+  function boilerplate(el) {
+    if (el.tagName === "MAIN" || el.tagName === "ARTICLE") return false;
+    const role = el.getAttribute("role") || "";
+    if (["navigation", "banner", "contentinfo", "complementary", "search"].includes(role)) return true;
+    return BOILERPLATE.test((el.id || "") + " " + (typeof el.className === "string" ? el.className : ""));
+  }
+
+  // Readability-style: prefer semantic containers, otherwise the block with most paragraph text
+  function mainContent() {
+    const semantic = document.querySelector("main, article, [role=main]");
+    if (semantic && semantic.innerText.trim().length > 200) return semantic;
+
+    let best = document.body;
+    let bestScore = 0;
+    for (const el of document.body.querySelectorAll("div, section, td")) {
+      if (boilerplate(el)) continue;
+      let score = 0;
+      for (const child of el.children) {
+        if (["P", "PRE", "UL", "OL", "TABLE", "H1", "H2", "H3", "BLOCKQUOTE"].includes(child.tagName)) {
+          score += child.innerText.length;
+        }
+      }
+      if (score > bestScore) {
+        best = el;
+        bestScore = score;
+      }
+    }
+    return bestScore > 200 ? best : document.body;
+  }
+
+  function inline(text) {
+    return text.replace(/\s+/g, " ");
+  }
+
+  function codeLanguage(el) {
+    const code = el.querySelector("code") || el;
+    const match = ((code.className || "") + " " + (el.className || "")).match(/(?:language|lang|highlight)-([\w+#-]+)/);
+    return match ? match[1] : "";
+  }
+
+  function fence(text) {
+    let fence = "```";
+    while (text.includes(fence)) fence += "`";
+    return fence;
+  }
+
+  function table(el) {
+    const rows = Array.from(el.querySelectorAll("tr")).map((tr) =>
+      Array.from(tr.querySelectorAll("th, td")).map((cell) =>
+        inline(cell.innerText).trim().replaceAll("|", "\\|"),
+      ),
+    ).filter((cells) => cells.length > 0);
+    if (rows.length === 0) return "";
+
+    const width = Math.max(...rows.map((cells) => cells.length));
+    const line = (cells) => "| " + Array.from({ length: width }, (_, i) => cells[i] || "").join(" | ") + " |";
+    const out = [line(rows[0]), line(Array(width).fill("---"))];
+    for (const cells of rows.slice(1)) out.push(line(cells));
+    return "\n\n" + out.join("\n") + "\n\n";
+  }
+
+  function list(el, depth) {
+    const ordered = el.tagName === "OL";
+    let i = 0;
+    let out = "\n";
+    for (const item of el.children) {
+      if (item.tagName !== "LI") continue;
+      i++;
+      const marker = ordered ? `${i}.` : "-";
+      const body = children(item, depth + 1).trim().replace(/\n+/g, (m) => m + "  ".repeat(depth + 1));
+      out += "  ".repeat(depth) + marker + " " + body + "\n";
+    }
+    return out + "\n";
+  }
+
+  function children(el, depth) {
+    let out = "";
+    for (const child of el.childNodes) out += convert(child, depth);
+    return out;
+  }
+
+  function convert(node, depth) {
+    if (node.nodeType === Node.TEXT_NODE) return inline(node.nodeValue);
+    if (node.nodeType !== Node.ELEMENT_NODE) return "";
+
+    const el = node;
+    if (SKIP.has(el.tagName) || hidden(el) || boilerplate(el)) return "";
+
+    switch (el.tagName) {
+      case "H1": case "H2": case "H3": case "H4": case "H5": case "H6": {
+        const text = inline(el.innerText).trim();
+        return text ? "\n\n" + "#".repeat(Number(el.tagName[1])) + " " + text + "\n\n" : "";
+      }
+      case "P":
+        return "\n\n" + children(el, depth).trim() + "\n\n";
+      case "BR":
+        return "\n";
+      case "HR":
+        return "\n\n---\n\n";
+      case "PRE": {
+        const text = el.innerText.replace(/\n+$/, "");
+        const f = fence(text);
+        return "\n\n" + f + codeLanguage(el) + "\n" + text + "\n" + f + "\n\n";
+      }
+      case "CODE": {
+        const text = el.innerText;
+        const tick = text.includes("`") ? "``" : "`";
+        return tick + text + tick;
+      }
+      case "STRONG": case "B": {
+        const text = children(el, depth).trim();
+        return text ? "**" + text + "**" : "";
+      }
+      case "EM": case "I": {
+        const text = children(el, depth).trim();
+        return text ? "_" + text + "_" : "";
+      }
+      case "A": {
+        const text = children(el, depth).trim();
+        const href = el.href;
+        if (!text) return "";
+        if (!href || href.startsWith("javascript:") || el.getAttribute("href").startsWith("#")) return text;
+        return "[" + text + "](" + href + ")";
+      }
+      case "IMG": {
+        const alt = (el.getAttribute("alt") || "").trim();
+        return alt ? "![" + inline(alt) + "](" + el.src + ")" : "";
+      }
+      case "UL": case "OL":
+        return list(el, depth);
+      case "TABLE":
+        return table(el);
+      case "BLOCKQUOTE":
+        return "\n\n" + children(el, depth).trim().split("\n").map((l) => "> " + l).join("\n") + "\n\n";
+      case "DIV": case "SECTION": case "ARTICLE": case "MAIN": case "HEADER":
+      case "DL": case "DT": case "DD": case "FIGURE": case "FIGCAPTION": case "DETAILS": case "SUMMARY":
+        return "\n\n" + children(el, depth) + "\n\n";
+      default:
+        return children(el, depth);
+    }
+  }
+
+  const root = mainContent();
+  const title = document.title ? "# " + inline(document.title).trim() + "\n\n" : "";
+  const body = convert(root, 0)
+    .replace(/[ \t]+\n/g, "\n")
+    .replace(/\n{3,}/g, "\n\n")
+    .trim();
+
+  return title + body;
+};
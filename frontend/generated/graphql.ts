@@ -57,9 +57,11 @@ export type Model = {
 export type Mutation = {
   __typename?: 'Mutation';
   _exec: Scalars['String']['output'];
+  approveTask: Task;
   createFlow: Flow;
   createTask: Task;
   finishFlow: Flow;
+  rejectTask: Task;
 };
 
 
@@ -69,6 +71,11 @@ export type Mutation_ExecArgs = {
 };
 
 
+export type MutationApproveTaskArgs = {
+  taskId: Scalars['Uint']['input'];
+};
+
+
 export type MutationCreateFlowArgs = {
   modelId: Scalars['String']['input'];
   modelProvider: Scalars['String']['input'];
@@ -85,6 +92,12 @@ export type MutationFinishFlowArgs = {
   flowId: Scalars['Uint']['input'];
 };
 
+
+export type MutationRejectTaskArgs = {
+  reason?: InputMaybe<Scalars['String']['input']>;
+  taskId: Scalars['Uint']['input'];
+};
+
 export type Query = {
   __typename?: 'Query';
   availableModels: Array<Model>;
@@ -122,21 +135,36 @@ export type SubscriptionTaskAddedArgs = {
 };
 
 
+export type SubscriptionTaskUpdatedArgs = {
+  flowId: Scalars['Uint']['input'];
+};
+
+
 export type SubscriptionTerminalLogsAddedArgs = {
   flowId: Scalars['Uint']['input'];
 };
 
 export type Task = {
   __typename?: 'Task';
+  approval?: Maybe<TaskApproval>;
   args: Scalars['JSON']['output'];
   createdAt: Scalars['Time']['output'];
   id: Scalars['Uint']['output'];
+  injectionFlags: Array<Scalars['String']['output']>;
   message: Scalars['String']['output'];
+  redactions: Scalars['JSON']['output'];
   results: Scalars['JSON']['output'];
   status: TaskStatus;
   type: TaskType;
+  untrusted: Scalars['Boolean']['output'];
 };
 
+export enum TaskApproval {
+  Approved = 'approved',
+  Pending = 'pending',
+  Rejected = 'rejected'
+}
+
 export enum TaskStatus {
   Failed = 'failed',
   Finished = 'finished',
@@ -148,8 +176,11 @@ export enum TaskType {
   Ask = 'ask',
   Browser = 'browser',
   Code = 'code',
+  Custom = 'custom',
   Done = 'done',
   Input = 'input',
+  Mcp = 'mcp',
+  Search = 'search',
   Terminal = 'terminal'
 }
 
@@ -194,6 +225,9 @@ export const TaskFragmentFragmentDoc = gql`
   args
   results
   createdAt
+  untrusted
+  injectionFlags
+  approval
 }
     `;
 export const FlowFragmentFragmentDoc = gql`
@@ -290,6 +324,28 @@ export const FinishFlowDocument = gql`
 export function useFinishFlowMutation() {
   return Urql.useMutation<FinishFlowMutation, FinishFlowMutationVariables>(FinishFlowDocument);
 };
+export const ApproveTaskDocument = gql`
+    mutation approveTask($taskId: Uint!) {
+  approveTask(taskId: $taskId) {
+    ...taskFragment
+  }
+}
+    ${TaskFragmentFragmentDoc}`;
+
+export function useApproveTaskMutation() {
+  return Urql.useMutation<ApproveTaskMutation, ApproveTaskMutationVariables>(ApproveTaskDocument);
+};
+export const RejectTaskDocument = gql`
+    mutation rejectTask($taskId: Uint!, $reason: String) {
+  rejectTask(taskId: $taskId, reason: $reason) {
+    ...taskFragment
+  }
+}
+    ${TaskFragmentFragmentDoc}`;
+
+export function useRejectTaskMutation() {
+  return Urql.useMutation<RejectTaskMutation, RejectTaskMutationVariables>(RejectTaskDocument);
+};
 export const TaskAddedDocument = gql`
     subscription taskAdded($flowId: Uint!) {
   taskAdded(flowId: $flowId) {
@@ -301,6 +357,17 @@ export const TaskAddedDocument = gql`
 export function useTaskAddedSubscription<TData = TaskAddedSubscription>(options: Omit<Urql.UseSubscriptionArgs<TaskAddedSubscriptionVariables>, 'query'>, handler?: Urql.SubscriptionHandler<TaskAddedSubscription, TData>) {
   return Urql.useSubscription<TaskAddedSubscription, TData, TaskAddedSubscriptionVariables>({ query: TaskAddedDocument, ...options }, handler);
 };
+export const TaskUpdatedDocument = gql`
+    subscription taskUpdated($flowId: Uint!) {
+  taskUpdated(flowId: $flowId) {
+    ...taskFragment
+  }
+}
+    ${TaskFragmentFragmentDoc}`;
+
+export function useTaskUpdatedSubscription<TData = TaskUpdatedSubscription>(options: Omit<Urql.UseSubscriptionArgs<TaskUpdatedSubscriptionVariables>, 'query'>, handler?: Urql.SubscriptionHandler<TaskUpdatedSubscription, TData>) {
+  return Urql.useSubscription<TaskUpdatedSubscription, TData, TaskUpdatedSubscriptionVariables>({ query: TaskUpdatedDocument, ...options }, handler);
+};
 export const TerminalLogsAddedDocument = gql`
     subscription terminalLogsAdded($flowId: Uint!) {
   terminalLogsAdded(flowId: $flowId) {
@@ -353,20 +420,20 @@ export type AvailableModelsQueryVariables = Exact<{ [key: string]: never; }>;
 
 export type AvailableModelsQuery = { __typename?: 'Query', availableModels: Array<{ __typename?: 'Model', id: string, provider: string }> };
 
-export type TaskFragmentFragment = { __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any };
+export type TaskFragmentFragment = { __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any, untrusted: boolean, injectionFlags: Array<string>, approval?: TaskApproval | null };
 
 export type LogFragmentFragment = { __typename?: 'Log', text: string, id: any };
 
 export type BrowserFragmentFragment = { __typename?: 'Browser', url: string, screenshotUrl: string };
 
-export type FlowFragmentFragment = { __typename?: 'Flow', id: any, name: string, status: FlowStatus, model: { __typename?: 'Model', id: string, provider: string }, terminal: { __typename?: 'Terminal', containerName: string, connected: boolean, logs: Array<{ __typename?: 'Log', text: string, id: any }> }, browser: { __typename?: 'Browser', url: string, screenshotUrl: string }, tasks: Array<{ __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any }> };
+export type FlowFragmentFragment = { __typename?: 'Flow', id: any, name: string, status: FlowStatus, model: { __typename?: 'Model', id: string, provider: string }, terminal: { __typename?: 'Terminal', containerName: string, connected: boolean, logs: Array<{ __typename?: 'Log', text: string, id: any }> }, browser: { __typename?: 'Browser', url: string, screenshotUrl: string }, tasks: Array<{ __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any, untrusted: boolean, injectionFlags: Array<string>, approval?: TaskApproval | null }> };
 
 export type FlowQueryVariables = Exact<{
   id: Scalars['Uint']['input'];
 }>;
 
 
-export type FlowQuery = { __typename?: 'Query', flow: { __typename?: 'Flow', id: any, name: string, status: FlowStatus, model: { __typename?: 'Model', id: string, provider: string }, terminal: { __typename?: 'Terminal', containerName: string, connected: boolean, logs: Array<{ __typename?: 'Log', text: string, id: any }> }, browser: { __typename?: 'Browser', url: string, screenshotUrl: string }, tasks: Array<{ __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any }> } };
+export type FlowQuery = { __typename?: 'Query', flow: { __typename?: 'Flow', id: any, name: string, status: FlowStatus, model: { __typename?: 'Model', id: string, provider: string }, terminal: { __typename?: 'Terminal', containerName: string, connected: boolean, logs: Array<{ __typename?: 'Log', text: string, id: any }> }, browser: { __typename?: 'Browser', url: string, screenshotUrl: string }, tasks: Array<{ __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any, untrusted: boolean, injectionFlags: Array<string>, approval?: TaskApproval | null }> } };
 
 export type CreateFlowMutationVariables = Exact<{
   modelProvider: Scalars['String']['input'];
@@ -382,7 +449,7 @@ export type CreateTaskMutationVariables = Exact<{
 }>;
 
 
-export type CreateTaskMutation = { __typename?: 'Mutation', createTask: { __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any } };
+export type CreateTaskMutation = { __typename?: 'Mutation', createTask: { __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any, untrusted: boolean, injectionFlags: Array<string>, approval?: TaskApproval | null } };
 
 export type FinishFlowMutationVariables = Exact<{
   flowId: Scalars['Uint']['input'];
@@ -391,12 +458,34 @@ export type FinishFlowMutationVariables = Exact<{
 
 export type FinishFlowMutation = { __typename?: 'Mutation', finishFlow: { __typename?: 'Flow', id: any, status: FlowStatus } };
 
+export type ApproveTaskMutationVariables = Exact<{
+  taskId: Scalars['Uint']['input'];
+}>;
+
+
+export type ApproveTaskMutation = { __typename?: 'Mutation', approveTask: { __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any, untrusted: boolean, injectionFlags: Array<string>, approval?: TaskApproval | null } };
+
+export type RejectTaskMutationVariables = Exact<{
+  taskId: Scalars['Uint']['input'];
+  reason?: InputMaybe<Scalars['String']['input']>;
+}>;
+
+
+export type RejectTaskMutation = { __typename?: 'Mutation', rejectTask: { __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any, untrusted: boolean, injectionFlags: Array<string>, approval?: TaskApproval | null } };
+
 export type TaskAddedSubscriptionVariables = Exact<{
   flowId: Scalars['Uint']['input'];
 }>;
 
 
-export type TaskAddedSubscription = { __typename?: 'Subscription', taskAdded: { __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any } };
+export type TaskAddedSubscription = { __typename?: 'Subscription', taskAdded: { __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any, untrusted: boolean, injectionFlags: Array<string>, approval?: TaskApproval | null } };
+
+export type TaskUpdatedSubscriptionVariables = Exact<{
+  flowId: Scalars['Uint']['input'];
+}>;
+
+
+export type TaskUpdatedSubscription = { __typename?: 'Subscription', taskUpdated: { __typename?: 'Task', id: any, type: TaskType, message: string, status: TaskStatus, args: any, results: any, createdAt: any, untrusted: boolean, injectionFlags: Array<string>, approval?: TaskApproval | null } };
 
 export type TerminalLogsAddedSubscriptionVariables = Exact<{
   flowId: Scalars['Uint']['input'];
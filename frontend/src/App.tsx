@@ -11,6 +11,7 @@ import { Provider as GraphqlProvider } from "urql";
 import { graphqlClient } from "./graphql";
 import { AppLayout } from "./layouts/AppLayout/AppLayout";
 import { ChatPage } from "./pages/ChatPage/ChatPage";
+import { LoginPage } from "./pages/LoginPage/LoginPage";
 import "./styles/font.css.ts";
 import "./styles/global.css.ts";
 import "./styles/theme.css.ts";
@@ -21,6 +22,7 @@ export const router = createBrowserRouter(
       <Route element={<AppLayout />}>
         <Route path="/chat/:id?" element={<ChatPage />} />
       </Route>
+      <Route path="/login" element={<LoginPage />} />
       <Route path="*" element={<Navigate to="/chat" />} />
     </>,
   ),
@@ -72,6 +72,17 @@ export const messageStyles = styleVariants({
       },
     },
   ],
+  Flagged: [
+    messageStylesBase,
+    {
+      border: `1px solid ${vars.color.warning4}`,
+      background: vars.color.warning2,
+      ":hover": {
+        background: vars.color.warning3,
+        border: `1px solid ${vars.color.warning6}`,
+      },
+    },
+  ],
 });
 
 export const contentStyles = style({
@@ -119,3 +130,20 @@ export const outputStyles = style([
     background: vars.color.gray2,
   },
 ]);
+
+export const flagsStyles = style([
+  font.textXsRegular,
+  {
+    color: vars.color.warning11,
+  },
+]);
+
+export const approvalStyles = style([
+  font.textXsRegular,
+  {
+    display: "flex",
+    gap: 8,
+    alignItems: "center",
+    color: vars.color.gray11,
+  },
+]);
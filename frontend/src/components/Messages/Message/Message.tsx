@@ -7,11 +7,13 @@ import logoPng from "@/assets/logo.png";
 import mePng from "@/assets/me.png";
 import { Button } from "@/components/Button/Button";
 import { Icon } from "@/components/Icon/Icon";
-import { TaskStatus, TaskType } from "@/generated/graphql";
+import { TaskApproval, TaskStatus, TaskType } from "@/generated/graphql";
 
 import {
+  approvalStyles,
   avatarStyles,
   contentStyles,
+  flagsStyles,
   iconStyles,
   messageStyles,
   outputStyles,
@@ -22,8 +24,11 @@ import {
 
 const taskTypeIcons: Record<TaskType, React.ReactNode> = {
   [TaskType.Browser]: <Icon.Browser />,
+  [TaskType.Search]: <Icon.Browser />,
   [TaskType.Terminal]: <Icon.Terminal />,
   [TaskType.Code]: <Icon.Code />,
+  [TaskType.Custom]: <Icon.Terminal />,
+  [TaskType.Mcp]: <Icon.Code />,
   [TaskType.Ask]: <Icon.MessageQuestion />,
   [TaskType.Done]: <Icon.CheckCircle />,
   [TaskType.Input]: null,
@@ -35,6 +40,11 @@ type MessageProps = {
   type: TaskType;
   status: TaskStatus;
   output: string;
+  untrusted?: boolean;
+  injectionFlags?: string[];
+  approval?: TaskApproval | null;
+  onApprove?: () => void;
+  onReject?: () => void;
 };
 
 export const Message = ({
@@ -43,10 +53,15 @@ export const Message = ({
   type,
   status,
   output,
+  injectionFlags = [],
+  approval,
+  onApprove,
+  onReject,
 }: MessageProps) => {
   const [isExpanded, setIsExpanded] = useState(false);
   const isInput = type === TaskType.Input;
   const isFailed = status === TaskStatus.Failed;
+  const isFlagged = injectionFlags.length > 0;
 
   const toggleExpand = () => {
     setIsExpanded((prev) => !prev);
@@ -54,7 +69,8 @@ export const Message = ({
 
   const getMessageStyle = () => {
     if (isInput) return messageStyles.Input;
-    return isFailed ? messageStyles.Failed : messageStyles.Regular;
+    if (isFailed) return messageStyles.Failed;
+    return isFlagged ? messageStyles.Flagged : messageStyles.Regular;
   };
 
   return (
@@ -83,6 +99,28 @@ export const Message = ({
             </Button>
           )}
         </div>
+        {isFlagged && (
+          <div className={flagsStyles}>
+            Possible prompt injection: {injectionFlags.join(", ")}
+          </div>
+        )}
+        {approval === TaskApproval.Pending && (
+          <div className={approvalStyles}>
+            Waiting for approval
+            <Button size="small" hierarchy="primary" onClick={onApprove}>
+              Approve
+            </Button>
+            <Button size="small" hierarchy="danger" onClick={onReject}>
+              Reject
+            </Button>
+          </div>
+        )}
+        {approval === TaskApproval.Approved && (
+          <div className={approvalStyles}>Approved</div>
+        )}
+        {approval === TaskApproval.Rejected && (
+          <div className={approvalStyles}>Rejected</div>
+        )}
         {isExpanded && <div className={outputStyles}>{output}</div>}
       </div>
     </div>
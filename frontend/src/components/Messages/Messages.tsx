@@ -18,6 +18,8 @@ type MessagesProps = {
   name: string;
   onSubmit: (message: string) => void;
   onFlowStop: () => void;
+  onTaskApprove: (taskId: string) => void;
+  onTaskReject: (taskId: string) => void;
   flowStatus?: FlowStatus;
   isNew?: boolean;
   model?: Model;
@@ -30,6 +32,8 @@ export const Messages = ({
   onSubmit,
   isNew,
   onFlowStop,
+  onTaskApprove,
+  onTaskReject,
   model,
 }: MessagesProps) => {
   const messages =
@@ -40,6 +44,9 @@ export const Messages = ({
       status: task.status,
       type: task.type,
       output: task.results,
+      untrusted: task.untrusted,
+      injectionFlags: task.injectionFlags,
+      approval: task.approval,
     })) ?? [];
 
   const messagesRef = useRef<HTMLDivElement>(null);
@@ -113,7 +120,12 @@ export const Messages = ({
         aria-label="Message history"
       >
         {messages.map((message) => (
-          <Message key={message.id} {...message} />
+          <Message
+            key={message.id}
+            {...message}
+            onApprove={() => onTaskApprove(message.id)}
+            onReject={() => onTaskReject(message.id)}
+          />
         ))}
       </div>
       <textarea
@@ -74,9 +74,21 @@ const wsClient = createWSClient({
   url: "ws://" + import.meta.env.VITE_API_URL + "/graphql",
 });
 
+// With MULTI_USER enabled the API rejects requests without a session
+const authFetch: typeof fetch = async (input, init) => {
+  const response = await fetch(input, init);
+
+  if (response.status === 401 && window.location.pathname !== "/login") {
+    window.location.assign("/login");
+  }
+
+  return response;
+};
+
 export const graphqlClient = createClient({
   url: window.location.origin + "/graphql",
-  fetchOptions: {},
+  fetchOptions: { credentials: "include" },
+  fetch: authFetch,
   exchanges: [
     devtoolsExchange,
     cache,
@@ -6,13 +6,16 @@ import {
   Log,
   Model,
   Task,
+  useApproveTaskMutation,
   useBrowserUpdatedSubscription,
   useCreateFlowMutation,
   useCreateTaskMutation,
   useFinishFlowMutation,
   useFlowQuery,
   useFlowUpdatedSubscription,
+  useRejectTaskMutation,
   useTaskAddedSubscription,
+  useTaskUpdatedSubscription,
   useTerminalLogsAddedSubscription,
 } from "@/generated/graphql";
 
@@ -43,6 +46,8 @@ export interface FlowData {
   // Actions
   handleSubmit: (message: string) => Promise<void>;
   handleFlowStop: () => void;
+  handleTaskApprove: (taskId: string) => void;
+  handleTaskReject: (taskId: string) => void;
 }
 
 export interface UseFlowDataOptions {
@@ -56,6 +61,8 @@ export function useFlowData(options: UseFlowDataOptions = {}): FlowData {
   const [, createFlowMutation] = useCreateFlowMutation();
   const [, createTaskMutation] = useCreateTaskMutation();
   const [, finishFlowMutation] = useFinishFlowMutation();
+  const [, approveTaskMutation] = useApproveTaskMutation();
+  const [, rejectTaskMutation] = useRejectTaskMutation();
   const [selectedModel] = useLocalStorage<Model>("model");
 
   const isNewFlow = !id || id === "new";
@@ -104,6 +111,11 @@ export function useFlowData(options: UseFlowDataOptions = {}): FlowData {
     pause: isNewFlow,
   });
 
+  useTaskUpdatedSubscription({
+    variables: { flowId: Number(id) },
+    pause: isNewFlow,
+  });
+
   useFlowUpdatedSubscription({
     variables: { flowId: Number(id) },
     pause: isNewFlow,
@@ -141,6 +153,14 @@ export function useFlowData(options: UseFlowDataOptions = {}): FlowData {
     finishFlowMutation({ flowId: id });
   };
 
+  const handleTaskApprove = (taskId: string) => {
+    approveTaskMutation({ taskId });
+  };
+
+  const handleTaskReject = (taskId: string) => {
+    rejectTaskMutation({ taskId });
+  };
+
   return {
     id,
     isNewFlow,
@@ -159,5 +179,7 @@ export function useFlowData(options: UseFlowDataOptions = {}): FlowData {
     },
     handleSubmit,
     handleFlowStop,
+    handleTaskApprove,
+    handleTaskReject,
   };
 }
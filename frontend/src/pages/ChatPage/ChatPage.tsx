@@ -59,6 +59,8 @@ export const ChatPage = () => {
           flowStatus={flow.status}
           isNew={flow.isNewFlow}
           onFlowStop={flow.handleFlowStop}
+          onTaskApprove={flow.handleTaskApprove}
+          onTaskReject={flow.handleTaskReject}
           model={flow.model}
         />
       </Panel>
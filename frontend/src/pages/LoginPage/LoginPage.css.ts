@@ -0,0 +1,49 @@
+import { style } from "@vanilla-extract/css";
+
+import { vars } from "@/styles/theme.css";
+
+export const wrapperStyles = style({
+  display: "flex",
+  flex: 1,
+  alignItems: "center",
+  justifyContent: "center",
+  minHeight: "100vh",
+});
+
+export const formStyles = style({
+  display: "flex",
+  flexDirection: "column",
+  gap: 12,
+  width: 320,
+});
+
+export const inputStyles = style({
+  padding: "8px 12px",
+  borderRadius: 6,
+  border: `1px solid ${vars.color.gray6}`,
+  backgroundColor: vars.color.gray2,
+  color: vars.color.gray12,
+
+  ":focus": {
+    outline: "none",
+    borderColor: vars.color.primary8,
+  },
+});
+
+export const errorStyles = style({
+  color: vars.color.error11,
+  fontSize: 14,
+});
+
+export const ssoStyles = style({
+  padding: "8px 12px",
+  borderRadius: 6,
+  border: `1px solid ${vars.color.gray6}`,
+  color: vars.color.gray12,
+  textAlign: "center",
+  textDecoration: "none",
+
+  ":hover": {
+    backgroundColor: vars.color.gray3,
+  },
+});
@@ -0,0 +1,101 @@
+import type React from "react";
+import { useEffect, useState } from "react";
+import { useNavigate, useSearchParams } from "react-router-dom";
+
+import { Button } from "@/components/Button/Button";
+
+import {
+  errorStyles,
+  formStyles,
+  inputStyles,
+  ssoStyles,
+  wrapperStyles,
+} from "./LoginPage.css";
+
+type AuthMethods = {
+  password: boolean;
+  oidc?: { name: string };
+};
+
+// Errors sent back by the single sign-on callback
+const ssoErrors: Record<string, string> = {
+  sso: "Single sign-on failed, please try again",
+  forbidden: "Your account is not allowed to use Arandu",
+};
+
+export const LoginPage = () => {
+  const navigate = useNavigate();
+  const [searchParams] = useSearchParams();
+  const [username, setUsername] = useState("");
+  const [password, setPassword] = useState("");
+  const [error, setError] = useState(
+    ssoErrors[searchParams.get("error") ?? ""] ?? "",
+  );
+  const [loading, setLoading] = useState(false);
+  const [methods, setMethods] = useState<AuthMethods>({ password: true });
+
+  useEffect(() => {
+    fetch(window.location.origin + "/auth/methods")
+      .then((response) => response.json())
+      .then(setMethods)
+      .catch(() => undefined);
+  }, []);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    setError("");
+
+    try {
+      // The server answers with an HttpOnly session cookie
+      const response = await fetch(window.location.origin + "/auth/login", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        credentials: "include",
+        body: JSON.stringify({ username, password }),
+      });
+
+      if (!response.ok) {
+        setError("Invalid username or password");
+        return;
+      }
+
+      navigate("/chat");
+    } catch {
+      setError("Could not reach the server");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className={wrapperStyles}>
+      <form className={formStyles} onSubmit={handleSubmit}>
+        <input
+          className={inputStyles}
+          placeholder="Username"
+          autoComplete="username"
+          value={username}
+          onChange={(e) => setUsername(e.target.value)}
+        />
+        <input
+          className={inputStyles}
+          type="password"
+          placeholder="Password"
+          autoComplete="current-password"
+          value={password}
+          onChange={(e) => setPassword(e.target.value)}
+        />
+        {error && <div className={errorStyles}>{error}</div>}
+        <Button type="submit" disabled={loading || !username || !password}>
+          Log in
+        </Button>
+        {methods.oidc && (
+          <a className={ssoStyles} href="/auth/oidc/login">
+            Sign in with {methods.oidc.name}
+          </a>
+        )}
+      </form>
+    </div>
+  );
+};